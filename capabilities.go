@@ -0,0 +1,105 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// Capabilities describe the parts of the provider protocol that a provider supports.
+//
+// Capabilities are advertised to the engine in response to Configure. The engine relies
+// on them to decide what it sends to the provider, so a provider should only advertise
+// what its code can handle. For example, a provider that cannot handle output values in
+// its inputs should set AcceptOutputs to false.
+type Capabilities struct {
+	// AcceptSecrets indicates that the provider can handle secret values in its inputs.
+	AcceptSecrets bool
+	// AcceptResources indicates that the provider can handle resource references in its
+	// inputs.
+	AcceptResources bool
+	// AcceptOutputs indicates that the provider can handle output values in its inputs.
+	AcceptOutputs bool
+	// SupportsPreview indicates that the provider can be called with Preview set on
+	// Create and Update.
+	SupportsPreview bool
+	// SupportsAutonamingConfiguration indicates that the provider respects the
+	// stack's autonaming configuration, passed as [CheckRequest.Autonaming].
+	//
+	// It is not enabled by [DefaultCapabilities], since a provider must handle
+	// [CheckRequest.Autonaming] itself. Providers built with infer enable it when a
+	// resource has auto-named inputs.
+	SupportsAutonamingConfiguration bool
+}
+
+// DefaultCapabilities returns the capabilities advertised by a provider that does not
+// declare its own. Every capability is enabled, except SupportsAutonamingConfiguration.
+func DefaultCapabilities() Capabilities {
+	return Capabilities{
+		AcceptSecrets:   true,
+		AcceptResources: true,
+		AcceptOutputs:   true,
+		SupportsPreview: true,
+	}
+}
+
+// WithCapabilities returns a provider that advertises capabilities to the engine instead
+// of [DefaultCapabilities]. It does not mutate its receiver.
+//
+//	provider := infer.Provider(opts).WithCapabilities(func() p.Capabilities {
+//		c := p.DefaultCapabilities()
+//		c.AcceptOutputs = false
+//		return c
+//	}())
+func (d Provider) WithCapabilities(capabilities Capabilities) Provider {
+	d.Capabilities = &capabilities
+	return d
+}
+
+func (c Capabilities) rpc() *rpc.ConfigureResponse {
+	return &rpc.ConfigureResponse{
+		AcceptSecrets:                   c.AcceptSecrets,
+		AcceptResources:                 c.AcceptResources,
+		AcceptOutputs:                   c.AcceptOutputs,
+		SupportsPreview:                 c.SupportsPreview,
+		SupportsAutonamingConfiguration: c.SupportsAutonamingConfiguration,
+	}
+}
+
+// MarshalOptions describe how property values are exchanged with the engine, as
+// negotiated when the provider is configured.
+type MarshalOptions struct {
+	// KeepSecrets indicates that secret values returned to the engine remain secret.
+	// Otherwise they are returned as plain values.
+	KeepSecrets bool
+	// KeepResources indicates that resource references are exchanged as references,
+	// rather than as their IDs.
+	KeepResources bool
+	// KeepOutputValues indicates that the engine may send output values in inputs.
+	KeepOutputValues bool
+}
+
+// negotiate returns the capabilities in effect once c has been advertised to an engine
+// that does or does not accept secrets and resource references, along with the marshal
+// options they imply.
+func (c Capabilities) negotiate(acceptSecrets, acceptResources bool) (Capabilities, MarshalOptions) {
+	c.AcceptSecrets = c.AcceptSecrets && acceptSecrets
+	c.AcceptResources = c.AcceptResources && acceptResources
+	return c, MarshalOptions{
+		KeepSecrets:      acceptSecrets,
+		KeepResources:    c.AcceptResources,
+		KeepOutputValues: c.AcceptOutputs,
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd provides a main function for providers that can also generate the
+// provider's schema and SDKs.
+//
+// A typical provider main function looks like this:
+//
+//	func main() {
+//		err := cmd.Main("my-provider", version, infer.Provider(infer.Options{...}))
+//		if err != nil {
+//			fmt.Fprintln(os.Stderr, err)
+//			os.Exit(1)
+//		}
+//	}
+//
+// Running the binary as `pulumi-resource-my-provider gen-sdk` then writes schema.json and
+// the SDKs for each language into sdk/, without needing a separate generator program.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	dotnetgen "github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
+	gogen "github.com/pulumi/pulumi/pkg/v3/codegen/go"
+	nodejsgen "github.com/pulumi/pulumi/pkg/v3/codegen/nodejs"
+	pythongen "github.com/pulumi/pulumi/pkg/v3/codegen/python"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// The tool name passed to the SDK generators, which is embedded in generated file headers.
+const tool = "pulumi-go-provider"
+
+// A Generator emits the files of an SDK for a single language, keyed by their path
+// relative to the SDK directory.
+type Generator func(pkg *schema.Package) (map[string][]byte, error)
+
+// Generators are the SDK generators used by gen-sdk, keyed by language.
+var Generators = map[string]Generator{
+	"go": func(pkg *schema.Package) (map[string][]byte, error) {
+		return gogen.GeneratePackage(tool, pkg, nil)
+	},
+	"nodejs": func(pkg *schema.Package) (map[string][]byte, error) {
+		return nodejsgen.GeneratePackage(tool, pkg, nil, nil, false)
+	},
+	"python": func(pkg *schema.Package) (map[string][]byte, error) {
+		return pythongen.GeneratePackage(tool, pkg, nil)
+	},
+	"dotnet": func(pkg *schema.Package) (map[string][]byte, error) {
+		return dotnetgen.GeneratePackage(tool, pkg, nil, nil)
+	},
+}
+
+// Main runs provider with the given name and version.
+//
+// If the first argument is "gen-sdk", Main instead writes the provider's schema and SDKs
+// into the current directory. See [GenerateSDKs].
+//
+//	gen-sdk [language...]
+//
+// When no languages are given, SDKs are generated for every language in [Generators].
+func Main(name, version string, provider p.Provider) error {
+	if len(os.Args) > 1 && os.Args[1] == "gen-sdk" {
+		return GenerateSDKs(context.Background(), name, version, provider, ".", os.Args[2:]...)
+	}
+	return p.RunProvider(name, version, provider)
+}
+
+// GenerateSDKs retrieves the schema of provider, writes it to dir/schema.json and generates
+// an SDK for each language into dir/sdk/<language>.
+//
+// If no languages are specified, SDKs are generated for every language in [Generators].
+func GenerateSDKs(
+	ctx context.Context, name, version string, provider p.Provider,
+	dir string, languages ...string,
+) error {
+	spec, err := p.GetSchema(ctx, name, version, provider)
+	if err != nil {
+		return fmt.Errorf("failed to get schema: %w", err)
+	}
+	if spec.Version == "" {
+		spec.Version = version
+	}
+
+	bytes, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "schema.json"), append(bytes, '\n'), 0o600); err != nil {
+		return err
+	}
+
+	if len(languages) == 0 {
+		for lang := range Generators {
+			languages = append(languages, lang)
+		}
+		sort.Strings(languages)
+	}
+
+	for _, lang := range languages {
+		generate, ok := Generators[lang]
+		if !ok {
+			return fmt.Errorf("unknown SDK language %q", lang)
+		}
+		// Each generator imports its own language info into the package, so each
+		// language is bound from a fresh copy of the spec.
+		pkg, diags, err := schema.BindSpec(spec, nil)
+		if err != nil {
+			return fmt.Errorf("failed to bind schema: %w", err)
+		}
+		if diags.HasErrors() {
+			return fmt.Errorf("invalid schema: %w", diags)
+		}
+		files, err := generate(pkg)
+		if err != nil {
+			return fmt.Errorf("failed to generate %s SDK: %w", lang, err)
+		}
+		if err := writeFiles(filepath.Join(dir, "sdk", lang), files); err != nil {
+			return fmt.Errorf("failed to write %s SDK: %w", lang, err)
+		}
+	}
+	return nil
+}
+
+// writeFiles replaces the contents of root with files.
+func writeFiles(root string, files map[string][]byte) error {
+	if err := os.RemoveAll(root); err != nil {
+		return err
+	}
+	for path, contents := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, contents, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
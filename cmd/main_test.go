@@ -0,0 +1,71 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type Echo struct{}
+
+type EchoArgs struct {
+	Value string `pulumi:"value"`
+}
+
+type EchoState struct {
+	EchoArgs
+}
+
+func (Echo) Create(
+	ctx context.Context, name string, input EchoArgs, preview bool,
+) (string, EchoState, error) {
+	return name, EchoState{input}, nil
+}
+
+func TestGenerateSDKs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	provider := infer.Provider(infer.Options{
+		Resources: []infer.InferredResource{infer.Resource[Echo, EchoArgs, EchoState]()},
+	})
+	err := GenerateSDKs(context.Background(), "echo", "1.2.3", provider, dir, "go", "python")
+	require.NoError(t, err)
+
+	bytes, err := os.ReadFile(filepath.Join(dir, "schema.json"))
+	require.NoError(t, err)
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal(bytes, &spec))
+	assert.Equal(t, "echo", spec.Name)
+	assert.Equal(t, "1.2.3", spec.Version)
+	assert.Contains(t, spec.Resources, "echo:cmd:Echo")
+
+	assert.FileExists(t, filepath.Join(dir, "sdk", "go", "echo", "cmd", "echo.go"))
+	assert.FileExists(t, filepath.Join(dir, "sdk", "python", "pulumi_echo", "cmd", "echo.py"))
+	assert.NoDirExists(t, filepath.Join(dir, "sdk", "nodejs"))
+
+	err = GenerateSDKs(context.Background(), "echo", "1.2.3", provider, dir, "cobol")
+	assert.ErrorContains(t, err, `unknown SDK language "cobol"`)
+}
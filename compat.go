@@ -0,0 +1,142 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/blang/semver"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// engineFeature is a part of the provider protocol that the framework relies on, but that
+// older versions of the Pulumi CLI don't implement.
+//
+// An engine that doesn't implement a feature sends requests that the framework can't
+// handle, which would otherwise surface as obscure failures deep in the SDK. Checking for
+// the feature up front lets us tell the user which CLI version they need instead.
+type engineFeature struct {
+	// name describes the feature to the user.
+	name string
+	// minCLIVersion is the first version of the Pulumi CLI that implements the feature.
+	minCLIVersion string
+}
+
+// The protocol features required by the framework, and the CLI version that introduced
+// each of them.
+var (
+	// featureSecrets is checked in Configure. An engine without it is sent secret values
+	// as plain values.
+	featureSecrets = engineFeature{"secret values", "2.0.0"}
+	// featureOutputValues is checked in Construct and Call, whose inputs and results are
+	// exchanged as output values. An engine without it is sent plain values.
+	featureOutputValues = engineFeature{"output values in Construct and Call", "3.36.0"}
+	// featureParameterize is checked in Parameterize.
+	featureParameterize = engineFeature{"parameterized providers", "3.121.0"}
+)
+
+func (f engineFeature) message() string {
+	return fmt.Sprintf("%s requires Pulumi CLI >= v%s; please upgrade the Pulumi CLI", f.name, f.minCLIVersion)
+}
+
+// unsupported returns the error reported when the engine doesn't implement f.
+func (f engineFeature) unsupported() error {
+	return status.Error(codes.FailedPrecondition, f.message())
+}
+
+// MinimumPulumiVersionLanguage is the key of the provider's minimum Pulumi CLI version in
+// the Language section of its schema, which holds a [MinimumPulumiVersionSpec].
+//
+// The meta section of a schema doesn't allow properties it doesn't define, so the version
+// is recorded alongside the language specific data, which SDK generators ignore.
+const MinimumPulumiVersionLanguage = "pulumi"
+
+// MinimumPulumiVersionSpec records the minimum Pulumi CLI version of a provider in its
+// schema.
+type MinimumPulumiVersionSpec struct {
+	MinimumVersion string `json:"minimumVersion"`
+}
+
+// WithMinimumPulumiVersion returns a provider that requires at least version of the Pulumi
+// CLI, such as "3.120.0". It does not mutate its receiver.
+//
+// The version is recorded in the schema under [MinimumPulumiVersionLanguage]. The engine
+// doesn't report its version, so it is checked by the protocol features the engine
+// implements: when the engine lacks a feature introduced by version or earlier, the
+// provider fails with a message asking the user to upgrade, instead of the protocol error
+// the missing feature would cause. An engine is checked when it configures the provider,
+// and again by requests that rely on newer features.
+func (d Provider) WithMinimumPulumiVersion(version string) Provider {
+	d.MinimumPulumiVersion = version
+	return d
+}
+
+func parseMinimumPulumiVersion(version string) (*semver.Version, error) {
+	if version == "" {
+		return nil, nil
+	}
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minimum Pulumi version %q: %w", version, err)
+	}
+	return &v, nil
+}
+
+// requires reports whether the minimum Pulumi version of the provider implements f, so an
+// engine without f is older than the provider supports.
+func (p *provider) requires(f engineFeature) bool {
+	return p.minimumVersion != nil && semver.MustParse(f.minCLIVersion).LTE(*p.minimumVersion)
+}
+
+// unsupported returns the error reported when the engine doesn't implement f.
+//
+// If the provider requires a version of the CLI that implements f, the error asks for
+// that version instead.
+func (p *provider) unsupported(f engineFeature) error {
+	if !p.requires(f) {
+		return f.unsupported()
+	}
+	return status.Errorf(codes.FailedPrecondition,
+		"%s requires Pulumi CLI >= v%s; please upgrade the Pulumi CLI", p.name, p.minimumVersion)
+}
+
+// withMinimumPulumiVersion records version in the Language section of schema.
+func withMinimumPulumiVersion(schema string, version semver.Version) (string, error) {
+	var spec map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(schema), &spec); err != nil {
+		return "", fmt.Errorf("unable to record the minimum Pulumi version in the schema: %w", err)
+	}
+	if spec == nil {
+		spec = map[string]json.RawMessage{}
+	}
+	language := map[string]json.RawMessage{}
+	if raw, ok := spec["language"]; ok {
+		if err := json.Unmarshal(raw, &language); err != nil {
+			return "", fmt.Errorf("unable to record the minimum Pulumi version in the schema: %w", err)
+		}
+	}
+	var err error
+	language[MinimumPulumiVersionLanguage], err = json.Marshal(MinimumPulumiVersionSpec{version.String()})
+	if err != nil {
+		return "", err
+	}
+	if spec["language"], err = json.Marshal(language); err != nil {
+		return "", err
+	}
+	bytes, err := json.Marshal(spec)
+	return string(bytes), err
+}
@@ -0,0 +1,157 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"sync"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// ContextDecorator is called on the [context.Context] of each request before the request
+// is handled.
+//
+// rpcName is the name of the [Provider] method being called, such as "Create" or
+// "GetSchema".
+type ContextDecorator = func(ctx context.Context, rpcName string) context.Context
+
+// WithContextDecorator returns a provider that calls decorate on the context of each
+// incoming request before passing it to the underlying method. It does not mutate its
+// receiver.
+//
+// This is useful for attaching request-scoped values (such as loggers or tenant
+// information) without writing a full middleware:
+//
+//	provider := infer.Provider(opts).WithContextDecorator(
+//		func(ctx context.Context, rpcName string) context.Context {
+//			return context.WithValue(ctx, rpcNameKey{}, rpcName)
+//		})
+//
+// Methods that are nil on the receiver remain nil.
+func (d Provider) WithContextDecorator(decorate ContextDecorator) Provider {
+	if decorate == nil {
+		return d
+	}
+	d.GetSchema = decorateIO(decorate, "GetSchema", d.GetSchema)
+	d.Parameterize = decorateIO(decorate, "Parameterize", d.Parameterize)
+	d.GetMapping = decorateIO(decorate, "GetMapping", d.GetMapping)
+	d.Cancel = decorateCtx(decorate, "Cancel", d.Cancel)
+	d.CheckConfig = decorateIO(decorate, "CheckConfig", d.CheckConfig)
+	d.DiffConfig = decorateIO(decorate, "DiffConfig", d.DiffConfig)
+	d.Configure = decorateI(decorate, "Configure", d.Configure)
+	d.Invoke = decorateIO(decorate, "Invoke", d.Invoke)
+	d.Check = decorateIO(decorate, "Check", d.Check)
+	d.Diff = decorateIO(decorate, "Diff", d.Diff)
+	d.Create = decorateIO(decorate, "Create", d.Create)
+	d.Read = decorateIO(decorate, "Read", d.Read)
+	d.Update = decorateIO(decorate, "Update", d.Update)
+	d.Delete = decorateIO(decorate, "Delete", d.Delete)
+	d.Call = decorateIO(decorate, "Call", d.Call)
+	d.Construct = decorateIO(decorate, "Construct", d.Construct)
+	return d
+}
+
+func decorateIO[I, O any, F func(context.Context, I) (O, error)](
+	decorate ContextDecorator, name string, method F,
+) F {
+	if method == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (O, error) { return method(decorate(ctx, name), req) }
+}
+
+func decorateI[I any, F func(context.Context, I) error](decorate ContextDecorator, name string, method F) F {
+	if method == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) error { return method(decorate(ctx, name), req) }
+}
+
+func decorateCtx[F func(context.Context) error](decorate ContextDecorator, name string, method F) F {
+	if method == nil {
+		return nil
+	}
+	return func(ctx context.Context) error { return method(decorate(ctx, name)) }
+}
+
+// GetOrganization returns the name of the organization that the current deployment
+// belongs to, or "" if it is not known.
+//
+// The organization is sent by the engine on Construct and Call requests, and is
+// available on all requests that follow.
+func GetOrganization(ctx context.Context) string { return getStackInfo(ctx).organization }
+
+// GetProject returns the name of the project being deployed, or "" if it is not known.
+//
+// The project is taken from the URN of the current request when there is one, and
+// otherwise from the most recent Construct, Call or CheckConfig request. This makes it
+// available to Invoke handlers, which are not sent a URN.
+func GetProject(ctx context.Context) string { return getStackInfo(ctx).project }
+
+// GetStack returns the name of the stack being deployed, or "" if it is not known.
+//
+// The stack is taken from the URN of the current request when there is one, and
+// otherwise from the most recent Construct, Call or CheckConfig request. This makes it
+// available to Invoke handlers, which are not sent a URN.
+func GetStack(ctx context.Context) string { return getStackInfo(ctx).stack }
+
+func getStackInfo(ctx context.Context) stackInfo {
+	info, _ := ctx.Value(key.Stack).(stackInfo)
+	if urn, ok := ctx.Value(key.URN).(presource.URN); ok && urn.IsValid() {
+		info.project = string(urn.Project())
+		info.stack = string(urn.Stack())
+	}
+	return info
+}
+
+// stackInfo describes the stack a provider is deployed into.
+type stackInfo struct {
+	organization, project, stack string
+}
+
+// stackMetadata holds a [stackInfo] that is safe for concurrent use.
+type stackMetadata struct {
+	m    sync.Mutex
+	info stackInfo
+}
+
+func (s *stackMetadata) get() stackInfo {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.info
+}
+
+func (s *stackMetadata) set(info stackInfo) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.info = info
+}
+
+// observe records the project and stack of urn, leaving the organization untouched.
+//
+// The provider's own URN is sent on CheckConfig and DiffConfig, which lets requests that
+// carry no URN of their own (such as Invoke) know which stack they belong to.
+func (s *stackMetadata) observe(urn presource.URN) {
+	if !urn.IsValid() {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.info.project = string(urn.Project())
+	s.info.stack = string(urn.Stack())
+}
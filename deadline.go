@@ -0,0 +1,79 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// Deadline returns the time by which the current operation should complete. ok is false
+// when the operation has no deadline.
+//
+// The deadline is the earlier of the deadline of ctx and the timeout sent by the engine
+// with Create, Update and Delete requests (see [CreateRequest.Timeout]). Unlike the
+// deadline of ctx, the timeout is known even when the provider isn't wrapped with
+// [github.com/pulumi/pulumi-go-provider/middleware/cancel.Wrap].
+func Deadline(ctx context.Context) (deadline time.Time, ok bool) {
+	deadline, ok = ctx.Deadline()
+	if timeout, hasTimeout := ctx.Value(key.Deadline).(time.Time); hasTimeout {
+		if !ok || timeout.Before(deadline) {
+			deadline, ok = timeout, true
+		}
+	}
+	return deadline, ok
+}
+
+// OnCancel arranges for fn to be called in its own goroutine when the current operation
+// is cancelled or reaches its [Deadline], whichever comes first. fn is called at most once.
+//
+// OnCancel lets resource code register cleanup, such as aborting a remote operation,
+// without watching ctx.Done() itself:
+//
+//	stop := p.OnCancel(ctx, func() { client.Abort(operationID) })
+//	defer stop()
+//
+// Calling stop unregisters fn. stop returns true if it stopped fn from being called, and
+// false if fn has already been started or stop has already been called.
+func OnCancel(ctx context.Context, fn func()) (stop func() bool) {
+	const (
+		pending int32 = iota
+		called
+		stopped
+	)
+	var state atomic.Int32
+	run := func() {
+		if state.CompareAndSwap(pending, called) {
+			fn()
+		}
+	}
+
+	stopCtx := context.AfterFunc(ctx, run)
+	var timer *time.Timer
+	if deadline, ok := ctx.Value(key.Deadline).(time.Time); ok {
+		timer = time.AfterFunc(time.Until(deadline), run)
+	}
+
+	return func() bool {
+		stopCtx()
+		if timer != nil {
+			timer.Stop()
+		}
+		return state.CompareAndSwap(pending, stopped)
+	}
+}
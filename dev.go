@@ -0,0 +1,295 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pprovider "github.com/pulumi/pulumi/pkg/v3/resource/provider"
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// DevOptions configures [RunProviderDev].
+type DevOptions struct {
+	// Build constructs the provider.
+	//
+	// Build is called once on startup and again each time a reload is triggered. Build
+	// runs in the same process each time, so a reload only picks up what Build reads at
+	// runtime, such as a schema file or configuration on disk. Changes to the provider's
+	// Go source take effect only once the binary is rebuilt and the engine restarts it.
+	// Build is required.
+	Build func(context.Context) (Provider, error)
+
+	// Watch is a list of files and directories. When the modification time of any file
+	// within Watch changes, a reload is triggered.
+	Watch []string
+
+	// PollInterval is how often Watch is checked for changes. It defaults to one second.
+	PollInterval time.Duration
+
+	// Reload triggers a reload each time a value is received.
+	Reload <-chan struct{}
+}
+
+// RunProviderDev runs a provider with the given name and version, calling
+// [DevOptions.Build] again to replace it in place when a reload is triggered. The running
+// binary is not recompiled; see [DevOptions.Build].
+//
+// The gRPC connection to the engine is kept open across reloads. After each reload, the
+// last Parameterize and Configure requests received are replayed against the new
+// provider before it starts serving requests. If Build fails, the error is written to
+// stderr and the previous provider continues to serve requests.
+//
+// RunProviderDev is intended for local development, for example with
+// `pulumi up --attach-debugger`. Use [RunProvider] for released providers.
+func RunProviderDev(name, version string, opts DevOptions) error {
+	if opts.Build == nil {
+		return fmt.Errorf("RunProviderDev: DevOptions.Build is required")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dev := &devProvider{name: name, version: version, build: opts.Build}
+	if err := dev.reload(ctx); err != nil {
+		return err
+	}
+
+	go dev.watch(ctx, opts)
+
+	served := dev.provider()
+	shutdown := newShutdown(served.ShutdownGracePeriod)
+	shutdown.onExit = served.OnShutdown
+	factory := newProvider(name, version, served, shutdown)
+	err := pprovider.Main(name, func(host *pprovider.HostClient) (rpc.ResourceProviderServer, error) {
+		server, err := factory(host)
+		if err == nil {
+			dev.server.Store(server.(*provider))
+		}
+		return server, err
+	})
+	shutdown.exit()
+	shutdown.flush()
+	return err
+}
+
+// devProvider delegates to the most recently built provider.
+type devProvider struct {
+	name, version string
+	build         func(context.Context) (Provider, error)
+
+	// server is the gRPC server that serves the provider, once the engine has
+	// connected.
+	server atomic.Pointer[provider]
+
+	m            sync.RWMutex
+	current      Provider
+	parameterize *ParameterizeRequest
+	configure    *ConfigureRequest
+}
+
+func (d *devProvider) get() Provider {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return d.current
+}
+
+// reload builds a new provider and swaps it in, replaying the configuration of the old
+// provider.
+//
+// The replay happens without holding d.m, so requests continue to be served by the old
+// provider until the new provider is fully configured.
+func (d *devProvider) reload(ctx context.Context) error {
+	ctx = d.ctx(ctx)
+	next, err := d.build(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to build provider: %w", err)
+	}
+	next = next.WithDefaults()
+
+	d.m.RLock()
+	parameterize, configure := d.parameterize, d.configure
+	d.m.RUnlock()
+	for {
+		if parameterize != nil {
+			if _, err := next.Parameterize(ctx, *parameterize); err != nil {
+				return fmt.Errorf("failed to replay Parameterize: %w", err)
+			}
+		}
+		if configure != nil {
+			if err := next.Configure(ctx, *configure); err != nil {
+				return fmt.Errorf("failed to replay Configure: %w", err)
+			}
+		}
+
+		d.m.Lock()
+		// The old provider may have been parameterized or configured while the replay
+		// was running. If so, replay again with the latest requests.
+		if d.parameterize == parameterize && d.configure == configure {
+			d.current = next
+			d.m.Unlock()
+			return nil
+		}
+		parameterize, configure = d.parameterize, d.configure
+		d.m.Unlock()
+	}
+}
+
+// ctx returns a context equivalent to the one the server gives each request, so that
+// Build and the replayed requests can use [GetRunInfo] and the provider's logger.
+func (d *devProvider) ctx(ctx context.Context) context.Context {
+	if server := d.server.Load(); server != nil {
+		return server.ctx(ctx, "")
+	}
+	// The engine has not connected yet, so the provider has not been configured.
+	return context.WithValue(ctx, key.RuntimeInfo, RunInfo{
+		PackageName: d.name,
+		Version:     d.version,
+	})
+}
+
+func (d *devProvider) watch(ctx context.Context, opts DevOptions) {
+	interval := opts.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastModified := latestModTime(opts.Watch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-opts.Reload:
+		case <-ticker.C:
+			if len(opts.Watch) == 0 {
+				continue
+			}
+			modified := latestModTime(opts.Watch)
+			if !modified.After(lastModified) {
+				continue
+			}
+			lastModified = modified
+		}
+		if err := d.reload(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Reload failed, continuing with the previous provider: %s\n", err)
+		}
+	}
+}
+
+// latestModTime returns the most recent modification time of any file in paths.
+func latestModTime(paths []string) time.Time {
+	var latest time.Time
+	for _, root := range paths {
+		// Errors are ignored: a file that is mid-write will be picked up on the next
+		// poll.
+		_ = filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := entry.Info()
+			if err == nil && info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return latest
+}
+
+// provider returns a [Provider] that forwards each request to the current provider.
+func (d *devProvider) provider() Provider {
+	return Provider{
+		GetSchema: func(ctx context.Context, req GetSchemaRequest) (GetSchemaResponse, error) {
+			return d.get().GetSchema(ctx, req)
+		},
+		Parameterize: func(ctx context.Context, req ParameterizeRequest) (ParameterizeResponse, error) {
+			resp, err := d.get().Parameterize(ctx, req)
+			if err == nil {
+				d.m.Lock()
+				d.parameterize = &req
+				d.m.Unlock()
+			}
+			return resp, err
+		},
+		Cancel: func(ctx context.Context) error { return d.get().Cancel(ctx) },
+		CheckConfig: func(ctx context.Context, req CheckRequest) (CheckResponse, error) {
+			return d.get().CheckConfig(ctx, req)
+		},
+		DiffConfig: func(ctx context.Context, req DiffRequest) (DiffResponse, error) {
+			return d.get().DiffConfig(ctx, req)
+		},
+		Configure: func(ctx context.Context, req ConfigureRequest) error {
+			err := d.get().Configure(ctx, req)
+			if err == nil {
+				d.m.Lock()
+				d.configure = &req
+				d.m.Unlock()
+			}
+			return err
+		},
+		GetMapping: func(ctx context.Context, req GetMappingRequest) (GetMappingResponse, error) {
+			return d.get().GetMapping(ctx, req)
+		},
+		Invoke: func(ctx context.Context, req InvokeRequest) (InvokeResponse, error) {
+			return d.get().Invoke(ctx, req)
+		},
+		Check: func(ctx context.Context, req CheckRequest) (CheckResponse, error) {
+			return d.get().Check(ctx, req)
+		},
+		Diff: func(ctx context.Context, req DiffRequest) (DiffResponse, error) {
+			return d.get().Diff(ctx, req)
+		},
+		Create: func(ctx context.Context, req CreateRequest) (CreateResponse, error) {
+			return d.get().Create(ctx, req)
+		},
+		Read: func(ctx context.Context, req ReadRequest) (ReadResponse, error) {
+			return d.get().Read(ctx, req)
+		},
+		Update: func(ctx context.Context, req UpdateRequest) (UpdateResponse, error) {
+			return d.get().Update(ctx, req)
+		},
+		Delete: func(ctx context.Context, req DeleteRequest) (DeleteResponse, error) {
+			return d.get().Delete(ctx, req)
+		},
+		Call: func(ctx context.Context, req CallRequest) (CallResponse, error) {
+			return d.get().Call(ctx, req)
+		},
+		Construct: func(ctx context.Context, req ConstructRequest) (ConstructResponse, error) {
+			return d.get().Construct(ctx, req)
+		},
+		// Capabilities are only read once, so they are taken from the first build.
+		Capabilities:         d.get().Capabilities,
+		LogHandler:           d.get().LogHandler,
+		FrameworkLog:         d.get().FrameworkLog,
+		ShutdownGracePeriod:  d.get().ShutdownGracePeriod,
+		MinimumPulumiVersion: d.get().MinimumPulumiVersion,
+		// The provider being served when the process exits is shut down.
+		OnShutdown: func(ctx context.Context) {
+			if onShutdown := d.get().OnShutdown; onShutdown != nil {
+				onShutdown(ctx)
+			}
+		},
+	}
+}
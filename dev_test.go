@@ -0,0 +1,136 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDevReloadReplaysConfigure(t *testing.T) {
+	t.Parallel()
+
+	var builds int
+	var infos []RunInfo
+	dev := &devProvider{
+		name:    "test",
+		version: "1.0.0",
+		build: func(context.Context) (Provider, error) {
+			builds++
+			return Provider{
+				Configure: func(ctx context.Context, _ ConfigureRequest) error {
+					infos = append(infos, GetRunInfo(ctx))
+					return nil
+				},
+			}, nil
+		},
+	}
+	ctx := context.Background()
+	require.NoError(t, dev.reload(ctx))
+
+	served := dev.provider()
+	require.NoError(t, served.Configure(dev.ctx(ctx), ConfigureRequest{}))
+	require.NoError(t, dev.reload(ctx))
+
+	assert.Equal(t, 2, builds)
+	require.Len(t, infos, 2)
+	assert.Equal(t, "test", infos[1].PackageName)
+	assert.Equal(t, "1.0.0", infos[1].Version)
+}
+
+func TestDevReloadServesDuringConfigure(t *testing.T) {
+	t.Parallel()
+
+	configuring := make(chan struct{})
+	release := make(chan struct{})
+	var builds int
+	dev := &devProvider{
+		build: func(context.Context) (Provider, error) {
+			builds++
+			generation := builds
+			return Provider{
+				Configure: func(context.Context, ConfigureRequest) error {
+					if generation > 1 {
+						close(configuring)
+						<-release
+					}
+					return nil
+				},
+				Invoke: func(context.Context, InvokeRequest) (InvokeResponse, error) {
+					return InvokeResponse{Failures: make([]CheckFailure, generation)}, nil
+				},
+			}, nil
+		},
+	}
+	ctx := context.Background()
+	require.NoError(t, dev.reload(ctx))
+	served := dev.provider()
+	require.NoError(t, served.Configure(ctx, ConfigureRequest{}))
+
+	done := make(chan error)
+	go func() { done <- dev.reload(ctx) }()
+	<-configuring
+
+	// The old provider keeps serving requests while the new provider is configured.
+	resp, err := served.Invoke(ctx, InvokeRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Failures, 1)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	resp, err = served.Invoke(ctx, InvokeRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Failures, 2)
+}
+
+func TestDevReloadKeepsProviderOnFailure(t *testing.T) {
+	t.Parallel()
+
+	fail := false
+	var builds int
+	dev := &devProvider{
+		build: func(context.Context) (Provider, error) {
+			builds++
+			generation := builds
+			return Provider{
+				Configure: func(context.Context, ConfigureRequest) error {
+					if fail {
+						return errors.New("bad config")
+					}
+					return nil
+				},
+				Invoke: func(context.Context, InvokeRequest) (InvokeResponse, error) {
+					return InvokeResponse{Failures: make([]CheckFailure, generation)}, nil
+				},
+			}, nil
+		},
+	}
+	ctx := context.Background()
+	require.NoError(t, dev.reload(ctx))
+	served := dev.provider()
+	require.NoError(t, served.Configure(ctx, ConfigureRequest{}))
+
+	fail = true
+	assert.ErrorContains(t, dev.reload(ctx), "failed to replay Configure: bad config")
+
+	resp, err := served.Invoke(ctx, InvokeRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Failures, 1)
+}
@@ -0,0 +1,158 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"fmt"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+)
+
+// DetailedDiff builds the [DiffResponse.DetailedDiff] of a resource.
+//
+// Properties are identified by the segments of their path: a string for an object key and
+// an int for an array index. Each path is escaped according to the grammar documented on
+// [DiffResponse.DetailedDiff], so keys containing dots, brackets or quotes are safe to
+// use:
+//
+//	resp := p.NewDetailedDiff().
+//		Update("spec", "replicas").
+//		Replace("metadata", "labels", "app.kubernetes.io/name").
+//		Response()
+type DetailedDiff struct {
+	diff map[string]PropertyDiff
+}
+
+// NewDetailedDiff returns an empty [DetailedDiff].
+func NewDetailedDiff() *DetailedDiff {
+	return &DetailedDiff{diff: map[string]PropertyDiff{}}
+}
+
+// Set records that the property at path changed with kind.
+func (d *DetailedDiff) Set(kind DiffKind, path ...any) *DetailedDiff {
+	d.diff[propertyPath(path)] = PropertyDiff{Kind: kind}
+	return d
+}
+
+// Add records that the property at path was added.
+func (d *DetailedDiff) Add(path ...any) *DetailedDiff { return d.Set(Add, path...) }
+
+// Update records that the value of the property at path was changed.
+func (d *DetailedDiff) Update(path ...any) *DetailedDiff { return d.Set(Update, path...) }
+
+// Delete records that the property at path was removed.
+func (d *DetailedDiff) Delete(path ...any) *DetailedDiff { return d.Set(Delete, path...) }
+
+// Replace records that the change to the property at path requires the resource to be
+// replaced.
+//
+// If a change to the property was already recorded, it is turned into its replacing kind.
+// Otherwise the property is recorded as [UpdateReplace].
+func (d *DetailedDiff) Replace(path ...any) *DetailedDiff {
+	key := propertyPath(path)
+	diff, ok := d.diff[key]
+	if !ok {
+		diff.Kind = Update
+	}
+	diff.Kind = diff.Kind.asReplace()
+	d.diff[key] = diff
+	return d
+}
+
+// FromObjectDiff records every property that differs between olds and news, down to the
+// most nested value that changed.
+func (d *DetailedDiff) FromObjectDiff(olds, news presource.PropertyMap) *DetailedDiff {
+	for k, v := range plugin.NewDetailedDiffFromObjectDiff(olds.Diff(news), false) {
+		var kind DiffKind
+		switch v.Kind {
+		case plugin.DiffAdd:
+			kind = Add
+		case plugin.DiffAddReplace:
+			kind = AddReplace
+		case plugin.DiffDelete:
+			kind = Delete
+		case plugin.DiffDeleteReplace:
+			kind = DeleteReplace
+		case plugin.DiffUpdate:
+			kind = Update
+		case plugin.DiffUpdateReplace:
+			kind = UpdateReplace
+		default:
+			continue
+		}
+		d.diff[k] = PropertyDiff{Kind: kind, InputDiff: v.InputDiff}
+	}
+	return d
+}
+
+// Build returns the detailed diff, suitable for [DiffResponse.DetailedDiff].
+func (d *DetailedDiff) Build() map[string]PropertyDiff {
+	diff := make(map[string]PropertyDiff, len(d.diff))
+	for k, v := range d.diff {
+		diff[k] = v
+	}
+	return diff
+}
+
+// Response returns a [DiffResponse] with the detailed diff. The response has changes if
+// any property changed.
+func (d *DetailedDiff) Response() DiffResponse {
+	diff := d.Build()
+	hasChanges := false
+	for _, v := range diff {
+		if v.Kind != Stable {
+			hasChanges = true
+			break
+		}
+	}
+	return DiffResponse{HasChanges: hasChanges, DetailedDiff: diff}
+}
+
+// asReplace returns the kind of change that replaces the resource for k.
+func (k DiffKind) asReplace() DiffKind {
+	switch k {
+	case Add:
+		return AddReplace
+	case Delete:
+		return DeleteReplace
+	case Update:
+		return UpdateReplace
+	default:
+		return k
+	}
+}
+
+// propertyPath renders the segments of a property path as a [DiffResponse.DetailedDiff]
+// key.
+func propertyPath(segments []any) string {
+	if len(segments) == 0 {
+		panic("a property path must have at least one segment")
+	}
+	path := make(presource.PropertyPath, len(segments))
+	for i, s := range segments {
+		switch s := s.(type) {
+		case string:
+			path[i] = s
+		case presource.PropertyKey:
+			path[i] = string(s)
+		case int:
+			path[i] = s
+		default:
+			panic(fmt.Sprintf("invalid property path segment %v (%T): expected a string or an int", s, s))
+		}
+	}
+	return path.String()
+}
@@ -8,7 +8,6 @@ import (
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/infer"
-	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 )
 
@@ -32,7 +31,9 @@ func provider() p.Provider {
 type (
 	User     struct{}
 	UserArgs struct {
-		Name *string `pulumi:"name,optional"`
+		// If Name is unset, infer generates a name based off of the resource name during
+		// Check, and keeps using it on subsequent updates.
+		Name *string `pulumi:"name,optional" provider:"autoname"`
 	}
 	UserState struct{ UserArgs }
 )
@@ -40,59 +41,3 @@ type (
 func (*User) Create(ctx context.Context, name string, input UserArgs, preview bool) (string, UserState, error) {
 	return name, UserState{input}, nil
 }
-
-var _ infer.CustomCheck[UserArgs] = ((*User)(nil))
-
-func (*User) Check(
-	ctx context.Context, name string, oldInputs, newInputs resource.PropertyMap,
-) (UserArgs, []p.CheckFailure, error) {
-	// Apply default arguments
-	args, failures, err := infer.DefaultCheck[UserArgs](ctx, newInputs)
-	if err != nil {
-		return args, failures, err
-	}
-
-	// Apply autonaming
-	//
-	// If args.Name is unset, we set it to a value based off of the resource name.
-	args.Name, err = autoname(args.Name, name, "name", oldInputs)
-	return args, failures, err
-}
-
-// autoname makes the field it is called on auto-named.
-//
-// It should be called in [infer.CustomCheck.Check].
-//
-// field is a reference to a *string typed field.
-//
-// name is the name of the resource as passed in via check.
-//
-// fieldName is the name of the place referenced by field. This is what was written in the
-// `pulumi:"<fieldName>"` tag.
-//
-// oldInputs are the old inputs as passed in via [infer.CustomCheck.Check].
-func autoname(
-	field *string, name string, fieldName resource.PropertyKey,
-	oldInputs resource.PropertyMap,
-) (*string, error) {
-	if field != nil {
-		return field, nil
-	}
-
-	prev := oldInputs[fieldName]
-	if prev.IsSecret() {
-		prev = prev.SecretValue().Element
-	}
-
-	if prev.IsString() && prev.StringValue() != "" {
-		n := prev.StringValue()
-		field = &n
-	} else {
-		n, err := resource.NewUniqueHex(name+"-", 6, 20)
-		if err != nil {
-			return nil, err
-		}
-		field = &n
-	}
-	return field, nil
-}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/infer"
-	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi-go-provider/localfile"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 )
 
@@ -31,7 +33,6 @@ func provider() p.Provider {
 type File struct{}
 
 var _ = (infer.CustomDelete[FileState])((*File)(nil))
-var _ = (infer.CustomCheck[FileArgs])((*File)(nil))
 var _ = (infer.CustomUpdate[FileArgs, FileState])((*File)(nil))
 var _ = (infer.CustomDiff[FileArgs, FileState])((*File)(nil))
 var _ = (infer.CustomRead[FileArgs, FileState])((*File)(nil))
@@ -53,7 +54,8 @@ type FileArgs struct {
 func (f *FileArgs) Annotate(a infer.Annotator) {
 	a.Describe(&f.Content, "The content of the file.")
 	a.Describe(&f.Force, "If an already existing file should be deleted if it exists.")
-	a.Describe(&f.Path, "The path of the file. This defaults to the name of the pulumi resource.")
+	a.Describe(&f.Path, "The path of the file.")
+	a.DefaultFromName(&f.Path)
 }
 
 type FileState struct {
@@ -80,18 +82,12 @@ func (*File) Create(ctx context.Context, name string, input FileArgs, preview bo
 		return input.Path, FileState{}, nil
 	}
 
-	f, err := os.Create(input.Path)
-	if err != nil {
-		return "", FileState{}, err
-	}
-	defer f.Close()
-	n, err := f.WriteString(input.Content)
+	err = localfile.WithLock(ctx, input.Path, func() error {
+		return localfile.WriteFile(input.Path, []byte(input.Content), 0o644)
+	})
 	if err != nil {
 		return "", FileState{}, err
 	}
-	if n != len(input.Content) {
-		return "", FileState{}, fmt.Errorf("only wrote %d/%d bytes", n, len(input.Content))
-	}
 	return input.Path, FileState{
 		Path:    input.Path,
 		Force:   input.Force,
@@ -100,35 +96,23 @@ func (*File) Create(ctx context.Context, name string, input FileArgs, preview bo
 }
 
 func (*File) Delete(ctx context.Context, id string, props FileState) error {
-	err := os.Remove(props.Path)
-	if os.IsNotExist(err) {
+	// Remove also removes the lock file, so that it isn't left next to the deleted file.
+	err := localfile.Remove(ctx, props.Path)
+	if errors.Is(err, fs.ErrNotExist) {
 		p.GetLogger(ctx).Warningf("file %q already deleted", props.Path)
 		err = nil
 	}
 	return err
 }
 
-func (*File) Check(ctx context.Context, name string, oldInputs, newInputs resource.PropertyMap) (FileArgs, []p.CheckFailure, error) {
-	if _, ok := newInputs["path"]; !ok {
-		newInputs["path"] = resource.NewStringProperty(name)
-	}
-	return infer.DefaultCheck[FileArgs](ctx, newInputs)
-}
-
 func (*File) Update(ctx context.Context, id string, olds FileState, news FileArgs, preview bool) (FileState, error) {
 	if !preview && olds.Content != news.Content {
-		f, err := os.Create(olds.Path)
-		if err != nil {
-			return FileState{}, err
-		}
-		defer f.Close()
-		n, err := f.WriteString(news.Content)
+		err := localfile.WithLock(ctx, olds.Path, func() error {
+			return localfile.WriteFile(olds.Path, []byte(news.Content), 0o644)
+		})
 		if err != nil {
 			return FileState{}, err
 		}
-		if n != len(news.Content) {
-			return FileState{}, fmt.Errorf("only wrote %d/%d bytes", n, len(news.Content))
-		}
 	}
 
 	return FileState{
@@ -0,0 +1,113 @@
+// package main shows how an existing provider can be wrapped and extended.
+//
+// legacyProvider stands in for a provider that is already implemented against the raw
+// gRPC interface, such as a Terraform bridged provider. It is projected into a
+// [p.Provider] with [rpc.Provider], exposed under this package's tokens with
+// [remap.Wrap] and then extended with new resources with [infer.Wrap]. The schemas of
+// both providers are merged.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/middleware/remap"
+	"github.com/pulumi/pulumi-go-provider/middleware/rpc"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func main() {
+	err := p.RunProvider("wrapped", "0.1.0", provider())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s", err.Error())
+		os.Exit(1)
+	}
+}
+
+func provider() p.Provider {
+	legacy := remap.Wrap(rpc.Provider(legacyProvider{}), remap.Options{
+		Tokens: map[tokens.Type]tokens.Type{
+			"legacy:index:Widget": "wrapped:index:Widget",
+		},
+	})
+	return infer.Wrap(legacy, infer.Options{
+		Resources: []infer.InferredResource{infer.Resource[*Gadget, GadgetArgs, GadgetState]()},
+		ModuleMap: map[tokens.ModuleName]tokens.ModuleName{
+			"wrapped": "index",
+		},
+	})
+}
+
+// Gadget is a new resource, implemented with infer, that is served alongside the wrapped
+// provider's resources.
+type Gadget struct{}
+
+type GadgetArgs struct {
+	Widget string `pulumi:"widget"`
+}
+
+type GadgetState struct {
+	GadgetArgs
+	Label string `pulumi:"label"`
+}
+
+func (*Gadget) Create(ctx context.Context, name string, input GadgetArgs, preview bool) (string, GadgetState, error) {
+	return name, GadgetState{input, strings.ToUpper(input.Widget)}, nil
+}
+
+// legacyProvider is a minimal raw gRPC provider serving a single resource:
+// legacy:index:Widget.
+type legacyProvider struct {
+	pulumirpc.UnimplementedResourceProviderServer
+}
+
+const legacySchema = `{
+  "name": "legacy",
+  "resources": {
+    "legacy:index:Widget": {
+      "properties": {"size": {"type": "integer"}},
+      "required": ["size"],
+      "inputProperties": {"size": {"type": "integer"}},
+      "requiredInputs": ["size"]
+    }
+  }
+}`
+
+func (legacyProvider) GetSchema(
+	context.Context, *pulumirpc.GetSchemaRequest,
+) (*pulumirpc.GetSchemaResponse, error) {
+	return &pulumirpc.GetSchemaResponse{Schema: legacySchema}, nil
+}
+
+func (legacyProvider) Configure(
+	context.Context, *pulumirpc.ConfigureRequest,
+) (*pulumirpc.ConfigureResponse, error) {
+	return &pulumirpc.ConfigureResponse{
+		AcceptSecrets:   true,
+		SupportsPreview: true,
+	}, nil
+}
+
+func (legacyProvider) Check(_ context.Context, req *pulumirpc.CheckRequest) (*pulumirpc.CheckResponse, error) {
+	if req.GetUrn() == "" || !strings.Contains(req.GetUrn(), "legacy:index:Widget") {
+		return nil, fmt.Errorf("unknown resource %q", req.GetUrn())
+	}
+	return &pulumirpc.CheckResponse{Inputs: req.GetNews()}, nil
+}
+
+func (legacyProvider) Create(_ context.Context, req *pulumirpc.CreateRequest) (*pulumirpc.CreateResponse, error) {
+	return &pulumirpc.CreateResponse{
+		Id:         "widget",
+		Properties: req.GetProperties(),
+	}, nil
+}
+
+func (legacyProvider) Delete(context.Context, *pulumirpc.DeleteRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
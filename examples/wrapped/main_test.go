@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrappedSchema(t *testing.T) {
+	t.Parallel()
+
+	s := integration.NewServer("wrapped", semver.MustParse("0.1.0"), provider())
+	integration.SchemaSnapshot(t, s, "testdata/schema.json")
+}
+
+func TestWrappedResource(t *testing.T) {
+	t.Parallel()
+
+	s := integration.NewServer("wrapped", semver.MustParse("0.1.0"), provider())
+	require.NoError(t, s.Configure(p.ConfigureRequest{}))
+
+	urn := resource.NewURN("dev", "test", "", "wrapped:index:Widget", "w")
+	check, err := s.Check(p.CheckRequest{
+		Urn:  urn,
+		News: resource.PropertyMap{"size": resource.NewProperty(3.0)},
+	})
+	require.NoError(t, err)
+	require.Empty(t, check.Failures)
+
+	create, err := s.Create(p.CreateRequest{Urn: urn, Properties: check.Inputs})
+	require.NoError(t, err)
+	assert.Equal(t, "widget", create.ID)
+	assert.Equal(t, resource.PropertyMap{"size": resource.NewProperty(3.0)}, create.Properties)
+}
+
+func TestOverlayResource(t *testing.T) {
+	t.Parallel()
+
+	s := integration.NewServer("wrapped", semver.MustParse("0.1.0"), provider())
+	create, err := s.Create(p.CreateRequest{
+		Urn:        resource.NewURN("dev", "test", "", "wrapped:index:Gadget", "g"),
+		Properties: resource.PropertyMap{"widget": resource.NewProperty("widget")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "WIDGET", create.Properties["label"].StringValue())
+}
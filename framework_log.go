@@ -0,0 +1,111 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// FrameworkLogEnvVar is the environment variable that enables logging of the framework's
+// own decisions when a provider is served, as a comma separated list of
+// [FrameworkLogTopic]s:
+//
+//	PULUMI_PROVIDER_FRAMEWORK_LOG=encoding,dependencies pulumi preview --debug
+//
+// "all" enables every topic, and unknown topics are ignored with a warning. The messages
+// are logged at debug severity, so they are shown by the engine with `--debug` or `-v`.
+const FrameworkLogEnvVar = "PULUMI_PROVIDER_FRAMEWORK_LOG"
+
+// FrameworkLogTopic is an area of the framework whose decisions can be logged, to explain
+// behavior that is otherwise hard to trace, such as why an output became secret or unknown.
+type FrameworkLogTopic string
+
+const (
+	// FrameworkLogEncoding logs the secrets and unknowns removed from property maps
+	// before they are decoded into Go values, which are restored when the values are
+	// encoded again.
+	FrameworkLogEncoding FrameworkLogTopic = "encoding"
+	// FrameworkLogMigrations logs which state migration, if any, is applied to the state
+	// of a resource.
+	FrameworkLogMigrations FrameworkLogTopic = "migrations"
+	// FrameworkLogDependencies logs the outputs that are made secret or unknown because of
+	// the inputs they depend on.
+	FrameworkLogDependencies FrameworkLogTopic = "dependencies"
+)
+
+var frameworkLogTopics = []FrameworkLogTopic{
+	FrameworkLogEncoding, FrameworkLogMigrations, FrameworkLogDependencies,
+}
+
+// WithFrameworkLog returns a provider that logs topics, in addition to those enabled by
+// [FrameworkLogEnvVar]. It does not mutate its receiver.
+func (d Provider) WithFrameworkLog(topics ...FrameworkLogTopic) Provider {
+	d.FrameworkLog = append(slices.Clip(d.FrameworkLog), topics...)
+	return d
+}
+
+// FrameworkLogEnabled returns true if topic is logged for the request of ctx.
+func FrameworkLogEnabled(ctx context.Context, topic FrameworkLogTopic) bool {
+	topics, _ := ctx.Value(key.FrameworkLog).([]FrameworkLogTopic)
+	return slices.Contains(topics, topic)
+}
+
+// FrameworkLogf logs a debug message about topic with [GetLogger], if topic is enabled.
+// The message is prefixed with the topic.
+//
+// FrameworkLogf is meant for libraries built on this one, such as
+// [github.com/pulumi/pulumi-go-provider/infer]. Providers should log with [GetLogger].
+func FrameworkLogf(ctx context.Context, topic FrameworkLogTopic, msg string, a ...any) {
+	if !FrameworkLogEnabled(ctx, topic) {
+		return
+	}
+	GetLogger(ctx).Debugf("["+string(topic)+"] "+msg, a...)
+}
+
+// frameworkLogFromEnv returns topics and the topics enabled by [FrameworkLogEnvVar],
+// along with the unknown topics in [FrameworkLogEnvVar], which are ignored.
+func frameworkLogFromEnv(topics []FrameworkLogTopic) ([]FrameworkLogTopic, []FrameworkLogTopic) {
+	v := os.Getenv(FrameworkLogEnvVar)
+	if v == "" {
+		return topics, nil
+	}
+	topics = slices.Clone(topics)
+	var unknown []FrameworkLogTopic
+	for _, t := range strings.Split(v, ",") {
+		topic := FrameworkLogTopic(strings.TrimSpace(t))
+		switch {
+		case topic == "all":
+			topics = append(topics, frameworkLogTopics...)
+		case slices.Contains(frameworkLogTopics, topic):
+			topics = append(topics, topic)
+		case topic == "":
+		default:
+			unknown = append(unknown, topic)
+		}
+	}
+	return topics, unknown
+}
+
+// warnUnknownFrameworkLog warns that the unknown topics in [FrameworkLogEnvVar] are
+// ignored.
+func warnUnknownFrameworkLog(ctx context.Context, unknown []FrameworkLogTopic) {
+	GetLogger(ctx).Warningf("%s: ignoring unknown topics %q, expected %q or one of %q",
+		FrameworkLogEnvVar, unknown, "all", frameworkLogTopics)
+}
@@ -0,0 +1,125 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"reflect"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// aliased is implemented by resources, components and functions that can be annotated
+// with [Annotator.AddAlias].
+type aliased interface {
+	aliases() []tokens.Type
+}
+
+// aliasesOf returns the aliases added to t with [Annotator.AddAlias].
+func aliasesOf(t reflect.Type) []tokens.Type {
+	annotations := getAnnotated(t)
+	aliases := make([]tokens.Type, len(annotations.Aliases))
+	for i, a := range annotations.Aliases {
+		aliases[i] = tokens.Type(a)
+	}
+	return aliases
+}
+
+// tokenAliases returns the aliases of v, if it has any.
+func tokenAliases(v any) []tokens.Type {
+	if a, ok := v.(aliased); ok {
+		return a.aliases()
+	}
+	return nil
+}
+
+func (*derivedResourceController[R, I, O]) aliases() []tokens.Type {
+	return aliasesOf(typeFor[R]())
+}
+
+func (*derivedComponentController[R, I, O]) aliases() []tokens.Type {
+	return aliasesOf(typeFor[R]())
+}
+
+func (*derivedInvokeController[F, I, O]) aliases() []tokens.Type {
+	return aliasesOf(typeFor[F]())
+}
+
+// functionAliases returns a function for each alias of fns.
+//
+// The schema has no aliases for functions, so each alias is served as a deprecated
+// copy of the function. This keeps programs and SDKs that use the old token working.
+func functionAliases(fns []InferredFunction) []InferredFunction {
+	var aliases []InferredFunction
+	for _, fn := range fns {
+		for _, tk := range tokenAliases(fn) {
+			aliases = append(aliases, &functionAlias{fn, tk})
+		}
+	}
+	return aliases
+}
+
+// functionAlias serves an InferredFunction under one of its aliases.
+type functionAlias struct {
+	InferredFunction
+	token tokens.Type
+}
+
+func (a *functionAlias) GetToken() (tokens.Type, error) { return a.token, nil }
+
+func (a *functionAlias) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	spec, err := a.InferredFunction.GetSchema(reg)
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	tk, err := a.InferredFunction.GetToken()
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	if spec.DeprecationMessage == "" {
+		spec.DeprecationMessage = renamedMessage(a.token, tk)
+	}
+	return spec, nil
+}
+
+// registerTypeAliases registers spec, the schema of the type t, under each of the aliases
+// of t.
+//
+// The schema has no aliases for types, so each alias is a copy of the type. This keeps
+// the old type available in generated SDKs.
+func registerTypeAliases(
+	reg schema.RegisterDerivativeType, t reflect.Type, tk tokens.Type, spec pschema.ComplexTypeSpec,
+) {
+	for _, alias := range aliasesOf(t) {
+		spec := spec
+		note := renamedMessage(alias, tk)
+		if spec.Description == "" {
+			spec.Description = note
+		} else {
+			spec.Description = note + "\n\n" + spec.Description
+		}
+		reg(alias, spec)
+	}
+}
+
+// renamedMessage explains that old has been renamed to tk.
+//
+// Only names are given, since modules may still be remapped by [Options.ModuleMap].
+func renamedMessage(old, tk tokens.Type) string {
+	return fmt.Sprintf("%s has been renamed to %s.", old.Name(), tk.Name())
+}
@@ -0,0 +1,225 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
+)
+
+// The length of the random suffix of a generated name.
+const autonameSuffixLen = 6
+
+// autonamedProperties returns the top level properties of I that are auto-named, either
+// through the `provider:"autoname"` tag or [Annotator.Autoname].
+func autonamedProperties[I any]() (map[resource.PropertyKey]AutonameOptions, error) {
+	typ := typeFor[I]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	annotated := getAnnotated(typ).AutonameFields
+
+	autonamed := map[resource.PropertyKey]AutonameOptions{}
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, err := introspect.ParseTag(field)
+		if err != nil {
+			return nil, err
+		}
+		opts, ok := annotated[tag.Name]
+		if tag.Internal || (!ok && !tag.Autoname) {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if !tag.Optional || fieldType.Kind() != reflect.String {
+			return nil, fmt.Errorf("auto-named property %q must be an optional string", tag.Name)
+		}
+		autonamed[resource.PropertyKey(tag.Name)] = opts
+	}
+	return autonamed, nil
+}
+
+// applyAutonaming fills in the auto-named properties of I that are missing from
+// req.News, returning the new inputs.
+//
+// A property keeps its previous value when there is one, so that existing resources are
+// not renamed. Otherwise the name proposed by the engine is used, and otherwise a name is
+// generated from the resource name. When the engine enforces its proposed name, the
+// proposed name is used even if there is a previous value. When the engine disables
+// autonaming, a failure is returned for each property that has neither a value nor a
+// previous value.
+func applyAutonaming[I any](req p.CheckRequest) (resource.PropertyMap, []p.CheckFailure, error) {
+	autonamed, err := autonamedProperties[I]()
+	if err != nil || len(autonamed) == 0 {
+		return req.News, nil, err
+	}
+	mode := p.AutonamingModePropose
+	var proposed string
+	if req.Autonaming != nil {
+		mode, proposed = req.Autonaming.Mode, req.Autonaming.ProposedName
+	}
+
+	// Generated names are derived from the engine's random seed, so that they are
+	// stable across previews.
+	random := newRandom(req.RandomSeed)
+	news := req.News.Copy()
+	if news == nil {
+		news = resource.PropertyMap{}
+	}
+	keys := make([]resource.PropertyKey, 0, len(autonamed))
+	for key := range autonamed {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var failures []p.CheckFailure
+	for _, key := range keys {
+		if v, ok := news[key]; ok && !v.IsNull() {
+			continue
+		}
+		if mode == p.AutonamingModeEnforce && proposed != "" {
+			news[key] = resource.NewStringProperty(proposed)
+			continue
+		}
+		if prev := putil.MakePublic(req.Olds[key]); prev.IsString() && prev.StringValue() != "" {
+			news[key] = req.Olds[key]
+			continue
+		}
+		switch {
+		case mode == p.AutonamingModeDisable:
+			failures = append(failures, p.CheckFailure{
+				Property: string(key),
+				Reason: fmt.Sprintf("%q must be set explicitly because autonaming is disabled "+
+					"for this stack", key),
+			})
+		case proposed != "":
+			news[key] = resource.NewStringProperty(proposed)
+		default:
+			name, err := generateName(autonamed[key].Prefix+req.Urn.Name()+"-", autonamed[key], random)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to generate a name for %q: %w", key, err)
+			}
+			news[key] = resource.NewStringProperty(name)
+		}
+	}
+	return news, failures, nil
+}
+
+// hasAutonamedProperties reports whether I has any auto-named properties.
+func hasAutonamedProperties[I any]() bool {
+	autonamed, err := autonamedProperties[I]()
+	return err == nil && len(autonamed) > 0
+}
+
+// generateName appends a random suffix to base, truncating base so that the result is
+// no longer than opts.MaxLen.
+func generateName(base string, opts AutonameOptions, random *rand.Rand) (string, error) {
+	charset := []rune(opts.Charset)
+	if len(charset) == 0 {
+		charset = []rune("0123456789abcdef")
+	}
+	if opts.MaxLen > 0 {
+		if opts.MaxLen < autonameSuffixLen {
+			return "", fmt.Errorf("MaxLen must be at least %d", autonameSuffixLen)
+		}
+		if len(base)+autonameSuffixLen > opts.MaxLen {
+			base = base[:opts.MaxLen-autonameSuffixLen]
+		}
+	}
+
+	suffix := make([]rune, autonameSuffixLen)
+	for i := range suffix {
+		suffix[i] = charset[random.Intn(len(charset))]
+	}
+	return base + string(suffix), nil
+}
+
+// defaultFromNameProperties returns the top level properties of I that default to the
+// resource name, marked with [Annotator.DefaultFromName].
+func defaultFromNameProperties[I any]() (map[resource.PropertyKey]bool, error) {
+	typ := typeFor[I]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	annotated := getAnnotated(typ).DefaultFromNameFields
+	if len(annotated) == 0 {
+		return nil, nil
+	}
+
+	fromName := map[resource.PropertyKey]bool{}
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, err := introspect.ParseTag(field)
+		if err != nil {
+			return nil, err
+		}
+		if tag.Internal || !annotated[tag.Name] {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if !tag.Optional || fieldType.Kind() != reflect.String {
+			return nil, fmt.Errorf("property %q defaults to the resource name, so it must be an optional string",
+				tag.Name)
+		}
+		fromName[resource.PropertyKey(tag.Name)] = true
+	}
+	return fromName, nil
+}
+
+// applyDefaultFromName fills in the properties of I that default to the resource name and
+// are missing from req.News, returning the new inputs.
+//
+// A property keeps its previous value when there is one, so that it is stable across
+// updates.
+func applyDefaultFromName[I any](req p.CheckRequest) (resource.PropertyMap, error) {
+	fromName, err := defaultFromNameProperties[I]()
+	if err != nil || len(fromName) == 0 {
+		return req.News, err
+	}
+
+	news := req.News.Copy()
+	if news == nil {
+		news = resource.PropertyMap{}
+	}
+	for key := range fromName {
+		if v, ok := news[key]; ok && !v.IsNull() {
+			continue
+		}
+		if prev := putil.MakePublic(req.Olds[key]); prev.IsString() && prev.StringValue() != "" {
+			news[key] = req.Olds[key]
+			continue
+		}
+		news[key] = resource.NewStringProperty(req.Urn.Name())
+	}
+	return news, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// selfKey is the argument of a method call that holds the resource the method is called
+// on.
+const selfKey resource.PropertyKey = "__self__"
+
+// CallMethod implements [p.Provider.Call] for a resource method with typed arguments and
+// results:
+//
+//	Call: func(ctx context.Context, req p.CallRequest) (p.CallResponse, error) {
+//		return infer.CallMethod(ctx, req, func(ctx context.Context,
+//			self resource.ResourceReference, args GetKubeconfigArgs,
+//		) (GetKubeconfigResult, error) {
+//			...
+//		})
+//	}
+//
+// The resource the method is called on is passed to fn as self. The other arguments are
+// decoded into Args, and the result of fn is encoded, just as [Function] decodes and
+// encodes the inputs and outputs of a function: arguments that don't fit Args are
+// returned as failures, defaults are applied, and secret arguments make the matching
+// results secret.
+func CallMethod[Args, Res any](
+	ctx context.Context, req p.CallRequest,
+	fn func(ctx context.Context, self resource.ResourceReference, args Args) (Res, error),
+) (p.CallResponse, error) {
+	args := req.Args.Copy()
+	self, ok := selfReference(args[selfKey])
+	if !ok {
+		return p.CallResponse{}, fmt.Errorf("%s must be called on a resource", req.Tok)
+	}
+	delete(args, selfKey)
+
+	resp, err := invoke(ctx, p.InvokeRequest{Token: tokens.Type(req.Tok), Args: args},
+		func(ctx context.Context, args Args) (Res, error) {
+			return fn(ctx, self, args)
+		})
+	return p.CallResponse{
+		Return:   resp.Return,
+		Failures: resp.Failures,
+	}, err
+}
+
+// selfReference returns the resource reference held by v, which may be secret or an
+// output.
+func selfReference(v resource.PropertyValue) (resource.ResourceReference, bool) {
+	for {
+		switch {
+		case v.IsSecret():
+			v = v.SecretValue().Element
+		case v.IsOutput():
+			v = v.OutputValue().Element
+		case v.IsResourceReference():
+			return v.ResourceReferenceValue(), true
+		default:
+			return resource.ResourceReference{}, false
+		}
+	}
+}
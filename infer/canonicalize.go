@@ -0,0 +1,155 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
+)
+
+// canonicalize sorts the elements of every field of T tagged with `provider:"set"`, so
+// that building a collection in a different order doesn't change the resource's state,
+// or show up in its diff. Fields tagged with `provider:"setKey=name"` are sorted by the
+// name field of their elements.
+//
+// Maps need no special handling, since a [resource.PropertyMap] has no order.
+func canonicalize[T any](m resource.PropertyMap) resource.PropertyMap {
+	return canonicalizeValue(typeFor[T](), resource.NewProperty(m)).ObjectValue()
+}
+
+func canonicalizeValue(t reflect.Type, p resource.PropertyValue) (out resource.PropertyValue) {
+	if t == nil {
+		return p
+	}
+
+	if putil.IsSecret(p) {
+		p = putil.MakePublic(p)
+		defer func() { out = putil.MakeSecret(out) }()
+	}
+	if putil.IsComputed(p) {
+		// There is nothing to sort in an unknown value.
+		return p
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !p.IsObject() {
+			return p
+		}
+		obj := p.ObjectValue().Copy()
+		for _, field := range reflect.VisibleFields(t) {
+			info, err := introspect.ParseTag(field)
+			if err != nil || info.Internal {
+				continue
+			}
+			key := resource.PropertyKey(info.Name)
+			v, ok := obj[key]
+			if !ok {
+				continue
+			}
+			v = canonicalizeValue(field.Type, v)
+			if info.Set {
+				v = sortArray(v, resource.PropertyKey(info.SetKey))
+			}
+			obj[key] = v
+		}
+		return resource.NewProperty(obj)
+	case reflect.Slice, reflect.Array:
+		if !p.IsArray() {
+			return p
+		}
+		arr := make([]resource.PropertyValue, len(p.ArrayValue()))
+		for i, v := range p.ArrayValue() {
+			arr[i] = canonicalizeValue(t.Elem(), v)
+		}
+		return resource.NewProperty(arr)
+	case reflect.Map:
+		if !p.IsObject() {
+			return p
+		}
+		obj := resource.PropertyMap{}
+		for k, v := range p.ObjectValue() {
+			obj[k] = canonicalizeValue(t.Elem(), v)
+		}
+		return resource.NewProperty(obj)
+	default:
+		return p
+	}
+}
+
+// sortArray stably sorts the elements of an array. Numbers are sorted numerically, and
+// all other values by their JSON representation.
+//
+// If by is set, object elements are sorted by their by property instead.
+func sortArray(p resource.PropertyValue, by resource.PropertyKey) (out resource.PropertyValue) {
+	if putil.IsSecret(p) {
+		p = putil.MakePublic(p)
+		defer func() { out = putil.MakeSecret(out) }()
+	}
+	if !p.IsArray() {
+		return p
+	}
+	arr := append([]resource.PropertyValue(nil), p.ArrayValue()...)
+	sortBy := make([]resource.PropertyValue, len(arr))
+	keys := make([]string, len(arr))
+	for i, v := range arr {
+		sortBy[i] = v
+		if by != "" && v.IsObject() {
+			sortBy[i] = putil.MakePublic(v.ObjectValue()[by])
+		}
+		keys[i] = sortKey(sortBy[i])
+	}
+	sort.Stable(byKey{arr, sortBy, keys})
+	return resource.NewProperty(arr)
+}
+
+func sortKey(v resource.PropertyValue) string {
+	b, err := json.Marshal(v.Mappable())
+	if err != nil {
+		return fmt.Sprint(v.Mappable())
+	}
+	return string(b)
+}
+
+type byKey struct {
+	values []resource.PropertyValue
+	// sortBy holds the value each element is sorted by.
+	sortBy []resource.PropertyValue
+	keys   []string
+}
+
+func (b byKey) Len() int { return len(b.values) }
+func (b byKey) Less(i, j int) bool {
+	if b.sortBy[i].IsNumber() && b.sortBy[j].IsNumber() {
+		return b.sortBy[i].NumberValue() < b.sortBy[j].NumberValue()
+	}
+	return b.keys[i] < b.keys[j]
+}
+func (b byKey) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.sortBy[i], b.sortBy[j] = b.sortBy[j], b.sortBy[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
@@ -0,0 +1,93 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	type inner struct {
+		Ports []int `pulumi:"ports" provider:"set"`
+	}
+	type outer struct {
+		Tags    []string         `pulumi:"tags" provider:"set"`
+		Ordered []string         `pulumi:"ordered"`
+		Inner   inner            `pulumi:"inner"`
+		ByName  map[string]inner `pulumi:"byName"`
+		Secret  []string         `pulumi:"secret" provider:"set,secret"`
+	}
+
+	type m = resource.PropertyMap
+	a := func(elems ...any) resource.PropertyValue {
+		arr := make([]resource.PropertyValue, len(elems))
+		for i, e := range elems {
+			arr[i] = resource.NewPropertyValue(e)
+		}
+		return resource.NewProperty(arr)
+	}
+
+	actual := canonicalize[outer](m{
+		"tags":    a("b", "c", "a"),
+		"ordered": a("b", "c", "a"),
+		"inner":   resource.NewProperty(m{"ports": a(443, 80, 8080)}),
+		"byName": resource.NewProperty(m{
+			"x": resource.NewProperty(m{"ports": a(2, 10, 1)}),
+		}),
+		"secret": resource.MakeSecret(a("z", "y")),
+	})
+
+	assert.Equal(t, m{
+		"tags":    a("a", "b", "c"),
+		"ordered": a("b", "c", "a"),
+		"inner":   resource.NewProperty(m{"ports": a(80, 443, 8080)}),
+		"byName": resource.NewProperty(m{
+			"x": resource.NewProperty(m{"ports": a(1, 2, 10)}),
+		}),
+		"secret": resource.MakeSecret(a("y", "z")),
+	}, actual)
+}
+
+func TestCanonicalizeSetKey(t *testing.T) {
+	t.Parallel()
+
+	type rule struct {
+		Port int    `pulumi:"port"`
+		Name string `pulumi:"name"`
+	}
+	type args struct {
+		Rules []rule `pulumi:"rules" provider:"setKey=port"`
+	}
+
+	type m = resource.PropertyMap
+	r := func(port int, name string) resource.PropertyValue {
+		return resource.NewProperty(m{
+			"port": resource.NewProperty(float64(port)),
+			"name": resource.NewProperty(name),
+		})
+	}
+	a := func(elems ...resource.PropertyValue) resource.PropertyValue {
+		return resource.NewProperty(elems)
+	}
+
+	// Elements are sorted by port, numerically, rather than by their contents.
+	actual := canonicalize[args](m{"rules": a(r(443, "a"), r(80, "b"), r(8080, "c"))})
+	assert.Equal(t, m{"rules": a(r(80, "b"), r(443, "a"), r(8080, "c"))}, actual)
+}
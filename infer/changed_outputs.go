@@ -0,0 +1,77 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"slices"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
+)
+
+// changedOutputs sets the [p.DiffResponse.ChangedOutputs] of resp to the outputs of r
+// that, according to its [ExplicitDependencies], depend on an input that changed.
+//
+// These are the outputs that are computed during a preview of the update, so the engine
+// can show them as changing.
+func changedOutputs[R, I, O any](r *R, req p.DiffRequest, resp p.DiffResponse) (p.DiffResponse, error) {
+	wirer, ok := (any)(*r).(ExplicitDependencies[I, O])
+	if !ok || !resp.HasChanges {
+		return resp, nil
+	}
+	var input I
+	var output O
+	fg := newFieldGenerator(&input, &output)
+	wirer.WireDependencies(fg, &input, &output)
+	if err := fg.err.ErrorOrNil(); err != nil {
+		return resp, err
+	}
+
+	inputProps, err := introspect.FindProperties(typeFor[I]())
+	if err != nil {
+		return resp, err
+	}
+	olds, err := renameLegacyProperties[O](req.Olds)
+	if err != nil {
+		return resp, err
+	}
+	// As when outputs are marked computed during a preview, an input only counts as
+	// changed if its old value is in the state.
+	changed := func(k resource.PropertyKey) bool {
+		old, ok := olds[k]
+		return putil.IsComputed(req.News[k]) || (ok && !putil.DeepEquals(old, req.News[k]))
+	}
+	for name, field := range fg.fields {
+		// Inputs are already in the diff, if they changed.
+		if _, ok := inputProps[name]; ok {
+			continue
+		}
+		key := resource.PropertyKey(name)
+		if slices.Contains(resp.ChangedOutputs, key) {
+			continue
+		}
+		for _, dep := range field.deps {
+			if dep.has(inputComputed) && changed(resource.PropertyKey(dep.name)) {
+				resp.ChangedOutputs = append(resp.ChangedOutputs, key)
+				break
+			}
+		}
+	}
+	slices.Sort(resp.ChangedOutputs)
+	return resp, nil
+}
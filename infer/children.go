@@ -0,0 +1,140 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// ComponentChildren returns the URNs of the child resources of the component being
+// constructed, keyed by their logical names.
+//
+// Assign the result to an output of the component to expose its children, which is
+// useful for debugging and for policies that need to enumerate them:
+//
+//	type MyComponent struct {
+//		pulumi.ResourceState
+//		Children pulumi.StringMapOutput `pulumi:"children"`
+//	}
+//
+//	comp.Children = infer.ComponentChildren(ctx)
+//
+// The output resolves once Construct returns, so it includes every resource registered
+// with the component as its parent, including those registered after ComponentChildren
+// is called. Resources parented to a child are not included. If two children share a
+// name, the URN of the one registered last is used.
+//
+// ComponentChildren must be called from the Construct method of a component served by
+// [Component]. Otherwise the returned output fails to resolve.
+func ComponentChildren(ctx *pulumi.Context) pulumi.StringMapOutput {
+	c, ok := ctx.Value(childrenKey{}).(*componentChildren)
+	if !ok {
+		out, _, reject := ctx.NewOutput()
+		reject(errors.New("ComponentChildren must be called from the Construct method of a component"))
+		return out.ApplyT(func(v any) map[string]string { return nil }).(pulumi.StringMapOutput)
+	}
+	return c.output(ctx)
+}
+
+type childrenKey struct{}
+
+// componentChildren records the direct children of a component during Construct.
+type componentChildren struct {
+	urn resource.URN
+
+	m         sync.Mutex
+	component pulumi.Resource
+	children  map[string]resource.URN
+	resolve   func(any)
+	reject    func(error)
+	out       pulumi.StringMapOutput
+}
+
+// withChildren records the children of the component urn, constructed with opts, for use
+// with [ComponentChildren].
+//
+// finish must be called once the component's Construct method returns, with its error.
+func withChildren(
+	ctx *pulumi.Context, opts pulumi.ResourceOption, urn resource.URN,
+) (*pulumi.Context, pulumi.ResourceOption, *componentChildren) {
+	c := &componentChildren{urn: urn, children: map[string]resource.URN{}}
+	// Transformations are inherited, so this sees the component and each of its
+	// descendants.
+	record := pulumi.Transformations([]pulumi.ResourceTransformation{
+		func(args *pulumi.ResourceTransformationArgs) *pulumi.ResourceTransformationResult {
+			c.record(args)
+			return nil
+		},
+	})
+	if opts != nil {
+		record = pulumi.Composite(opts, record)
+	}
+	return ctx.WithValue(childrenKey{}, c), record, c
+}
+
+func (c *componentChildren) record(args *pulumi.ResourceTransformationArgs) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.component == nil {
+		if args.Type == c.urn.Type().String() && args.Name == c.urn.Name() {
+			c.component = args.Resource
+		}
+		return
+	}
+	options, err := pulumi.NewResourceOptions(args.Opts...)
+	if err != nil || options.Parent != c.component {
+		return
+	}
+	// The URN of a child is derived from the URN of its parent, so it is known without
+	// waiting for the child to be registered.
+	c.children[args.Name] = resource.NewURN(c.urn.Stack(), c.urn.Project(),
+		c.urn.QualifiedType(), tokens.Type(args.Type), args.Name)
+}
+
+func (c *componentChildren) output(ctx *pulumi.Context) pulumi.StringMapOutput {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.resolve == nil {
+		var out pulumi.Output
+		out, c.resolve, c.reject = ctx.NewOutput()
+		c.out = out.ApplyT(func(v any) map[string]string {
+			return v.(map[string]string)
+		}).(pulumi.StringMapOutput)
+	}
+	return c.out
+}
+
+// finish resolves the output of [ComponentChildren], or rejects it with err.
+func (c *componentChildren) finish(err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.resolve == nil {
+		return
+	}
+	if err != nil {
+		c.reject(err)
+		return
+	}
+	children := make(map[string]string, len(c.children))
+	for name, urn := range c.children {
+		children[name] = string(urn)
+	}
+	c.resolve(children)
+}
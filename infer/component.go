@@ -17,8 +17,10 @@ package infer
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 	pprovider "github.com/pulumi/pulumi/sdk/v3/go/pulumi/provider"
@@ -35,6 +37,13 @@ type ComponentResource[I any, O pulumi.ComponentResource] interface {
 	//
 	// ctx.RegisterResource needs to be called, but ctx.RegisterOutputs does not need to
 	// be called.
+	//
+	// To expose the URNs of the component's children as an output, see
+	// [ComponentChildren].
+	//
+	// ctx.Context() is canceled when the engine cancels the deployment. Pending
+	// registrations then fail on their own, but code that waits on anything else should
+	// also select on ctx.Context().Done() and return its error.
 	Construct(ctx *pulumi.Context, name, typ string, inputs I, opts pulumi.ResourceOption) (O, error)
 }
 
@@ -53,6 +62,22 @@ func (derivedComponentController[R, I, O]) isInferredComponent() {}
 // Component defines a component resource from go code. Here `R` is the component resource
 // anchor, `I` describes its inputs and `O` its outputs. To add descriptions to `R`, `I`
 // and `O`, see the `Annotated` trait defined in this module.
+//
+// Fields of `I` may be pulumi.Input types, or plain Go values such as `Length int`. The
+// values of plain fields are resolved before the component is constructed, and each of
+// the component's children depends on the resources they came from. If a plain value
+// is unknown during a preview, the component is registered without being constructed,
+// and each of its outputs is unknown. Plain fields can't hold secrets; use a
+// pulumi.Input type for inputs that may be secret.
+//
+// Fields of `I` and `O` may hold resources or types from other packages, such as
+// *s3.Bucket or pulumix.Output[*s3.Bucket], given a tag naming them in that package's
+// schema:
+//
+//	Bucket *s3.Bucket `pulumi:"bucket" provider:"type=aws@6.0.0:s3/bucket:Bucket"`
+//	Region string     `pulumi:"region" provider:"type=aws@6.0.0:index/region:Region"`
+//
+// Scalar fields, such as Region, refer to an enum of the other package.
 func Component[R ComponentResource[I, O], I any, O pulumi.ComponentResource]() InferredComponent {
 	return &derivedComponentController[R, I, O]{}
 }
@@ -78,26 +103,57 @@ func (rc *derivedComponentController[R, I, O]) GetToken() (tokens.Type, error) {
 	return getToken[R](nil)
 }
 
+// SourcePosition returns where R is declared, for errors about the component.
+func (rc *derivedComponentController[R, I, O]) SourcePosition() string {
+	return introspect.TypePosition(typeFor[R]())
+}
+
 func (rc *derivedComponentController[R, I, O]) Construct(
-	ctx context.Context, req p.ConstructRequest,
+	goCtx context.Context, req p.ConstructRequest,
 ) (p.ConstructResponse, error) {
-	return req.Construct(ctx,
+	return req.Construct(goCtx,
 		func(
 			ctx *pulumi.Context, inputs pprovider.ConstructInputs, opts pulumi.ResourceOption,
 		) (pulumi.ComponentResource, error) {
 			var r R
 			var i I
 			urn := req.URN
-			err := inputs.CopyTo(&i)
+			deps, known, err := copyComponentInputs(ctx, inputs.CopyTo, &i)
 			if err != nil {
 				return nil, fmt.Errorf("failed to copy inputs for %s (%s): %w",
 					urn.Name(), urn.Type(), err)
 			}
+			if !known {
+				// A plain input is unknown, so the component can't be constructed until
+				// the update.
+				return registerUnknownComponent[O](ctx, urn, opts)
+			}
+			if err := checkComponent(ctx.Context(), r, urn, i); err != nil {
+				return nil, err
+			}
+			if len(deps) > 0 {
+				opts = pulumi.Composite(opts, inheritDependencies(deps))
+			}
+			ctx, err = withProviders(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			ctx = ctx.WithValue(constructRequestKey{}, req)
+			if req.CustomTimeouts != nil {
+				opts = pulumi.Composite(opts, inheritTimeouts(*req.CustomTimeouts))
+			}
+			strict, _ := goCtx.Value(strictDryRunKey{}).(*StrictDryRun)
+			ctx, opts, violations := withStrictDryRun(ctx, opts, strict)
+			ctx, opts, children := withChildren(ctx, opts, urn)
 			res, err := r.Construct(ctx,
 				urn.Name(),
 				urn.Type().String(),
 				i, opts)
+			children.finish(err)
 			if err != nil {
+				return nil, componentConstructError(urn, err)
+			}
+			if err := violations.err(); err != nil {
 				return nil, err
 			}
 
@@ -110,3 +166,135 @@ func (rc *derivedComponentController[R, I, O]) Construct(
 			return res, err
 		})
 }
+
+type providersKey struct{}
+
+// GetProviders returns the providers passed to the component being constructed, as set
+// with the `provider` and `providers` resource options in the Pulumi program.
+//
+// These providers are already part of the options passed to
+// [ComponentResource.Construct], so they flow to every child resource parented to the
+// component. GetProviders is useful when a provider has to be passed explicitly, such as
+// to a resource that is not a child of the component.
+func GetProviders(ctx *pulumi.Context) []pulumi.ProviderResource {
+	providers, _ := ctx.Value(providersKey{}).([]pulumi.ProviderResource)
+	return providers
+}
+
+// withProviders records the providers of opts in ctx, for use with [GetProviders].
+func withProviders(ctx *pulumi.Context, opts pulumi.ResourceOption) (*pulumi.Context, error) {
+	options, err := pulumi.NewResourceOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.WithValue(providersKey{}, options.Providers), nil
+}
+
+type constructRequestKey struct{}
+
+// ResourceOptionsFromConstruct returns the Protect, RetainOnDelete, IgnoreChanges,
+// ReplaceOnChanges and AdditionalSecretOutputs options set on the component being
+// constructed, for use on its children.
+//
+// Unlike providers and timeouts, these options are not inherited by the component's
+// children. Spread them onto a child to apply them there too:
+//
+//	err := ctx.RegisterResource("pkg:index:Child", name, args, &child,
+//		infer.ResourceOptionsFromConstruct(ctx), pulumi.Parent(comp))
+//
+// Options set on the child after the returned option take precedence. Only options that
+// are set on the component are included, so outside of Construct the returned option
+// has no effect.
+func ResourceOptionsFromConstruct(ctx *pulumi.Context) pulumi.ResourceOption {
+	req, ok := ctx.Value(constructRequestKey{}).(p.ConstructRequest)
+	if !ok {
+		return pulumi.Composite()
+	}
+	var opts []pulumi.ResourceOption
+	if req.Protect {
+		opts = append(opts, pulumi.Protect(true))
+	}
+	if req.RetainOnDelete {
+		opts = append(opts, pulumi.RetainOnDelete(true))
+	}
+	if len(req.IgnoreChanges) > 0 {
+		opts = append(opts, pulumi.IgnoreChanges(req.IgnoreChanges))
+	}
+	if len(req.ReplaceOnChanges) > 0 {
+		opts = append(opts, pulumi.ReplaceOnChanges(req.ReplaceOnChanges))
+	}
+	if len(req.AdditionalSecretOutputs) > 0 {
+		opts = append(opts, pulumi.AdditionalSecretOutputs(req.AdditionalSecretOutputs))
+	}
+	return pulumi.Composite(opts...)
+}
+
+// inheritDependencies makes the component and each of its children depend on deps.
+//
+// Plain inputs are resolved before the component is constructed, so the dependencies of
+// their values would otherwise be lost.
+func inheritDependencies(deps []pulumi.Resource) pulumi.ResourceOption {
+	return pulumi.Transformations([]pulumi.ResourceTransformation{
+		func(args *pulumi.ResourceTransformationArgs) *pulumi.ResourceTransformationResult {
+			return &pulumi.ResourceTransformationResult{
+				Props: args.Props,
+				Opts:  append(args.Opts, pulumi.DependsOn(deps)),
+			}
+		},
+	})
+}
+
+// registerUnknownComponent registers the component at urn without constructing it. Each
+// of its outputs is unknown.
+func registerUnknownComponent[O pulumi.ComponentResource](
+	ctx *pulumi.Context, urn resource.URN, opts pulumi.ResourceOption,
+) (O, error) {
+	var o O
+	typ := reflect.TypeOf(o)
+	if typ == nil || typ.Kind() != reflect.Pointer || typ.Elem().Kind() != reflect.Struct {
+		return o, fmt.Errorf("%s has unknown inputs, so it must be a pointer to a struct", urn.Type())
+	}
+	v := reflect.New(typ.Elem())
+	o = v.Interface().(O)
+	if err := ctx.RegisterComponentResource(urn.Type().String(), urn.Name(), o, opts); err != nil {
+		return o, err
+	}
+	outputs := pulumi.Map{}
+	for _, field := range reflect.VisibleFields(typ.Elem()) {
+		if _, ok := field.Tag.Lookup("pulumi"); !ok || !field.IsExported() || !field.Type.Implements(outputType) {
+			continue
+		}
+		tag, err := introspect.ParseTag(field)
+		if err != nil {
+			return o, err
+		}
+		elem := reflect.New(field.Type).Elem().Interface().(pulumi.Output).ElementType()
+		// An apply on an unknown output is never run, so the result is an unknown output
+		// of the field's type.
+		applier := reflect.MakeFunc(
+			reflect.FuncOf([]reflect.Type{reflect.TypeOf((*any)(nil)).Elem()}, []reflect.Type{elem}, false),
+			func([]reflect.Value) []reflect.Value { return []reflect.Value{reflect.Zero(elem)} })
+		out := pulumi.UnsafeUnknownOutput(nil).ApplyT(applier.Interface())
+		if reflect.TypeOf(out).AssignableTo(field.Type) {
+			v.Elem().FieldByIndex(field.Index).Set(reflect.ValueOf(out))
+			outputs[tag.Name] = out
+		}
+	}
+	return o, ctx.RegisterResourceOutputs(o, outputs)
+}
+
+// inheritTimeouts applies timeouts to the component and each of its children.
+//
+// Custom timeouts are not inherited from a parent resource, so the timeouts set on a
+// component would otherwise have no effect on the resources it creates. Timeouts set
+// explicitly on a child take precedence.
+func inheritTimeouts(timeouts pulumi.CustomTimeouts) pulumi.ResourceOption {
+	return pulumi.Transformations([]pulumi.ResourceTransformation{
+		func(args *pulumi.ResourceTransformationArgs) *pulumi.ResourceTransformationResult {
+			return &pulumi.ResourceTransformationResult{
+				Props: args.Props,
+				Opts:  append([]pulumi.ResourceOption{pulumi.Timeouts(&timeouts)}, args.Opts...),
+			}
+		},
+	})
+}
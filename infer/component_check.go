@@ -0,0 +1,109 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	perrors "github.com/pulumi/pulumi/sdk/v3/go/pulumi/errors"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// ComponentCheck describes a component that validates its inputs before it is
+// constructed.
+//
+// Check is called with the inputs of the component once their values are known. If it
+// returns failures, the component is not constructed, and each failure is reported
+// against the input property it names, just like the failures of a custom resource's
+// Check:
+//
+//	func (*Cluster) Check(ctx context.Context, name string, inputs ClusterArgs) ([]p.CheckFailure, error) {
+//		if inputs.Nodes < 1 {
+//			return []p.CheckFailure{{Property: "nodes", Reason: "must be at least 1"}}, nil
+//		}
+//		return nil, nil
+//	}
+//
+// Construct can report invalid inputs in the same way by returning an [InputError], or
+// several joined with errors.Join.
+type ComponentCheck[I any] interface {
+	Check(ctx context.Context, name string, inputs I) ([]p.CheckFailure, error)
+}
+
+// checkComponent validates the inputs of the component at urn, if r implements
+// [ComponentCheck].
+func checkComponent[R, I any](ctx context.Context, r R, urn resource.URN, inputs I) error {
+	c, ok := any(r).(ComponentCheck[I])
+	if !ok {
+		return nil
+	}
+	failures, err := c.Check(ctx, urn.Name(), inputs)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return componentInputsError(urn, failures)
+}
+
+// componentConstructError converts err, returned by the Construct of the component at
+// urn, so that the engine attributes any [InputError] it holds to the input property it
+// names. Other errors are returned as is.
+func componentConstructError(urn resource.URN, err error) error {
+	failures, ok := inputFailures(err)
+	if !ok {
+		return err
+	}
+	return componentInputsError(urn, failures)
+}
+
+// inputFailures returns the failures described by err, if err consists only of
+// [InputError]s.
+func inputFailures(err error) ([]p.CheckFailure, bool) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var failures []p.CheckFailure
+		for _, err := range joined.Unwrap() {
+			f, ok := inputFailures(err)
+			if !ok {
+				return nil, false
+			}
+			failures = append(failures, f...)
+		}
+		return failures, len(failures) > 0
+	}
+	var inputErr InputError
+	if !errors.As(err, &inputErr) {
+		return nil, false
+	}
+	return []p.CheckFailure{{Property: inputErr.Property, Reason: inputErr.reason()}}, true
+}
+
+// componentInputsError reports failures as the invalid inputs of the component at urn.
+//
+// Construct sends an [perrors.InputPropertiesError] to the engine as an InvalidArgument
+// status that lists each property, just as [InputError] is sent for a custom resource.
+func componentInputsError(urn resource.URN, failures []p.CheckFailure) error {
+	details := make([]perrors.InputPropertyErrorDetails, len(failures))
+	for i, f := range failures {
+		details[i] = perrors.InputPropertyErrorDetails{PropertyPath: f.Property, Reason: f.Reason}
+	}
+	return perrors.NewInputPropertiesError(
+		fmt.Sprintf("%s %q has invalid inputs", urn.Type(), urn.Name()), details...)
+}
@@ -0,0 +1,135 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+var (
+	inputType     = reflect.TypeOf((*pulumi.Input)(nil)).Elem()
+	outputType    = reflect.TypeOf((*pulumi.Output)(nil)).Elem()
+	anyOutputType = reflect.TypeOf(pulumi.AnyOutput{})
+)
+
+// isPlainInput reports whether a component input of type t holds a plain Go value, rather
+// than a pulumi.Input or pulumi.Output (or a slice or map of them).
+func isPlainInput(t reflect.Type) bool {
+	isInputty := func(t reflect.Type) bool { return t.Implements(inputType) || t.Implements(outputType) }
+	if isInputty(t) {
+		return false
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Map) && isInputty(t.Elem()) {
+		return false
+	}
+	return true
+}
+
+// copyComponentInputs copies the inputs of a component to dst, a pointer to a struct, with
+// copyTo (usually [pprovider.ConstructInputs.CopyTo]).
+//
+// Fields that hold plain Go values, such as `Length int`, are resolved from the output
+// value sent by the engine. The dependencies of those values are returned, so they can be
+// passed on to the component's children. known is false if any of those values is unknown,
+// which only happens during a preview. Secret values can't be held by a plain field, so
+// they are rejected.
+func copyComponentInputs(
+	ctx *pulumi.Context, copyTo func(any) error, dst any,
+) (deps []pulumi.Resource, known bool, err error) {
+	dstV := reflect.ValueOf(dst).Elem()
+	typ := dstV.Type()
+	if typ.Kind() != reflect.Struct {
+		return nil, true, copyTo(dst)
+	}
+
+	// Copy the inputs to a shadow of dst in which each plain field is an output, so
+	// that its value can be resolved.
+	var shadowFields, plainFields []reflect.StructField
+	var shadowIndex, plainIndex []int
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup("pulumi"); !ok || !field.IsExported() {
+			continue
+		}
+		shadow := reflect.StructField{Name: field.Name, Type: field.Type, Tag: field.Tag}
+		if isPlainInput(field.Type) {
+			plainFields = append(plainFields, shadow)
+			plainIndex = append(plainIndex, i)
+			shadow.Type = anyOutputType
+		}
+		shadowFields = append(shadowFields, shadow)
+		shadowIndex = append(shadowIndex, i)
+	}
+	if len(plainFields) == 0 {
+		return nil, true, copyTo(dst)
+	}
+	shadow := reflect.New(reflect.StructOf(shadowFields)).Elem()
+	if err := copyTo(shadow.Addr().Interface()); err != nil {
+		return nil, false, err
+	}
+
+	known = true
+	plain := resource.PropertyMap{}
+	for i, field := range shadowFields {
+		v := shadow.Field(i)
+		original := typ.Field(shadowIndex[i])
+		if !isPlainInput(original.Type) {
+			dstV.Field(shadowIndex[i]).Set(v)
+			continue
+		}
+		if v.IsZero() {
+			// The input was not set.
+			continue
+		}
+		result, err := internals.UnsafeAwaitOutput(ctx.Context(), v.Interface().(pulumi.AnyOutput))
+		if err != nil {
+			return nil, false, err
+		}
+		if !result.Known {
+			known = false
+			continue
+		}
+		tag, err := introspect.ParseTag(field)
+		if err != nil {
+			return nil, false, err
+		}
+		if result.Secret {
+			return nil, false, fmt.Errorf("copying input %q: %s.%s is typed as %v, which can't hold a secret; "+
+				"use a type that implements pulumi.Input instead", tag.Name, typ, field.Name, original.Type)
+		}
+		deps = append(deps, result.Dependencies...)
+		plain[resource.PropertyKey(tag.Name)] = resource.NewPropertyValue(result.Value)
+	}
+
+	if !known {
+		return nil, false, nil
+	}
+	values := reflect.New(reflect.StructOf(plainFields))
+	if _, err := ende.DecodeTolerateMissing(plain, values.Interface()); err != nil {
+		return nil, false, err
+	}
+	for i, index := range plainIndex {
+		dstV.Field(index).Set(values.Elem().Field(i))
+	}
+	return deps, known, nil
+}
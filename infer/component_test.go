@@ -0,0 +1,405 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil/rpcerror"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/internals"
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+func TestInheritTimeouts(t *testing.T) {
+	t.Parallel()
+
+	type child struct{ pulumi.CustomResourceState }
+	type parent struct{ pulumi.ResourceState }
+
+	mocks := &integration.MockResourceMonitor{}
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		comp := &parent{}
+		err := ctx.RegisterComponentResource("pkg:index:Parent", "parent", comp,
+			inheritTimeouts(pulumi.CustomTimeouts{Create: "5m", Delete: "1h"}))
+		if err != nil {
+			return err
+		}
+		err = ctx.RegisterResource("pkg:index:Child", "inherits", nil, &child{},
+			pulumi.Parent(comp))
+		if err != nil {
+			return err
+		}
+		return ctx.RegisterResource("pkg:index:Child", "explicit", nil, &child{},
+			pulumi.Parent(comp), pulumi.Timeouts(&pulumi.CustomTimeouts{Create: "1m"}))
+	}, mocks)
+	require.NoError(t, err)
+
+	timeouts := map[string][3]string{}
+	for _, r := range mocks.Registered() {
+		if !r.Custom {
+			continue
+		}
+		ct := r.RegisterRPC.GetCustomTimeouts()
+		timeouts[r.Name] = [3]string{ct.GetCreate(), ct.GetUpdate(), ct.GetDelete()}
+	}
+	assert.Equal(t, map[string][3]string{
+		"inherits": {"5m", "", "1h"},
+		"explicit": {"1m", "", ""},
+	}, timeouts)
+}
+
+func TestGetProviders(t *testing.T) {
+	t.Parallel()
+
+	type provider struct{ pulumi.ProviderResourceState }
+
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		assert.Empty(t, GetProviders(ctx))
+
+		prov := &provider{}
+		err := ctx.RegisterResource("pulumi:providers:pkg", "prov", nil, prov)
+		if err != nil {
+			return err
+		}
+
+		ctx, err = withProviders(ctx, pulumi.Providers(prov))
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, []pulumi.ProviderResource{prov}, GetProviders(ctx))
+		return nil
+	}, &integration.MockResourceMonitor{})
+	require.NoError(t, err)
+}
+
+func TestStrictDryRun(t *testing.T) {
+	t.Parallel()
+
+	type custom struct{ pulumi.CustomResourceState }
+	type component struct{ pulumi.ResourceState }
+
+	strict := &StrictDryRun{NoPreview: []string{"command", "other:index:Script"}}
+	construct := func(dryRun bool) (sideEffect, constructErr error) {
+		err := pulumi.RunErr(func(ctx *pulumi.Context) error {
+			ctx, opts, violations := withStrictDryRun(ctx, nil, strict)
+			comp := &component{}
+			err := ctx.RegisterComponentResource("pkg:index:Component", "comp", comp, opts)
+			if err != nil {
+				return err
+			}
+			for _, tk := range []string{"command:local:Command", "other:index:Script", "other:index:Safe"} {
+				err := ctx.RegisterResource(tk, "child", nil, &custom{}, pulumi.Parent(comp))
+				if err != nil {
+					return err
+				}
+			}
+			sideEffect = CheckSideEffect(ctx, "send an email")
+			return violations.err()
+		}, pulumi.WithMocks("project", "stack", &integration.MockResourceMonitor{}),
+			func(info *pulumi.RunInfo) { info.DryRun = dryRun })
+		return sideEffect, err
+	}
+
+	sideEffect, err := construct(false)
+	assert.NoError(t, sideEffect)
+	assert.NoError(t, err)
+
+	sideEffect, err = construct(true)
+	assert.EqualError(t, sideEffect, "attempted to send an email during a preview")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `command:local:Command "child" was registered during a preview`)
+	assert.ErrorContains(t, err, `other:index:Script "child" was registered during a preview`)
+	assert.NotContains(t, err.Error(), "other:index:Safe")
+	assert.ErrorContains(t, err, "attempted to send an email during a preview")
+}
+
+func TestComponentChildren(t *testing.T) {
+	t.Parallel()
+
+	type custom struct{ pulumi.CustomResourceState }
+	type component struct{ pulumi.ResourceState }
+
+	urn := resource.NewURN("stack", "project", "", "pkg:index:Component", "comp")
+	var children map[string]string
+	want := map[string]string{}
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		ctx, opts, c := withChildren(ctx, nil, urn)
+		comp := &component{}
+		err := ctx.RegisterComponentResource("pkg:index:Component", "comp", comp, opts)
+		if err != nil {
+			return err
+		}
+		ComponentChildren(ctx).ApplyT(func(m map[string]string) map[string]string {
+			children = m
+			return m
+		})
+
+		child := &custom{}
+		err = ctx.RegisterResource("pkg:index:Child", "child", nil, child, pulumi.Parent(comp))
+		if err != nil {
+			return err
+		}
+		nested := &component{}
+		err = ctx.RegisterComponentResource("pkg:index:Nested", "nested", nested, pulumi.Parent(comp))
+		if err != nil {
+			return err
+		}
+		// Neither a grandchild nor a resource outside of the component is a child.
+		err = ctx.RegisterResource("pkg:index:Child", "grandchild", nil, &custom{}, pulumi.Parent(nested))
+		if err != nil {
+			return err
+		}
+		err = ctx.RegisterResource("pkg:index:Child", "other", nil, &custom{})
+		if err != nil {
+			return err
+		}
+
+		pulumi.All(child.URN(), nested.URN()).ApplyT(func(urns []any) error {
+			want["child"] = string(urns[0].(pulumi.URN))
+			want["nested"] = string(urns[1].(pulumi.URN))
+			return nil
+		})
+		c.finish(nil)
+		return nil
+	}, &integration.MockResourceMonitor{})
+	require.NoError(t, err)
+
+	assert.Len(t, children, 2)
+	assert.Equal(t, want, children)
+}
+
+func TestResourceOptionsFromConstruct(t *testing.T) {
+	t.Parallel()
+
+	type custom struct{ pulumi.CustomResourceState }
+
+	mocks := &integration.MockResourceMonitor{}
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		err := ctx.RegisterResource("pkg:index:Child", "outside", nil, &custom{},
+			ResourceOptionsFromConstruct(ctx))
+		if err != nil {
+			return err
+		}
+
+		ctx = ctx.WithValue(constructRequestKey{}, p.ConstructRequest{
+			Protect:                 true,
+			RetainOnDelete:          true,
+			IgnoreChanges:           []string{"tags"},
+			ReplaceOnChanges:        []string{"name"},
+			AdditionalSecretOutputs: []string{"password"},
+		})
+		return ctx.RegisterResource("pkg:index:Child", "inside", nil, &custom{},
+			ResourceOptionsFromConstruct(ctx))
+	}, mocks)
+	require.NoError(t, err)
+
+	registered := mocks.Registered()
+	require.Len(t, registered, 2)
+
+	outside := registered[0].RegisterRPC
+	assert.False(t, outside.GetProtect())
+	assert.False(t, outside.GetRetainOnDelete())
+	assert.Empty(t, outside.GetIgnoreChanges())
+
+	inside := registered[1].RegisterRPC
+	assert.True(t, inside.GetProtect())
+	assert.True(t, inside.GetRetainOnDelete())
+	assert.Equal(t, []string{"tags"}, inside.GetIgnoreChanges())
+	assert.Equal(t, []string{"name"}, inside.GetReplaceOnChanges())
+	assert.Equal(t, []string{"password"}, inside.GetAdditionalSecretOutputs())
+}
+
+func TestCopyComponentInputs(t *testing.T) {
+	t.Parallel()
+
+	type custom struct{ pulumi.CustomResourceState }
+	type args struct {
+		Name   pulumi.StringInput `pulumi:"name"`
+		Length int                `pulumi:"length"`
+		Tags   map[string]string  `pulumi:"tags,optional"`
+		Unset  *string            `pulumi:"unset,optional"`
+	}
+
+	// copyTo sets the fields of the shadow struct from values, keyed by their tags.
+	copyTo := func(values map[string]any) func(any) error {
+		return func(dst any) error {
+			v := reflect.ValueOf(dst).Elem()
+			for i := 0; i < v.NumField(); i++ {
+				tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("pulumi"), ",")
+				if value, ok := values[tag]; ok {
+					v.Field(i).Set(reflect.ValueOf(value))
+				}
+			}
+			return nil
+		}
+	}
+	toAny := func(o pulumi.Output) pulumi.AnyOutput {
+		return o.ApplyT(func(v any) any { return v }).(pulumi.AnyOutput)
+	}
+
+	t.Run("resolved", func(t *testing.T) {
+		t.Parallel()
+		err := integration.RunComponent(func(ctx *pulumi.Context) error {
+			dep := &custom{}
+			if err := ctx.RegisterResource("pkg:index:Custom", "dep", nil, dep); err != nil {
+				return err
+			}
+			name := pulumi.String("n")
+			var a args
+			deps, known, err := copyComponentInputs(ctx, copyTo(map[string]any{
+				"name": name,
+				// The length comes from another resource, so it has a dependency.
+				"length": toAny(dep.ID().ApplyT(func(pulumi.ID) int { return 3 })),
+				"tags":   toAny(pulumi.StringMap{"env": pulumi.String("dev")}.ToStringMapOutput()),
+			}), &a)
+			require.NoError(t, err)
+			assert.True(t, known)
+			assert.Equal(t, []pulumi.Resource{dep}, deps)
+			assert.Equal(t, args{Name: name, Length: 3, Tags: map[string]string{"env": "dev"}}, a)
+			return nil
+		}, &integration.MockResourceMonitor{})
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		err := integration.RunComponent(func(ctx *pulumi.Context) error {
+			var a args
+			_, known, err := copyComponentInputs(ctx, copyTo(map[string]any{
+				"length": pulumi.UnsafeUnknownOutput(nil),
+			}), &a)
+			require.NoError(t, err)
+			assert.False(t, known)
+			return nil
+		}, &integration.MockResourceMonitor{})
+		require.NoError(t, err)
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		t.Parallel()
+		err := integration.RunComponent(func(ctx *pulumi.Context) error {
+			var a args
+			_, _, err := copyComponentInputs(ctx, copyTo(map[string]any{
+				"length": toAny(pulumi.ToSecret(pulumi.Int(3)).(pulumi.IntOutput)),
+			}), &a)
+			assert.ErrorContains(t, err, `copying input "length"`)
+			return nil
+		}, &integration.MockResourceMonitor{})
+		require.NoError(t, err)
+	})
+}
+
+func TestRegisterUnknownComponent(t *testing.T) {
+	t.Parallel()
+
+	type component struct {
+		pulumi.ResourceState
+		Length pulumi.IntOutput `pulumi:"length"`
+	}
+
+	urn := resource.NewURN("stack", "project", "", "pkg:index:Component", "comp")
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		comp, err := registerUnknownComponent[*component](ctx, urn, nil)
+		require.NoError(t, err)
+		result, err := internals.UnsafeAwaitOutput(ctx.Context(), comp.Length)
+		require.NoError(t, err)
+		assert.False(t, result.Known)
+		return nil
+	}, &integration.MockResourceMonitor{})
+	require.NoError(t, err)
+}
+
+type checkedComponent struct{ pulumi.ResourceState }
+
+type checkedComponentArgs struct {
+	Nodes int `pulumi:"nodes"`
+}
+
+func (*checkedComponent) Construct(
+	ctx *pulumi.Context, name, typ string, inputs checkedComponentArgs, opts pulumi.ResourceOption,
+) (*checkedComponent, error) {
+	return nil, nil
+}
+
+func (*checkedComponent) Check(
+	ctx context.Context, name string, inputs checkedComponentArgs,
+) ([]p.CheckFailure, error) {
+	if inputs.Nodes < 1 {
+		return []p.CheckFailure{{Property: "nodes", Reason: "must be at least 1"}}, nil
+	}
+	return nil, nil
+}
+
+func TestComponentCheck(t *testing.T) {
+	t.Parallel()
+
+	urn := resource.NewURN("stack", "project", "", "pkg:index:Cluster", "cluster")
+	details := func(err error) []*rpc.InputPropertiesError_PropertyError {
+		s, ok := status.FromError(rpcerror.WrapDetailedError(err))
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+		require.Len(t, s.Details(), 1)
+		return s.Details()[0].(*rpc.InputPropertiesError).GetErrors()
+	}
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		r := &checkedComponent{}
+		require.NoError(t, checkComponent(context.Background(), r, urn, checkedComponentArgs{Nodes: 3}))
+
+		err := checkComponent(context.Background(), r, urn, checkedComponentArgs{})
+		assert.ErrorContains(t, err, `pkg:index:Cluster "cluster" has invalid inputs`)
+		errs := details(err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, "nodes", errs[0].GetPropertyPath())
+		assert.Equal(t, "must be at least 1", errs[0].GetReason())
+	})
+
+	t.Run("input errors", func(t *testing.T) {
+		t.Parallel()
+		err := componentConstructError(urn, errors.Join(
+			InputErrorf("nodes", "must be at least 1"),
+			fmt.Errorf("validating: %w", InputErrorf("region", "unknown region %q", "mars")),
+		))
+		errs := details(err)
+		require.Len(t, errs, 2)
+		assert.Equal(t, "nodes", errs[0].GetPropertyPath())
+		assert.Equal(t, "region", errs[1].GetPropertyPath())
+		assert.Equal(t, `unknown region "mars"`, errs[1].GetReason())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		t.Parallel()
+		other := errors.New("boom")
+		assert.Equal(t, other, componentConstructError(urn, other))
+
+		mixed := errors.Join(InputErrorf("nodes", "must be at least 1"), other)
+		assert.Equal(t, mixed, componentConstructError(urn, mixed))
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"reflect"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// configFunctionToken is the token of the function added by [Options.ExposeConfig].
+const configFunctionToken tokens.Type = "pkg:index:getProviderConfig"
+
+func (c *config[T]) function() InferredFunction { return &configFunction[T]{c} }
+
+// configFunction returns the non-secret parts of the provider's configuration.
+type configFunction[T any] struct{ config *config[T] }
+
+func (*configFunction[T]) isInferredFunction() {}
+
+func (*configFunction[T]) GetToken() (tokens.Type, error) { return configFunctionToken, nil }
+
+func (*configFunction[T]) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	if err := registerTypes[T](reg); err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	props, _, err := propertyListFromType(reflect.TypeOf(new(T)), false)
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	secret, err := secretProperties[T]()
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	for k := range secret {
+		delete(props, string(k))
+	}
+
+	return pschema.FunctionSpec{
+		Description: "Returns the configuration of the provider, for debugging.\n\n" +
+			"Secret values are omitted. Since a value may be set as a secret at runtime, " +
+			"every property is optional.",
+		Inputs:  &pschema.ObjectTypeSpec{Type: "object"},
+		Outputs: &pschema.ObjectTypeSpec{Type: "object", Properties: props},
+	}, nil
+}
+
+func (f *configFunction[T]) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	c := f.config
+	if c.t == nil || c.encoder == nil {
+		// The provider has not been configured yet, so there is nothing to report.
+		return p.InvokeResponse{Return: resource.PropertyMap{}}, nil
+	}
+	m, err := c.encoder.Encode(*c.t)
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	m = applySecrets[T](m)
+	for k, v := range m {
+		if v.ContainsSecrets() {
+			delete(m, k)
+		}
+	}
+	return p.InvokeResponse{Return: m}, nil
+}
+
+// secretProperties returns the top level properties of T that are tagged as secret.
+func secretProperties[T any]() (map[resource.PropertyKey]struct{}, error) {
+	typ := typeFor[T]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	props, err := introspect.FindProperties(typ)
+	if err != nil {
+		return nil, err
+	}
+	secret := map[resource.PropertyKey]struct{}{}
+	for name, prop := range props {
+		if prop.Secret {
+			secret[resource.PropertyKey(name)] = struct{}{}
+		}
+	}
+	return secret, nil
+}
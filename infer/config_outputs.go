@@ -0,0 +1,112 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// CustomConfigOutputs describes a provider configuration that computes outputs of the
+// explicit provider resource, such as the account ID its credentials resolve to:
+//
+//	type Config struct {
+//		Token     string `pulumi:"token" provider:"secret"`
+//		AccountID string `pulumi:"accountId,optional" provider:"output"`
+//	}
+//
+//	func (c *Config) ConfigOutputs(ctx context.Context) error {
+//		account, err := lookupAccount(ctx, c.Token)
+//		c.AccountID = account.ID
+//		return err
+//	}
+//
+// ConfigOutputs is called on the checked configuration at the end of CheckConfig, and
+// should set the fields tagged with `provider:"output"`. The engine records the checked
+// configuration as the state of the provider resource, so the fields set here become its
+// outputs. They are also passed back to the provider by Configure.
+//
+// This interface should be implemented by reference to allow setting fields on its
+// receiver.
+type CustomConfigOutputs interface {
+	ConfigOutputs(ctx context.Context) error
+}
+
+// configOutputProperties returns the top level properties of T that are tagged with
+// `provider:"output"`.
+//
+// Outputs are never set by the user, so they must be optional for the configuration to
+// be decoded.
+func configOutputProperties[T any]() (map[resource.PropertyKey]struct{}, error) {
+	typ := typeFor[T]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	props, err := introspect.FindProperties(typ)
+	if err != nil {
+		return nil, err
+	}
+	outputs := map[resource.PropertyKey]struct{}{}
+	for name, prop := range props {
+		if !prop.Output {
+			continue
+		}
+		if !prop.Optional {
+			return nil, fmt.Errorf("output property %q must be optional", name)
+		}
+		outputs[resource.PropertyKey(name)] = struct{}{}
+	}
+	return outputs, nil
+}
+
+// computeConfigOutputs sets the outputs of the checked configuration in resp, if T
+// implements [CustomConfigOutputs].
+func computeConfigOutputs[T any](ctx context.Context, resp p.CheckResponse) (p.CheckResponse, error) {
+	var t T
+	if v := reflect.ValueOf(t); v.Kind() == reflect.Pointer && v.IsNil() {
+		t = reflect.New(v.Type().Elem()).Interface().(T)
+	}
+	if !reflect.PointerTo(typeFor[T]()).Implements(typeFor[CustomConfigOutputs]()) &&
+		!typeFor[T]().Implements(typeFor[CustomConfigOutputs]()) {
+		return resp, nil
+	}
+	encoder, err := ende.DecodeConfig(resp.Inputs, &t)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	o, ok := ((interface{})(t)).(CustomConfigOutputs)
+	if !ok {
+		o = ((interface{})(&t)).(CustomConfigOutputs)
+	}
+	if err := o.ConfigOutputs(ctx); err != nil {
+		return p.CheckResponse{}, err
+	}
+	inputs, err := encoder.Encode(t)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	resp.Inputs = applySecrets[T](inputs)
+	return resp, nil
+}
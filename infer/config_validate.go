@@ -0,0 +1,167 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// configFailure is a problem with a nested configuration value.
+type configFailure struct {
+	path        resource.PropertyPath
+	reason      string
+	missing     bool   // true if the value is a required field that was not set.
+	description string // the description of the missing field.
+}
+
+// validateConfig reports the problems with the nested values of m, configuration for T.
+//
+// The mapper reports a problem in a nested object against the top level key, so
+// `pulumi config set --path` users can't tell which value to fix. validateConfig reports
+// missing required fields and invalid enum values by their full path instead.
+// Top level keys are left to the mapper.
+func validateConfig[T any](m resource.PropertyMap) []configFailure {
+	typ := typeFor[T]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	var failures []configFailure
+	for _, f := range reflect.VisibleFields(typ) {
+		tag, err := introspect.ParseTag(f)
+		if err != nil || tag.Internal {
+			continue
+		}
+		if v, ok := m[resource.PropertyKey(tag.Name)]; ok {
+			failures = append(failures, validateConfigValue(resource.PropertyPath{tag.Name}, f.Type, v)...)
+		}
+	}
+	return failures
+}
+
+func validateConfigValue(path resource.PropertyPath, typ reflect.Type, v resource.PropertyValue) []configFailure {
+	switch {
+	case v.IsSecret():
+		return validateConfigValue(path, typ, v.SecretValue().Element)
+	case v.IsOutput():
+		if !v.OutputValue().Known {
+			return nil
+		}
+		return validateConfigValue(path, typ, v.OutputValue().Element)
+	case v.IsComputed(), v.IsNull():
+		return nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if _, ok := ende.UnionVariants(typ); ok {
+		return nil
+	}
+
+	if enum, ok := isEnum(typ); ok {
+		allowed := make([]string, len(enum.values))
+		for i, ev := range enum.values {
+			allowed[i] = fmt.Sprint(ev.Value)
+			if allowed[i] == fmt.Sprint(v.V) {
+				return nil
+			}
+		}
+		return []configFailure{{
+			path:   path,
+			reason: "expected one of " + strings.Join(allowed, ", "),
+		}}
+	}
+
+	var failures []configFailure
+	switch typ.Kind() {
+	case reflect.Struct:
+		if !v.IsObject() {
+			return nil
+		}
+		obj := v.ObjectValue()
+		annotations := getAnnotated(typ)
+		for _, f := range reflect.VisibleFields(typ) {
+			tag, err := introspect.ParseTag(f)
+			if err != nil || tag.Internal {
+				continue
+			}
+			fieldPath := append(slices.Clone(path), tag.Name)
+			fv, ok := obj[resource.PropertyKey(tag.Name)]
+			if !ok || fv.IsNull() {
+				if !tag.Optional {
+					failures = append(failures, configFailure{
+						path:        fieldPath,
+						reason:      "missing required field",
+						missing:     true,
+						description: annotations.Descriptions[tag.Name],
+					})
+				}
+				continue
+			}
+			failures = append(failures, validateConfigValue(fieldPath, f.Type, fv)...)
+		}
+	case reflect.Slice, reflect.Array:
+		if !v.IsArray() {
+			return nil
+		}
+		for i, el := range v.ArrayValue() {
+			failures = append(failures, validateConfigValue(append(slices.Clone(path), i), typ.Elem(), el)...)
+		}
+	case reflect.Map:
+		if !v.IsObject() {
+			return nil
+		}
+		for k, el := range v.ObjectValue() {
+			failures = append(failures, validateConfigValue(append(slices.Clone(path), string(k)), typ.Elem(), el)...)
+		}
+	}
+	return failures
+}
+
+// withConfigFailures replaces the check failures of top level keys with the more precise
+// failures found by [validateConfig].
+func withConfigFailures(failures []p.CheckFailure, nested []configFailure) []p.CheckFailure {
+	if len(nested) == 0 {
+		return failures
+	}
+	roots := map[string]bool{}
+	for _, f := range nested {
+		roots[f.path[0].(string)] = true
+	}
+	merged := make([]p.CheckFailure, 0, len(failures)+len(nested))
+	for _, f := range failures {
+		if !roots[rootPropertyName(f.Property)] {
+			merged = append(merged, f)
+		}
+	}
+	for _, f := range nested {
+		merged = append(merged, p.CheckFailure{
+			Property: f.path.String(),
+			Reason:   f.reason,
+		})
+	}
+	return merged
+}
@@ -18,13 +18,16 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/mapper"
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
 	"github.com/pulumi/pulumi-go-provider/middleware/schema"
 )
 
@@ -33,7 +36,24 @@ import (
 // `T` has the same properties as an input or output type for a custom resource, and is
 // responsive to the same interfaces.
 //
-// `T` can implement [CustomDiff] and [CustomCheck] and [CustomConfigure] and [Annotated].
+// `T` can implement [CustomDiff], [CustomDiffConfig], [CustomCheck], [CustomCheckConfig],
+// [CustomConfigure], [CustomConfigOutputs] and [Annotated].
+//
+// Fields tagged with `provider:"secret"` are marked as secret in the config schema, so
+// `pulumi config set` warns when they are set without `--secret`, and they are returned as
+// secrets from CheckConfig even when they were set in plain text.
+//
+// Fields may be nested structs, slices, maps and enums. Their types are added to the
+// schema, so they can be set with `pulumi config set --path`, and check failures for
+// nested values name the full path of the value, such as `endpoint.url`.
+//
+// Unless `T` implements [CustomDiff] or [CustomDiffConfig], changes to the configuration update the provider in
+// place. Fields tagged with `provider:"forceNew"` replace the provider (and so every
+// resource it manages) when they change.
+//
+// Fields tagged with `provider:"output"` are outputs of the explicit provider resource,
+// so programs can reference them like the outputs of any other resource. They are not
+// accepted as configuration, and are set by [CustomConfigOutputs].
 func Config[T any]() InferredConfig {
 	return &config[T]{}
 }
@@ -41,6 +61,7 @@ func Config[T any]() InferredConfig {
 type InferredConfig interface {
 	schema.Resource
 	underlyingType() reflect.Type
+	function() InferredFunction
 	checkConfig(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error)
 	diffConfig(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error)
 	configure(ctx context.Context, req p.ConfigureRequest) error
@@ -62,7 +83,38 @@ type CustomConfigure interface {
 	Configure(ctx context.Context) error
 }
 
-type config[T any] struct{ t *T }
+// CustomCheckConfig describes a provider configuration that validates itself as a whole,
+// such as rejecting mutually exclusive keys.
+//
+// CheckConfig is called with the raw [p.CheckRequest] before the provider is configured.
+// The returned failures are reported to the user against their config keys, so
+// misconfiguration is caught before [CustomConfigure.Configure] runs. The returned value
+// becomes the checked configuration. Unlike the default check, defaults are not applied.
+//
+// If T implements both CustomCheckConfig and [CustomCheck], CustomCheckConfig is used.
+type CustomCheckConfig[T any] interface {
+	CheckConfig(ctx context.Context, req p.CheckRequest) (T, []p.CheckFailure, error)
+}
+
+// CustomDiffConfig describes a provider configuration that decides which of its changes
+// require replacing the provider and which can be applied in place.
+//
+// DiffConfig is called with the old and new configuration, decoded into T. A
+// [p.DiffResponse] that replaces the provider replaces every resource it manages, so
+// replacements should be reserved for changes (such as the region) that invalidate
+// existing resources.
+//
+// If T implements both CustomDiffConfig and [CustomDiff], CustomDiffConfig is used.
+type CustomDiffConfig[T any] interface {
+	DiffConfig(ctx context.Context, olds, news T) (p.DiffResponse, error)
+}
+
+type config[T any] struct {
+	t *T
+	// encoder is the encoder of the configuration passed to Configure. It records which
+	// values were secret.
+	encoder *ende.Encoder
+}
 
 func (*config[T]) underlyingType() reflect.Type {
 	var t T
@@ -75,16 +127,58 @@ func (*config[T]) GetSchema(reg schema.RegisterDerivativeType) (pschema.Resource
 		return pschema.ResourceSpec{}, err
 	}
 	r, errs := getResourceSchema[T, T, T](false)
+	outputs, err := configOutputProperties[T]()
+	if err != nil {
+		errs.Errors = append(errs.Errors, err)
+	}
+	for k := range outputs {
+		delete(r.InputProperties, string(k))
+	}
 	return r, errs.ErrorOrNil()
 }
 
 func (c *config[T]) checkConfig(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+	outputs, err := configOutputProperties[T]()
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	if len(outputs) > 0 {
+		// Outputs are computed by the provider, so values set by the user are dropped.
+		req.News = req.News.Copy()
+		for k := range outputs {
+			delete(req.News, k)
+		}
+	}
+	resp, err := c.checkConfigInputs(ctx, req)
+	if err != nil || len(resp.Failures) > 0 {
+		return resp, err
+	}
+	return computeConfigOutputs[T](ctx, resp)
+}
+
+func (c *config[T]) checkConfigInputs(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+	ctx = withRandomSeed(ctx, req.RandomSeed)
+	warnDeprecatedInputs[T](ctx, req.News)
 	var t T
 	if v := reflect.ValueOf(t); v.Kind() == reflect.Pointer && v.IsNil() {
 		t = reflect.New(v.Type().Elem()).Interface().(T)
 	}
 
 	encoder, decodeError := ende.DecodeConfig(req.News, &t)
+	if t, ok := ((interface{})(t)).(CustomCheckConfig[T]); ok {
+		checked, failures, err := t.CheckConfig(ctx, req)
+		if err != nil {
+			return p.CheckResponse{}, err
+		}
+		inputs, err := encoder.Encode(checked)
+		if err != nil {
+			return p.CheckResponse{}, err
+		}
+		return p.CheckResponse{
+			Inputs:   applySecrets[T](inputs),
+			Failures: failures,
+		}, nil
+	}
 	if t, ok := ((interface{})(t)).(CustomCheck[T]); ok {
 		// The user implemented check manually, so call that.
 		//
@@ -116,6 +210,7 @@ func (c *config[T]) checkConfig(ctx context.Context, req p.CheckRequest) (p.Chec
 	if err != nil {
 		return p.CheckResponse{}, err
 	}
+	failures = withConfigFailures(failures, validateConfig[T](req.News))
 
 	err = applyDefaults(&t)
 	if err != nil {
@@ -133,17 +228,66 @@ func (c *config[T]) checkConfig(ctx context.Context, req p.CheckRequest) (p.Chec
 	}, nil
 }
 
+// diffConfig computes the diff between the old and new provider configuration.
+//
+// By default, changes to the configuration update the provider in place. Only changes to
+// fields tagged with `provider:"forceNew"` (or `provider:"replaceOnChanges"`) replace
+// the provider, since replacing a provider replaces every resource it manages.
+//
+// If T implements [CustomDiff], it is used instead.
 func (c *config[T]) diffConfig(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
 	c.ensure()
-	return diff[T, T, T](ctx, req, c.t, func(string) bool { return true })
+	if d, ok := ((interface{})(*c.t)).(CustomDiffConfig[T]); ok {
+		decode := func(m resource.PropertyMap) (T, error) {
+			var t T
+			if v := reflect.ValueOf(t); v.Kind() == reflect.Pointer && v.IsNil() {
+				t = reflect.New(v.Type().Elem()).Interface().(T)
+			}
+			if _, err := ende.DecodeConfig(m, &t); err != nil {
+				return t, err
+			}
+			return t, nil
+		}
+		olds, err := decode(req.Olds)
+		if err != nil {
+			return p.DiffResponse{}, err
+		}
+		news, err := decode(req.News)
+		if err != nil {
+			return p.DiffResponse{}, err
+		}
+		return d.DiffConfig(ctx, olds, news)
+	}
+	props, err := introspect.FindProperties(typeFor[T]())
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	return diff[T, T, T](ctx, req, c.t, func(path string) bool {
+		prop, ok := props[rootPropertyName(path)]
+		return ok && (prop.ForceNew || prop.ReplaceOnChanges)
+	})
+}
+
+// rootPropertyName returns the name of the top level property referenced by a
+// [p.DiffResponse.DetailedDiff] key.
+func rootPropertyName(path string) string {
+	parsed, err := resource.ParsePropertyPath(path)
+	if err != nil || len(parsed) == 0 {
+		return path
+	}
+	if root, ok := parsed[0].(string); ok {
+		return root
+	}
+	return path
 }
 
 func (c *config[T]) configure(ctx context.Context, req p.ConfigureRequest) error {
 	c.ensure()
-	_, err := ende.DecodeConfig(req.Args, c.t)
+	encoder, err := ende.DecodeConfig(req.Args, c.t)
 	if err != nil {
-		return c.handleConfigFailures(ctx, err)
+		return c.handleConfigFailures(ctx, req.Args, err)
 	}
+	c.encoder = &encoder
 
 	// If we have a custom configure command, call that and return the error if any.
 	if typ := reflect.TypeOf(c.t).Elem(); typ.Implements(reflect.TypeOf((*CustomConfigure)(nil)).Elem()) {
@@ -165,7 +309,9 @@ func (c *config[T]) ensure() {
 	}
 }
 
-func (c *config[T]) handleConfigFailures(ctx context.Context, err mapper.MappingError) error {
+func (c *config[T]) handleConfigFailures(
+	ctx context.Context, args resource.PropertyMap, err mapper.MappingError,
+) error {
 	if err == nil {
 		return nil
 	}
@@ -183,7 +329,23 @@ func (c *config[T]) handleConfigFailures(ctx context.Context, err mapper.Mapping
 			tk := fmt.Sprintf("%s:%s", pkgName, err.Field())
 			missing[tk] = schema.InputProperties[err.Field()].Description
 		default:
-			return fmt.Errorf("unknown mapper error: %w", err)
+			nested := validateConfig[T](args)
+			if len(nested) == 0 {
+				return fmt.Errorf("unknown mapper error: %w", err)
+			}
+			// Nested values are reported by their path, as they would be set with
+			// `pulumi config set --path`.
+			var invalid []string
+			for _, f := range nested {
+				if f.missing {
+					missing[fmt.Sprintf("%s:%s", pkgName, f.path)] = f.description
+				} else {
+					invalid = append(invalid, fmt.Sprintf("%s: %s", f.path, f.reason))
+				}
+			}
+			if len(invalid) > 0 {
+				return fmt.Errorf("invalid configuration: %s", strings.Join(invalid, "; "))
+			}
 		}
 	}
 	return p.ConfigMissingKeys(missing)
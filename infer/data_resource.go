@@ -0,0 +1,181 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// DataSource is a read-only resource: an object that exists outside of Pulumi, which
+// users want to track in state and reference from other resources.
+//
+// A DataSource sits between a function and a [CustomResource]. Like a function, it only
+// looks up data, but like a resource its result is kept in state and refreshed with
+// `pulumi refresh`.
+//
+// Read is called to look up the object described by inputs when the resource is
+// created and whenever it is refreshed. Pulumi never modifies the object: any change to
+// the inputs replaces the resource with a new lookup, and deleting the resource only
+// removes it from state.
+//
+// Example:
+//
+//	type Image struct{}
+//
+//	type ImageArgs struct {
+//		Name string `pulumi:"name"`
+//	}
+//
+//	type ImageState struct {
+//		ImageArgs
+//		Digest string `pulumi:"digest"`
+//	}
+//
+//	func (Image) Read(ctx context.Context, name string, args ImageArgs) (string, ImageState, error) {
+//		digest, err := registry.Lookup(ctx, args.Name)
+//		return digest, ImageState{args, digest}, err
+//	}
+type DataSource[I, O any] interface {
+	// Read looks up the object described by inputs, returning its ID and current state.
+	//
+	// name is the name of the resource, as given in the Pulumi program.
+	Read(ctx context.Context, name string, inputs I) (id string, output O, err error)
+}
+
+// DataResource creates a new InferredResource from a [DataSource], where `R` is the
+// resource controller, `I` is the resources inputs and `O` is the resources outputs.
+//
+// Every input of a DataResource is marked as replaceOnChanges in the schema, since the
+// resource cannot be updated.
+func DataResource[R DataSource[I, O], I, O any]() InferredResource {
+	return &derivedDataResourceController[R, I, O]{
+		readOnlyController: readOnlyController[O]{kind: "data resource"},
+	}
+}
+
+type derivedDataResourceController[R DataSource[I, O], I, O any] struct {
+	readOnlyController[O]
+}
+
+func (*derivedDataResourceController[R, I, O]) isInferredResource() {}
+
+func (*derivedDataResourceController[R, I, O]) GetSchema(reg schema.RegisterDerivativeType) (
+	pschema.ResourceSpec, error) {
+	if err := registerTypes[I](reg); err != nil {
+		return pschema.ResourceSpec{}, err
+	}
+	if err := registerTypes[O](reg); err != nil {
+		return pschema.ResourceSpec{}, err
+	}
+	r, errs := getResourceSchema[R, I, O](false)
+	for k, prop := range r.InputProperties {
+		prop.ReplaceOnChanges = true
+		r.InputProperties[k] = prop
+	}
+	return r, errs.ErrorOrNil()
+}
+
+func (*derivedDataResourceController[R, I, O]) GetToken() (tokens.Type, error) {
+	return getToken[R](nil)
+}
+
+func (*derivedDataResourceController[R, I, O]) Check(
+	ctx context.Context, req p.CheckRequest,
+) (p.CheckResponse, error) {
+	var r R
+	return check[R, I](ctx, r, req)
+}
+
+func (*derivedDataResourceController[R, I, O]) Diff(
+	ctx context.Context, req p.DiffRequest,
+) (p.DiffResponse, error) {
+	var r R
+	// A data resource can't be updated, so every change is a replace.
+	return diff[R, I, O](ctx, req, &r, func(string) bool { return true })
+}
+
+func (*derivedDataResourceController[R, I, O]) Create(
+	ctx context.Context, req p.CreateRequest,
+) (p.CreateResponse, error) {
+	var r R
+	var err error
+	encoder, input, err := ende.Decode[I](req.Properties)
+	if err != nil {
+		return p.CreateResponse{}, fmt.Errorf("invalid inputs: %w", err)
+	}
+
+	// We can't look up an object whose inputs are not yet known, so during preview we
+	// leave the outputs unknown instead.
+	var id string
+	var o O
+	if !req.Preview || !req.Properties.ContainsUnknowns() {
+		id, o, err = r.Read(ctx, req.Urn.Name(), input)
+		if err != nil {
+			return p.CreateResponse{}, err
+		}
+		if id == "" && !req.Preview {
+			return p.CreateResponse{}, ProviderErrorf("'%s' was read without an id", req.Urn)
+		}
+	}
+
+	m, err := encoder.AllowUnknown(req.Preview).Encode(o)
+	if err != nil {
+		return p.CreateResponse{}, fmt.Errorf("encoding resource properties: %w", err)
+	}
+	m = canonicalize[O](m)
+
+	setDeps, err := getDependencies(ctx, &r, &input, &o, true /* isCreate */, req.Preview)
+	if err != nil {
+		return p.CreateResponse{}, err
+	}
+	setDeps(nil, req.Properties, m)
+
+	return p.CreateResponse{
+		ID:         id,
+		Properties: m,
+	}, nil
+}
+
+func (rc *derivedDataResourceController[R, I, O]) Read(
+	ctx context.Context, req p.ReadRequest,
+) (p.ReadResponse, error) {
+	var r R
+	var err error
+	// Unlike a custom resource, a data resource can only be found again from its
+	// inputs, so they are required to refresh or import it.
+	inputEncoder, inputs, err := ende.Decode[I](req.Inputs)
+	if err != nil {
+		return p.ReadResponse{}, fmt.Errorf("reading %s requires its inputs: %w", req.Urn, err)
+	}
+
+	id, o, err := r.Read(ctx, req.Urn.Name(), inputs)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+
+	i, err := inputEncoder.Encode(inputs)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	return rc.readResponse(req, id, o, i)
+}
@@ -0,0 +1,70 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
+)
+
+type dependenciesKey struct{}
+
+// withDependencies records the dependencies of the inputs of a Create or Update, for
+// [Dependencies]. They are taken from the request, which carries the dependencies the
+// engine sent, and from any output values left in props.
+func withDependencies(
+	ctx context.Context, props resource.PropertyMap, known map[resource.PropertyKey][]resource.URN,
+) context.Context {
+	deps := map[string][]resource.URN{}
+	add := func(from map[resource.PropertyKey][]resource.URN) {
+		for k, urns := range from {
+			for _, urn := range urns {
+				if !slices.Contains(deps[string(k)], urn) {
+					deps[string(k)] = append(deps[string(k)], urn)
+				}
+			}
+		}
+	}
+	add(known)
+	add(putil.Dependencies(props))
+	return context.WithValue(ctx, dependenciesKey{}, deps)
+}
+
+// Dependencies returns the URNs of the resources each input of the current Create or
+// Update depends on, by the name of the input.
+//
+// Typed inputs don't record where their values came from, so resources that need to
+// know which inputs were taken from other resources, such as to order calls to an
+// external API, can read it here:
+//
+//	func (*Record) Create(
+//		ctx context.Context, name string, input RecordArgs, preview bool,
+//	) (string, RecordState, error) {
+//		if deps := infer.Dependencies(ctx)["zone"]; len(deps) > 0 {
+//			...
+//		}
+//	}
+//
+// Dependencies are only known when the engine sends inputs as output values, which it
+// does for providers that advertise [p.Capabilities.AcceptOutputs]. Inputs without known
+// dependencies are not in the returned map.
+func Dependencies(ctx context.Context) map[string][]resource.URN {
+	deps, _ := ctx.Value(dependenciesKey{}).(map[string][]resource.URN)
+	return deps
+}
@@ -0,0 +1,92 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// DeprecationNotice gives operators of a deprecated provider migration guidance inside
+// the Pulumi CLI.
+//
+// The notice is logged as a warning the first time the provider is configured, and is
+// recorded as the deprecation message of the provider in the schema (unless
+// [schema.Metadata] already sets a deprecation message).
+type DeprecationNotice struct {
+	// Message explains why the provider is deprecated and what to use instead.
+	Message string
+	// DocURL optionally links to a migration guide.
+	DocURL string
+
+	once sync.Once
+}
+
+// WithDeprecationNotice creates a [DeprecationNotice] for [Options.Deprecation].
+func WithDeprecationNotice(msg, docURL string) *DeprecationNotice {
+	return &DeprecationNotice{Message: msg, DocURL: docURL}
+}
+
+func (n *DeprecationNotice) String() string {
+	if n.DocURL == "" {
+		return n.Message
+	}
+	return n.Message + " See " + n.DocURL + " for migration guidance."
+}
+
+// wrapConfigure logs the notice on the first call to Configure.
+func (n *DeprecationNotice) wrapConfigure(
+	configure func(context.Context, p.ConfigureRequest) error,
+) func(context.Context, p.ConfigureRequest) error {
+	return func(ctx context.Context, req p.ConfigureRequest) error {
+		n.once.Do(func() {
+			p.GetLogger(ctx).Warningf("This provider is deprecated: %s", n)
+		})
+		if configure == nil {
+			return nil
+		}
+		return configure(ctx, req)
+	}
+}
+
+// warnDeprecatedInputs logs a warning for each top level property of I that was marked
+// with [Annotator.Deprecate] and is set in news.
+func warnDeprecatedInputs[I any](ctx context.Context, news resource.PropertyMap) {
+	typ := typeFor[I]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	deprecated := getAnnotated(typ).DeprecatedFields
+
+	keys := make([]string, 0, len(deprecated))
+	for k := range deprecated {
+		if v, ok := news[resource.PropertyKey(k)]; ok && !v.IsNull() {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		p.GetLogger(ctx).Warningf("Property %q is deprecated: %s", k, deprecated[k])
+	}
+}
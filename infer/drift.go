@@ -0,0 +1,101 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"maps"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// CustomDriftDetect describes a resource that can detect changes made to it outside of
+// Pulumi.
+//
+// During Diff, DetectDrift is given the state recorded by the last Create, Update or
+// Read. It should compare that state against the live resource and report each property
+// that no longer matches. Each drifted property becomes an entry of the detailed diff
+// with InputDiff set to false, so the engine shows it as a change to state rather than
+// to the program's inputs, and the resource is updated to remove the drift.
+//
+// Drift is reported in addition to the diff between the old and new inputs, computed by
+// [CustomDiff] or by default. When a property has both, the input diff is kept.
+type CustomDriftDetect[O any] interface {
+	DetectDrift(ctx context.Context, id string, state O) (DriftReport, error)
+}
+
+// DriftReport describes the changes made to a resource outside of Pulumi.
+type DriftReport struct {
+	// Properties lists the properties that differ between the recorded and the live
+	// state. An empty report means that the resource has not drifted.
+	Properties []PropertyDrift
+}
+
+// PropertyDrift describes a single property that changed outside of Pulumi.
+type PropertyDrift struct {
+	// Path is the path of the property, using the syntax of [p.DiffResponse.DetailedDiff],
+	// such as "tags.env" or "rules[0]".
+	Path string
+	// Reason explains the drift to the user, such as "modified outside of Pulumi". If
+	// set, it is logged as a warning on the resource.
+	Reason string
+	// Kind is the kind of change needed to remove the drift. It defaults to [p.Update],
+	// or [p.UpdateReplace] if changing the property replaces the resource.
+	Kind p.DiffKind
+}
+
+// detectDrift adds the drift reported by r to diff.
+func detectDrift[R, I, O any](
+	ctx context.Context, r *R, req p.DiffRequest, diff p.DiffResponse, forceReplace func(string) bool,
+) (p.DiffResponse, error) {
+	d, ok := (any(*r)).(CustomDriftDetect[O])
+	if !ok {
+		return diff, nil
+	}
+	_, state, err := hydrateFromState[R, I, O](ctx, *r, req.Olds)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	report, err := d.DetectDrift(ctx, req.ID, state)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	if len(report.Properties) == 0 {
+		return diff, nil
+	}
+
+	diff.DetailedDiff = maps.Clone(diff.DetailedDiff)
+	if diff.DetailedDiff == nil {
+		diff.DetailedDiff = map[string]p.PropertyDiff{}
+	}
+	for _, drift := range report.Properties {
+		if drift.Reason != "" {
+			p.GetLogger(ctx).Warningf("%s drifted: %s", drift.Path, drift.Reason)
+		}
+		if _, ok := diff.DetailedDiff[drift.Path]; ok {
+			continue
+		}
+		kind := drift.Kind
+		if kind == "" {
+			kind = p.Update
+			if forceReplace(drift.Path) {
+				kind = p.UpdateReplace
+			}
+		}
+		diff.DetailedDiff[drift.Path] = p.PropertyDiff{Kind: kind, InputDiff: false}
+	}
+	diff.HasChanges = true
+	return diff, nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// StrictDryRun makes components fail to construct during a preview when they do
+// something that is only safe during an update.
+//
+// Nothing stops a component's Construct method from changing external systems while the
+// engine is only previewing. Strict dry-run mode is an opt-in contract check, intended for
+// tests and development builds, that catches the common mistakes:
+//
+//   - Registering a custom resource whose provider does not support previews, listed in
+//     NoPreview.
+//   - Performing an action with side effects, guarded by [CheckSideEffect].
+//
+// Violations are collected while the component is constructed, and reported together as
+// the error of Construct.
+type StrictDryRun struct {
+	// NoPreview lists the resources whose providers do not support previews, either by
+	// package ("command") or by type token ("command:local:Command").
+	NoPreview []string
+}
+
+type strictDryRunKey struct{}
+
+// dryRunViolations collects the violations of a [StrictDryRun] during a single Construct.
+type dryRunViolations struct {
+	strict *StrictDryRun
+
+	m    sync.Mutex
+	errs []error
+}
+
+func (v *dryRunViolations) add(err error) {
+	v.m.Lock()
+	defer v.m.Unlock()
+	v.errs = append(v.errs, err)
+}
+
+func (v *dryRunViolations) err() error {
+	if v == nil {
+		return nil
+	}
+	v.m.Lock()
+	defer v.m.Unlock()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("strict dry-run: %w", errors.Join(v.errs...))
+}
+
+// noPreview returns true if tk names a resource whose provider does not support previews.
+func (v *dryRunViolations) noPreview(tk tokens.Type) bool {
+	return slices.ContainsFunc(v.strict.NoPreview, func(s string) bool {
+		if strings.Contains(s, ":") {
+			return tokens.Type(s) == tk
+		}
+		return tk.Package().String() == s
+	})
+}
+
+// withStrictDryRun enforces strict on the children of a component constructed with opts
+// during a preview.
+//
+// If ctx is not a preview or strict is nil, ctx and opts are returned unchanged along
+// with nil violations.
+func withStrictDryRun(
+	ctx *pulumi.Context, opts pulumi.ResourceOption, strict *StrictDryRun,
+) (*pulumi.Context, pulumi.ResourceOption, *dryRunViolations) {
+	if strict == nil || !ctx.DryRun() {
+		return ctx, opts, nil
+	}
+	v := &dryRunViolations{strict: strict}
+	// Transformations are inherited, so this sees every resource registered as a
+	// descendant of the component.
+	check := pulumi.Transformations([]pulumi.ResourceTransformation{
+		func(args *pulumi.ResourceTransformationArgs) *pulumi.ResourceTransformationResult {
+			_, custom := args.Resource.(pulumi.CustomResource)
+			if custom && v.noPreview(tokens.Type(args.Type)) {
+				v.add(fmt.Errorf("%s %q was registered during a preview, but its provider "+
+					"does not support previews", args.Type, args.Name))
+			}
+			return &pulumi.ResourceTransformationResult{Props: args.Props, Opts: args.Opts}
+		},
+	})
+	if opts != nil {
+		check = pulumi.Composite(opts, check)
+	}
+	return ctx.WithValue(strictDryRunKey{}, v), check, v
+}
+
+// CheckSideEffect guards an action with side effects outside of Pulumi, such as an
+// invoke that creates or modifies an external object, in a component's Construct method.
+//
+// Under [StrictDryRun], CheckSideEffect returns an error describing action during a
+// preview, and Construct fails even if the error is ignored. Otherwise it returns nil, so
+// the guard can be left in production builds:
+//
+//	if err := infer.CheckSideEffect(ctx, "rotate the API key"); err != nil {
+//		return nil, err
+//	}
+//	key, err := client.RotateKey(ctx.Context(), args.KeyID)
+func CheckSideEffect(ctx *pulumi.Context, action string) error {
+	v, ok := ctx.Value(strictDryRunKey{}).(*dryRunViolations)
+	if !ok {
+		return nil
+	}
+	err := fmt.Errorf("attempted to %s during a preview", action)
+	v.add(err)
+	return err
+}
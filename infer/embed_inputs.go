@@ -0,0 +1,68 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"reflect"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// EmbedInputs copies inputs into the resource's outputs after Create and Update.
+//
+// An input is copied when the outputs have a property with the same name and type, and
+// Create or Update left that property unset. This keeps the inputs in state, as
+// [CustomResource] recommends, without copying them by hand. Write-only inputs are never
+// copied.
+func EmbedInputs() ResourceOption {
+	return func(o *resourceOptions) { o.embedInputs = true }
+}
+
+// embedInputs copies the fields of input into the zero valued fields of output that
+// share their name and type.
+func embedInputs[I, O any](input *I, output *O) {
+	src := reflect.ValueOf(input).Elem()
+	dst := reflect.ValueOf(output).Elem()
+	if src.Kind() != reflect.Struct || dst.Kind() != reflect.Struct {
+		return
+	}
+
+	inputs := map[string]reflect.Value{}
+	for _, f := range reflect.VisibleFields(src.Type()) {
+		tag, err := introspect.ParseTag(f)
+		if err != nil || tag.Internal || tag.WriteOnly {
+			continue
+		}
+		if v, err := src.FieldByIndexErr(f.Index); err == nil {
+			inputs[tag.Name] = v
+		}
+	}
+
+	for _, f := range reflect.VisibleFields(dst.Type()) {
+		tag, err := introspect.ParseTag(f)
+		if err != nil || tag.Internal {
+			continue
+		}
+		v, ok := inputs[tag.Name]
+		if !ok || !v.Type().AssignableTo(f.Type) {
+			continue
+		}
+		field, err := dst.FieldByIndexErr(f.Index)
+		if err != nil || !field.CanSet() || !field.IsZero() {
+			continue
+		}
+		field.Set(v)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encoding converts Go values to and from the property maps Pulumi uses for the
+// inputs and outputs of resources, in the same way [github.com/pulumi/pulumi-go-provider/infer]
+// does.
+//
+// Types that can't be mapped field by field, such as time.Time, can be given a [Codec]
+// with [Register]. They can then be used in args and state structs directly:
+//
+//	func main() {
+//		encoding.Register(encoding.Text[time.Time]())
+//		encoding.Register(encoding.Text[net.IP]())
+//		p.RunProvider("my-provider", "0.1.0", infer.Provider(...))
+//	}
+//
+//	type ScheduleArgs struct {
+//		Start time.Time `pulumi:"start"`
+//		Host  net.IP    `pulumi:"host"`
+//	}
+//
+// json.RawMessage has a codec registered by default, which represents it as free-form JSON
+// (pulumi.json#/Json). Fields of type any or map[string]any hold any property value
+// (pulumi.json#/Any) without a codec.
+package encoding
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+)
+
+// Codec converts values of type T to and from property values.
+type Codec[T any] struct {
+	// The schema type values of T are represented as, such as
+	//
+	//	schema.TypeSpec{Type: "string"}
+	Type pschema.TypeSpec
+	// Encode converts a value of T to a property value of Type.
+	Encode func(T) (resource.PropertyValue, error)
+	// Decode converts a property value of Type to a value of T.
+	Decode func(resource.PropertyValue) (T, error)
+}
+
+// Register uses codec for every value of type T in the config, inputs and outputs of
+// inferred resources, components and functions, and in the schema.
+//
+// Register should be called before the provider is served, such as at the start of main.
+// Registering another codec for T replaces the first.
+func Register[T any](codec Codec[T]) {
+	if codec.Encode == nil || codec.Decode == nil {
+		panic(fmt.Sprintf("the codec for %s must have both Encode and Decode", reflect.TypeFor[T]()))
+	}
+	ende.RegisterCodec(reflect.TypeFor[T](), ende.Codec{
+		Schema: codec.Type,
+		Encode: func(v any) (resource.PropertyValue, error) { return codec.Encode(v.(T)) },
+		Decode: func(v resource.PropertyValue) (any, error) { return codec.Decode(v) },
+	})
+}
+
+// Text returns a codec that represents values of T as strings, using their MarshalText
+// and UnmarshalText methods.
+//
+// time.Time is represented in RFC 3339 format, and net.IP in its usual notation.
+func Text[T encoding.TextMarshaler, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}]() Codec[T] {
+	return Codec[T]{
+		Type: pschema.TypeSpec{Type: "string"},
+		Encode: func(v T) (resource.PropertyValue, error) {
+			text, err := v.MarshalText()
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			return resource.NewStringProperty(string(text)), nil
+		},
+		Decode: func(v resource.PropertyValue) (T, error) {
+			var t T
+			if !v.IsString() {
+				return t, fmt.Errorf("expected a string, found %s", v.TypeString())
+			}
+			err := PT(&t).UnmarshalText([]byte(v.StringValue()))
+			return t, err
+		},
+	}
+}
+
+// Decode decodes m into a value of type T, which is usually a struct with `pulumi:"x"`
+// tags.
+//
+// Secrets and unknowns in m are decoded as their underlying values.
+func Decode[T any](m resource.PropertyMap) (T, error) {
+	_, v, err := ende.Decode[T](m)
+	if err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// Encode encodes v, which is usually a struct with `pulumi:"x"` tags, into a property map.
+func Encode(v any) (resource.PropertyMap, error) {
+	m, err := ende.Encoder{}.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
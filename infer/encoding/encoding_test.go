@@ -0,0 +1,128 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	Register(Text[time.Time]())
+	Register(Text[net.IP]())
+	Register(Codec[url.URL]{
+		Type: pschema.TypeSpec{Type: "string"},
+		Encode: func(u url.URL) (resource.PropertyValue, error) {
+			return resource.NewStringProperty(u.String()), nil
+		},
+		Decode: func(v resource.PropertyValue) (url.URL, error) {
+			u, err := url.Parse(v.StringValue())
+			if err != nil {
+				return url.URL{}, err
+			}
+			return *u, nil
+		},
+	})
+}
+
+type (
+	Schedule struct {
+		Start    time.Time            `pulumi:"start"`
+		End      *time.Time           `pulumi:"end,optional"`
+		Hosts    []net.IP             `pulumi:"hosts"`
+		Webhooks map[string]url.URL   `pulumi:"webhooks,optional"`
+		Window   Window               `pulumi:"window"`
+		Labels   map[string]time.Time `pulumi:"labels,optional"`
+	}
+	Window struct {
+		Opens time.Time `pulumi:"opens"`
+	}
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	m := resource.PropertyMap{
+		"start": resource.NewStringProperty("2024-03-01T12:30:00Z"),
+		"hosts": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("10.0.0.1"),
+			resource.MakeSecret(resource.NewStringProperty("::1")),
+		}),
+		"webhooks": resource.NewObjectProperty(resource.PropertyMap{
+			"deploy": resource.NewStringProperty("https://example.com/hook?x=1"),
+		}),
+		"window": resource.NewObjectProperty(resource.PropertyMap{
+			"opens": resource.NewStringProperty("2024-03-01T08:00:00Z"),
+		}),
+	}
+
+	s, err := Decode[Schedule](m)
+	require.NoError(t, err)
+	assert.Equal(t, start, s.Start)
+	assert.Nil(t, s.End)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("::1")}, s.Hosts)
+	assert.Equal(t, "example.com", s.Webhooks["deploy"].Host)
+	assert.Equal(t, 8, s.Window.Opens.Hour())
+
+	end := start.Add(time.Hour)
+	s.End = &end
+	s.Labels = map[string]time.Time{"created": start}
+	encoded, err := Encode(s)
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"start": resource.NewStringProperty("2024-03-01T12:30:00Z"),
+		"end":   resource.NewStringProperty("2024-03-01T13:30:00Z"),
+		"hosts": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("10.0.0.1"),
+			resource.NewStringProperty("::1"),
+		}),
+		"webhooks": resource.NewObjectProperty(resource.PropertyMap{
+			"deploy": resource.NewStringProperty("https://example.com/hook?x=1"),
+		}),
+		"window": resource.NewObjectProperty(resource.PropertyMap{
+			"opens": resource.NewStringProperty("2024-03-01T08:00:00Z"),
+		}),
+		"labels": resource.NewObjectProperty(resource.PropertyMap{
+			"created": resource.NewStringProperty("2024-03-01T12:30:00Z"),
+		}),
+	}, encoded)
+}
+
+func TestDecodeError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Decode[Schedule](resource.PropertyMap{
+		"start": resource.NewStringProperty("yesterday"),
+		"hosts": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewNumberProperty(10),
+		}),
+		"window": resource.NewObjectProperty(resource.PropertyMap{
+			"opens": resource.NewStringProperty("2024-03-01T08:00:00Z"),
+		}),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `start`)
+	assert.ErrorContains(t, err, `cannot parse "yesterday"`)
+	assert.ErrorContains(t, err, `hosts[0]`)
+	assert.ErrorContains(t, err, "expected a string, found number")
+}
@@ -16,6 +16,10 @@ package infer
 
 import (
 	"fmt"
+
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // ResourceInitFailedError indicates that the resource was created but failed to initialize.
@@ -82,3 +86,54 @@ func (err ProviderError) Error() string {
 	}
 	return prefix + ": " + err.Inner.Error() + suffix
 }
+
+// InputError indicates that an input property of a resource is invalid.
+//
+// When returned from Create or Update, the error is sent to the engine as an
+// InvalidArgument status that names the offending property, so the CLI can point the
+// user at the input that needs to change:
+//
+//	if input.Size > maxSize {
+//		return "", State{}, infer.InputErrorf("size", "must be at most %d", maxSize)
+//	}
+type InputError struct {
+	// Property is the path of the invalid property, such as "size" or "tags.env".
+	Property string
+	Inner    error
+}
+
+// InputErrorf creates a new [InputError] for property.
+//
+// Arguments are formatted with [fmt.Errorf].
+func InputErrorf(property, msg string, a ...any) error {
+	return InputError{Property: property, Inner: fmt.Errorf(msg, a...)}
+}
+
+func (err InputError) Error() string {
+	return fmt.Sprintf("invalid value for %q: %s", err.Property, err.reason())
+}
+
+func (err InputError) Unwrap() error { return err.Inner }
+
+// GRPCStatus converts err into an InvalidArgument status annotated with the property
+// path.
+func (err InputError) GRPCStatus() *status.Status {
+	s := status.New(codes.InvalidArgument, err.Error())
+	detailed, detailErr := s.WithDetails(&rpc.InputPropertiesError{
+		Errors: []*rpc.InputPropertiesError_PropertyError{{
+			PropertyPath: err.Property,
+			Reason:       err.reason(),
+		}},
+	})
+	if detailErr != nil {
+		return s
+	}
+	return detailed
+}
+
+func (err InputError) reason() string {
+	if err.Inner == nil {
+		return "invalid input"
+	}
+	return err.Inner.Error()
+}
@@ -0,0 +1,128 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// ExternalSource is a resource that is owned by another system, but that users need in
+// their dependency graph.
+//
+// An ExternalSource can only be read: users bring it into their program with the
+// resource's `get` method or with `pulumi import`, and Pulumi never creates, updates or
+// deletes it. Deleting the resource only removes it from state.
+//
+// Example:
+//
+//	type Account struct{}
+//
+//	type AccountState struct {
+//		Email string `pulumi:"email"`
+//	}
+//
+//	func (Account) Read(ctx context.Context, id string) (string, AccountState, error) {
+//		account, err := client.GetAccount(ctx, id)
+//		if errors.Is(err, client.ErrNotFound) {
+//			return "", AccountState{}, nil
+//		}
+//		return id, AccountState{Email: account.Email}, err
+//	}
+type ExternalSource[O any] interface {
+	// Read fetches the current state of the resource with the given id.
+	//
+	// If the resource no longer exists, Read should return an empty id.
+	Read(ctx context.Context, id string) (canonicalID string, output O, err error)
+}
+
+// ExternalResource creates a new InferredResource from an [ExternalSource], where `R` is
+// the resource controller and `O` is the resources outputs.
+//
+// External resources have no inputs, and their schema description notes that they can
+// only be read.
+func ExternalResource[R ExternalSource[O], O any]() InferredResource {
+	return &derivedExternalResourceController[R, O]{
+		readOnlyController: readOnlyController[O]{kind: "external resource"},
+	}
+}
+
+type derivedExternalResourceController[R ExternalSource[O], O any] struct {
+	readOnlyController[O]
+}
+
+func (*derivedExternalResourceController[R, O]) isInferredResource() {}
+
+func (*derivedExternalResourceController[R, O]) GetSchema(reg schema.RegisterDerivativeType) (
+	pschema.ResourceSpec, error) {
+	if err := registerTypes[O](reg); err != nil {
+		return pschema.ResourceSpec{}, err
+	}
+	r, errs := getResourceSchema[R, struct{}, O](false)
+	const note = "This resource is managed outside of Pulumi. It can be read with `get` " +
+		"or imported, but not created, updated or deleted."
+	if r.Description == "" {
+		r.Description = note
+	} else {
+		r.Description += "\n\n" + note
+	}
+	return r, errs.ErrorOrNil()
+}
+
+func (*derivedExternalResourceController[R, O]) GetToken() (tokens.Type, error) {
+	return getToken[R](nil)
+}
+
+func (*derivedExternalResourceController[R, O]) Check(
+	ctx context.Context, req p.CheckRequest,
+) (p.CheckResponse, error) {
+	// External resources have no inputs. Check is still called when a resource is
+	// imported, so we accept whatever we are given.
+	return p.CheckResponse{Inputs: resource.PropertyMap{}}, nil
+}
+
+func (*derivedExternalResourceController[R, O]) Diff(
+	ctx context.Context, req p.DiffRequest,
+) (p.DiffResponse, error) {
+	// Without inputs, there is nothing that can change.
+	return p.DiffResponse{}, nil
+}
+
+func (*derivedExternalResourceController[R, O]) Create(
+	ctx context.Context, req p.CreateRequest,
+) (p.CreateResponse, error) {
+	return p.CreateResponse{}, status.Errorf(codes.FailedPrecondition,
+		"%s is managed outside of Pulumi and cannot be created; use get or import instead",
+		req.Urn.Type())
+}
+
+func (rc *derivedExternalResourceController[R, O]) Read(
+	ctx context.Context, req p.ReadRequest,
+) (p.ReadResponse, error) {
+	var r R
+	id, o, err := r.Read(ctx, req.ID)
+	if err != nil || id == "" {
+		return p.ReadResponse{}, err
+	}
+	return rc.readResponse(req, id, o, resource.PropertyMap{})
+}
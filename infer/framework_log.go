@@ -0,0 +1,40 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"reflect"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+)
+
+// logRemoved logs the secrets and unknowns that enc removed from a property map when it
+// was decoded into a value of typ, for [p.FrameworkLogEncoding].
+func logRemoved(ctx context.Context, typ reflect.Type, enc ende.Encoder) {
+	if !p.FrameworkLogEnabled(ctx, p.FrameworkLogEncoding) {
+		return
+	}
+	secrets, unknowns := enc.Removed()
+	if len(secrets) > 0 {
+		p.FrameworkLogf(ctx, p.FrameworkLogEncoding,
+			"decoding %s: removed secrets at %q, which are restored when it is encoded", typ, secrets)
+	}
+	if len(unknowns) > 0 {
+		p.FrameworkLogf(ctx, p.FrameworkLogEncoding,
+			"decoding %s: removed unknowns at %q, which are restored when it is encoded", typ, unknowns)
+	}
+}
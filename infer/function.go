@@ -18,6 +18,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"unicode"
 
 	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
@@ -25,6 +28,7 @@ import (
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
 	t "github.com/pulumi/pulumi-go-provider/middleware"
 	"github.com/pulumi/pulumi-go-provider/middleware/schema"
 )
@@ -33,6 +37,9 @@ import (
 // and `O` is the function output. Both must be structs.
 type Fn[I any, O any] interface {
 	// A function is a mapping from `I` to `O`.
+	//
+	// The project and stack of the caller are available from ctx with [p.GetProject]
+	// and [p.GetStack].
 	Call(ctx context.Context, input I) (output O, err error)
 }
 
@@ -50,9 +57,11 @@ func Function[F Fn[I, O], I, O any]() InferredFunction {
 	return &derivedInvokeController[F, I, O]{}
 }
 
-type derivedInvokeController[F Fn[I, O], I, O any] struct{}
+type derivedInvokeController[F Fn[I, O], I, O any] struct {
+	deprecation deprecationWarning
+}
 
-func (derivedInvokeController[F, I, O]) isInferredFunction() {}
+func (*derivedInvokeController[F, I, O]) isInferredFunction() {}
 
 func (*derivedInvokeController[F, I, O]) GetToken() (tokens.Type, error) {
 	// By default, we get resource style tokens:
@@ -66,6 +75,11 @@ func (*derivedInvokeController[F, I, O]) GetToken() (tokens.Type, error) {
 	return getToken[F](fnToken)
 }
 
+// SourcePosition returns where F is declared, for errors about the function.
+func (*derivedInvokeController[F, I, O]) SourcePosition() string {
+	return introspect.TypePosition(typeFor[F]())
+}
+
 func fnToken(tk tokens.Type) tokens.Type {
 	name := []rune(tk.Name().String())
 	for i, r := range name {
@@ -81,8 +95,13 @@ func fnToken(tk tokens.Type) tokens.Type {
 
 func (*derivedInvokeController[F, I, O]) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
 	var f F
-	descriptions := getAnnotated(reflect.TypeOf(f))
+	return functionSchema[I, O](reg, getAnnotated(reflect.TypeOf(f)))
+}
 
+// functionSchema returns the schema of a function from I to O, described by annotations.
+func functionSchema[I, O any](
+	reg schema.RegisterDerivativeType, annotations introspect.Annotator,
+) (pschema.FunctionSpec, error) {
 	input, err := objectSchema(reflect.TypeOf(new(I)))
 	if err != nil {
 		return pschema.FunctionSpec{}, err
@@ -100,9 +119,10 @@ func (*derivedInvokeController[F, I, O]) GetSchema(reg schema.RegisterDerivative
 	}
 
 	return pschema.FunctionSpec{
-		Description: descriptions.Descriptions[""],
-		Inputs:      input,
-		Outputs:     output,
+		Description:        annotations.Descriptions[""],
+		Inputs:             input,
+		Outputs:            output,
+		DeprecationMessage: deprecationMessage(annotations),
 	}, nil
 }
 
@@ -124,6 +144,34 @@ func objectSchema(t reflect.Type) (*pschema.ObjectTypeSpec, error) {
 }
 
 func (r *derivedInvokeController[F, I, O]) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	var f F
+	// If F is a *struct, we need to rehydrate the underlying struct
+	if v := reflect.ValueOf(f); v.Kind() == reflect.Pointer && v.IsNil() {
+		f = reflect.New(v.Type().Elem()).Interface().(F)
+	}
+	r.deprecation.warn(ctx, req.Token, getAnnotated(reflect.TypeOf(f)))
+	return invoke(ctx, req, f.Call)
+}
+
+// deprecationWarning warns that a function is deprecated the first time it is invoked.
+type deprecationWarning struct{ once sync.Once }
+
+// warn logs a warning when the function tk, described by annotations, is deprecated,
+// unless it has already been logged.
+func (w *deprecationWarning) warn(ctx context.Context, tk tokens.Type, annotations introspect.Annotator) {
+	msg := deprecationMessage(annotations)
+	if msg == "" {
+		return
+	}
+	w.once.Do(func() {
+		p.GetLogger(ctx).Warningf("Function %q is deprecated: %s", tk, msg)
+	})
+}
+
+// invoke implements Invoke for a function from I to O, implemented by call.
+func invoke[I, O any](
+	ctx context.Context, req p.InvokeRequest, call func(context.Context, I) (O, error),
+) (p.InvokeResponse, error) {
 	encoder, i, mapErr := ende.Decode[I](req.Args)
 	mapFailures, err := checkFailureFromMapError(mapErr)
 	if err != nil {
@@ -140,12 +188,7 @@ func (r *derivedInvokeController[F, I, O]) Invoke(ctx context.Context, req p.Inv
 		return p.InvokeResponse{}, fmt.Errorf("unable to apply defaults: %w", err)
 	}
 
-	var f F
-	// If F is a *struct, we need to rehydrate the underlying struct
-	if v := reflect.ValueOf(f); v.Kind() == reflect.Pointer && v.IsNil() {
-		f = reflect.New(v.Type().Elem()).Interface().(F)
-	}
-	o, err := f.Call(ctx, i)
+	o, err := call(ctx, i)
 	if err != nil {
 		return p.InvokeResponse{}, err
 	}
@@ -157,3 +200,69 @@ func (r *derivedInvokeController[F, I, O]) Invoke(ctx context.Context, req p.Inv
 		Return: applySecrets[O](m),
 	}, nil
 }
+
+// FunctionF infers a function from fn, which maps `I` to `O`.
+//
+// FunctionF is a shorthand for [Function] when a function does not need a controller
+// struct:
+//
+//	func GetJoin(ctx context.Context, args JoinArgs) (JoinResult, error) {
+//		return JoinResult{strings.Join(args.Elems, args.Sep)}, nil
+//	}
+//
+//	infer.FunctionF(GetJoin)
+//
+// The token is derived from the name and package of fn, just as [Function] derives it
+// from the name and package of `F`, so GetJoin above has the token "pkg:index:getJoin"
+// when declared in package main. fn must be declared at the top level of its package:
+// anonymous functions have no name to derive a token from.
+//
+// The description of the function is the description of `I`.
+func FunctionF[I, O any](fn func(ctx context.Context, input I) (output O, err error)) InferredFunction {
+	return &derivedFuncController[I, O]{fn: fn}
+}
+
+type derivedFuncController[I, O any] struct {
+	fn          func(context.Context, I) (O, error)
+	deprecation deprecationWarning
+}
+
+func (*derivedFuncController[I, O]) isInferredFunction() {}
+
+func (c *derivedFuncController[I, O]) GetToken() (tokens.Type, error) {
+	if c.fn == nil {
+		return "", fmt.Errorf("cannot get token of nil function")
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(c.fn).Pointer())
+	if fn == nil {
+		return "", fmt.Errorf("cannot get token of function %T", c.fn)
+	}
+	// Function names are fully qualified, such as
+	//
+	//	github.com/org/repo/pkg.GetJoin
+	//
+	full := fn.Name()
+	slash := strings.LastIndex(full, "/")
+	dot := slash + 1 + strings.Index(full[slash+1:], ".")
+	if dot <= slash {
+		return "", fmt.Errorf("cannot get token of function %q", full)
+	}
+	mod, name := full[slash+1:dot], full[dot+1:]
+	if mod == "main" {
+		mod = "index"
+	}
+	if strings.ContainsAny(name, ".()-") {
+		return "", fmt.Errorf("cannot get token of %q: FunctionF requires a top level function", full)
+	}
+	m := tokens.NewModuleToken("pkg", tokens.ModuleName(mod))
+	return fnToken(tokens.NewTypeToken(m, tokens.TypeName(name))), nil
+}
+
+func (*derivedFuncController[I, O]) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	return functionSchema[I, O](reg, getAnnotated(reflect.TypeOf(new(I))))
+}
+
+func (c *derivedFuncController[I, O]) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	c.deprecation.warn(ctx, req.Token, getAnnotated(reflect.TypeOf(new(I))))
+	return invoke(ctx, req, c.fn)
+}
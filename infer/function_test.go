@@ -15,10 +15,12 @@
 package infer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFnTokens(t *testing.T) {
@@ -39,3 +41,22 @@ func TestFnTokens(t *testing.T) {
 	}
 
 }
+
+type echoArgs struct {
+	Value string `pulumi:"value"`
+}
+
+func GetEcho(_ context.Context, args echoArgs) (echoArgs, error) { return args, nil }
+
+func TestFunctionFTokens(t *testing.T) {
+	t.Parallel()
+
+	tk, err := FunctionF(GetEcho).GetToken()
+	require.NoError(t, err)
+	assert.Equal(t, tokens.Type("pkg:infer:getEcho"), tk)
+
+	_, err = FunctionF(func(_ context.Context, args echoArgs) (echoArgs, error) {
+		return args, nil
+	}).GetToken()
+	assert.ErrorContains(t, err, "FunctionF requires a top level function")
+}
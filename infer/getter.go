@@ -0,0 +1,156 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// ResourceOption configures a resource created by [Resource].
+type ResourceOption func(*resourceOptions)
+
+type resourceOptions struct {
+	getter          bool
+	preserveUnknown bool
+	embedInputs     bool
+	readConflicts   ReadConflictResolver
+}
+
+// WithGetter adds a function that looks up an existing resource by its ID, backed by the
+// resource's [CustomRead] implementation. This lets programs reference resources that
+// they don't manage, without importing them.
+//
+// The function is named after the resource, so that the resource `pkg:index:Foo` gets the
+// function `pkg:index:getFoo`. It takes the ID of the resource as its only argument, and
+// returns the resource's outputs along with its ID.
+//
+// The resource must implement [CustomRead], or schema generation fails.
+func WithGetter() ResourceOption {
+	return func(o *resourceOptions) { o.getter = true }
+}
+
+// getters returns the getter functions of the resources that asked for one with
+// [WithGetter].
+func getters(resources []InferredResource) []InferredFunction {
+	var fns []InferredFunction
+	for _, r := range resources {
+		if g, ok := r.(interface{ getter() InferredFunction }); ok {
+			if fn := g.getter(); fn != nil {
+				fns = append(fns, fn)
+			}
+		}
+	}
+	return fns
+}
+
+func (rc *derivedResourceController[R, I, O]) getter() InferredFunction {
+	if !rc.opts.getter {
+		return nil
+	}
+	return &getterFunction[R, I, O]{rc}
+}
+
+// getterFunction looks up an existing resource by its ID. See [WithGetter].
+type getterFunction[R CustomResource[I, O], I, O any] struct {
+	resource *derivedResourceController[R, I, O]
+}
+
+func (*getterFunction[R, I, O]) isInferredFunction() {}
+
+func (*getterFunction[R, I, O]) GetToken() (tokens.Type, error) {
+	tk, err := getToken[R](nil)
+	if err != nil {
+		return "", err
+	}
+	return tokens.NewTypeToken(tk.Module(), "get"+tk.Name()), nil
+}
+
+func (g *getterFunction[R, I, O]) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	var r R
+	if _, ok := (any(r)).(CustomRead[I, O]); !ok {
+		return pschema.FunctionSpec{}, fmt.Errorf("WithGetter requires %T to implement CustomRead", r)
+	}
+	if err := registerTypes[O](reg); err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	spec, errs := getResourceSchema[R, I, O](false)
+	if err := errs.ErrorOrNil(); err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	tk, err := getToken[R](nil)
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+
+	idProperty := pschema.PropertySpec{
+		TypeSpec:    pschema.TypeSpec{Type: "string"},
+		Description: fmt.Sprintf("The ID of the %s.", tk.Name()),
+	}
+	outputs := spec.ObjectTypeSpec
+	outputs.Properties = maps.Clone(outputs.Properties)
+	if outputs.Properties == nil {
+		outputs.Properties = map[string]pschema.PropertySpec{}
+	}
+	outputs.Properties["id"] = idProperty
+	outputs.Required = append(outputs.Required, "id")
+	outputs.Description = ""
+
+	return pschema.FunctionSpec{
+		Description: fmt.Sprintf("Looks up an existing %s by its ID.", tk.Name()),
+		Inputs: &pschema.ObjectTypeSpec{
+			Type:       "object",
+			Properties: map[string]pschema.PropertySpec{"id": idProperty},
+			Required:   []string{"id"},
+		},
+		Outputs: &outputs,
+	}, nil
+}
+
+func (g *getterFunction[R, I, O]) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	var id string
+	if v, ok := req.Args["id"]; ok && v.IsString() {
+		id = v.StringValue()
+	}
+	if id == "" {
+		return p.InvokeResponse{Failures: []p.CheckFailure{{Property: "id", Reason: "missing required property"}}}, nil
+	}
+
+	resp, err := g.resource.Read(ctx, p.ReadRequest{ID: id})
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	if resp.ID == "" {
+		tk, err := getToken[R](nil)
+		if err != nil {
+			return p.InvokeResponse{}, err
+		}
+		return p.InvokeResponse{}, fmt.Errorf("%s %q not found", tk.Name(), id)
+	}
+	ret := resp.Properties.Copy()
+	if ret == nil {
+		ret = resource.PropertyMap{}
+	}
+	ret["id"] = resource.NewStringProperty(resp.ID)
+	return p.InvokeResponse{Return: ret}, nil
+}
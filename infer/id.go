@@ -0,0 +1,273 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// idSeparator separates the components of a structured ID.
+const idSeparator = "/"
+
+var idEscaper = strings.NewReplacer("%", "%25", idSeparator, "%2F")
+var idUnescaper = strings.NewReplacer("%2F", idSeparator, "%25", "%")
+
+// ID formats a structured identifier into a resource ID.
+//
+// T must be a struct whose exported fields are strings, integers or booleans. Fields are
+// joined with "/" in declaration order. Each field is escaped, so any value (including
+// values that contain "/") will be recovered by [ParseID].
+//
+// ID is useful for resources whose identity is a composite of several values:
+//
+//	type BucketID struct {
+//		Project string
+//		Region  string
+//		Name    string
+//	}
+//
+//	func (*Bucket) Create(
+//		ctx context.Context, name string, input BucketArgs, preview bool,
+//	) (string, BucketState, error) {
+//		id, err := infer.ID(BucketID{input.Project, input.Region, name})
+//		...
+//	}
+//
+//	func (*Bucket) Delete(ctx context.Context, id string, props BucketState) error {
+//		bucketID, err := infer.ParseID[BucketID](id)
+//		...
+//	}
+func ID[T any](v T) (string, error) {
+	value := reflect.ValueOf(v)
+	fields, err := idFields(value.Type())
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		field := value.FieldByIndex(f.Index)
+		var s string
+		switch field.Kind() {
+		case reflect.String:
+			s = field.String()
+		case reflect.Bool:
+			s = strconv.FormatBool(field.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s = strconv.FormatInt(field.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = strconv.FormatUint(field.Uint(), 10)
+		}
+		parts[i] = idEscaper.Replace(s)
+	}
+	return strings.Join(parts, idSeparator), nil
+}
+
+// ParseID parses a resource ID created by [ID] back into T.
+func ParseID[T any](id string) (T, error) {
+	var t T
+	value := reflect.ValueOf(&t).Elem()
+	fields, err := idFields(value.Type())
+	if err != nil {
+		return t, err
+	}
+	parts := strings.Split(id, idSeparator)
+	if len(parts) != len(fields) {
+		return t, fmt.Errorf("invalid ID %q: expected %d components, found %d",
+			id, len(fields), len(parts))
+	}
+	for i, f := range fields {
+		s := idUnescaper.Replace(parts[i])
+		field := value.FieldByIndex(f.Index)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(s)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return t, fmt.Errorf("invalid ID %q: field %s: %w", id, f.Name, err)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, field.Type().Bits())
+			if err != nil {
+				return t, fmt.Errorf("invalid ID %q: field %s: %w", id, f.Name, err)
+			}
+			field.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, field.Type().Bits())
+			if err != nil {
+				return t, fmt.Errorf("invalid ID %q: field %s: %w", id, f.Name, err)
+			}
+			field.SetUint(n)
+		}
+	}
+	return t, nil
+}
+
+// idFields returns the fields of typ that make up a structured ID.
+func idFields(typ reflect.Type) ([]reflect.StructField, error) {
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("structured IDs must be structs, found %s", typ)
+	}
+	var fields []reflect.StructField
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		switch f.Type.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fields = append(fields, f)
+		default:
+			return nil, fmt.Errorf("unsupported type %s for field %s.%s in structured ID",
+				f.Type, typ, f.Name)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("structured ID %s has no exported fields", typ)
+	}
+	return fields, nil
+}
+
+// CustomIDPolicy describes a resource whose ID is chosen by an [IDPolicy] instead of by
+// Create and Read.
+//
+// IDPolicy is called with the state returned by Create or Read, and returns the policy
+// that derives the ID of the resource:
+//
+//	func (*Bucket) IDPolicy(state *BucketState) infer.IDPolicy {
+//		return infer.IDFromField(&state.Arn)
+//	}
+//
+// Create may then return an empty ID. If it returns an ID anyway, it must match the ID
+// chosen by the policy. If it doesn't, or the policy fails, Create fails with the resource
+// recorded as partially created under the ID it returned, so that it can be retried or
+// deleted instead of being leaked.
+type CustomIDPolicy[O any] interface {
+	IDPolicy(state *O) IDPolicy
+}
+
+// IDPolicy derives the ID of a resource. See [CustomIDPolicy].
+//
+// An IDPolicy is created with [IDFromName], [IDFromField] or [IDRandomHex].
+type IDPolicy interface {
+	// createID returns the ID of a resource named name that has just been created.
+	createID(name string) (string, error)
+	// readID returns the canonical ID of a resource read with the ID id.
+	readID(id string) (string, error)
+}
+
+// IDFromName uses the name of the resource as its ID.
+//
+// On Read, the ID of the resource is kept as is, so resources imported under another
+// name keep the ID they were imported with.
+func IDFromName() IDPolicy { return idFromName{} }
+
+type idFromName struct{}
+
+func (idFromName) createID(name string) (string, error) { return name, nil }
+func (idFromName) readID(id string) (string, error)     { return id, nil }
+
+// IDFromField uses a field of the resource's state as its ID. field must be a pointer to a
+// string field of the state passed to [CustomIDPolicy.IDPolicy].
+//
+// The field is used on both Create and Read, so the ID of an imported resource is
+// normalized to the value of the field.
+func IDFromField(field any) IDPolicy { return idFromField{field} }
+
+type idFromField struct{ field any }
+
+func (p idFromField) createID(string) (string, error) { return p.value() }
+func (p idFromField) readID(string) (string, error)   { return p.value() }
+
+func (p idFromField) value() (string, error) {
+	v := reflect.ValueOf(p.field)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.String {
+		return "", fmt.Errorf("IDFromField requires a pointer to a string field, found %T", p.field)
+	}
+	id := v.Elem().String()
+	if id == "" {
+		return "", fmt.Errorf("the ID field is empty")
+	}
+	return id, nil
+}
+
+// IDRandomHex uses n random bytes, encoded as 2n hexadecimal characters, as the ID of a
+// resource.
+//
+// The ID is generated once, on Create. On Read, the ID of the resource is kept as is.
+func IDRandomHex(n int) IDPolicy { return idRandomHex{n} }
+
+type idRandomHex struct{ n int }
+
+func (p idRandomHex) createID(string) (string, error) {
+	if p.n <= 0 {
+		return "", fmt.Errorf("IDRandomHex requires a positive length, found %d", p.n)
+	}
+	buf := make([]byte, p.n)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (idRandomHex) readID(id string) (string, error) { return id, nil }
+
+// idPolicy returns the ID policy of r for state, if r has one.
+func idPolicy[R, O any](r *R, state *O) (IDPolicy, bool) {
+	c, ok := (any(*r)).(CustomIDPolicy[O])
+	if !ok {
+		return nil, false
+	}
+	return c.IDPolicy(state), true
+}
+
+// createdID returns the ID of a resource named name that Create returned with id and
+// state, applying the ID policy of r.
+func createdID[R, O any](r *R, name, id string, state *O) (string, error) {
+	policy, ok := idPolicy(r, state)
+	if !ok {
+		return id, nil
+	}
+	chosen, err := policy.createID(name)
+	if err != nil {
+		return "", fmt.Errorf("choosing the ID of %s: %w", name, err)
+	}
+	if id != "" && id != chosen {
+		return "", fmt.Errorf("the ID %q returned by Create does not match the ID %q chosen by the ID policy",
+			id, chosen)
+	}
+	return chosen, nil
+}
+
+// readID returns the canonical ID of a resource that Read returned with id and state,
+// applying the ID policy of r. A resource that was not found keeps its empty ID.
+func readID[R, O any](r *R, id string, state *O) (string, error) {
+	policy, ok := idPolicy(r, state)
+	if !ok || id == "" {
+		return id, nil
+	}
+	canonical, err := policy.readID(id)
+	if err != nil {
+		return "", fmt.Errorf("choosing the ID of %s: %w", id, err)
+	}
+	return canonical, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredID(t *testing.T) {
+	t.Parallel()
+
+	type bucketID struct {
+		Project string
+		Region  string
+		Index   int
+		Public  bool
+		hidden  string
+	}
+
+	cases := []struct {
+		value    bucketID
+		expected string
+	}{
+		{bucketID{"proj", "us-west-2", 3, true, ""}, "proj/us-west-2/3/true"},
+		{bucketID{"a/b", "100%", -1, false, ""}, "a%2Fb/100%25/-1/false"},
+		{bucketID{"%2F", "", 0, false, ""}, "%252F//0/false"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.expected, func(t *testing.T) {
+			t.Parallel()
+			id, err := ID(c.value)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, id)
+
+			parsed, err := ParseID[bucketID](id)
+			require.NoError(t, err)
+			assert.Equal(t, c.value, parsed)
+		})
+	}
+
+	t.Run("wrong-arity", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseID[bucketID]("proj/us-west-2")
+		assert.ErrorContains(t, err, "expected 4 components, found 2")
+	})
+
+	t.Run("bad-field", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseID[bucketID]("proj/us-west-2/three/true")
+		assert.ErrorContains(t, err, "field Index")
+	})
+
+	t.Run("unsupported-type", func(t *testing.T) {
+		t.Parallel()
+		_, err := ID(struct{ Tags []string }{})
+		assert.ErrorContains(t, err, "unsupported type []string")
+	})
+}
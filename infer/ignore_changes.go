@@ -0,0 +1,71 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+type ignoreChangesKey struct{}
+
+// withIgnoreChanges undoes the changes to news that the ignoreChanges resource option
+// asks to ignore, and records its paths for [IgnoreChanges].
+//
+// Only top level properties are reset: a nested path isn't a key of news, and adding it
+// as one would fail to decode.
+func withIgnoreChanges(
+	ctx context.Context, ignoreChanges []resource.PropertyKey, olds, news resource.PropertyMap,
+) context.Context {
+	paths := make([]resource.PropertyPath, 0, len(ignoreChanges))
+	for _, k := range ignoreChanges {
+		path, err := resource.ParsePropertyPath(string(k))
+		if err != nil {
+			// The engine validates ignoreChanges, so treat anything else as a top
+			// level property.
+			path = resource.PropertyPath{string(k)}
+		}
+		if len(path) == 0 {
+			// An empty path names no property, so there is nothing to ignore.
+			continue
+		}
+		if key, ok := path[0].(string); ok && len(path) == 1 {
+			news[resource.PropertyKey(key)] = olds[resource.PropertyKey(key)]
+		}
+		paths = append(paths, path)
+	}
+	return context.WithValue(ctx, ignoreChangesKey{}, paths)
+}
+
+// IgnoreChanges returns the paths of the properties whose changes should be ignored, as
+// set by the ignoreChanges resource option, when called from the Diff or Update method of
+// a resource.
+//
+// Changes to the top level properties listed are already undone in the news passed to
+// Diff and Update. Nested paths, such as "tags.env" or "rules[0].ports", are not, so a
+// [CustomDiff] that compares nested values itself should skip them:
+//
+//	func (*Firewall) Diff(
+//		ctx context.Context, id string, olds FirewallState, news FirewallArgs,
+//	) (p.DiffResponse, error) {
+//		for _, path := range infer.IgnoreChanges(ctx) {
+//			...
+//		}
+//	}
+func IgnoreChanges(ctx context.Context) []resource.PropertyPath {
+	paths, _ := ctx.Value(ignoreChangesKey{}).([]resource.PropertyPath)
+	return paths
+}
@@ -0,0 +1,181 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ende
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/mapper"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// Codec converts values of a Go type to and from property values, for types that can't
+// be mapped field by field, such as time.Time.
+type Codec struct {
+	// The schema type values are represented as.
+	Schema pschema.TypeSpec
+	// Encode converts a value of the type to a property value.
+	Encode func(any) (resource.PropertyValue, error)
+	// Decode converts a property value to a value of the type.
+	Decode func(resource.PropertyValue) (any, error)
+}
+
+var (
+	codecs    sync.Map // reflect.Type -> Codec
+	hasCodecs atomic.Bool
+)
+
+// RegisterCodec registers codec for values of type t, replacing any codec already
+// registered for t.
+func RegisterCodec(t reflect.Type, codec Codec) {
+	codecs.Store(t, codec)
+	hasCodecs.Store(true)
+}
+
+// CodecFor returns the codec registered for t, if any.
+func CodecFor(t reflect.Type) (Codec, bool) {
+	if t == nil || !hasCodecs.Load() {
+		return Codec{}, false
+	}
+	c, ok := codecs.Load(t)
+	if !ok {
+		return Codec{}, false
+	}
+	return c.(Codec), true
+}
+
+// decodedValue is a value decoded by a [Codec], to be placed at path before the rest of
+// the value is decoded.
+type decodedValue struct {
+	path  resource.PropertyPath
+	value any
+}
+
+// walkCodec decodes v, of type typ, with c.
+//
+// The decoded value is recorded to replace v when the property map is decoded, since the
+// mapper can't decode it itself. Secrets and unknowns nested in v are removed before c sees
+// it, and restored at the same paths when the value is encoded.
+func (e *ende) walkCodec(
+	v resource.PropertyValue, path resource.PropertyPath, typ reflect.Type, c Codec, alignTypes bool,
+) resource.PropertyValue {
+	if v.IsNull() && !alignTypes {
+		return v
+	}
+	value := reflect.Zero(typ).Interface()
+	if !alignTypes {
+		v = e.walk(v, path, nil, false)
+		decoded, err := c.Decode(v)
+		if err != nil {
+			e.errs = append(e.errs, mapper.NewTypeFieldError(typ, path.String(), err))
+		} else {
+			value = decoded
+		}
+	}
+	e.decoded = append(e.decoded, decodedValue{slices.Clone(path), value})
+	return v
+}
+
+// setDecoded places the values decoded by codecs into obj, the mappable form of the
+// property map being decoded.
+func (e *ende) setDecoded(obj map[string]any) {
+	for _, d := range e.decoded {
+		setMappable(obj, d.path, d.value)
+	}
+}
+
+// setMappable sets the value at path in obj, if path is in obj.
+func setMappable(obj any, path resource.PropertyPath, value any) {
+	for i, seg := range path {
+		last := i == len(path)-1
+		switch seg := seg.(type) {
+		case string:
+			m, ok := obj.(map[string]any)
+			if !ok {
+				return
+			}
+			if last {
+				m[seg] = value
+			}
+			obj = m[seg]
+		case int:
+			arr, ok := obj.([]any)
+			if !ok || seg >= len(arr) {
+				return
+			}
+			if last {
+				arr[seg] = value
+			}
+			obj = arr[seg]
+		default:
+			return
+		}
+	}
+}
+
+// encodeCodecs replaces each value in v that has a registered codec with its encoding in
+// m, the property value v was encoded to.
+func encodeCodecs(v reflect.Value, path resource.PropertyPath, m resource.PropertyValue, errs *[]error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if c, ok := CodecFor(v.Type()); ok && len(path) > 0 {
+		encoded, err := c.Encode(v.Interface())
+		if err != nil {
+			*errs = append(*errs, mapper.NewTypeFieldError(v.Type(), path.String(), err))
+			return
+		}
+		path.Set(m, encoded)
+		return
+	}
+	child := func(seg any) resource.PropertyPath {
+		return append(slices.Clip(path), seg)
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			tag, err := introspect.ParseTag(field)
+			if err != nil || tag.Internal {
+				continue
+			}
+			f, err := v.FieldByIndexErr(field.Index)
+			if err != nil {
+				continue
+			}
+			encodeCodecs(f, child(tag.Name), m, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			encodeCodecs(v.Index(i), child(i), m, errs)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			encodeCodecs(iter.Value(), child(iter.Key().String()), m, errs)
+		}
+	}
+}
@@ -73,10 +73,21 @@ func decode(
 		target = target.Elem()
 	}
 	m = e.simplify(m, target.Type())
-	return Encoder{e}, mapper.New(&mapper.Opts{
+	obj := m.Mappable()
+	e.setDecoded(obj)
+	err := mapper.New(&mapper.Opts{
 		IgnoreUnrecognized: ignoreUnrecognized,
 		IgnoreMissing:      allowMissing,
-	}).Decode(m.Mappable(), target.Addr().Interface())
+	}).Decode(obj, target.Addr().Interface())
+	if len(e.errs) > 0 {
+		errs := e.errs
+		if err != nil {
+			errs = append(errs, err.Failures()...)
+		}
+		err = mapper.NewMappingError(errs)
+	}
+	e.decoded, e.errs = nil, nil
+	return Encoder{e}, err
 }
 
 func DecodeAny(m resource.PropertyMap, dst any) (Encoder, mapper.MappingError) {
@@ -84,7 +95,14 @@ func DecodeAny(m resource.PropertyMap, dst any) (Encoder, mapper.MappingError) {
 }
 
 // An ENcoder DEcoder.
-type ende struct{ changes []change }
+type ende struct {
+	changes []change
+
+	// Values decoded by a [Codec], and the errors decoding them. They are only used
+	// while decoding.
+	decoded []decodedValue
+	errs    []error
+}
 
 type change struct {
 	path        resource.PropertyPath
@@ -186,6 +204,13 @@ func (e *ende) walk(
 		return el
 	}
 
+	if c, ok := CodecFor(typ); ok {
+		return e.walkCodec(v, path, typ, c, alignTypes)
+	}
+	if variants, ok := UnionVariants(typ); ok {
+		return e.walkUnion(v, path, variants, alignTypes)
+	}
+
 	var elemType reflect.Type
 	if typ != nil {
 		switch typ.Kind() {
@@ -329,7 +354,14 @@ func (e *ende) Encode(src any) (resource.PropertyMap, mapper.MappingError) {
 
 	m := resource.NewPropertyValueRepl(props,
 		nil, // keys are not changed
-		flattenAssets)
+		flatten)
+	if hasCodecs.Load() {
+		var errs []error
+		encodeCodecs(reflect.ValueOf(src), resource.PropertyPath{}, m, &errs)
+		if len(errs) > 0 {
+			return nil, mapper.NewMappingError(errs)
+		}
+	}
 
 	contract.Assertf(!m.ContainsUnknowns(),
 		"NewPropertyMapFromMap cannot produce unknown values")
@@ -412,6 +444,23 @@ happen. Please file an issue at https://github.com/pulumi/pulumi-go-provider/iss
 	return resource.NewNullProperty(), false
 }
 
+// Removed returns the paths of the secrets and unknowns that were removed when decoding,
+// which are restored by Encode.
+func (e *ende) Removed() (secrets, unknowns []string) {
+	if e == nil {
+		return nil, nil
+	}
+	for _, c := range e.changes {
+		if c.secret {
+			secrets = append(secrets, c.path.String())
+		}
+		if c.computed {
+			unknowns = append(unknowns, c.path.String())
+		}
+	}
+	return secrets, unknowns
+}
+
 // Mark an encoder as generating values only.
 //
 // This is appropriate when you are encoding a value where all fields must be known, such
@@ -430,5 +479,5 @@ func (e *ende) AllowUnknown(allowUnknowns bool) Encoder {
 		changes = append(changes, v)
 	}
 
-	return Encoder{&ende{changes}}
+	return Encoder{&ende{changes: changes}}
 }
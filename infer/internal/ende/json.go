@@ -0,0 +1,45 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ende
+
+import (
+	"encoding/json"
+	"reflect"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// json.RawMessage holds arbitrary JSON, so it is represented as pulumi.json#/Json and
+// passed through as the property value the JSON describes.
+func init() {
+	RegisterCodec(reflect.TypeOf(json.RawMessage(nil)), Codec{
+		Schema: pschema.TypeSpec{Ref: "pulumi.json#/Json"},
+		Encode: func(v any) (resource.PropertyValue, error) {
+			raw := v.(json.RawMessage)
+			if len(raw) == 0 {
+				return resource.NewNullProperty(), nil
+			}
+			var value any
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return resource.PropertyValue{}, err
+			}
+			return resource.NewPropertyValue(value), nil
+		},
+		Decode: func(v resource.PropertyValue) (any, error) {
+			return json.Marshal(v.Mappable())
+		},
+	})
+}
@@ -0,0 +1,173 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ende
+
+import (
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
+)
+
+// UnionSignatures are the unique keys used for each variant of a union type, in order.
+//
+// A union type is a struct whose only fields are optional pointers tagged with these keys,
+// and which implements [Union].
+var UnionSignatures = [...]string{
+	"14241ebf4485779f2e2801abee70c4c6",
+	"7c9d7083ba42db7aa0c2fb74039a9536",
+}
+
+// Union is implemented by union types, such as infer.Union.
+type Union interface {
+	// UnionVariants returns the type of each variant of the union, in the same order as
+	// UnionSignatures.
+	UnionVariants() []reflect.Type
+}
+
+// Discriminated is implemented by union variants that can be identified by the value of
+// a property.
+type Discriminated interface {
+	Discriminator() (property, value string)
+}
+
+// UnionVariants returns the variants of t if t is a union type.
+func UnionVariants(t reflect.Type) ([]reflect.Type, bool) {
+	if t == nil {
+		return nil, false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !t.Implements(reflect.TypeOf((*Union)(nil)).Elem()) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(Union).UnionVariants(), true
+}
+
+// Discriminator returns the discriminator of a union variant, if it has one.
+func Discriminator(t reflect.Type) (property, value string, ok bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !t.Implements(reflect.TypeOf((*Discriminated)(nil)).Elem()) {
+		return "", "", false
+	}
+	property, value = reflect.Zero(t).Interface().(Discriminated).Discriminator()
+	return property, value, true
+}
+
+// walkUnion wraps v in the key of the variant of variants that it matches, so that it can
+// be decoded into a union type.
+//
+// The variant is walked at path, since the wrapping key is removed again on encode.
+func (e *ende) walkUnion(
+	v resource.PropertyValue, path resource.PropertyPath,
+	variants []reflect.Type, alignTypes bool,
+) resource.PropertyValue {
+	if v.IsNull() {
+		return v
+	}
+	for i, variant := range variants {
+		if i >= len(UnionSignatures) || !matchesVariant(v, variant) {
+			continue
+		}
+		return resource.NewObjectProperty(resource.PropertyMap{
+			resource.PropertyKey(UnionSignatures[i]): e.walk(v, path, variant, alignTypes),
+		})
+	}
+	// No variant matches, so leave v as is. Decoding will fail with a mapping error.
+	return v
+}
+
+// matchesVariant checks if v could be decoded as t.
+//
+// If t has a discriminator, only the discriminator is checked. Otherwise objects match
+// structs when every key is a field of the struct and every required field is present.
+func matchesVariant(v resource.PropertyValue, t reflect.Type) bool {
+	v = putil.MakePublic(v)
+	if putil.IsComputed(v) {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if !v.IsObject() {
+			return false
+		}
+		obj := v.ObjectValue()
+		if property, value, ok := Discriminator(t); ok {
+			d := putil.MakePublic(obj[resource.PropertyKey(property)])
+			return d.IsString() && d.StringValue() == value
+		}
+		props, err := introspect.FindProperties(t)
+		if err != nil {
+			return false
+		}
+		for k := range obj {
+			if _, ok := props[string(k)]; !ok {
+				return false
+			}
+		}
+		for name, prop := range props {
+			if _, ok := obj[resource.PropertyKey(name)]; !ok && !prop.Optional {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		return v.IsObject()
+	case reflect.Slice, reflect.Array:
+		return v.IsArray()
+	case reflect.String:
+		return v.IsString()
+	case reflect.Bool:
+		return v.IsBool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Float32, reflect.Float64:
+		return v.IsNumber()
+	case reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// flattenUnion replaces an encoded union with the value of its variant.
+func flattenUnion(a any) (resource.PropertyValue, bool) {
+	m, ok := a.(map[string]any)
+	if !ok || len(m) != 1 {
+		return resource.PropertyValue{}, false
+	}
+	for _, signature := range UnionSignatures {
+		if inner, ok := m[signature]; ok {
+			return resource.NewPropertyValueRepl(inner, nil, flatten), true
+		}
+	}
+	return resource.PropertyValue{}, false
+}
+
+// flatten replaces the encoded forms of union types and AssetOrArchive with their
+// Pulumi representation.
+func flatten(a any) (resource.PropertyValue, bool) {
+	if v, ok := flattenUnion(a); ok {
+		return v, true
+	}
+	return flattenAssets(a)
+}
@@ -0,0 +1,197 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// listers returns the list functions of the resources that implement [CustomList].
+func listers(resources []InferredResource) []InferredFunction {
+	var fns []InferredFunction
+	for _, r := range resources {
+		if l, ok := r.(interface{ lister() InferredFunction }); ok {
+			if fn := l.lister(); fn != nil {
+				fns = append(fns, fn)
+			}
+		}
+	}
+	return fns
+}
+
+func (rc *derivedResourceController[R, I, O]) lister() InferredFunction {
+	if _, ok := typeFor[R]().MethodByName("List"); !ok {
+		return nil
+	}
+	return &listFunction[R, I, O]{rc}
+}
+
+// listFunction lists the resources held by the backend of R. See [CustomList].
+type listFunction[R CustomResource[I, O], I, O any] struct {
+	resource *derivedResourceController[R, I, O]
+}
+
+func (*listFunction[R, I, O]) isInferredFunction() {}
+
+func (*listFunction[R, I, O]) GetToken() (tokens.Type, error) {
+	if tk := getAnnotated(typeFor[R]()).ListToken; tk != "" {
+		return tokens.Type(tk), nil
+	}
+	tk, err := getToken[R](nil)
+	if err != nil {
+		return "", err
+	}
+	return tokens.NewTypeToken(tk.Module(), "list"+tk.Name()+"s"), nil
+}
+
+// itemToken returns the token of the type of the items listed for R.
+func (*listFunction[R, I, O]) itemToken() (tokens.Type, error) {
+	tk, err := getToken[R](nil)
+	if err != nil {
+		return "", err
+	}
+	return tokens.NewTypeToken(tk.Module(), tk.Name()+"ListItem"), nil
+}
+
+// method returns the List method of r and the type of its filters, checking that it
+// implements [CustomList].
+func (*listFunction[R, I, O]) method(r R) (reflect.Value, reflect.Type, error) {
+	list := reflect.ValueOf(r).MethodByName("List")
+	if list.IsValid() {
+		t := list.Type()
+		if t.NumIn() == 2 && t.NumOut() == 2 && !t.IsVariadic() &&
+			t.In(0) == typeFor[context.Context]() && t.In(1).Kind() == reflect.Struct &&
+			t.Out(0) == typeFor[[]ListItem[O]]() && t.Out(1) == typeFor[error]() {
+			return list, t.In(1), nil
+		}
+	}
+	return reflect.Value{}, nil, fmt.Errorf(
+		"%T has a List method, but it does not implement CustomList: "+
+			"List must have the signature func(context.Context, F) ([]infer.ListItem[%s], error), "+
+			"where F is a struct",
+		r, typeFor[O]())
+}
+
+func (l *listFunction[R, I, O]) GetSchema(reg schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	_, filters, err := l.method(*l.resource.getInstance())
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	tk, err := getToken[R](nil)
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+
+	inputs, err := objectSchema(filters)
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	if err := registerTypesOf(filters, reg); err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+
+	// Each item has the properties of O, along with the ID of the resource. "id" is
+	// reserved in the state of a resource, so it can't clash with them.
+	item, err := objectSchema(typeFor[O]())
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	if err := registerTypes[O](reg); err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	item.Description = fmt.Sprintf("An existing %s resource.", tk.Name())
+	if item.Properties == nil {
+		item.Properties = map[string]pschema.PropertySpec{}
+	}
+	item.Properties["id"] = pschema.PropertySpec{
+		TypeSpec:    pschema.TypeSpec{Type: "string"},
+		Description: "The ID of the resource.",
+	}
+	item.Required = append(item.Required, "id")
+	itemTk, err := l.itemToken()
+	if err != nil {
+		return pschema.FunctionSpec{}, err
+	}
+	reg(itemTk, pschema.ComplexTypeSpec{ObjectTypeSpec: *item})
+
+	return pschema.FunctionSpec{
+		Description: fmt.Sprintf("Lists the existing %s resources that match the arguments.", tk.Name()),
+		Inputs:      inputs,
+		Outputs: &pschema.ObjectTypeSpec{
+			Type: "object",
+			Properties: map[string]pschema.PropertySpec{
+				"items": {
+					TypeSpec: pschema.TypeSpec{
+						Type:  "array",
+						Items: &pschema.TypeSpec{Ref: "#/types/" + string(itemTk)},
+					},
+					Description: "The resources that match the arguments.",
+				},
+			},
+			Required: []string{"items"},
+		},
+	}, nil
+}
+
+func (l *listFunction[R, I, O]) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	list, filtersType, err := l.method(*l.resource.getInstance())
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+
+	filters := reflect.New(filtersType)
+	_, mapErr := ende.DecodeAny(req.Args, filters.Interface())
+	mapFailures, err := checkFailureFromMapError(mapErr)
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	if len(mapFailures) > 0 {
+		return p.InvokeResponse{Failures: mapFailures}, nil
+	}
+	var defaults defaultsWalker
+	if _, err := defaults.walk(filters.Elem()); err != nil {
+		return p.InvokeResponse{}, fmt.Errorf("unable to apply defaults: %w", err)
+	}
+
+	out := list.Call([]reflect.Value{reflect.ValueOf(ctx), filters.Elem()})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return p.InvokeResponse{}, err
+	}
+	listed := out[0].Interface().([]ListItem[O])
+
+	items := make([]resource.PropertyValue, len(listed))
+	for i, item := range listed {
+		m, err := ende.Encoder{}.Encode(item.State)
+		if err != nil {
+			return p.InvokeResponse{}, err
+		}
+		m = applySecrets[O](m)
+		m["id"] = resource.NewStringProperty(item.ID)
+		items[i] = resource.NewObjectProperty(m)
+	}
+	return p.InvokeResponse{Return: resource.PropertyMap{
+		"items": resource.NewArrayProperty(items),
+	}}, nil
+}
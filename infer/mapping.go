@@ -0,0 +1,56 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Mapping is the data a provider supplies to a converter, such as `pulumi convert --from
+// terraform`, so that it can translate another tool's resources into the provider's
+// resources.
+//
+// See [Options.Mappings].
+type Mapping struct {
+	// Provider is the name of the provider in the other tool, such as "aws" for the
+	// Terraform AWS provider. If empty, the name of this provider is used.
+	Provider string
+	// Data is the mapping itself, in the format the converter expects.
+	Data []byte
+}
+
+// getMapping returns a GetMapping method that serves mappings, delegating requests for
+// other mappings to next.
+func getMapping(
+	mappings map[string]Mapping, next func(context.Context, p.GetMappingRequest) (p.GetMappingResponse, error),
+) func(context.Context, p.GetMappingRequest) (p.GetMappingResponse, error) {
+	return func(ctx context.Context, req p.GetMappingRequest) (p.GetMappingResponse, error) {
+		if m, ok := mappings[req.Key]; ok {
+			provider := m.Provider
+			if provider == "" {
+				provider = p.GetRunInfo(ctx).PackageName
+			}
+			if req.Provider == "" || req.Provider == provider {
+				return p.GetMappingResponse{Provider: provider, Data: m.Data}, nil
+			}
+		}
+		if next == nil {
+			return p.GetMappingResponse{}, nil
+		}
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// Parameterization is the package served by a provider after it has been parameterized.
+//
+// See [Options.Parameterize].
+type Parameterization struct {
+	// The name and version of the parameterized package.
+	Name    string
+	Version semver.Version
+
+	// Value is recorded in the schema of the parameterized package, and so in SDKs
+	// generated from it. When a program uses such an SDK, the provider is parameterized
+	// again with Value as [p.ParameterizeRequestValue.Value].
+	Value []byte
+
+	// The resources, components and functions of the parameterized package. They
+	// replace those listed in [Options].
+	Resources  []InferredResource
+	Components []InferredComponent
+	Functions  []InferredFunction
+}
+
+// parameterizedProvider serves the provider built from the most recent
+// [Parameterization].
+type parameterizedProvider struct {
+	base p.Provider
+	opts Options
+
+	m       sync.RWMutex
+	current p.Provider
+}
+
+func wrapParameterized(provider p.Provider, opts Options) p.Provider {
+	d := &parameterizedProvider{
+		base:    provider,
+		opts:    opts,
+		current: wrap(provider, opts),
+	}
+	return d.provider()
+}
+
+func (d *parameterizedProvider) get() p.Provider {
+	d.m.RLock()
+	defer d.m.RUnlock()
+	return d.current
+}
+
+func (d *parameterizedProvider) parameterize(
+	ctx context.Context, req p.ParameterizeRequest,
+) (p.ParameterizeResponse, error) {
+	params, err := d.opts.Parameterize(ctx, req)
+	if err != nil {
+		return p.ParameterizeResponse{}, err
+	}
+	if params.Name == "" {
+		return p.ParameterizeResponse{}, fmt.Errorf("parameterized package must have a name")
+	}
+
+	opts := d.opts
+	opts.Resources = params.Resources
+	opts.Components = params.Components
+	opts.Functions = params.Functions
+	next := wrap(d.base, opts)
+
+	getSchema := next.GetSchema
+	next.GetSchema = func(ctx context.Context, req p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+		base := p.GetRunInfo(ctx)
+		info := base
+		info.PackageName = params.Name
+		info.Version = params.Version.String()
+		ctx = context.WithValue(ctx, key.RuntimeInfo, info)
+		resp, err := getSchema(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		var spec pschema.PackageSpec
+		if err := json.Unmarshal([]byte(resp.Schema), &spec); err != nil {
+			return p.GetSchemaResponse{}, err
+		}
+		spec.Parameterization = &pschema.ParameterizationSpec{
+			BaseProvider: pschema.BaseProviderSpec{
+				Name:    base.PackageName,
+				Version: base.Version,
+			},
+			Parameter: params.Value,
+		}
+		bytes, err := json.Marshal(spec)
+		if err != nil {
+			return p.GetSchemaResponse{}, err
+		}
+		return p.GetSchemaResponse{Schema: string(bytes)}, nil
+	}
+
+	d.m.Lock()
+	d.current = next
+	d.m.Unlock()
+
+	return p.ParameterizeResponse{Name: params.Name, Version: params.Version}, nil
+}
+
+// provider returns a [p.Provider] that forwards each request to the current provider.
+func (d *parameterizedProvider) provider() p.Provider {
+	return p.Provider{
+		GetSchema: func(ctx context.Context, req p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+			return d.get().GetSchema(ctx, req)
+		},
+		Parameterize: d.parameterize,
+		Cancel:       func(ctx context.Context) error { return d.get().Cancel(ctx) },
+		GetMapping: func(ctx context.Context, req p.GetMappingRequest) (p.GetMappingResponse, error) {
+			if getMapping := d.get().GetMapping; getMapping != nil {
+				return getMapping(ctx, req)
+			}
+			return p.GetMappingResponse{}, nil
+		},
+		CheckConfig: func(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			return d.get().CheckConfig(ctx, req)
+		},
+		DiffConfig: func(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+			return d.get().DiffConfig(ctx, req)
+		},
+		Configure: func(ctx context.Context, req p.ConfigureRequest) error {
+			return d.get().Configure(ctx, req)
+		},
+		Invoke: func(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			return d.get().Invoke(ctx, req)
+		},
+		Check: func(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			return d.get().Check(ctx, req)
+		},
+		Diff: func(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+			return d.get().Diff(ctx, req)
+		},
+		Create: func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			return d.get().Create(ctx, req)
+		},
+		Read: func(ctx context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+			return d.get().Read(ctx, req)
+		},
+		Update: func(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+			return d.get().Update(ctx, req)
+		},
+		Delete: func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+			return d.get().Delete(ctx, req)
+		},
+		Call: func(ctx context.Context, req p.CallRequest) (p.CallResponse, error) {
+			return d.get().Call(ctx, req)
+		},
+		Construct: func(ctx context.Context, req p.ConstructRequest) (p.ConstructResponse, error) {
+			return d.get().Construct(ctx, req)
+		},
+		Capabilities:         d.current.Capabilities,
+		LogHandler:           d.current.LogHandler,
+		FrameworkLog:         d.current.FrameworkLog,
+		ShutdownGracePeriod:  d.current.ShutdownGracePeriod,
+		MinimumPulumiVersion: d.current.MinimumPulumiVersion,
+		OnShutdown:           d.current.OnShutdown,
+	}
+}
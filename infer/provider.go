@@ -17,6 +17,7 @@ package infer
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	p "github.com/pulumi/pulumi-go-provider"
 	t "github.com/pulumi/pulumi-go-provider/middleware"
@@ -77,11 +78,65 @@ type Options struct {
 	// will instead result in exposing the same resources at `pkg:bar:Foo`, `pkg:bar:Bar` and
 	// `pkg:fizz:Buzz`.
 	ModuleMap map[tokens.ModuleName]tokens.ModuleName
+
+	// Deprecation marks the whole provider as deprecated, for example because it is being
+	// sunset or renamed.
+	//
+	// See [DeprecationNotice] for how the notice is surfaced.
+	Deprecation *DeprecationNotice
+
+	// StrictDryRun, if set, fails the construction of components that do something
+	// during a preview that is only safe during an update.
+	//
+	// See [StrictDryRun] for what is checked.
+	StrictDryRun *StrictDryRun
+
+	// ExposeConfig adds a `getProviderConfig` function to the provider, which returns the
+	// resolved provider configuration for debugging.
+	//
+	// Secret values are never returned: properties tagged with `provider:"secret"` are
+	// left out of the function's schema, and values that were set as secrets are left
+	// out of its result. ExposeConfig has no effect unless Config is set.
+	ExposeConfig bool
+
+	// Mappings are served to converters such as `pulumi convert`, keyed by the kind of
+	// mapping they ask for. For example, a provider bridged from Terraform can serve
+	// its Terraform mapping with
+	//
+	//	Mappings: map[string]infer.Mapping{"terraform": {Provider: "aws", Data: mapping}}
+	Mappings map[string]Mapping
+
+	// Parameterize, if set, lets the provider be parameterized, replacing its resources,
+	// components and functions with those of the returned [Parameterization].
+	//
+	// This allows a provider to derive its surface at runtime, for example from an
+	// OpenAPI document or a CRD passed to `pulumi package add`. The dispatch table and
+	// schema are rebuilt each time the provider is parameterized.
+	//
+	// When req.Args is set, Parameterize parses the arguments of `pulumi package add`.
+	// When req.Value is set, Parameterize must return the same package as the one
+	// described by req.Value, which is the [Parameterization.Value] that was returned
+	// when the SDK was generated.
+	Parameterize func(ctx context.Context, req p.ParameterizeRequest) (Parameterization, error)
+}
+
+// functions returns the functions served by the provider, including those added by
+// options.
+func (o Options) functions() []InferredFunction {
+	fns := append(slices.Clip(o.Functions), getters(o.Resources)...)
+	fns = append(fns, listers(o.Resources)...)
+	if fn := rotationFunction(o.Resources); fn != nil {
+		fns = append(fns, fn)
+	}
+	if o.ExposeConfig && o.Config != nil {
+		fns = append(fns, o.Config.function())
+	}
+	return append(fns, functionAliases(fns)...)
 }
 
 func (o Options) dispatch() dispatch.Options {
 	functions := map[tokens.Type]t.Invoke{}
-	for _, r := range o.Functions {
+	for _, r := range o.functions() {
 		typ, err := r.GetToken()
 		contract.AssertNoErrorf(err, "failed to get token for function %v", r)
 		functions[typ] = r
@@ -91,12 +146,18 @@ func (o Options) dispatch() dispatch.Options {
 		typ, err := r.GetToken()
 		contract.AssertNoErrorf(err, "failed to get token for resource %v", r)
 		customs[typ] = r
+		for _, alias := range tokenAliases(r) {
+			customs[alias] = r
+		}
 	}
 	components := map[tokens.Type]t.ComponentResource{}
 	for _, r := range o.Components {
 		typ, err := r.GetToken()
 		contract.AssertNoErrorf(err, "failed to get token for component %v", r)
 		components[typ] = r
+		for _, alias := range tokenAliases(r) {
+			components[alias] = r
+		}
 	}
 	return dispatch.Options{
 		Customs:    customs,
@@ -114,16 +175,22 @@ func (o Options) schema() schema.Options {
 	for i, c := range o.Components {
 		resources[i+len(o.Resources)] = c
 	}
-	functions := make([]schema.Function, len(o.Functions))
-	for i, f := range o.Functions {
+	fns := o.functions()
+	functions := make([]schema.Function, len(fns))
+	for i, f := range fns {
 		functions[i] = f
 	}
 
+	metadata := o.Metadata
+	if o.Deprecation != nil && metadata.DeprecationMessage == "" {
+		metadata.DeprecationMessage = o.Deprecation.String()
+	}
+
 	return schema.Options{
 		Resources: resources,
 		Invokes:   functions,
 		Provider:  o.Config,
-		Metadata:  o.Metadata,
+		Metadata:  metadata,
 		ModuleMap: o.ModuleMap,
 	}
 }
@@ -141,8 +208,18 @@ func Provider(opts Options) p.Provider {
 // The resulting provider will respond to resources and functions that are described in `opts`, delegating
 // unknown calls to the underlying provider.
 func Wrap(provider p.Provider, opts Options) p.Provider {
+	if opts.Parameterize != nil {
+		return wrapParameterized(provider, opts)
+	}
+	return wrap(provider, opts)
+}
+
+func wrap(provider p.Provider, opts Options) p.Provider {
 	provider = dispatch.Wrap(provider, opts.dispatch())
 	provider = schema.Wrap(provider, opts.schema())
+	if len(opts.Mappings) > 0 {
+		provider.GetMapping = getMapping(opts.Mappings, provider.GetMapping)
+	}
 
 	config := opts.Config
 	if config != nil {
@@ -168,8 +245,39 @@ func Wrap(provider p.Provider, opts Options) p.Provider {
 		})
 	}
 
+	if opts.Deprecation != nil {
+		provider.Configure = opts.Deprecation.wrapConfigure(provider.Configure)
+	}
+
+	if opts.StrictDryRun != nil {
+		provider = mContext.Wrap(provider, func(ctx context.Context) context.Context {
+			return context.WithValue(ctx, strictDryRunKey{}, opts.StrictDryRun)
+		})
+	}
+
 	provider = complexconfig.Wrap(provider)
-	return cancel.Wrap(provider)
+	provider = cancel.Wrap(provider)
+
+	if opts.autonamed() {
+		capabilities := p.DefaultCapabilities()
+		if provider.Capabilities != nil {
+			capabilities = *provider.Capabilities
+		}
+		capabilities.SupportsAutonamingConfiguration = true
+		provider = provider.WithCapabilities(capabilities)
+	}
+	return provider
+}
+
+// autonamed reports whether any resource in o has auto-named inputs, so the provider
+// should respect the stack's autonaming configuration.
+func (o Options) autonamed() bool {
+	for _, r := range o.Resources {
+		if r, ok := r.(interface{ autonamed() bool }); ok && r.autonamed() {
+			return true
+		}
+	}
+	return false
 }
 
 // GetConfig retrieves the configuration of this provider.
@@ -0,0 +1,79 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+)
+
+type randomSeedKey struct{}
+
+func withRandomSeed(ctx context.Context, seed []byte) context.Context {
+	return context.WithValue(ctx, randomSeedKey{}, seed)
+}
+
+// GetRandomSeed returns the random seed sent by the engine with the current Check or
+// CheckConfig request, or nil if there is none.
+//
+// The seed is unique to the resource, and stays the same when a Check is retried. See
+// [NewDeterministicRandom] to generate values from it.
+func GetRandomSeed(ctx context.Context) []byte {
+	seed, _ := ctx.Value(randomSeedKey{}).([]byte)
+	return seed
+}
+
+// NewDeterministicRandom returns a source of random numbers seeded from
+// [GetRandomSeed].
+//
+// Values generated during Check, such as names or salts, are then the same each time the
+// resource is checked, so previews are stable across retries:
+//
+//	func (*Salted) Check(
+//		ctx context.Context, name string, olds, news resource.PropertyMap,
+//	) (SaltedArgs, []p.CheckFailure, error) {
+//		args, failures, err := infer.DefaultCheck[SaltedArgs](ctx, news)
+//		if args.Salt == "" {
+//			args.Salt = strconv.Itoa(infer.NewDeterministicRandom(ctx).Int())
+//		}
+//		return args, failures, err
+//	}
+//
+// If there is no seed, such as outside of Check, the source is seeded randomly.
+//
+// The returned source is not suitable for generating secrets.
+func NewDeterministicRandom(ctx context.Context) *rand.Rand {
+	return newRandom(GetRandomSeed(ctx))
+}
+
+func newRandom(seed []byte) *rand.Rand {
+	var source int64
+	if len(seed) > 0 {
+		sum := sha256.Sum256(seed)
+		source = int64(binary.BigEndian.Uint64(sum[:8])) //nolint:gosec
+	} else {
+		var buf [8]byte
+		if _, err := crand.Read(buf[:]); err == nil {
+			source = int64(binary.BigEndian.Uint64(buf[:])) //nolint:gosec
+		}
+	}
+	// Determinism is the point, so a cryptographically secure source would not help.
+	//
+	//nolint:gosec
+	return rand.New(rand.NewSource(source))
+}
@@ -0,0 +1,41 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	t "github.com/pulumi/pulumi-go-provider/middleware"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// RawCustomResource is a custom resource that implements the provider protocol directly,
+// with its own token and schema.
+type RawCustomResource interface {
+	t.CustomResource
+	schema.Resource
+}
+
+// RawResource creates a new InferredResource from a resource that implements the provider
+// protocol directly.
+//
+// RawResource is for resources whose shape is only known at runtime, such as resources
+// generated from an OpenAPI document, which cannot be described by Go types. Resources
+// described by Go types should use [Resource].
+func RawResource(r RawCustomResource) InferredResource {
+	return rawResource{r}
+}
+
+type rawResource struct{ RawCustomResource }
+
+func (rawResource) isInferredResource() {}
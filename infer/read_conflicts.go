@@ -0,0 +1,75 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// ReadConflictResolver decides the inputs returned by Read, given the inputs the
+// resource had before Read and the inputs returned by [CustomRead].
+//
+// Before Read, the inputs are those last set by the program, or empty during an import.
+// The returned inputs are recorded in state, and the next diff compares the program's
+// inputs against them.
+type ReadConflictResolver func(ctx context.Context, program, remote resource.PropertyMap) resource.PropertyMap
+
+// PreferRemote returns the inputs returned by [CustomRead] as is, overwriting the inputs
+// set by the program. This is the default.
+//
+// The next diff then shows every input that drifted from the program, so the program can
+// correct it.
+func PreferRemote(_ context.Context, _, remote resource.PropertyMap) resource.PropertyMap {
+	return remote
+}
+
+// PreferProgramInputs keeps each top level input set by the program, taking only the
+// inputs the program did not set from [CustomRead].
+//
+// This suits inputs the remote API normalizes or doesn't return faithfully, where
+// overwriting them would show spurious diffs.
+func PreferProgramInputs(_ context.Context, program, remote resource.PropertyMap) resource.PropertyMap {
+	merged := remote.Copy()
+	if merged == nil {
+		merged = resource.PropertyMap{}
+	}
+	for k, v := range program {
+		if !v.IsNull() {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// ResolveReadConflicts decides how the inputs returned by [CustomRead] are merged with
+// the inputs the resource had before Read, such as during `pulumi refresh`.
+//
+// Without this option, [PreferRemote] is used.
+func ResolveReadConflicts(resolve ReadConflictResolver) ResourceOption {
+	return func(o *resourceOptions) { o.readConflicts = resolve }
+}
+
+// resolveReadConflicts merges the inputs returned by Read with the inputs the resource
+// had before Read, according to the resource's [ReadConflictResolver].
+func (o resourceOptions) resolveReadConflicts(
+	ctx context.Context, program, remote resource.PropertyMap,
+) resource.PropertyMap {
+	if o.readConflicts == nil {
+		return remote
+	}
+	return o.readConflicts(ctx, program, remote)
+}
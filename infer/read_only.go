@@ -0,0 +1,68 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+)
+
+// readOnlyController implements the parts of a resource controller that are shared by
+// resources which Pulumi reads but never modifies: [DataResource] and
+// [ExternalResource].
+type readOnlyController[O any] struct {
+	// kind describes the resource in error messages, such as "data resource".
+	kind string
+}
+
+// readResponse encodes o as the state of a resource that was read with the given id.
+//
+// The previous state in req is decoded to preserve which of its values are secret.
+// Fields that are missing from it, for example because O gained a field, are tolerated.
+func (readOnlyController[O]) readResponse(
+	req p.ReadRequest, id string, o O, inputs resource.PropertyMap,
+) (p.ReadResponse, error) {
+	var err error
+	encoder, err := ende.DecodeTolerateMissing(req.Properties, new(O))
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	s, err := encoder.Encode(o)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	return p.ReadResponse{
+		ID:         id,
+		Properties: canonicalize[O](s),
+		Inputs:     inputs,
+	}, nil
+}
+
+func (c readOnlyController[O]) Update(
+	_ context.Context, req p.UpdateRequest,
+) (p.UpdateResponse, error) {
+	// Diff never reports an update, so the engine should never call Update.
+	return p.UpdateResponse{}, ProviderErrorf("%s %s cannot be updated", c.kind, req.Urn)
+}
+
+func (readOnlyController[O]) Delete(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+	// The object is not owned by Pulumi, so deleting the resource only removes it from
+	// state.
+	return p.DeleteResponse{}, nil
+}
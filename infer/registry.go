@@ -0,0 +1,192 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// Registry collects resources, components and functions that are defined apart from the
+// provider that serves them, such as in Go modules maintained by different teams.
+//
+// Each module registers what it defines, usually from an init function:
+//
+//	package buckets
+//
+//	func init() {
+//		infer.Register(
+//			infer.Resource[*Bucket, BucketArgs, BucketState](),
+//			infer.Function[*GetBucketPolicy, GetBucketPolicyArgs, BucketPolicy](),
+//		)
+//	}
+//
+// The provider then imports each module for its side effects, and serves everything
+// that was registered with [Options.WithRegistry]:
+//
+//	import _ "example.com/storage/buckets"
+//
+//	func main() {
+//		p.RunProvider("storage", "0.1.0",
+//			infer.Provider(infer.Options{}.WithRegistry(infer.DefaultRegistry())))
+//	}
+//
+// A token can only be registered once, so two modules that define the same resource
+// are caught when the provider starts instead of one silently replacing the other.
+//
+// The zero value is an empty Registry, ready to use.
+type Registry struct {
+	m            sync.Mutex
+	resources    []InferredResource
+	components   []InferredComponent
+	functions    []InferredFunction
+	registeredAt map[registryKey]string
+}
+
+// registryKey identifies a registration. Resources and components share a namespace,
+// since they are both resources in the schema.
+type registryKey struct {
+	function bool
+	token    tokens.Type
+}
+
+func (k registryKey) String() string {
+	if k.function {
+		return fmt.Sprintf("function %s", k.token)
+	}
+	return fmt.Sprintf("resource %s", k.token)
+}
+
+// NewRegistry creates an empty [Registry].
+func NewRegistry() *Registry { return &Registry{} }
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry returns the registry that [Register] adds to.
+func DefaultRegistry() *Registry { return defaultRegistry }
+
+// Register adds resources, components and functions to the [DefaultRegistry]. See
+// [Registry.Register].
+func Register(items ...any) { defaultRegistry.register(items) }
+
+// Register adds resources, components and functions to r. Each item must be an
+// [InferredResource], [InferredComponent] or [InferredFunction].
+//
+// Register panics if an item is of another type, or if its token has already been
+// registered.
+func (r *Registry) Register(items ...any) { r.register(items) }
+
+func (r *Registry) register(items []any) {
+	// register is called directly by both Register functions, so skip them to find
+	// where the items were registered.
+	where := "unknown location"
+	if _, file, line, ok := runtime.Caller(2); ok {
+		where = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.registeredAt == nil {
+		r.registeredAt = map[registryKey]string{}
+	}
+	for _, item := range items {
+		key, err := registryKeyOf(item)
+		if err != nil {
+			panic(err.Error())
+		}
+		if prev, ok := r.registeredAt[key]; ok {
+			panic(fmt.Sprintf("%s is registered twice: at %s and at %s", key, prev, where))
+		}
+		r.registeredAt[key] = where
+		switch item := item.(type) {
+		case InferredResource:
+			r.resources = append(r.resources, item)
+		case InferredComponent:
+			r.components = append(r.components, item)
+		case InferredFunction:
+			r.functions = append(r.functions, item)
+		}
+	}
+}
+
+func registryKeyOf(item any) (registryKey, error) {
+	var key registryKey
+	var err error
+	switch item := item.(type) {
+	case InferredResource:
+		key.token, err = item.GetToken()
+	case InferredComponent:
+		key.token, err = item.GetToken()
+	case InferredFunction:
+		key.function = true
+		key.token, err = item.GetToken()
+	default:
+		return key, fmt.Errorf("cannot register %T: expected an InferredResource, "+
+			"InferredComponent or InferredFunction", item)
+	}
+	if err != nil {
+		return key, fmt.Errorf("cannot register %T: %w", item, err)
+	}
+	return key, nil
+}
+
+// WithRegistry returns a copy of o that also serves the resources, components and
+// functions in reg.
+//
+// WithRegistry panics if reg holds a token that o already serves.
+func (o Options) WithRegistry(reg *Registry) Options {
+	reg.m.Lock()
+	defer reg.m.Unlock()
+
+	served := map[registryKey]struct{}{}
+	for _, r := range o.Resources {
+		key, err := registryKeyOf(r)
+		if err == nil {
+			served[key] = struct{}{}
+		}
+	}
+	for _, c := range o.Components {
+		key, err := registryKeyOf(c)
+		if err == nil {
+			served[key] = struct{}{}
+		}
+	}
+	for _, f := range o.Functions {
+		key, err := registryKeyOf(f)
+		if err == nil {
+			served[key] = struct{}{}
+		}
+	}
+	var conflicts []string
+	for key, where := range reg.registeredAt {
+		if _, ok := served[key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s registered at %s is already served by the provider", key, where))
+		}
+	}
+	if len(conflicts) > 0 {
+		slices.Sort(conflicts)
+		panic(strings.Join(conflicts, "; "))
+	}
+
+	o.Resources = append(slices.Clip(o.Resources), reg.resources...)
+	o.Components = append(slices.Clip(o.Components), reg.components...)
+	o.Functions = append(slices.Clip(o.Functions), reg.functions...)
+	return o
+}
@@ -0,0 +1,77 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// replaceOnChanges returns true if a change at path, a [p.DiffResponse.DetailedDiff]
+// key, of a value of type typ requires a replacement.
+//
+// A change requires a replacement when the changed property or any property containing
+// it is marked with the `provider:"replaceOnChanges"` tag or with
+// [Annotator.ReplaceOnChanges].
+func replaceOnChanges(typ reflect.Type, path string) bool {
+	parsed, err := resource.ParsePropertyPath(path)
+	if err != nil {
+		return false
+	}
+	for _, elem := range parsed {
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		switch elem := elem.(type) {
+		case string:
+			switch typ.Kind() {
+			case reflect.Map:
+				typ = typ.Elem()
+			case reflect.Struct:
+				field, tag, ok := fieldByPropertyName(typ, elem)
+				if !ok {
+					return false
+				}
+				if tag.ReplaceOnChanges || getAnnotated(typ).ReplaceOnChangesFields[elem] {
+					return true
+				}
+				typ = field.Type
+			default:
+				return false
+			}
+		case int:
+			if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+				return false
+			}
+			typ = typ.Elem()
+		}
+	}
+	return false
+}
+
+// fieldByPropertyName returns the field of the struct typ that holds the property name.
+func fieldByPropertyName(typ reflect.Type, name string) (reflect.StructField, introspect.FieldTag, bool) {
+	for _, field := range reflect.VisibleFields(typ) {
+		tag, err := introspect.ParseTag(field)
+		if err != nil || tag.Internal || tag.Name != name {
+			continue
+		}
+		return field, tag, true
+	}
+	return reflect.StructField{}, introspect.FieldTag{}, false
+}
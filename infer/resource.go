@@ -18,7 +18,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
@@ -44,9 +46,20 @@ import (
 //
 // This interface should be implemented by the resource controller, with `I` the resource
 // inputs and `O` the full set of resource fields. It is recommended that `O` is a
-// superset of `I`, but it is not strictly required. The fields of `I` and `O` should
-// consist of non-pulumi types i.e. `string` and `int` instead of `pulumi.StringInput` and
-// `pulumi.IntOutput`.
+// superset of `I`, but it is not strictly required. [EmbedInputs] fills `O` from `I`
+// automatically. The fields of `I` and `O` should consist of non-pulumi types i.e.
+// `string` and `int` instead of `pulumi.StringInput` and `pulumi.IntOutput`.
+//
+// Slice fields of `O` whose order is not significant can be tagged with
+// `provider:"set"`. Their elements are sorted before being saved to state, and before
+// inputs are diffed, so that building them, or an API returning them, in a different
+// order doesn't show up as a diff. A slice of objects can be tagged with
+// `provider:"setKey=name"` instead, to sort its elements by their name field, so that
+// the diff of each element is reported against the element with the same name:
+//
+//	type FirewallArgs struct {
+//		Rules []Rule `pulumi:"rules" provider:"setKey=port"`
+//	}
 //
 // The behavior of a CustomResource resource can be extended by implementing any of the
 // following interfaces on the resource controller:
@@ -55,8 +68,12 @@ import (
 // - [CustomDiff]
 // - [CustomUpdate]
 // - [CustomRead]
-// - [CustomDelete]
+// - [CustomDelete] or [CustomDeleteWithResponse]
+// - [CustomCreated]
+// - [CustomUpdated]
 // - [CustomStateMigrations]
+// - [CustomDriftDetect]
+// - [CustomIDPolicy]
 // - [Annotated]
 //
 // Example:
@@ -160,14 +177,75 @@ type CustomRead[I, O any] interface {
 		canonicalID string, normalizedInputs I, normalizedState O, err error)
 }
 
+// CustomList describes a resource whose backend can enumerate the resources it holds.
+//
+// A resource that implements CustomList gets a function that lists them, so that programs
+// can discover resources to reference or import. The function takes F as its arguments
+// and returns the listed resources as `items`, each with the properties of O and the `id`
+// of the resource. The resource `pkg:index:Foo` gets the function `pkg:index:listFoos`,
+// unless another token is set with [Annotator.SetListToken].
+//
+// F must be a struct, and is described in the schema like the inputs of a [Function].
+// Since F can't be inferred from R, any method named List on the resource must match
+// CustomList, or schema generation fails.
+type CustomList[F, O any] interface {
+	// List returns each resource that matches filters.
+	List(ctx context.Context, filters F) ([]ListItem[O], error)
+}
+
+// ListItem is a resource returned by [CustomList].
+type ListItem[O any] struct {
+	// ID is the ID of the resource, as returned by Create.
+	ID string
+	// State is the state of the resource.
+	State O
+}
+
 // CustomDelete describes a resource that knows how to delete itself.
 //
 // If a resource does not implement Delete, no code will be run on resource deletion.
+//
+// When the engine sets a delete timeout, ctx carries the corresponding deadline. If Delete
+// has not returned by the deadline, the delete fails with a timeout error.
 type CustomDelete[O any] interface {
 	// Delete is called before a resource is removed from pulumi state.
 	Delete(ctx context.Context, id string, props O) error
 }
 
+// CustomDeleteWithResponse describes a resource that knows how to delete itself, and
+// reports the outcome in a [p.DeleteResponse]. It is implemented instead of
+// [CustomDelete].
+//
+// The response is logged against the resource. For example, a resource whose API doesn't
+// support deletion can leave the object in place, and tell the user so:
+//
+//	func (*Archive) Delete(ctx context.Context, id string, props ArchiveState) (p.DeleteResponse, error) {
+//		return p.DeleteResponse{Retained: true}, nil
+//	}
+type CustomDeleteWithResponse[O any] interface {
+	// Delete is called before a resource is removed from pulumi state.
+	Delete(ctx context.Context, id string, props O) (p.DeleteResponse, error)
+}
+
+// CustomCreated describes a resource that needs to run code after it has been created,
+// such as emitting an event or warming a cache.
+//
+// OnCreated is called with the final state of the resource after a successful, non-preview
+// Create. The resource has already been created, so an error returned from OnCreated is
+// logged as a warning instead of failing the operation.
+type CustomCreated[O any] interface {
+	OnCreated(ctx context.Context, id string, state O) error
+}
+
+// CustomUpdated describes a resource that needs to run code after it has been updated.
+//
+// OnUpdated is called with the old and new state of the resource after a successful,
+// non-preview Update. An error returned from OnUpdated is logged as a warning instead of
+// failing the operation.
+type CustomUpdated[O any] interface {
+	OnUpdated(ctx context.Context, id string, olds, news O) error
+}
+
 // StateMigrationFunc represents a stateless mapping from an old state shape to a new
 // state shape. Each StateMigrationFunc is parameterized by the shape of the type it
 // produces, ensuring that all successful migrations end up in a valid state.
@@ -240,6 +318,15 @@ func (stateMigrationFunc[O, N, F]) oldShape() reflect.Type       { return typeFo
 func (stateMigrationFunc[O, N, F]) newShape() reflect.Type       { return typeFor[N]() }
 func (m stateMigrationFunc[O, N, F]) migrateFunc() reflect.Value { return reflect.ValueOf(m.f) }
 
+// CustomStateMigrations describes a resource whose state may need to be migrated from an
+// older shape.
+//
+// Fields that have only been renamed don't need a migration: tag the field with its old
+// names instead, and state persisted under those names will be read into the field:
+//
+//	type MyResourceState struct {
+//		DisplayName string `pulumi:"displayName" migrate:"display_name"`
+//	}
 type CustomStateMigrations[O any] interface {
 	// StateMigrations is the list of know migrations.
 	//
@@ -266,8 +353,29 @@ type Annotator interface {
 	// Annotate a struct field with a text description.
 	Describe(i any, description string)
 
+	// Annotate a struct field, or the struct itself, with the contents of the Markdown
+	// file name in fsys.
+	//
+	// Long-form docs, such as a resource's description with examples, can then live in
+	// Markdown files next to the code. Embed the files with go:embed, so they are part of
+	// the provider binary:
+	//
+	//	//go:embed docs
+	//	var docs embed.FS
+	//
+	//	func (r *Resource) Annotate(a infer.Annotator) {
+	//		a.DescribeFromFile(&r, docs, "docs/resource.md")
+	//	}
+	//
+	// DescribeFromFile panics if the file can't be read.
+	DescribeFromFile(i any, fsys fs.FS, name string)
+
 	// Annotate a struct field with a default value. The default value must be a primitive
 	// type in the pulumi type system.
+	//
+	// env lists environment variables that are checked, in order, for a value before
+	// falling back to defaultValue. They are also recorded in the property's
+	// `defaultInfo` in the schema, so generated SDKs read them too.
 	SetDefault(i any, defaultValue any, env ...string)
 
 	// Set the token of the annotated type.
@@ -285,17 +393,120 @@ type Annotator interface {
 	//
 	SetToken(module tokens.ModuleName, name tokens.TypeName)
 
+	// Set the token of the function that lists the resource, if it implements
+	// [CustomList].
+	//
+	// The module and the name are assembled in the same way as `SetToken`, so the name
+	// should follow the function naming convention:
+	//
+	//	a.SetListToken("index", "listTopics")
+	//
+	SetListToken(module tokens.ModuleName, name tokens.TypeName)
+
 	// Add a type [alias](https://www.pulumi.com/docs/using-pulumi/pulumi-packages/schema/#alias) for
-	// this resource.
+	// this resource, function or type.
 	//
 	// The module and the name will be assembled into a type specifier of the form
-	// `mypkg:mymodule:MyResource`, in the same way `SetToken` does.
+	// `mypkg:mymodule:MyResource`, in the same way `SetToken` does. Function aliases are
+	// used as given, so they should follow the function naming convention
+	// (`mypkg:mymodule:getThing`).
+	//
+	// Requests for an alias are served by the annotated resource or function. The schema
+	// only supports aliases for resources, so an aliased function is also published under
+	// its alias as a deprecated copy, and an aliased type as a copy of the type. This
+	// keeps programs and SDKs written against the old token working after a rename.
 	AddAlias(module tokens.ModuleName, name tokens.TypeName)
 
 	// Set a deprecation message for the resource, which officially marks it as deprecated.
 	SetResourceDeprecationMessage(message string)
+
+	// Mark the resource or function as deprecated in favor of replacement, the token of
+	// the resource or function that supersedes it, such as "pkg:index:newFn".
+	//
+	// i must be a pointer to the annotated struct itself:
+	//
+	//	func (f *GetThing) Annotate(a infer.Annotator) {
+	//		a.DeprecateWithReplacement(&f, "pkg:index:getThingV2")
+	//	}
+	//
+	// The replacement is named in the deprecation message of the schema, so generated
+	// SDKs point users to it. A deprecated function also logs the message as a warning
+	// the first time it is invoked. The token is used as given.
+	DeprecateWithReplacement(i any, replacement string)
+
+	// Set the category the resource is listed under in the registry, such as
+	// "Networking".
+	//
+	// The category is recorded in the resource's schema, along with any keywords added
+	// with AddKeywords. Module descriptions are set with
+	// [github.com/pulumi/pulumi-go-provider/middleware/schema.Metadata.ModuleDescriptions].
+	SetCategory(category string)
+
+	// Add search terms for the resource in the registry.
+	AddKeywords(keywords ...string)
+
+	// Set how long the resource is kept before it is replaced, such as 30 days for a
+	// credential that should be rotated monthly.
+	//
+	// The resource's state must embed [Rotation], which records when it was created.
+	// Once the window has elapsed, the next Diff replaces the resource.
+	SetRotation(window time.Duration)
+
+	// Mark a top level input field as write-only.
+	//
+	// Write-only fields are passed to Create and Update, but are never returned in the
+	// resource's outputs and are ignored when computing the default diff. This is
+	// equivalent to the `provider:"writeOnly"` tag. Write-only fields must be optional.
+	WriteOnly(i any)
+
+	// Mark a field as requiring a replacement of the resource when it changes.
+	//
+	// This is equivalent to the `provider:"replaceOnChanges"` tag. Like the tag, it is
+	// recorded in the schema and respected by the default diff, but not by [CustomDiff].
+	ReplaceOnChanges(i any)
+
+	// Mark a field as deprecated, with a message explaining what to use instead.
+	//
+	// The message is recorded in the schema, so generated SDKs flag uses of the field.
+	// When a deprecated top level input is set, Check also logs the message as a
+	// warning, so users see it during `pulumi preview` and `pulumi up`.
+	Deprecate(i any, message string)
+
+	// Mark a top level input field as auto-named.
+	//
+	// When an auto-named field is not set, Check fills it in: with its previous value if
+	// there is one, otherwise with the name proposed by the engine's autonaming
+	// configuration (`pulumi config set pulumi:autonaming`), and otherwise with a name
+	// generated from the resource name according to opts. If the autonaming configuration
+	// enforces its proposed name, that name is used even for an existing resource; if it
+	// disables autonaming, Check fails unless the field is set. This is equivalent to the
+	// `provider:"autoname"` tag with default options. Auto-named fields must be optional
+	// strings.
+	Autoname(i any, opts AutonameOptions)
+
+	// Mark a top level input field as defaulting to the name of the resource.
+	//
+	// When the field is not set, Check fills it in with its previous value if there is
+	// one, and otherwise with the resource name. The value is part of the resource's
+	// inputs, so it stays the same across updates, even if the resource is renamed with
+	// an alias. Unlike [Annotator.Autoname], no random suffix is added and the engine's
+	// autonaming configuration doesn't apply. Fields that default to the resource name
+	// must be optional strings.
+	DefaultFromName(i any)
+
+	// Mark an input or output field as intentionally left out of
+	// [ExplicitDependencies.WireDependencies].
+	//
+	// When a resource implements [ExplicitDependencies], the first Create or Update logs
+	// a warning that lists the fields its WireDependencies leaves unwired, since their
+	// secretness and computedness won't flow. Unwired fields are not listed.
+	Unwired(i any)
 }
 
+// AutonameOptions control how a name is generated for a field marked with
+// [Annotator.Autoname].
+type AutonameOptions = introspect.AutonameOptions
+
 // Annotated is used to describe the fields of an object or a resource. Annotated can be
 // implemented by `CustomResource`s, the input and output types for all resources and
 // invokes, as well as other structs used the above.
@@ -336,8 +547,16 @@ func (*fieldGenerator) isFieldSelector() {}
 // ExplicitDependencies then WireDependencies will be called for each Create and Update
 // call with `args` and `state` holding the values they will have for that call.
 //
+// WireDependencies is also called during Diff, with zero values, to find the outputs that
+// depend on an input that changed. They are reported as [p.DiffResponse.ChangedOutputs],
+// so `pulumi preview --diff` shows them as changing.
+//
 // If ExplicitDependencies is not implemented, it is assumed that all outputs depend on
 // all inputs.
+//
+// Fields that WireDependencies leaves unwired are logged as a warning, so that fields
+// added later are not silently left out. Fields that are unwired on purpose can be
+// marked with [Annotator.Unwired].
 type ExplicitDependencies[I, O any] interface {
 	// WireDependencies specifies the dependencies between inputs and outputs.
 	WireDependencies(f FieldSelector, args *I, state *O)
@@ -382,6 +601,10 @@ type fieldGenerator struct {
 	err          multierror.Error
 
 	fields map[string]*field
+
+	// trace logs why MarkMap makes an output secret or unknown, for
+	// [p.FrameworkLogDependencies].
+	trace func(msg string, a ...any)
 }
 
 func (g *fieldGenerator) getField(name string) *field {
@@ -421,13 +644,13 @@ func (g *fieldGenerator) MarkMap(isCreate, isPreview bool) func(oldInputs, input
 	return func(oldInputs, inputs, m resource.PropertyMap) {
 		// Flow secretness and computedness
 		for k, v := range m {
-			m[k] = markField(g.getField(string(k)), k, v, oldInputs, inputs, isCreate, isPreview)
+			m[k] = markField(g.trace, g.getField(string(k)), k, v, oldInputs, inputs, isCreate, isPreview)
 		}
 	}
 }
 
 func markComputed(
-	field *field, key resource.PropertyKey, prop resource.PropertyValue,
+	trace func(string, ...any), field *field, key resource.PropertyKey, prop resource.PropertyValue,
 	oldInputs, inputs resource.PropertyMap, isCreate bool,
 ) resource.PropertyValue {
 	// If the value is already computed or if it is guaranteed to be known, we don't need to do anything
@@ -443,6 +666,7 @@ func markComputed(
 
 	// If this is during a create and the value is not explicitly marked as known, we mark it computed.
 	if isCreate {
+		trace("output %q is unknown: it is not marked as always known during the preview of a create", key)
 		return putil.MakeComputed(prop)
 	}
 
@@ -465,7 +689,12 @@ func markComputed(
 		// (or do it for the user), ensuring that we have access to information
 		// that changed..
 		oldInput, hasOldInput := oldInputs[k]
-		if putil.IsComputed(inputs[k]) || (hasOldInput && !putil.DeepEquals(inputs[k], oldInput)) {
+		if putil.IsComputed(inputs[k]) {
+			trace("output %q is unknown: it depends on input %q, which is unknown", key, k)
+			return putil.MakeComputed(prop)
+		}
+		if hasOldInput && !putil.DeepEquals(inputs[k], oldInput) {
+			trace("output %q is unknown: it depends on input %q, which changed", key, k)
 			return putil.MakeComputed(prop)
 		}
 	}
@@ -474,11 +703,15 @@ func markComputed(
 }
 
 func markSecret(
-	field *field, key resource.PropertyKey, prop resource.PropertyValue, inputs resource.PropertyMap,
+	trace func(string, ...any), field *field, key resource.PropertyKey, prop resource.PropertyValue,
+	inputs resource.PropertyMap,
 ) resource.PropertyValue {
 	// If we should never return a secret, ensure that the field *is not* marked as
 	// secret, then return.
 	if field.neverSecret {
+		if putil.IsSecret(prop) {
+			trace("output %q is not secret: it is marked as never secret", key)
+		}
 		return putil.MakePublic(prop)
 	}
 
@@ -489,6 +722,7 @@ func markSecret(
 	// If we should always return a secret, ensure that the field *is* marked as secret,
 	// then return.
 	if field.alwaysSecret {
+		trace("output %q is secret: it is marked as always secret", key)
 		return putil.MakeSecret(prop)
 	}
 
@@ -506,6 +740,7 @@ func markSecret(
 			continue
 		}
 		if inputs[resource.PropertyKey(k.name)].ContainsSecrets() {
+			trace("output %q is secret: it depends on input %q, which is secret", key, k.name)
 			return putil.MakeSecret(prop)
 		}
 	}
@@ -514,15 +749,15 @@ func markSecret(
 }
 
 func markField(
-	field *field, key resource.PropertyKey, prop resource.PropertyValue,
+	trace func(string, ...any), field *field, key resource.PropertyKey, prop resource.PropertyValue,
 	oldInputs, inputs resource.PropertyMap, isCreate, isPreview bool,
 ) resource.PropertyValue {
 	// Fields can only be computed during preview. They must be known by when the resource is actually created.
 	if isPreview {
-		prop = markComputed(field, key, prop, oldInputs, inputs, isCreate)
+		prop = markComputed(trace, field, key, prop, oldInputs, inputs, isCreate)
 	}
 
-	return markSecret(field, key, prop, inputs)
+	return markSecret(trace, field, key, prop, inputs)
 
 }
 
@@ -591,6 +826,7 @@ func newFieldGenerator(i, o any) *fieldGenerator {
 		},
 
 		fields: map[string]*field{},
+		trace:  func(string, ...any) {},
 	}
 }
 
@@ -816,11 +1052,39 @@ type InferredResource interface {
 
 // Resource creates a new InferredResource, where `R` is the resource controller, `I` is
 // the resources inputs and `O` is the resources outputs.
-func Resource[R CustomResource[I, O], I, O any]() InferredResource {
-	return &derivedResourceController[R, I, O]{}
+func Resource[R CustomResource[I, O], I, O any](opts ...ResourceOption) InferredResource {
+	rc := &derivedResourceController[R, I, O]{}
+	for _, opt := range opts {
+		opt(&rc.opts)
+	}
+	return rc
+}
+
+// ResourceInstance creates a new InferredResource like [Resource], but controlled by r
+// instead of the zero value of R.
+//
+// Each call to a method of R is made on a copy of r, so R can carry dependencies such as
+// clients, loggers and clocks, which tests can replace:
+//
+//	infer.ResourceInstance[Widget, WidgetArgs, WidgetState](Widget{client: c})
+//
+// Since the copy is shallow, fields that hold pointers are shared between calls.
+func ResourceInstance[R CustomResource[I, O], I, O any](r R, opts ...ResourceOption) InferredResource {
+	rc := &derivedResourceController[R, I, O]{instance: &r}
+	for _, opt := range opts {
+		opt(&rc.opts)
+	}
+	return rc
 }
 
-type derivedResourceController[R CustomResource[I, O], I, O any] struct{}
+type derivedResourceController[R CustomResource[I, O], I, O any] struct {
+	opts resourceOptions
+	// instance is the controller given to [ResourceInstance], or nil to use the zero value
+	// of R.
+	instance *R
+	// diffs holds the result of Diff for [UpdateDiff].
+	diffs diffCache
+}
 
 func (*derivedResourceController[R, I, O]) isInferredResource() {}
 
@@ -859,13 +1123,40 @@ func (*derivedResourceController[R, I, O]) GetToken() (tokens.Type, error) {
 	return getToken[R](nil)
 }
 
-func (*derivedResourceController[R, I, O]) getInstance() *R {
+// SourcePosition returns where R is declared, for errors about the resource.
+func (*derivedResourceController[R, I, O]) SourcePosition() string {
+	return introspect.TypePosition(typeFor[R]())
+}
+
+func (rc *derivedResourceController[R, I, O]) getInstance() *R {
 	var r R
+	if rc.instance != nil {
+		r = *rc.instance
+	}
 	return &r
 }
 
+func (*derivedResourceController[R, I, O]) autonamed() bool { return hasAutonamedProperties[I]() }
+
 func (rc *derivedResourceController[R, I, O]) Check(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
-	var r R
+	return check[R, I](withResourceOptions(ctx, rc.opts), *rc.getInstance(), req)
+}
+
+// check implements Check for a resource controlled by r with inputs I.
+func check[R, I any](ctx context.Context, r R, req p.CheckRequest) (p.CheckResponse, error) {
+	ctx = withRandomSeed(ctx, req.RandomSeed)
+	warnDeprecatedInputs[I](ctx, req.News)
+	news, failures, err := applyAutonaming[I](req)
+	if err != nil || len(failures) > 0 {
+		return p.CheckResponse{Inputs: req.News, Failures: failures}, err
+	}
+	req.News = news
+	news, err = applyDefaultFromName[I](req)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	req.News = news
+
 	if r, ok := ((interface{})(r)).(CustomCheck[I]); ok {
 		// The user implemented check manually, so call that.
 		//
@@ -887,18 +1178,19 @@ func (rc *derivedResourceController[R, I, O]) Check(ctx context.Context, req p.C
 		// and `I`, so we are not guaranteed that `decodeCheckingMapErrors` won't
 		// produce errors.
 		if encoder == nil {
-			backupEncoder, _, _, _ := decodeCheckingMapErrors[I](req.News)
+			backupEncoder, _, _, _ := decodeCheckingMapErrors[I](ctx, req.News)
 			encoder = &backupEncoder
 		}
 
 		inputs, err := encoder.Encode(i)
+		inputs = restoreUnknownFields[I](ctx, req.News, inputs)
 		return p.CheckResponse{
 			Inputs:   applySecrets[I](inputs),
 			Failures: failures,
 		}, err
 	}
 
-	encoder, i, failures, err := decodeCheckingMapErrors[I](req.News)
+	encoder, i, failures, err := decodeCheckingMapErrors[I](ctx, req.News)
 	if err != nil {
 		return p.CheckResponse{}, err
 	}
@@ -916,6 +1208,7 @@ func (rc *derivedResourceController[R, I, O]) Check(ctx context.Context, req p.C
 	}
 
 	inputs, err := encoder.Encode(i)
+	inputs = restoreUnknownFields[I](ctx, req.News, inputs)
 
 	return p.CheckResponse{Inputs: applySecrets[I](inputs)}, err
 }
@@ -943,8 +1236,10 @@ func callCustomCheck[T any](
 // validation that is performed when leaving Check unimplemented.
 //
 // It also adds defaults to inputs as necessary, as defined by [Annotator.SetDefault].
+//
+// Unknown inputs are ignored for resources created with [PreserveUnknownFields].
 func DefaultCheck[I any](ctx context.Context, inputs resource.PropertyMap) (I, []p.CheckFailure, error) {
-	enc, i, failures, err := decodeCheckingMapErrors[I](inputs)
+	enc, i, failures, err := decodeCheckingMapErrors[I](ctx, inputs)
 
 	if v, ok := ctx.Value(defaultCheckEncoderKey{}).(*defaultCheckEncoderValue); ok {
 		v.enc = &enc
@@ -965,8 +1260,10 @@ func defaultCheck[I any](i I) (I, error) {
 	return i, nil
 }
 
-func decodeCheckingMapErrors[I any](inputs resource.PropertyMap) (ende.Encoder, I, []p.CheckFailure, error) {
-	encoder, i, err := ende.Decode[I](inputs)
+func decodeCheckingMapErrors[I any](
+	ctx context.Context, inputs resource.PropertyMap,
+) (ende.Encoder, I, []p.CheckFailure, error) {
+	encoder, i, err := decodeInputs[I](ctx, inputs)
 	if err != nil {
 		failures, e := checkFailureFromMapError(err)
 		return encoder, i, failures, e
@@ -1000,25 +1297,47 @@ func checkFailureFromMapError(err mapper.MappingError) ([]p.CheckFailure, error)
 }
 
 func (rc *derivedResourceController[R, I, O]) Diff(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+	resp, err := rc.computeDiff(ctx, req)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	// Only a diff with changes is followed by an update that can reuse it. Ignored
+	// changes have been applied to req.News, just as they are for Update.
+	if resp.HasChanges {
+		rc.diffs.store(req.Urn, req.Olds, req.News, resp)
+	}
+	return resp, nil
+}
+
+// computeDiff computes the diff of a resource, as served by Diff.
+func (rc *derivedResourceController[R, I, O]) computeDiff(
+	ctx context.Context, req p.DiffRequest,
+) (p.DiffResponse, error) {
+	ctx = withResourceOptions(ctx, rc.opts)
 	r := rc.getInstance()
 	_, hasUpdate := ((interface{})(*r)).(CustomUpdate[I, O])
 	var forceReplace func(string) bool
 	if hasUpdate {
-		schema, err := rc.GetSchema(func(tokens.Type, pschema.ComplexTypeSpec) bool { return false })
-		if err != nil {
-			return p.DiffResponse{}, err
-		}
-		forceReplace = func(s string) bool {
-			if schema.InputProperties == nil {
-				return false
-			}
-			return schema.InputProperties[s].ReplaceOnChanges
+		forceReplace = func(path string) bool {
+			return replaceOnChanges(typeFor[I](), path)
 		}
 	} else {
 		// No update => every change is a replace
 		forceReplace = func(string) bool { return true }
 	}
-	return diff[R, I, O](ctx, req, r, forceReplace)
+	resp, err := diff[R, I, O](ctx, req, r, forceReplace)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	resp, err = changedOutputs[R, I, O](r, req, resp)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	resp, err = detectDrift[R, I, O](ctx, r, req, resp, forceReplace)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	return rotate[R, O](ctx, req, resp)
 }
 
 // Compute a diff request.
@@ -1026,20 +1345,18 @@ func diff[R, I, O any](
 	ctx context.Context, req p.DiffRequest, r *R, forceReplace func(string) bool,
 ) (p.DiffResponse, error) {
 
-	for _, ignoredChange := range req.IgnoreChanges {
-		req.News[ignoredChange] = req.Olds[ignoredChange]
-	}
+	ctx = withIgnoreChanges(ctx, req.IgnoreChanges, req.Olds, req.News)
 
-	if r, ok := ((interface{})(*r)).(CustomDiff[I, O]); ok {
-		_, olds, err := hydrateFromState[R, I, O](ctx, req.Olds) // TODO
+	if d, ok := ((interface{})(*r)).(CustomDiff[I, O]); ok {
+		_, olds, err := hydrateFromState[R, I, O](ctx, *r, req.Olds) // TODO
 		if err != nil {
 			return p.DiffResponse{}, err
 		}
-		_, news, err := ende.Decode[I](req.News)
+		_, news, err := decodeInputs[I](ctx, req.News)
 		if err != nil {
 			return p.DiffResponse{}, err
 		}
-		diff, err := r.Diff(ctx, req.ID, olds, news)
+		diff, err := d.Diff(ctx, req.ID, olds, news)
 		if err != nil {
 			return p.DiffResponse{}, err
 		}
@@ -1052,12 +1369,26 @@ func diff[R, I, O any](
 	}
 	// Olds is an Output, but news is an Input. Output should be a superset of Input,
 	// so we need to filter out fields that are in Output but not Input.
+	olds, err := renameLegacyProperties[O](req.Olds)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
 	oldInputs := resource.PropertyMap{}
 	for k := range inputProps {
 		key := resource.PropertyKey(k)
-		oldInputs[key] = req.Olds[key]
+		oldInputs[key] = olds[key]
 	}
-	objDiff := oldInputs.Diff(req.News)
+	// Write-only inputs are not in state, so they can't participate in the diff. Neither
+	// can unknown inputs, which the resource doesn't act on.
+	news := knownFields[I](ctx, req.News).Copy()
+	if err := stripWriteOnly[I](news); err != nil {
+		return p.DiffResponse{}, err
+	}
+	if err := stripWriteOnly[I](oldInputs); err != nil {
+		return p.DiffResponse{}, err
+	}
+	// Sets are saved to state sorted, so the order they are configured in isn't a change.
+	objDiff := canonicalize[I](oldInputs).Diff(canonicalize[I](news))
 	pluginDiff := plugin.NewDetailedDiffFromObjectDiff(objDiff, false)
 	diff := map[string]p.PropertyDiff{}
 
@@ -1098,15 +1429,19 @@ func diff[R, I, O any](
 func (rc *derivedResourceController[R, I, O]) Create(
 	ctx context.Context, req p.CreateRequest,
 ) (resp p.CreateResponse, retError error) {
+	rc.diffs.evict(req.Urn)
+	ctx = withResourceOptions(ctx, rc.opts)
+	ctx = withDependencies(ctx, req.Properties, req.PropertyDependencies)
 	r := rc.getInstance()
 
 	var err error
-	encoder, input, err := ende.Decode[I](req.Properties)
+	encoder, input, err := decodeInputs[I](ctx, req.Properties)
 	if err != nil {
 		return p.CreateResponse{}, fmt.Errorf("invalid inputs: %w", err)
 	}
 
 	id, o, err := (*r).Create(ctx, req.Urn.Name(), input, req.Preview)
+	succeeded := err == nil
 	if initFailed := (ResourceInitFailedError{}); errors.As(err, &initFailed) {
 		defer func(createErr error) {
 			// If there was an error, it indicates a problem with serializing
@@ -1130,7 +1465,29 @@ func (rc *derivedResourceController[R, I, O]) Create(
 	} else if err != nil {
 		return p.CreateResponse{}, err
 	}
-
+	if rc.opts.embedInputs {
+		embedInputs(&input, &o)
+	}
+	startRotation(&o)
+
+	// The ID policy runs after Create, when the resource already exists. If the policy
+	// fails but Create returned an ID, the resource is reported as partially created under
+	// that ID, so that it is recorded in state instead of leaked.
+	var idErr error
+	if !req.Preview {
+		chosen, err := createdID(r, req.Urn.Name(), id, &o)
+		switch {
+		case err == nil:
+			id = chosen
+		case id == "":
+			return p.CreateResponse{}, err
+		case !succeeded:
+			// Create already reported the resource as partially created under id.
+			p.GetLogger(ctx).Warningf("%s", err)
+		default:
+			idErr, succeeded = err, false
+		}
+	}
 	if id == "" && !req.Preview {
 		return p.CreateResponse{}, ProviderErrorf("'%s' was created without an id", req.Urn)
 	}
@@ -1139,13 +1496,30 @@ func (rc *derivedResourceController[R, I, O]) Create(
 	if err != nil {
 		return p.CreateResponse{}, fmt.Errorf("encoding resource properties: %w", err)
 	}
+	m = canonicalize[O](m)
 
-	setDeps, err := getDependencies(r, &input, &o, true /* isCreate */, req.Preview)
+	setDeps, err := getDependencies(ctx, r, &input, &o, true /* isCreate */, req.Preview)
 	if err != nil {
 		return p.CreateResponse{}, err
 	}
 	setDeps(nil, req.Properties, m)
+	if err := stripWriteOnly[I](m); err != nil {
+		return p.CreateResponse{}, err
+	}
 
+	if hook, ok := ((interface{})(*r)).(CustomCreated[O]); ok && !req.Preview && succeeded {
+		if err := hook.OnCreated(ctx, id, o); err != nil {
+			p.GetLogger(ctx).Warningf("OnCreated hook failed: %s", err)
+		}
+	}
+
+	if idErr != nil {
+		return p.CreateResponse{
+			ID:           id,
+			Properties:   m,
+			PartialState: &p.InitializationFailed{Reasons: []string{idErr.Error()}},
+		}, idErr
+	}
 	return p.CreateResponse{
 		ID:         id,
 		Properties: m,
@@ -1155,10 +1529,12 @@ func (rc *derivedResourceController[R, I, O]) Create(
 func (rc *derivedResourceController[R, I, O]) Read(
 	ctx context.Context, req p.ReadRequest,
 ) (resp p.ReadResponse, retError error) {
+	rc.diffs.evict(req.Urn)
+	ctx = withResourceOptions(ctx, rc.opts)
 	r := rc.getInstance()
 	var inputs I
 	var err error
-	inputEncoder, err := ende.DecodeTolerateMissing(req.Inputs, &inputs)
+	inputEncoder, err := ende.DecodeTolerateMissing(knownFields[I](ctx, req.Inputs), &inputs)
 	if err != nil {
 		return p.ReadResponse{}, err
 	}
@@ -1177,7 +1553,7 @@ func (rc *derivedResourceController[R, I, O]) Read(
 	var state O
 
 	// If (1), then we expect that the state is complete and may need to be upgraded.
-	if enc, s, err := hydrateFromState[R, I, O](ctx, req.Properties); err == nil {
+	if enc, s, err := hydrateFromState[R, I, O](ctx, *r, req.Properties); err == nil {
 		stateEncoder = enc
 		state = s
 	} else {
@@ -1201,6 +1577,7 @@ func (rc *derivedResourceController[R, I, O]) Read(
 			Inputs:     req.Inputs,
 		}, nil
 	}
+	rotated := rotationTimestamp(&state)
 	id, inputs, state, err := read.Read(ctx, req.ID, inputs, state)
 	if initFailed := (ResourceInitFailedError{}); errors.As(err, &initFailed) {
 		defer func(readErr error) {
@@ -1225,15 +1602,22 @@ func (rc *derivedResourceController[R, I, O]) Read(
 	} else if err != nil {
 		return p.ReadResponse{}, err
 	}
+	if id, err = readID(r, id, &state); err != nil {
+		return p.ReadResponse{}, err
+	}
+	keepRotation(rotated, &state)
 
 	i, err := inputEncoder.Encode(inputs)
 	if err != nil {
 		return p.ReadResponse{}, err
 	}
+	i = restoreUnknownFields[I](ctx, req.Inputs, i)
+	i = rc.opts.resolveReadConflicts(ctx, req.Inputs, i)
 	s, err := stateEncoder.Encode(state)
 	if err != nil {
 		return p.ReadResponse{}, err
 	}
+	s = canonicalize[O](s)
 
 	return p.ReadResponse{
 		ID:         id,
@@ -1245,25 +1629,39 @@ func (rc *derivedResourceController[R, I, O]) Read(
 func (rc *derivedResourceController[R, I, O]) Update(
 	ctx context.Context, req p.UpdateRequest,
 ) (resp p.UpdateResponse, retError error) {
+	ctx = withResourceOptions(ctx, rc.opts)
 	r := rc.getInstance()
 	update, ok := ((interface{})(*r)).(CustomUpdate[I, O])
 	if !ok {
 		return p.UpdateResponse{}, status.Errorf(codes.Unimplemented,
 			"Update is not implemented for resource %s", req.Urn)
 	}
-	for _, ignoredChange := range req.IgnoreChanges {
-		req.News[ignoredChange] = req.Olds[ignoredChange]
-	}
+	ctx = withIgnoreChanges(ctx, req.IgnoreChanges, req.Olds, req.News)
+	ctx = withDependencies(ctx, req.News, req.PropertyDependencies)
 
-	_, olds, err := hydrateFromState[R, I, O](ctx, req.Olds)
+	_, olds, err := hydrateFromState[R, I, O](ctx, *r, req.Olds)
 	if err != nil {
 		return p.UpdateResponse{}, err
 	}
-	encoder, news, err := ende.Decode[I](req.News)
+	encoder, news, err := decodeInputs[I](ctx, req.News)
 	if err != nil {
 		return p.UpdateResponse{}, err
 	}
+	// The cached diff is loaded even if Update never asks for it, so it isn't kept around.
+	cached, isCached := rc.diffs.load(req.Urn, req.Olds, req.News)
+	ctx = withUpdateDiff(ctx, func() (p.DiffResponse, error) {
+		if isCached {
+			return cached, nil
+		}
+		return rc.computeDiff(ctx, p.DiffRequest{
+			ID:   req.ID,
+			Urn:  req.Urn,
+			Olds: req.Olds.Copy(),
+			News: req.News.Copy(),
+		})
+	})
 	o, err := update.Update(ctx, req.ID, olds, news, req.Preview)
+	succeeded := err == nil
 	if initFailed := (ResourceInitFailedError{}); errors.As(err, &initFailed) {
 		defer func(updateErr error) {
 			// If there was an error, it indicates a problem with serializing
@@ -1289,32 +1687,94 @@ func (rc *derivedResourceController[R, I, O]) Update(
 	if err != nil {
 		return p.UpdateResponse{}, err
 	}
+	if rc.opts.embedInputs {
+		embedInputs(&news, &o)
+	}
+	keepRotation(rotationTimestamp(&olds), &o)
 	m, err := encoder.AllowUnknown(req.Preview).Encode(o)
 	if err != nil {
 		return p.UpdateResponse{}, err
 	}
-	setDeps, err := getDependencies(r, &news, &o, false /* isCreate */, req.Preview)
+	m = canonicalize[O](m)
+	setDeps, err := getDependencies(ctx, r, &news, &o, false /* isCreate */, req.Preview)
 	if err != nil {
 		return p.UpdateResponse{}, err
 	}
 	setDeps(req.Olds, req.News, m)
+	if err := stripWriteOnly[I](m); err != nil {
+		return p.UpdateResponse{}, err
+	}
+
+	if hook, ok := ((interface{})(*r)).(CustomUpdated[O]); ok && !req.Preview && succeeded {
+		if err := hook.OnUpdated(ctx, req.ID, olds, o); err != nil {
+			p.GetLogger(ctx).Warningf("OnUpdated hook failed: %s", err)
+		}
+	}
 
 	return p.UpdateResponse{
 		Properties: m,
 	}, nil
 }
 
-func (rc *derivedResourceController[R, I, O]) Delete(ctx context.Context, req p.DeleteRequest) error {
+func (rc *derivedResourceController[R, I, O]) Delete(
+	ctx context.Context, req p.DeleteRequest,
+) (p.DeleteResponse, error) {
+	rc.diffs.evict(req.Urn)
 	r := rc.getInstance()
-	del, ok := ((interface{})(*r)).(CustomDelete[O])
-	if ok {
-		_, olds, err := hydrateFromState[R, I, O](ctx, req.Properties)
-		if err != nil {
-			return err
+	var del func(olds O) (p.DeleteResponse, error)
+	switch d := ((interface{})(*r)).(type) {
+	case CustomDeleteWithResponse[O]:
+		del = func(olds O) (p.DeleteResponse, error) { return d.Delete(ctx, req.ID, olds) }
+	case CustomDelete[O]:
+		del = func(olds O) (p.DeleteResponse, error) { return p.DeleteResponse{}, d.Delete(ctx, req.ID, olds) }
+	default:
+		return p.DeleteResponse{}, nil
+	}
+	_, olds, err := hydrateFromState[R, I, O](ctx, *r, req.Properties)
+	if err != nil {
+		return p.DeleteResponse{}, err
+	}
+	return awaitDelete(ctx, req, func() (p.DeleteResponse, error) { return del(olds) })
+}
+
+// awaitDelete runs del, returning early with a timeout error if ctx expires before del
+// returns.
+//
+// The context passed to Delete carries a deadline when the engine sets a delete timeout,
+// but Delete implementations are not required to observe it. Returning when the deadline
+// passes prevents a hung delete from blocking `pulumi destroy` indefinitely.
+func awaitDelete(
+	ctx context.Context, req p.DeleteRequest, del func() (p.DeleteResponse, error),
+) (p.DeleteResponse, error) {
+	timedOut := func(err error) error {
+		timeout := time.Duration(req.Timeout * float64(time.Second))
+		return fmt.Errorf("timed out deleting %q after %s: %w", req.ID, timeout, err)
+	}
+	if _, ok := ctx.Deadline(); !ok || req.Timeout <= 0 {
+		return del()
+	}
+
+	type result struct {
+		resp p.DeleteResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := del()
+		done <- result{resp, err}
+	}()
+	select {
+	case r := <-done:
+		if errors.Is(r.err, context.DeadlineExceeded) {
+			return p.DeleteResponse{}, timedOut(r.err)
+		}
+		return r.resp, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return p.DeleteResponse{}, timedOut(ctx.Err())
 		}
-		return del.Delete(ctx, req.ID, olds)
+		return p.DeleteResponse{}, ctx.Err()
 	}
-	return nil
 }
 
 // Apply dependencies to a property map, flowing secretness and computedness from input to
@@ -1323,23 +1783,29 @@ type setDeps func(oldInputs, input, output resource.PropertyMap)
 
 // Get the decency mapping between inputs and outputs of a resource.
 func getDependencies[R, I, O any](
-	r *R, input *I, output *O, isCreate, isPreview bool,
+	ctx context.Context, r *R, input *I, output *O, isCreate, isPreview bool,
 ) (setDeps, error) {
 	var wire func(FieldSelector)
 
 	if r, ok := ((interface{})(*r)).(ExplicitDependencies[I, O]); ok {
 		wire = func(fg FieldSelector) {
 			r.WireDependencies(fg, input, output)
+			warnUnwired[R, I, O](ctx, fg.(*fieldGenerator))
 		}
 	}
-	return getDependenciesRaw(input, output, wire, isCreate, isPreview)
+	return getDependenciesRaw(ctx, input, output, wire, isCreate, isPreview)
 }
 
 // getDependenciesRaw is the untyped implementation of getDependencies.
 func getDependenciesRaw(
-	input, output any, wire func(FieldSelector), isCreate, isPreview bool,
+	ctx context.Context, input, output any, wire func(FieldSelector), isCreate, isPreview bool,
 ) (setDeps, error) {
 	fg := newFieldGenerator(input, output)
+	if p.FrameworkLogEnabled(ctx, p.FrameworkLogDependencies) {
+		fg.trace = func(msg string, a ...any) {
+			p.FrameworkLogf(ctx, p.FrameworkLogDependencies, msg, a...)
+		}
+	}
 	if wire != nil {
 		wire(fg)
 		if err := fg.err.ErrorOrNil(); err != nil {
@@ -1361,9 +1827,8 @@ func getDependenciesRaw(
 // hydrateFromState takes a blob from state and hydrates it for user consumption, running any relevant state
 // migrations.
 func hydrateFromState[R, I, O any](
-	ctx context.Context, state resource.PropertyMap,
+	ctx context.Context, r R, state resource.PropertyMap,
 ) (ende.Encoder, O, error) {
-	var r R
 	if r, ok := ((interface{})(r)).(CustomStateMigrations[O]); ok {
 		enc, newState, didMigrate, err := migrateState[O](ctx, r, state)
 		if err != nil || didMigrate {
@@ -1371,14 +1836,61 @@ func hydrateFromState[R, I, O any](
 		}
 	}
 
-	return ende.Decode[O](state)
+	state, err := renameLegacyProperties[O](state)
+	if err != nil {
+		var o O
+		return ende.Encoder{}, o, err
+	}
+	enc, o, err := ende.Decode[O](state)
+	logRemoved(ctx, typeFor[O](), enc)
+	return enc, o, err
+}
+
+// renameLegacyProperties moves top level properties of state that are stored under a
+// legacy name (declared with a `migrate:"oldName"` tag on O) to their current name.
+//
+// If a property is present under both names, the current name wins. state is not
+// mutated.
+func renameLegacyProperties[O any](state resource.PropertyMap) (resource.PropertyMap, error) {
+	typ := typeFor[O]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return state, nil
+	}
+	props, err := introspect.FindProperties(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var renamed resource.PropertyMap
+	for name, prop := range props {
+		for _, legacy := range prop.LegacyNames {
+			v, ok := state[resource.PropertyKey(legacy)]
+			if !ok {
+				continue
+			}
+			if renamed == nil {
+				renamed = state.Copy()
+			}
+			delete(renamed, resource.PropertyKey(legacy))
+			if _, ok := renamed[resource.PropertyKey(name)]; !ok {
+				renamed[resource.PropertyKey(name)] = v
+			}
+		}
+	}
+	if renamed == nil {
+		return state, nil
+	}
+	return renamed, nil
 }
 
 func migrateState[O any](
 	ctx context.Context, r CustomStateMigrations[O], state resource.PropertyMap,
 ) (ende.Encoder, O, bool, error) {
 	var o O
-	for _, upgrader := range r.StateMigrations(ctx) {
+	for i, upgrader := range r.StateMigrations(ctx) {
 		oldType := upgrader.oldShape()
 		f := upgrader.migrateFunc()
 
@@ -1398,6 +1910,8 @@ func migrateState[O any](
 			enc, err = ende.DecodeAny(state, oldValue.Interface())
 			if err != nil {
 				// If we couldn't encode cleanly, then state doesn't fit into the migrator.
+				p.FrameworkLogf(ctx, p.FrameworkLogMigrations,
+					"skipping migration %d: the state does not fit %s: %v", i, oldType, err)
 				continue
 			}
 
@@ -1422,8 +1936,11 @@ func migrateState[O any](
 			result, results[0].Interface())
 
 		if result.Result == nil {
+			p.FrameworkLogf(ctx, p.FrameworkLogMigrations,
+				"skipping migration %d: it returned no result for the state", i)
 			continue
 		}
+		p.FrameworkLogf(ctx, p.FrameworkLogMigrations, "applying migration %d from %s", i, oldType)
 
 		// The migration succeeded, so we are done
 		//
@@ -1442,5 +1959,6 @@ func migrateState[O any](
 	}
 
 	// No migration was run
+	p.FrameworkLogf(ctx, p.FrameworkLogMigrations, "no migration applies to the state of %s", typeFor[O]())
 	return ende.Encoder{}, o, false, nil
 }
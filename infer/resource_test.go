@@ -48,7 +48,7 @@ func testGetDependencies[I any, O any](t *testing.T,
 		}
 	}
 	setDeps, err := getDependenciesRaw(
-		&i, &o, wireDeps,
+		context.Background(), &i, &o, wireDeps,
 		false, /*isCreate*/
 		true /*isPreview*/)
 	require.NoError(t, err)
@@ -348,7 +348,8 @@ func testHydrateFromState[O any](
 			Context: context.WithValue(context.Background(), migrationsKey, migrations),
 		}
 
-		enc, actual, err := hydrateFromState[CustomHydrateFromState[O], struct{}, O](ctx, oldState)
+		enc, actual, err := hydrateFromState[CustomHydrateFromState[O], struct{}, O](
+			ctx, CustomHydrateFromState[O]{}, oldState)
 		if expectedError != nil {
 			assert.ErrorIs(t, err, expectedError)
 			return
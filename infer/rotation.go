@@ -0,0 +1,196 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// Rotation replaces a resource periodically, as is common for credentials that should be
+// rotated. Embed Rotation in the state of the resource, and set how long the resource is
+// kept with [Annotator.SetRotation]:
+//
+//	type KeyState struct {
+//		KeyArgs
+//		infer.Rotation
+//		Secret string `pulumi:"secret" provider:"secret"`
+//	}
+//
+//	func (*Key) Annotate(a infer.Annotator) {
+//		a.SetRotation(30 * 24 * time.Hour)
+//	}
+//
+// The time the resource was created is recorded in its state, but left out of its schema.
+// Once the rotation window has elapsed, Diff replaces the resource. A rotation can also be
+// requested early with the `triggerRotation` function, which is added to the provider
+// when a resource embeds Rotation. It takes the URN of the resource to rotate, and must
+// be called before the resource is registered in the same deployment.
+type Rotation struct {
+	// RotationTimestamp is the time the resource was created, in RFC 3339 format. It is
+	// set by infer.
+	RotationTimestamp string `pulumi:"rotationTimestamp,optional"`
+}
+
+// rotationTimestampKey is the property [Rotation.RotationTimestamp] is stored under.
+const rotationTimestampKey resource.PropertyKey = "rotationTimestamp"
+
+// rotationFunctionToken is the token of the function that triggers a rotation.
+const rotationFunctionToken tokens.Type = "pkg:index:triggerRotation"
+
+// pendingRotations holds the URNs of the resources for which a rotation was triggered,
+// and that have not been diffed since.
+var pendingRotations sync.Map // resource.URN -> struct{}
+
+// rotationOf returns the [Rotation] embedded in o, or nil if o doesn't embed one.
+func rotationOf[O any](o *O) *Rotation {
+	v := reflect.ValueOf(o).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	f, ok := v.Type().FieldByName("Rotation")
+	if !ok || !f.Anonymous || f.Type != typeFor[Rotation]() {
+		return nil
+	}
+	field, err := v.FieldByIndexErr(f.Index)
+	if err != nil {
+		return nil
+	}
+	return field.Addr().Interface().(*Rotation)
+}
+
+// startRotation records that o was created now.
+func startRotation[O any](o *O) {
+	if rot := rotationOf(o); rot != nil {
+		rot.RotationTimestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+}
+
+// keepRotation carries the rotation timestamp of a resource over from previous to o, so
+// that updating or reading a resource doesn't restart its rotation window.
+func keepRotation[O any](previous string, o *O) {
+	rot := rotationOf(o)
+	if rot == nil || rot.RotationTimestamp != "" {
+		return
+	}
+	if previous == "" {
+		startRotation(o)
+		return
+	}
+	rot.RotationTimestamp = previous
+}
+
+// rotationTimestamp returns the rotation timestamp of o, if any.
+func rotationTimestamp[O any](o *O) string {
+	if rot := rotationOf(o); rot != nil {
+		return rot.RotationTimestamp
+	}
+	return ""
+}
+
+// rotate adds a replacement to diff when the rotation window of the resource has elapsed,
+// or when a rotation was triggered for it.
+func rotate[R, O any](ctx context.Context, req p.DiffRequest, diff p.DiffResponse) (p.DiffResponse, error) {
+	if rotationOf(new(O)) == nil {
+		return diff, nil
+	}
+	_, triggered := pendingRotations.LoadAndDelete(req.Urn)
+	if !triggered {
+		window := getAnnotated(typeFor[R]()).RotationWindow
+		ts := req.Olds[rotationTimestampKey]
+		if window == 0 || !ts.IsString() {
+			return diff, nil
+		}
+		created, err := time.Parse(time.RFC3339, ts.StringValue())
+		if err != nil {
+			return p.DiffResponse{}, fmt.Errorf("invalid %s: %w", rotationTimestampKey, err)
+		}
+		if time.Since(created) < window {
+			return diff, nil
+		}
+		p.GetLogger(ctx).Infof("Rotating the resource: it was created more than %s ago", window)
+	}
+
+	diff.DetailedDiff = maps.Clone(diff.DetailedDiff)
+	if diff.DetailedDiff == nil {
+		diff.DetailedDiff = map[string]p.PropertyDiff{}
+	}
+	diff.DetailedDiff[string(rotationTimestampKey)] = p.PropertyDiff{Kind: p.UpdateReplace}
+	diff.HasChanges = true
+	return diff, nil
+}
+
+// rotationFunction returns the function that triggers rotations, if any of resources
+// embeds [Rotation] in its state.
+func rotationFunction(resources []InferredResource) InferredFunction {
+	for _, r := range resources {
+		if r, ok := r.(interface{ rotates() bool }); ok && r.rotates() {
+			return triggerRotation{}
+		}
+	}
+	return nil
+}
+
+func (*derivedResourceController[R, I, O]) rotates() bool { return rotationOf(new(O)) != nil }
+
+// triggerRotation requests the rotation of a resource. See [Rotation].
+type triggerRotation struct{}
+
+func (triggerRotation) isInferredFunction() {}
+
+func (triggerRotation) GetToken() (tokens.Type, error) { return rotationFunctionToken, nil }
+
+func (triggerRotation) GetSchema(schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	return pschema.FunctionSpec{
+		Description: "Replaces a resource that supports rotation the next time it is updated, " +
+			"even if its rotation window has not elapsed.\n\n" +
+			"The function must be called before the resource is registered.",
+		Inputs: &pschema.ObjectTypeSpec{
+			Type: "object",
+			Properties: map[string]pschema.PropertySpec{
+				"urn": {
+					TypeSpec:    pschema.TypeSpec{Type: "string"},
+					Description: "The URN of the resource to rotate.",
+				},
+			},
+			Required: []string{"urn"},
+		},
+		Outputs: &pschema.ObjectTypeSpec{Type: "object"},
+	}, nil
+}
+
+func (triggerRotation) Invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	v := req.Args["urn"]
+	if !v.IsString() {
+		return p.InvokeResponse{Failures: []p.CheckFailure{{Property: "urn", Reason: "missing required property"}}}, nil
+	}
+	urn, err := resource.ParseURN(v.StringValue())
+	if err != nil {
+		return p.InvokeResponse{Failures: []p.CheckFailure{{Property: "urn", Reason: err.Error()}}}, nil
+	}
+	pendingRotations.Store(urn, struct{}{})
+	return p.InvokeResponse{Return: resource.PropertyMap{}}, nil
+}
@@ -15,6 +15,7 @@
 package infer
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -24,6 +25,7 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
 
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
 	"github.com/pulumi/pulumi-go-provider/infer/types"
 	"github.com/pulumi/pulumi-go-provider/internal/introspect"
 	sch "github.com/pulumi/pulumi-go-provider/middleware/schema"
@@ -57,15 +59,48 @@ func getAnnotated(t reflect.Type) introspect.Annotator {
 		for k, v := range src.DefaultEnvs {
 			(*dst).DefaultEnvs[k] = v
 		}
+		for k, v := range src.WriteOnlyFields {
+			(*dst).WriteOnlyFields[k] = v
+		}
+		for k, v := range src.AutonameFields {
+			(*dst).AutonameFields[k] = v
+		}
+		for k, v := range src.DeprecatedFields {
+			(*dst).DeprecatedFields[k] = v
+		}
+		for k, v := range src.ReplaceOnChangesFields {
+			(*dst).ReplaceOnChangesFields[k] = v
+		}
+		for k, v := range src.UnwiredFields {
+			(*dst).UnwiredFields[k] = v
+		}
+		for k, v := range src.DefaultFromNameFields {
+			(*dst).DefaultFromNameFields[k] = v
+		}
 		dst.Token = src.Token
+		dst.ListToken = src.ListToken
 		dst.Aliases = append(dst.Aliases, src.Aliases...)
 		dst.DeprecationMessage = src.DeprecationMessage
+		dst.Replacement = src.Replacement
+		if src.Category != "" {
+			dst.Category = src.Category
+		}
+		dst.Keywords = append(dst.Keywords, src.Keywords...)
+		if src.RotationWindow != 0 {
+			dst.RotationWindow = src.RotationWindow
+		}
 	}
 
 	ret := introspect.Annotator{
-		Descriptions: map[string]string{},
-		Defaults:     map[string]any{},
-		DefaultEnvs:  map[string][]string{},
+		Descriptions:           map[string]string{},
+		Defaults:               map[string]any{},
+		DefaultEnvs:            map[string][]string{},
+		WriteOnlyFields:        map[string]bool{},
+		AutonameFields:         map[string]introspect.AutonameOptions{},
+		DeprecatedFields:       map[string]string{},
+		ReplaceOnChangesFields: map[string]bool{},
+		UnwiredFields:          map[string]bool{},
+		DefaultFromNameFields:  map[string]bool{},
 	}
 	if t.Elem().Kind() == reflect.Struct {
 		for _, f := range reflect.VisibleFields(t.Elem()) {
@@ -85,6 +120,19 @@ func getAnnotated(t reflect.Type) introspect.Annotator {
 	return ret
 }
 
+// deprecationMessage returns the deprecation message described by annotations, naming
+// the replacement if there is one. It is empty if nothing is deprecated.
+func deprecationMessage(annotations introspect.Annotator) string {
+	if annotations.Replacement == "" {
+		return annotations.DeprecationMessage
+	}
+	msg := fmt.Sprintf("Use %s instead.", annotations.Replacement)
+	if annotations.DeprecationMessage != "" {
+		msg = annotations.DeprecationMessage + " " + msg
+	}
+	return msg
+}
+
 func getResourceSchema[R, I, O any](isComponent bool) (schema.ResourceSpec, multierror.Error) {
 	var r R
 	var errs multierror.Error
@@ -102,23 +150,91 @@ func getResourceSchema[R, I, O any](isComponent bool) (schema.ResourceSpec, mult
 		errs.Errors = append(errs.Errors, fmt.Errorf("could not serialize input type %T: %w", i, err))
 	}
 
+	// Write-only inputs are never returned as outputs.
+	writeOnly, err := writeOnlyProperties[I]()
+	if err != nil {
+		errs.Errors = append(errs.Errors, err)
+	}
+	for k := range writeOnly {
+		delete(properties, string(k))
+	}
+
+	// The rotation timestamp is kept in state, but is not part of the resource's API.
+	if rotationOf(new(O)) != nil {
+		delete(properties, string(rotationTimestampKey))
+	} else if annotations.RotationWindow != 0 {
+		var o O
+		errs.Errors = append(errs.Errors, fmt.Errorf("SetRotation requires %T to embed infer.Rotation", o))
+	}
+
+	// Record that auto-named inputs are generated when omitted.
+	autonamed, err := autonamedProperties[I]()
+	if err != nil {
+		errs.Errors = append(errs.Errors, err)
+	}
+	for k := range autonamed {
+		prop, ok := inputProperties[string(k)]
+		if !ok {
+			continue
+		}
+		const note = "If not set, a name is generated from the resource name."
+		if prop.Description == "" {
+			prop.Description = note
+		} else {
+			prop.Description += "\n\n" + note
+		}
+		inputProperties[string(k)] = prop
+	}
+
+	// Record that inputs defaulting to the resource name are filled in when omitted.
+	fromName, err := defaultFromNameProperties[I]()
+	if err != nil {
+		errs.Errors = append(errs.Errors, err)
+	}
+	for k := range fromName {
+		prop, ok := inputProperties[string(k)]
+		if !ok {
+			continue
+		}
+		const note = "If not set, the resource name is used."
+		if prop.Description == "" {
+			prop.Description = note
+		} else {
+			prop.Description += "\n\n" + note
+		}
+		inputProperties[string(k)] = prop
+	}
+
 	var aliases []schema.AliasSpec
 	for _, alias := range annotations.Aliases {
 		a := alias
 		aliases = append(aliases, schema.AliasSpec{Type: &a})
 	}
 
+	var language map[string]schema.RawMessage
+	if annotations.Category != "" || len(annotations.Keywords) > 0 {
+		registry, err := json.Marshal(sch.RegistryResource{
+			Category: annotations.Category,
+			Keywords: annotations.Keywords,
+		})
+		if err != nil {
+			errs.Errors = append(errs.Errors, err)
+		}
+		language = map[string]schema.RawMessage{sch.RegistryLanguage: registry}
+	}
+
 	return schema.ResourceSpec{
 		ObjectTypeSpec: schema.ObjectTypeSpec{
 			Properties:  properties,
 			Description: annotations.Descriptions[""],
 			Required:    required,
+			Language:    language,
 		},
 		InputProperties:    inputProperties,
 		RequiredInputs:     requiredInputs,
 		IsComponent:        isComponent,
 		Aliases:            aliases,
-		DeprecationMessage: annotations.DeprecationMessage,
+		DeprecationMessage: deprecationMessage(annotations),
 	}, errs
 }
 
@@ -128,6 +244,9 @@ func serializeTypeAsPropertyType(
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
+	if codec, ok := ende.CodecFor(t); ok {
+		return codec.Schema, nil
+	}
 	if t == reflect.TypeOf(resource.Asset{}) {
 		// Provider authors should not be using resource.Asset directly, but rather types.AssetOrArchive. #243
 		return schema.TypeSpec{
@@ -149,6 +268,9 @@ func serializeTypeAsPropertyType(
 			Ref: "#/types/" + enum.token,
 		}, nil
 	}
+	if variants, ok := ende.UnionVariants(t); ok {
+		return serializeUnion(variants, indicatePlain)
+	}
 	t, inputy, err := underlyingType(t)
 	if err != nil {
 		return schema.TypeSpec{}, err
@@ -210,6 +332,47 @@ func serializeTypeAsPropertyType(
 	}
 }
 
+// serializeUnion projects the variants of a [Union] into a `oneOf` type.
+//
+// If every variant has a discriminator on the same property, a discriminator is added
+// to the type.
+func serializeUnion(variants []reflect.Type, indicatePlain bool) (schema.TypeSpec, error) {
+	spec := schema.TypeSpec{}
+	var discriminator *schema.DiscriminatorSpec
+	for i, variant := range variants {
+		// Nested unions are flattened into a single oneOf.
+		if nested, ok := ende.UnionVariants(variant); ok {
+			inner, err := serializeUnion(nested, indicatePlain)
+			if err != nil {
+				return schema.TypeSpec{}, err
+			}
+			spec.OneOf = append(spec.OneOf, inner.OneOf...)
+			discriminator = nil
+			continue
+		}
+		variantSpec, err := serializeTypeAsPropertyType(variant, indicatePlain, nil)
+		if err != nil {
+			return schema.TypeSpec{}, err
+		}
+		spec.OneOf = append(spec.OneOf, variantSpec)
+
+		property, value, ok := ende.Discriminator(variant)
+		if i == 0 && ok {
+			discriminator = &schema.DiscriminatorSpec{
+				PropertyName: property,
+				Mapping:      map[string]string{},
+			}
+		}
+		if !ok || variantSpec.Ref == "" || discriminator == nil || discriminator.PropertyName != property {
+			discriminator = nil
+			continue
+		}
+		discriminator.Mapping[value] = variantSpec.Ref
+	}
+	spec.Discriminator = discriminator
+	return spec, nil
+}
+
 // underlyingType find the non-inputty, non-ptr type of t. It returns the underlying type
 // and if t was an Inputty or Outputty type.
 func underlyingType(t reflect.Type) (reflect.Type, bool, error) {
@@ -238,6 +401,10 @@ func underlyingType(t reflect.Type) (reflect.Type, bool, error) {
 		}
 
 		toOutMethod, ok := t.MethodByName("To" + T + "Output")
+		if !ok {
+			// Generic inputs, such as pulumix.Input[T], are converted with ToOutput.
+			toOutMethod, ok = t.MethodByName("ToOutput")
+		}
 		if !ok {
 			return nil, false, fmt.Errorf("%v is an input type, but does not have a To%vOutput method", t.Name(), T)
 		}
@@ -273,24 +440,37 @@ func propertyListFromType(typ reflect.Type, indicatePlain bool) (
 		}
 		tags, err := introspect.ParseTag(field)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid fields '%s' on '%s': %w", field.Name, typ, err)
+			return nil, nil, fmt.Errorf("invalid fields '%s' on '%s'%s: %w",
+				field.Name, typ, introspect.At(introspect.FieldPosition(typ, field)), err)
 		}
 		if tags.Internal {
 			continue
 		}
+		if tags.Set && fieldType.Kind() != reflect.Slice && fieldType.Kind() != reflect.Array {
+			return nil, nil, fmt.Errorf("invalid field '%s' on '%s'%s: `provider:\"set\"` requires a slice or array, found %s",
+				field.Name, typ, introspect.At(introspect.FieldPosition(typ, field)), fieldType)
+		}
+		if tags.SetKey != "" && !hasSetKey(fieldType, tags.SetKey) {
+			return nil, nil, fmt.Errorf("invalid field '%s' on '%s'%s: "+
+				"`provider:\"setKey=%s\"` requires a slice of structs with a %q field, found %s",
+				field.Name, typ, introspect.At(introspect.FieldPosition(typ, field)),
+				tags.SetKey, tags.SetKey, fieldType)
+		}
 		serialized, err := serializeTypeAsPropertyType(fieldType, indicatePlain, tags.ExplicitRef)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid type '%s' on '%s.%s': %w", fieldType, typ, field.Name, err)
+			return nil, nil, fmt.Errorf("invalid type '%s' on '%s.%s'%s: %w",
+				fieldType, typ, field.Name, introspect.At(introspect.FieldPosition(typ, field)), err)
 		}
 		if !tags.Optional {
 			required = append(required, tags.Name)
 		}
 		spec := &schema.PropertySpec{
-			TypeSpec:         serialized,
-			Secret:           tags.Secret,
-			ReplaceOnChanges: tags.ReplaceOnChanges,
-			Description:      annotations.Descriptions[tags.Name],
-			Default:          annotations.Defaults[tags.Name],
+			TypeSpec:           serialized,
+			Secret:             tags.Secret,
+			ReplaceOnChanges:   tags.ReplaceOnChanges || annotations.ReplaceOnChangesFields[tags.Name],
+			Description:        annotations.Descriptions[tags.Name],
+			Default:            annotations.Defaults[tags.Name],
+			DeprecationMessage: annotations.DeprecatedFields[tags.Name],
 		}
 		if envs := annotations.DefaultEnvs[tags.Name]; len(envs) > 0 {
 			spec.DefaultInfo = &schema.DefaultSpec{
@@ -334,15 +514,18 @@ func resourceReferenceToken(
 }
 
 func structReferenceToken(t reflect.Type, extTag *introspect.ExplicitType) (schema.TypeSpec, bool, error) {
+	if extTag != nil && extTag.Pkg != "" &&
+		t.Kind() != reflect.Map && t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		// Besides objects, the type may be a scalar, such as a string holding a value of an
+		// enum from another package.
+		return schema.TypeSpec{
+			Ref: fmt.Sprintf("/%s/%s/schema.json#/types/%s:%s:%s",
+				extTag.Pkg, extTag.Version,
+				extTag.Pkg, extTag.Module, extTag.Name,
+			),
+		}, true, nil
+	}
 	if t.Kind() == reflect.Struct && extTag != nil {
-		if extTag.Pkg != "" {
-			return schema.TypeSpec{
-				Ref: fmt.Sprintf("/%s/%s/schema.json#/types/%s:%s:%s",
-					extTag.Pkg, extTag.Version,
-					extTag.Pkg, extTag.Module, extTag.Name,
-				),
-			}, true, nil
-		}
 		return schema.TypeSpec{
 			Ref: fmt.Sprintf("#/types/pkg:%s:%s", extTag.Module, extTag.Name),
 		}, true, nil
@@ -376,3 +559,23 @@ func schemaNameForType(t reflect.Kind) string {
 		panic(fmt.Sprintf("unknown primitive type: %s", t))
 	}
 }
+
+// hasSetKey reports whether the elements of the slice typ are structs with a key field.
+func hasSetKey(typ reflect.Type, key string) bool {
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		return false
+	}
+	elem := typ.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return false
+	}
+	props, err := introspect.FindProperties(elem)
+	if err != nil {
+		return false
+	}
+	_, ok := props[key]
+	return ok
+}
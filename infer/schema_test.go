@@ -15,10 +15,15 @@
 package infer
 
 import (
+	"encoding/json"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+	sch "github.com/pulumi/pulumi-go-provider/middleware/schema"
 )
 
 type TestResource struct {
@@ -29,6 +34,8 @@ func (r *TestResource) Annotate(a Annotator) {
 	a.AddAlias("myMod", "MyAlias")
 	a.SetResourceDeprecationMessage("This resource is deprecated.")
 	a.SetToken("myMod", "TheResource")
+	a.SetCategory("Testing")
+	a.AddKeywords("test", "example")
 }
 
 func TestResourceAnnotations(t *testing.T) {
@@ -43,4 +50,73 @@ func TestResourceAnnotations(t *testing.T) {
 	require.Equal(t, "This is a test resource.", spec.Description)
 
 	require.Equal(t, "This resource is deprecated.", spec.DeprecationMessage)
+
+	var registry sch.RegistryResource
+	require.NoError(t, json.Unmarshal(spec.Language[sch.RegistryLanguage], &registry))
+	assert.Equal(t, sch.RegistryResource{
+		Category: "Testing",
+		Keywords: []string{"test", "example"},
+	}, registry)
+}
+
+var docs = fstest.MapFS{
+	"docs/resource.md": {Data: []byte("# Documented\n\nA resource documented in Markdown.\n")},
+	"docs/size.md":     {Data: []byte("The size, in bytes.\n")},
+}
+
+type DocumentedResource struct {
+	Size int `pulumi:"size"`
+}
+
+func (r *DocumentedResource) Annotate(a Annotator) {
+	a.DescribeFromFile(&r, docs, "docs/resource.md")
+	a.DescribeFromFile(&r.Size, docs, "docs/size.md")
+}
+
+func TestDescribeFromFile(t *testing.T) {
+	t.Parallel()
+
+	spec, err := getResourceSchema[DocumentedResource, DocumentedResource, DocumentedResource](false)
+	require.NoError(t, err.ErrorOrNil())
+	assert.Equal(t, "# Documented\n\nA resource documented in Markdown.", spec.Description)
+	assert.Equal(t, "The size, in bytes.", spec.InputProperties["size"].Description)
+
+	assert.PanicsWithValue(t, "could not read description: open docs/missing.md: file does not exist", func() {
+		r := &DocumentedResource{}
+		a := introspect.NewAnnotator(r)
+		a.DescribeFromFile(&r, docs, "docs/missing.md")
+	})
+}
+
+type defaultEnvArgs struct {
+	Region   *string `pulumi:"region,optional"`
+	Endpoint *string `pulumi:"endpoint,optional"`
+	Retries  *int    `pulumi:"retries,optional"`
+}
+
+func (a *defaultEnvArgs) Annotate(an Annotator) {
+	an.SetDefault(&a.Region, "us-west-2", "AWS_REGION", "AWS_DEFAULT_REGION")
+	an.SetDefault(&a.Endpoint, nil, "ENDPOINT")
+	an.SetDefault(&a.Retries, 3)
+}
+
+func TestDefaultInfo(t *testing.T) {
+	t.Parallel()
+
+	spec, err := getResourceSchema[TestResource, defaultEnvArgs, defaultEnvArgs](false /* isComponent */)
+	require.NoError(t, err.ErrorOrNil())
+
+	region := spec.InputProperties["region"]
+	assert.Equal(t, "us-west-2", region.Default)
+	require.NotNil(t, region.DefaultInfo)
+	assert.Equal(t, []string{"AWS_REGION", "AWS_DEFAULT_REGION"}, region.DefaultInfo.Environment)
+
+	endpoint := spec.InputProperties["endpoint"]
+	assert.Nil(t, endpoint.Default)
+	require.NotNil(t, endpoint.DefaultInfo)
+	assert.Equal(t, []string{"ENDPOINT"}, endpoint.DefaultInfo.Environment)
+
+	retries := spec.InputProperties["retries"]
+	assert.Equal(t, 3, retries.Default)
+	assert.Nil(t, retries.DefaultInfo, "defaultInfo should only be set when env vars are given")
 }
@@ -0,0 +1,120 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+type (
+	Label     struct{}
+	LabelArgs struct {
+		Text  string      `pulumi:"text"`
+		Style *LabelStyle `pulumi:"style,optional"`
+	}
+	LabelStyle struct {
+		Color string `pulumi:"color"`
+	}
+)
+
+func (*Label) Annotate(a infer.Annotator) {
+	a.AddAlias("legacy", "Tag")
+}
+
+func (s *LabelStyle) Annotate(a infer.Annotator) {
+	a.Describe(&s, "How a label is displayed.")
+	a.AddAlias("index", "TagStyle")
+}
+
+func (*Label) Create(ctx context.Context, name string, inputs LabelArgs, preview bool) (string, LabelArgs, error) {
+	return name, inputs, nil
+}
+
+type GetLabel struct{}
+
+func (*GetLabel) Annotate(a infer.Annotator) {
+	a.AddAlias("index", "getTag")
+}
+
+func (*GetLabel) Call(ctx context.Context, args LabelArgs) (LabelArgs, error) {
+	return args, nil
+}
+
+func aliasProvider() integration.Server {
+	opts := providerOpts(nil)
+	opts.Resources = append(opts.Resources, infer.Resource[*Label, LabelArgs, LabelArgs]())
+	opts.Functions = append(opts.Functions, infer.Function[*GetLabel, LabelArgs, LabelArgs]())
+	return integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+}
+
+func TestFunctionAlias(t *testing.T) {
+	t.Parallel()
+
+	server := aliasProvider()
+	spec := schematest.Spec(t, server)
+
+	require.Contains(t, spec.Functions, "test:index:getLabel")
+	require.Contains(t, spec.Functions, "test:index:getTag")
+	assert.Empty(t, spec.Functions["test:index:getLabel"].DeprecationMessage)
+	assert.Equal(t, "getTag has been renamed to getLabel.", spec.Functions["test:index:getTag"].DeprecationMessage)
+	schematest.AssertInputProperty(t, spec, "test:index:getTag", "text", schematest.Required, schematest.TypeString)
+
+	for _, tk := range []string{"test:index:getLabel", "test:index:getTag"} {
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: tokens.Type(tk),
+			Args:  resource.PropertyMap{"text": resource.NewStringProperty("hello")},
+		})
+		require.NoError(t, err, tk)
+		assert.Equal(t, resource.PropertyMap{"text": resource.NewStringProperty("hello")}, resp.Return, tk)
+	}
+}
+
+func TestTypeAlias(t *testing.T) {
+	t.Parallel()
+
+	spec := schematest.Spec(t, aliasProvider())
+
+	require.Contains(t, spec.Types, "test:index:LabelStyle")
+	require.Contains(t, spec.Types, "test:index:TagStyle")
+	assert.Equal(t, "How a label is displayed.", spec.Types["test:index:LabelStyle"].Description)
+	assert.Equal(t, "TagStyle has been renamed to LabelStyle.\n\nHow a label is displayed.",
+		spec.Types["test:index:TagStyle"].Description)
+	schematest.AssertProperty(t, spec, "test:index:TagStyle", "color", schematest.Required, schematest.TypeString)
+	schematest.AssertInputProperty(t, spec, "test:index:Label", "style",
+		schematest.Ref("#/types/test:index:LabelStyle"))
+}
+
+func TestResourceAlias(t *testing.T) {
+	t.Parallel()
+
+	resp, err := aliasProvider().Create(p.CreateRequest{
+		Urn:        resource.NewURN("stack", "proj", "", "test:legacy:Tag", "old"),
+		Properties: resource.PropertyMap{"text": resource.NewStringProperty("hello")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old", resp.ID)
+}
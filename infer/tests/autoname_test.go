@@ -0,0 +1,146 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+func TestAutoname(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+
+	check := func(t *testing.T, req p.CheckRequest) m {
+		req.Urn = urn("Autonamed", "a-long-resource-name")
+		resp, err := provider().Check(req)
+		require.NoError(t, err)
+		require.Empty(t, resp.Failures)
+		return resp.Inputs
+	}
+
+	t.Run("generated", func(t *testing.T) {
+		t.Parallel()
+		name := check(t, p.CheckRequest{})["name"]
+		require.True(t, name.IsString())
+		// The base name is truncated to fit MaxLen.
+		assert.Regexp(t, `^app-a-long[xyz]{6}$`, name.StringValue())
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		t.Parallel()
+		// The same seed always generates the same name.
+		seed := []byte("seed")
+		first := check(t, p.CheckRequest{RandomSeed: seed})["name"]
+		second := check(t, p.CheckRequest{RandomSeed: seed})["name"]
+		assert.Equal(t, first, second)
+		other := check(t, p.CheckRequest{RandomSeed: []byte("other seed")})["name"]
+		assert.NotEqual(t, first, other)
+	})
+
+	t.Run("explicit", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{News: m{"name": s("mine")}})
+		assert.Equal(t, m{"name": s("mine")}, inputs)
+	})
+
+	t.Run("previous", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{Olds: m{"name": s("app-old")}})
+		assert.Equal(t, m{"name": s("app-old")}, inputs)
+	})
+
+	t.Run("proposed", func(t *testing.T) {
+		t.Parallel()
+		autonaming := &p.AutonamingOptions{ProposedName: "from-config"}
+		inputs := check(t, p.CheckRequest{Autonaming: autonaming})
+		assert.Equal(t, m{"name": s("from-config")}, inputs)
+
+		// A proposed name does not rename an existing resource.
+		inputs = check(t, p.CheckRequest{Olds: m{"name": s("app-old")}, Autonaming: autonaming})
+		assert.Equal(t, m{"name": s("app-old")}, inputs)
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{
+			Olds: m{"name": s("app-old")},
+			Autonaming: &p.AutonamingOptions{
+				ProposedName: "from-config",
+				Mode:         p.AutonamingModeEnforce,
+			},
+		})
+		assert.Equal(t, m{"name": s("from-config")}, inputs)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		autonaming := &p.AutonamingOptions{Mode: p.AutonamingModeDisable}
+		resp, err := provider().Check(p.CheckRequest{
+			Urn:        urn("Autonamed", "a-long-resource-name"),
+			Autonaming: autonaming,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []p.CheckFailure{{
+			Property: "name",
+			Reason:   `"name" must be set explicitly because autonaming is disabled for this stack`,
+		}}, resp.Failures)
+
+		inputs := check(t, p.CheckRequest{News: m{"name": s("mine")}, Autonaming: autonaming})
+		assert.Equal(t, m{"name": s("mine")}, inputs)
+	})
+
+	t.Run("capability", func(t *testing.T) {
+		t.Parallel()
+		configure := func(provider p.Provider) bool {
+			s, err := p.RawServer("test", "1.0.0", provider)(nil)
+			require.NoError(t, err)
+			resp, err := s.Configure(context.Background(), &pulumirpc.ConfigureRequest{})
+			require.NoError(t, err)
+			return resp.SupportsAutonamingConfiguration
+		}
+		assert.True(t, configure(infer.Provider(providerOpts(nil))))
+		assert.False(t, configure(infer.Provider(infer.Options{
+			Resources: []infer.InferredResource{infer.Resource[*Echo, EchoInputs, EchoOutputs]()},
+		})))
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				InputProperties map[string]struct {
+					Description string `json:"description"`
+				} `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		assert.Equal(t, "If not set, a name is generated from the resource name.",
+			spec.Resources["test:index:Autonamed"].InputProperties["name"].Description)
+	})
+}
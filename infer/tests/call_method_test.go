@@ -0,0 +1,94 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	GreetArgs struct {
+		Name     string `pulumi:"name"`
+		Greeting string `pulumi:"greeting,optional"`
+	}
+	GreetResult struct {
+		Message string `pulumi:"name"`
+		Caller  string `pulumi:"caller"`
+	}
+)
+
+func (a *GreetArgs) Annotate(an infer.Annotator) {
+	an.SetDefault(&a.Greeting, "Hello")
+}
+
+func greet(ctx context.Context, self resource.ResourceReference, args GreetArgs) (GreetResult, error) {
+	return GreetResult{
+		Message: args.Greeting + ", " + args.Name,
+		Caller:  string(self.URN),
+	}, nil
+}
+
+func TestCallMethod(t *testing.T) {
+	t.Parallel()
+
+	self := urn("Greeter", "g")
+	call := func(args resource.PropertyMap) (p.CallResponse, error) {
+		return infer.CallMethod(context.Background(), p.CallRequest{
+			Tok:  "test:index:Greeter/greet",
+			Args: args,
+		}, greet)
+	}
+
+	t.Run("typed", func(t *testing.T) {
+		t.Parallel()
+		resp, err := call(resource.PropertyMap{
+			"__self__": resource.MakeSecret(resource.NewResourceReferenceProperty(resource.ResourceReference{URN: self})),
+			"name":     resource.MakeSecret(resource.NewStringProperty("Pulumi")),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, resource.PropertyMap{
+			// The result shares the key of the secret argument, so it is secret too.
+			"name":   resource.MakeSecret(resource.NewStringProperty("Hello, Pulumi")),
+			"caller": resource.NewStringProperty(string(self)),
+		}, resp.Return)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		t.Parallel()
+		resp, err := call(resource.PropertyMap{
+			"__self__": resource.NewResourceReferenceProperty(resource.ResourceReference{URN: self}),
+			"name":     resource.NewNumberProperty(42),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "name", resp.Failures[0].Property)
+		assert.Nil(t, resp.Return)
+	})
+
+	t.Run("no self", func(t *testing.T) {
+		t.Parallel()
+		_, err := call(resource.PropertyMap{"name": resource.NewStringProperty("Pulumi")})
+		assert.ErrorContains(t, err, "test:index:Greeter/greet must be called on a resource")
+	})
+}
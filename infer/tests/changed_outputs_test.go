@@ -0,0 +1,74 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestDiffChangedOutputs(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+
+	olds := m{
+		"string": s("foo"), "int": n(1),
+		"name": s("(wired)"), "stringAndInt": s("foo-1"), "stringPlus": s("foo+"),
+	}
+	diff := func(t *testing.T, news m) p.DiffResponse {
+		resp, err := provider().Diff(p.DiffRequest{
+			ID:   "wired-id",
+			Urn:  urn("WiredPlus", "wired"),
+			Olds: olds,
+			News: news,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		resp := diff(t, m{"string": s("foo"), "int": n(1)})
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.ChangedOutputs)
+	})
+
+	t.Run("int", func(t *testing.T) {
+		t.Parallel()
+		resp := diff(t, m{"string": s("foo"), "int": n(2)})
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, []resource.PropertyKey{"stringAndInt"}, resp.ChangedOutputs)
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+		resp := diff(t, m{"string": s("bar"), "int": n(1)})
+		assert.Equal(t, []resource.PropertyKey{"stringAndInt", "stringPlus"}, resp.ChangedOutputs)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		resp := diff(t, m{"string": s("foo"), "int": resource.MakeComputed(s(""))})
+		assert.Equal(t, []resource.PropertyKey{"stringAndInt"}, resp.ChangedOutputs)
+	})
+}
@@ -15,13 +15,18 @@
 package tests
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil/rpcerror"
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
 )
 
 func TestCheckConfig(t *testing.T) {
@@ -78,3 +83,234 @@ func TestCheckConfigCustom(t *testing.T) {
 			pMap{"number": pNumber(42.5)})
 	})
 }
+
+func TestCheckConfigCustomCheckConfig(t *testing.T) {
+	t.Parallel()
+
+	pString := resource.NewStringProperty
+	type pMap = resource.PropertyMap
+
+	check := func(t *testing.T, news pMap) p.CheckResponse {
+		resp, err := providerWithConfig[*ConfigExclusive]().CheckConfig(p.CheckRequest{
+			Urn:  urn("provider", "provider"),
+			News: news,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("token", func(t *testing.T) {
+		t.Parallel()
+		resp := check(t, pMap{"token": pString("abc")})
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{"token": pString("abc")}, resp.Inputs)
+	})
+
+	t.Run("username-password", func(t *testing.T) {
+		t.Parallel()
+		resp := check(t, pMap{"username": pString("u"), "password": pString("p")})
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{
+			"username": pString("u"),
+			"password": resource.MakeSecret(pString("p")),
+		}, resp.Inputs)
+	})
+
+	t.Run("both", func(t *testing.T) {
+		t.Parallel()
+		resp := check(t, pMap{"token": pString("abc"), "username": pString("u")})
+		assert.Equal(t, []p.CheckFailure{{
+			Property: "token",
+			Reason:   "token cannot be used with username or password",
+		}}, resp.Failures)
+	})
+
+	t.Run("neither", func(t *testing.T) {
+		t.Parallel()
+		resp := check(t, pMap{"username": pString("u")})
+		assert.Equal(t, []p.CheckFailure{{
+			Property: "username",
+			Reason:   "either token or both username and password must be set",
+		}}, resp.Failures)
+	})
+}
+
+type ConfigSecretNested struct {
+	Key string `pulumi:"key" provider:"secret"`
+}
+
+type ConfigSecret struct {
+	Token  *string             `pulumi:"token,optional" provider:"secret"`
+	Port   *int                `pulumi:"port,optional" provider:"secret"`
+	Nested *ConfigSecretNested `pulumi:"nested,optional"`
+	Plain  *string             `pulumi:"plain,optional"`
+}
+
+// TestConfigSecrets checks that `provider:"secret"` on config fields is reflected in the
+// config schema, so the CLI can warn on `pulumi config set` without `--secret`, and in the
+// checked config.
+func TestConfigSecrets(t *testing.T) {
+	t.Parallel()
+
+	pString := resource.NewStringProperty
+	pNumber := resource.NewNumberProperty
+	secret := resource.MakeSecret
+	type pMap = resource.PropertyMap
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := providerWithConfig[ConfigSecret]().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Config struct {
+				Variables map[string]struct {
+					Secret bool `json:"secret"`
+				} `json:"variables"`
+			} `json:"config"`
+			Types map[string]struct {
+				Properties map[string]struct {
+					Secret bool `json:"secret"`
+				} `json:"properties"`
+			} `json:"types"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		assert.True(t, spec.Config.Variables["token"].Secret)
+		assert.True(t, spec.Config.Variables["port"].Secret)
+		assert.False(t, spec.Config.Variables["plain"].Secret)
+		assert.True(t, spec.Types["test:index:ConfigSecretNested"].Properties["key"].Secret)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		// Config values arrive from the engine as strings, with non-string values JSON
+		// encoded. Values set with `--secret` are additionally wrapped as secrets.
+		resp, err := providerWithConfig[ConfigSecret]().CheckConfig(p.CheckRequest{
+			Urn: urn("provider", "provider"),
+			News: pMap{
+				"token":  pString("abc"),
+				"port":   secret(pString("443")),
+				"nested": pString(`{"key":"k"}`),
+				"plain":  pString("visible"),
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{
+			"token":  secret(pString("abc")),
+			"port":   secret(pNumber(443)),
+			"nested": resource.NewObjectProperty(pMap{"key": secret(pString("k"))}),
+			"plain":  pString("visible"),
+		}, resp.Inputs)
+	})
+}
+
+type ConfigRegion string
+
+func (ConfigRegion) Values() []infer.EnumValue[ConfigRegion] {
+	return []infer.EnumValue[ConfigRegion]{
+		{Name: "East", Value: "east"},
+		{Name: "West", Value: "west"},
+	}
+}
+
+type ConfigEndpoint struct {
+	URL     string         `pulumi:"url"`
+	Regions []ConfigRegion `pulumi:"regions,optional"`
+}
+
+func (e *ConfigEndpoint) Annotate(a infer.Annotator) {
+	a.Describe(&e.URL, "The URL of the endpoint.")
+}
+
+type ConfigNested struct {
+	Endpoint  *ConfigEndpoint           `pulumi:"endpoint,optional"`
+	Fallbacks []ConfigEndpoint          `pulumi:"fallbacks,optional"`
+	Named     map[string]ConfigEndpoint `pulumi:"named,optional"`
+	Region    *ConfigRegion             `pulumi:"region,optional"`
+}
+
+// TestConfigNested checks that nested objects, arrays, maps and enums in config are
+// typed in the schema, and that problems with them are reported by the path they would
+// be set with `pulumi config set --path`.
+func TestConfigNested(t *testing.T) {
+	t.Parallel()
+
+	pString := resource.NewStringProperty
+	type pMap = resource.PropertyMap
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		spec := schematest.Spec(t, providerWithConfig[ConfigNested]())
+		assert.Equal(t, "#/types/test:index:ConfigEndpoint", spec.Config.Variables["endpoint"].Ref)
+		assert.Equal(t, "#/types/test:index:ConfigEndpoint", spec.Config.Variables["fallbacks"].Items.Ref)
+		assert.Equal(t, "#/types/test:index:ConfigEndpoint",
+			spec.Config.Variables["named"].AdditionalProperties.Ref)
+		assert.Equal(t, "#/types/test:index:ConfigRegion", spec.Config.Variables["region"].Ref)
+		assert.Len(t, spec.Types["test:index:ConfigRegion"].Enum, 2)
+		schematest.AssertProperty(t, spec, "test:index:ConfigEndpoint", "url",
+			schematest.Required, schematest.TypeString)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		resp, err := providerWithConfig[ConfigNested]().CheckConfig(p.CheckRequest{
+			Urn: urn("provider", "provider"),
+			News: pMap{
+				"endpoint":  pString(`{"url":"https://example.com","regions":["east"]}`),
+				"fallbacks": pString(`[{"url":"https://a.example.com"}]`),
+				"region":    pString("west"),
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{
+			"endpoint": resource.NewObjectProperty(pMap{
+				"url":     pString("https://example.com"),
+				"regions": resource.NewArrayProperty([]resource.PropertyValue{pString("east")}),
+			}),
+			"fallbacks": resource.NewArrayProperty([]resource.PropertyValue{
+				resource.NewObjectProperty(pMap{"url": pString("https://a.example.com")}),
+			}),
+			"region": pString("west"),
+		}, resp.Inputs)
+	})
+
+	t.Run("check failures", func(t *testing.T) {
+		t.Parallel()
+		resp, err := providerWithConfig[ConfigNested]().CheckConfig(p.CheckRequest{
+			Urn: urn("provider", "provider"),
+			News: pMap{
+				"endpoint":  pString(`{"regions":["east","north"]}`),
+				"fallbacks": pString(`[{"url":"https://a.example.com"},{}]`),
+				"named":     pString(`{"main":{"url":"https://b.example.com","regions":["south"]}}`),
+				"region":    pString("up"),
+			},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []p.CheckFailure{
+			{Property: "endpoint.url", Reason: "missing required field"},
+			{Property: "endpoint.regions[1]", Reason: "expected one of east, west"},
+			{Property: "fallbacks[1].url", Reason: "missing required field"},
+			{Property: "named.main.regions[0]", Reason: "expected one of east, west"},
+			{Property: "region", Reason: "expected one of east, west"},
+		}, resp.Failures)
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		t.Parallel()
+		err := providerWithConfig[ConfigNested]().Configure(p.ConfigureRequest{
+			Args: pMap{
+				"endpoint": resource.NewObjectProperty(pMap{}),
+			},
+		})
+		require.Error(t, err)
+		rpcErr, ok := rpcerror.FromError(err)
+		require.True(t, ok)
+		require.Len(t, rpcErr.Details(), 1)
+		missing, ok := rpcErr.Details()[0].(*rpc.ConfigureErrorMissingKeys)
+		require.True(t, ok)
+		require.Len(t, missing.MissingKeys, 1)
+		assert.Equal(t, "test:endpoint.url", missing.MissingKeys[0].Name)
+		assert.Equal(t, "The URL of the endpoint.", missing.MissingKeys[0].Description)
+	})
+}
@@ -278,3 +278,21 @@ func TestCheckAlwaysAppliesSecrets(t *testing.T) {
 		"input": resource.MakeSecret(resource.NewProperty("value")),
 	}, resp.Inputs)
 }
+
+func TestCheckRandomSeed(t *testing.T) {
+	t.Parallel()
+
+	salt := func(seed []byte) resource.PropertyValue {
+		resp, err := provider().Check(p.CheckRequest{
+			Urn:        urn("Salted", "salted"),
+			News:       resource.PropertyMap{},
+			RandomSeed: seed,
+		})
+		require.NoError(t, err)
+		require.Empty(t, resp.Failures)
+		return resp.Inputs["salt"]
+	}
+
+	assert.Equal(t, salt([]byte("seed")), salt([]byte("seed")))
+	assert.NotEqual(t, salt([]byte("seed")), salt([]byte("other seed")))
+}
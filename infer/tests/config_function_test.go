@@ -0,0 +1,88 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type ExposedConfig struct {
+	Region   string `pulumi:"region"`
+	Endpoint string `pulumi:"endpoint,optional"`
+	Token    string `pulumi:"token,optional" provider:"secret"`
+}
+
+func TestExposeConfig(t *testing.T) {
+	t.Parallel()
+
+	opts := providerOpts(infer.Config[ExposedConfig]())
+	opts.ExposeConfig = true
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec struct {
+		Functions map[string]struct {
+			Outputs struct {
+				Properties map[string]any `json:"properties"`
+				Required   []string       `json:"required"`
+			} `json:"outputs"`
+		} `json:"functions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+	fn, ok := spec.Functions["test:index:getProviderConfig"]
+	require.True(t, ok, "missing getProviderConfig")
+	assert.Len(t, fn.Outputs.Properties, 2)
+	assert.Contains(t, fn.Outputs.Properties, "region")
+	assert.Contains(t, fn.Outputs.Properties, "endpoint")
+	assert.Empty(t, fn.Outputs.Required)
+
+	// Before the provider is configured, there is nothing to return.
+	invoked, err := server.Invoke(p.InvokeRequest{Token: "test:index:getProviderConfig"})
+	require.NoError(t, err)
+	assert.Empty(t, invoked.Return)
+
+	err = server.Configure(p.ConfigureRequest{Args: resource.PropertyMap{
+		"region":   resource.NewStringProperty("us-west-2"),
+		"endpoint": resource.MakeSecret(resource.NewStringProperty("https://internal")),
+		"token":    resource.NewStringProperty("hunter2"),
+	}})
+	require.NoError(t, err)
+
+	invoked, err = server.Invoke(p.InvokeRequest{Token: "test:index:getProviderConfig"})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"region": resource.NewStringProperty("us-west-2"),
+	}, invoked.Return)
+}
+
+func TestExposeConfigDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := providerWithConfig[ExposedConfig]()
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, resp.Schema, "getProviderConfig")
+}
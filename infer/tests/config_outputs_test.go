@@ -0,0 +1,82 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+type ConfigWithOutputs struct {
+	Token     string  `pulumi:"token" provider:"secret"`
+	AccountID *string `pulumi:"accountId,optional" provider:"output"`
+}
+
+func (c *ConfigWithOutputs) ConfigOutputs(ctx context.Context) error {
+	account := "account-for-" + c.Token
+	c.AccountID = &account
+	return nil
+}
+
+func TestConfigOutputs(t *testing.T) {
+	t.Parallel()
+
+	type pMap = resource.PropertyMap
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		spec := schematest.Spec(t, providerWithConfig[*ConfigWithOutputs]())
+		assert.Contains(t, spec.Config.Variables, "token")
+		assert.NotContains(t, spec.Config.Variables, "accountId")
+		assert.NotContains(t, spec.Provider.InputProperties, "accountId")
+		assert.Contains(t, spec.Provider.Properties, "accountId")
+	})
+
+	t.Run("check", func(t *testing.T) {
+		t.Parallel()
+		resp, err := providerWithConfig[*ConfigWithOutputs]().CheckConfig(p.CheckRequest{
+			Urn: urn("provider", "provider"),
+			News: pMap{
+				"token": resource.NewStringProperty("abc"),
+				// Outputs can't be set by the user.
+				"accountId": resource.NewStringProperty("spoofed"),
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{
+			"token":     resource.MakeSecret(resource.NewStringProperty("abc")),
+			"accountId": resource.NewStringProperty("account-for-abc"),
+		}, resp.Inputs)
+	})
+
+	t.Run("check failures", func(t *testing.T) {
+		t.Parallel()
+		resp, err := providerWithConfig[*ConfigWithOutputs]().CheckConfig(p.CheckRequest{
+			Urn:  urn("provider", "provider"),
+			News: pMap{},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "token", resp.Failures[0].Property)
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestDataResource(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Lookup", "create"),
+			Properties: m{"key": s("foo")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "id-foo", resp.ID)
+		assert.Equal(t, m{"key": s("foo"), "value": s("FOO")}, resp.Properties)
+	})
+
+	t.Run("create-fails", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Lookup", "missing"),
+			Properties: m{"key": s("missing")},
+		})
+		assert.ErrorContains(t, err, `no value for "missing"`)
+	})
+
+	t.Run("preview-unknown", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Lookup", "preview"),
+			Properties: m{"key": resource.MakeComputed(s(""))},
+			Preview:    true,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.Properties["value"].IsComputed())
+	})
+
+	t.Run("diff-replaces", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Diff(p.DiffRequest{
+			ID:   "id-foo",
+			Urn:  urn("Lookup", "diff"),
+			Olds: m{"key": s("foo"), "value": s("FOO")},
+			News: m{"key": s("bar")},
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, p.UpdateReplace, resp.DetailedDiff["key"].Kind)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Read(p.ReadRequest{
+			ID:         "id-foo",
+			Urn:        urn("Lookup", "refresh"),
+			Properties: m{"key": s("foo"), "value": s("stale")},
+			Inputs:     m{"key": s("foo")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, p.ReadResponse{
+			ID:         "id-foo",
+			Properties: m{"key": s("foo"), "value": s("FOO")},
+			Inputs:     m{"key": s("foo")},
+		}, resp)
+	})
+
+	t.Run("refresh-invalid-state", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Read(p.ReadRequest{
+			ID:         "id-foo",
+			Urn:        urn("Lookup", "refresh"),
+			Properties: m{"key": s("foo"), "value": resource.NewNumberProperty(1)},
+			Inputs:     m{"key": s("foo")},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Update(p.UpdateRequest{
+			ID:   "id-foo",
+			Urn:  urn("Lookup", "update"),
+			Olds: m{"key": s("foo"), "value": s("FOO")},
+			News: m{"key": s("bar")},
+		})
+		assert.ErrorContains(t, err, "data resource "+string(urn("Lookup", "update"))+" cannot be updated")
+	})
+
+	t.Run("import-without-inputs", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Read(p.ReadRequest{
+			ID:  "id-foo",
+			Urn: urn("Lookup", "import"),
+		})
+		assert.ErrorContains(t, err, "requires its inputs")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Delete(p.DeleteRequest{
+			ID:         "id-foo",
+			Urn:        urn("Lookup", "delete"),
+			Properties: m{"key": s("foo"), "value": s("FOO")},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				InputProperties map[string]struct {
+					ReplaceOnChanges bool `json:"replaceOnChanges"`
+				} `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		assert.True(t, spec.Resources["test:index:Lookup"].InputProperties["key"].ReplaceOnChanges)
+	})
+}
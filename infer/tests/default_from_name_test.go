@@ -0,0 +1,110 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Document     struct{}
+	DocumentArgs struct {
+		Path    string `pulumi:"path,optional"`
+		Content string `pulumi:"content"`
+	}
+)
+
+func (a *DocumentArgs) Annotate(an infer.Annotator) {
+	an.Describe(&a.Path, "The path of the document.")
+	an.DefaultFromName(&a.Path)
+}
+
+func (*Document) Create(
+	ctx context.Context, name string, inputs DocumentArgs, preview bool,
+) (string, DocumentArgs, error) {
+	return inputs.Path, inputs, nil
+}
+
+func TestDefaultFromName(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	server := getterProvider(infer.Resource[*Document, DocumentArgs, DocumentArgs]())
+
+	check := func(t *testing.T, req p.CheckRequest) m {
+		req.Urn = urn("Document", "readme")
+		resp, err := server.Check(req)
+		require.NoError(t, err)
+		require.Empty(t, resp.Failures)
+		return resp.Inputs
+	}
+
+	t.Run("name", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{News: m{"content": s("hello")}})
+		assert.Equal(t, m{"path": s("readme"), "content": s("hello")}, inputs)
+	})
+
+	t.Run("explicit", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{News: m{"path": s("README.md"), "content": s("hello")}})
+		assert.Equal(t, m{"path": s("README.md"), "content": s("hello")}, inputs)
+	})
+
+	t.Run("previous", func(t *testing.T) {
+		t.Parallel()
+		// The resource was renamed, but keeps the path it was created with.
+		inputs := check(t, p.CheckRequest{
+			Olds: m{"path": s("old-name"), "content": s("hello")},
+			News: m{"content": s("world")},
+		})
+		assert.Equal(t, m{"path": s("old-name"), "content": s("world")}, inputs)
+	})
+
+	t.Run("autonaming disabled", func(t *testing.T) {
+		t.Parallel()
+		inputs := check(t, p.CheckRequest{
+			News:       m{"content": s("hello")},
+			Autonaming: &p.AutonamingOptions{Mode: p.AutonamingModeDisable},
+		})
+		assert.Equal(t, m{"path": s("readme"), "content": s("hello")}, inputs)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				InputProperties map[string]struct {
+					Description string `json:"description"`
+				} `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		assert.Equal(t, "The path of the document.\n\nIf not set, the resource name is used.",
+			spec.Resources["test:index:Document"].InputProperties["path"].Description)
+	})
+}
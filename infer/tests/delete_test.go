@@ -0,0 +1,77 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+func TestDeleteTimeout(t *testing.T) {
+	t.Parallel()
+	t.Cleanup(func() { close(releaseHangingDelete) })
+
+	_, err := provider().Delete(p.DeleteRequest{
+		ID:      "hanging",
+		Urn:     urn("HangingDelete", "hanging"),
+		Timeout: 0.1,
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, `timed out deleting "hanging" after 100ms`)
+}
+
+// Archived is a resource the provider keeps when it is deleted, as for APIs that can't
+// delete what they create.
+type (
+	Archived      struct{}
+	ArchivedArgs  struct{}
+	ArchivedState struct{}
+)
+
+func (Archived) Create(
+	ctx context.Context, name string, inputs ArchivedArgs, preview bool,
+) (string, ArchivedState, error) {
+	return name, ArchivedState{}, nil
+}
+
+func (Archived) Delete(ctx context.Context, id string, props ArchivedState) (p.DeleteResponse, error) {
+	return p.DeleteResponse{
+		Retained:    true,
+		Diagnostics: []string{"archived " + id + " instead"},
+	}, nil
+}
+
+func TestDeleteWithResponse(t *testing.T) {
+	t.Parallel()
+
+	prov := getterProvider(infer.Resource[Archived, ArchivedArgs, ArchivedState]())
+	resp, err := prov.Delete(p.DeleteRequest{
+		ID:         "a",
+		Urn:        urn("Archived", "a"),
+		Properties: resource.PropertyMap{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, p.DeleteResponse{
+		Retained:    true,
+		Diagnostics: []string{"archived a instead"},
+	}, resp)
+}
@@ -0,0 +1,141 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type (
+	Ordered     struct{}
+	OrderedArgs struct {
+		Zone  string   `pulumi:"zone"`
+		Hosts []string `pulumi:"hosts"`
+	}
+	OrderedState struct {
+		OrderedArgs
+		After []string `pulumi:"after"`
+	}
+)
+
+func (*Ordered) Create(
+	ctx context.Context, name string, input OrderedArgs, preview bool,
+) (string, OrderedState, error) {
+	return name, OrderedState{input, orderedAfter(ctx)}, nil
+}
+
+func (*Ordered) Update(
+	ctx context.Context, id string, olds OrderedState, news OrderedArgs, preview bool,
+) (OrderedState, error) {
+	return OrderedState{news, orderedAfter(ctx)}, nil
+}
+
+func orderedAfter(ctx context.Context) []string {
+	var after []string
+	for _, k := range []string{"zone", "hosts"} {
+		for _, urn := range infer.Dependencies(ctx)[k] {
+			after = append(after, k+"="+urn.Name())
+		}
+	}
+	return after
+}
+
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+
+	prov := getterProvider(infer.Resource[*Ordered, OrderedArgs, OrderedState]())
+	zone := urn("Zone", "zone")
+	host := urn("Host", "host")
+	props := resource.PropertyMap{
+		"zone": resource.NewOutputProperty(resource.Output{
+			Element:      resource.NewStringProperty("z"),
+			Known:        true,
+			Dependencies: []resource.URN{zone},
+		}),
+		"hosts": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewOutputProperty(resource.Output{
+				Element:      resource.NewStringProperty("a"),
+				Known:        true,
+				Dependencies: []resource.URN{host, zone},
+			}),
+			resource.NewOutputProperty(resource.Output{
+				Element:      resource.NewStringProperty("b"),
+				Known:        true,
+				Dependencies: []resource.URN{host},
+			}),
+		}),
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		resp, err := prov.Create(p.CreateRequest{Urn: urn("Ordered", "o"), Properties: props})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"zone=zone", "hosts=host", "hosts=zone"},
+			afterNames(resp.Properties))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		resp, err := prov.Update(p.UpdateRequest{
+			ID:  "o",
+			Urn: urn("Ordered", "o"),
+			Olds: resource.PropertyMap{
+				"zone":  resource.NewStringProperty("z"),
+				"hosts": resource.NewArrayProperty(nil),
+				"after": resource.NewArrayProperty(nil),
+			},
+			News: resource.PropertyMap{
+				"zone":  resource.NewStringProperty("z"),
+				"hosts": props["hosts"],
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"hosts=host", "hosts=zone"}, afterNames(resp.Properties))
+	})
+
+	// The provider server flattens output values before they reach the resource, so
+	// their dependencies are sent alongside.
+	t.Run("over gRPC", func(t *testing.T) {
+		t.Parallel()
+		opts := providerOpts(nil)
+		opts.Resources = append(opts.Resources, infer.Resource[*Ordered, OrderedArgs, OrderedState]())
+		prov := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts),
+			integration.WithConfigureResponse(p.DefaultCapabilities()))
+		require.NoError(t, prov.Configure(p.ConfigureRequest{}))
+
+		resp, err := prov.Create(p.CreateRequest{Urn: urn("Ordered", "o"), Properties: props})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"zone=zone", "hosts=host", "hosts=zone"},
+			afterNames(resp.Properties))
+	})
+}
+
+func afterNames(m resource.PropertyMap) []string {
+	var names []string
+	for _, v := range m["after"].ArrayValue() {
+		names = append(names, v.StringValue())
+	}
+	return names
+}
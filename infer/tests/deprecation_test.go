@@ -0,0 +1,156 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+// TestDeprecationNotice replaces the default slog logger, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestDeprecationNotice(t *testing.T) {
+	var out bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	opts := providerOpts(infer.Config[Config]())
+	opts.Deprecation = infer.WithDeprecationNotice(
+		"test has been renamed to test2.", "https://example.com/migrate")
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+
+	const notice = "test has been renamed to test2. See https://example.com/migrate for migration guidance."
+	spec := schematest.Spec(t, server)
+	assert.Equal(t, notice, spec.Provider.DeprecationMessage)
+	assert.Empty(t, spec.Attribution)
+
+	// The notice is logged once and does not interfere with configuring the provider,
+	// however often Configure is called.
+	for i := 0; i < 2; i++ {
+		err := server.Configure(p.ConfigureRequest{
+			Args: resource.PropertyMap{"value": resource.NewStringProperty("foo")},
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, strings.Count(out.String(), "level=WARN"))
+	assert.Contains(t, out.String(), "This provider is deprecated: "+notice)
+
+	created, err := server.Create(p.CreateRequest{Urn: urn("ReadConfig", "config")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"Value":"foo"}`, created.Properties["config"].StringValue())
+}
+
+// TestDeprecatedInput replaces the default slog logger, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestDeprecatedInput(t *testing.T) {
+	var out bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	server := provider()
+
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec struct {
+		Resources map[string]struct {
+			InputProperties map[string]struct {
+				DeprecationMessage string `json:"deprecationMessage"`
+			} `json:"inputProperties"`
+		} `json:"resources"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+	inputs := spec.Resources["test:index:Renamed"].InputProperties
+	assert.Equal(t, "Use name instead.", inputs["oldName"].DeprecationMessage)
+	assert.Empty(t, inputs["name"].DeprecationMessage)
+
+	_, err = server.Check(p.CheckRequest{
+		Urn:  urn("Renamed", "current"),
+		News: resource.PropertyMap{"name": resource.NewStringProperty("n")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+
+	_, err = server.Check(p.CheckRequest{
+		Urn:  urn("Renamed", "legacy"),
+		News: resource.PropertyMap{"oldName": resource.NewStringProperty("n")},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "level=WARN")
+	assert.Contains(t, out.String(), `Property \"oldName\" is deprecated: Use name instead.`)
+	assert.Contains(t, out.String(), "urn="+string(urn("Renamed", "legacy")))
+}
+
+type GetLabelV1 struct{}
+
+func (f *GetLabelV1) Annotate(a infer.Annotator) {
+	a.DeprecateWithReplacement(&f, "test:index:getLabel")
+}
+
+func (*GetLabelV1) Call(ctx context.Context, args LabelArgs) (LabelArgs, error) {
+	return args, nil
+}
+
+// TestDeprecatedFunction replaces the default slog logger, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestDeprecatedFunction(t *testing.T) {
+	var out bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	opts := providerOpts(nil)
+	opts.Functions = append(opts.Functions,
+		infer.Function[*GetLabel, LabelArgs, LabelArgs](),
+		infer.Function[*GetLabelV1, LabelArgs, LabelArgs](),
+	)
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+
+	spec := schematest.Spec(t, server)
+	assert.Equal(t, "Use test:index:getLabel instead.", spec.Functions["test:index:getLabelV1"].DeprecationMessage)
+	assert.Empty(t, spec.Functions["test:index:getLabel"].DeprecationMessage)
+
+	args := resource.PropertyMap{"text": resource.NewStringProperty("hello")}
+	_, err := server.Invoke(p.InvokeRequest{Token: "test:index:getLabel", Args: args})
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+
+	for i := 0; i < 2; i++ {
+		resp, err := server.Invoke(p.InvokeRequest{Token: "test:index:getLabelV1", Args: args})
+		require.NoError(t, err)
+		assert.Equal(t, args, resp.Return)
+	}
+	assert.Equal(t, 1, strings.Count(out.String(), "level=WARN"))
+	assert.Contains(t, out.String(),
+		`Function \"test:index:getLabelV1\" is deprecated: Use test:index:getLabel instead.`)
+}
@@ -0,0 +1,141 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+type ConfigForceNew struct {
+	Token  *string `pulumi:"token,optional" provider:"secret"`
+	Region *string `pulumi:"region,optional" provider:"forceNew"`
+}
+
+func TestDiffConfig(t *testing.T) {
+	t.Parallel()
+
+	pString := resource.NewStringProperty
+	type pMap = resource.PropertyMap
+
+	test := func(t *testing.T, olds, news pMap, expected map[string]p.PropertyDiff) {
+		prov := providerWithConfig[ConfigForceNew]()
+		resp, err := prov.DiffConfig(p.DiffRequest{
+			Urn:  urn("provider", "provider"),
+			Olds: olds,
+			News: news,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, len(expected) > 0, resp.HasChanges)
+		assert.Equal(t, expected, resp.DetailedDiff)
+	}
+
+	t.Run("no-change", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"region": pString("us-west-2")},
+			pMap{"region": pString("us-west-2")},
+			map[string]p.PropertyDiff{})
+	})
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"token": pString("old"), "region": pString("us-west-2")},
+			pMap{"token": pString("new"), "region": pString("us-west-2")},
+			map[string]p.PropertyDiff{"token": {Kind: p.Update}})
+	})
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"token": pString("old"), "region": pString("us-west-2")},
+			pMap{"token": pString("old"), "region": pString("us-east-1")},
+			map[string]p.PropertyDiff{"region": {Kind: p.UpdateReplace}})
+	})
+}
+
+type ConfigCustomDiff struct {
+	Endpoint *string `pulumi:"endpoint,optional"`
+}
+
+func (*ConfigCustomDiff) DiffConfig(
+	ctx context.Context, olds, news *ConfigCustomDiff,
+) (p.DiffResponse, error) {
+	value := func(c *ConfigCustomDiff) string {
+		if c.Endpoint == nil {
+			return ""
+		}
+		return *c.Endpoint
+	}
+	if value(olds) == value(news) {
+		return p.DiffResponse{}, nil
+	}
+	// Moving between hosts replaces the provider, but changing the path does not.
+	kind := p.Update
+	if strings.Split(value(olds), "/")[0] != strings.Split(value(news), "/")[0] {
+		kind = p.UpdateReplace
+	}
+	return p.DiffResponse{
+		HasChanges:   true,
+		DetailedDiff: map[string]p.PropertyDiff{"endpoint": {Kind: kind}},
+	}, nil
+}
+
+func TestDiffConfigCustom(t *testing.T) {
+	t.Parallel()
+
+	pString := resource.NewStringProperty
+	type pMap = resource.PropertyMap
+
+	test := func(t *testing.T, olds, news pMap, expected map[string]p.PropertyDiff) {
+		prov := providerWithConfig[*ConfigCustomDiff]()
+		resp, err := prov.DiffConfig(p.DiffRequest{
+			Urn:  urn("provider", "provider"),
+			Olds: olds,
+			News: news,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, len(expected) > 0, resp.HasChanges)
+		assert.Equal(t, expected, resp.DetailedDiff)
+	}
+
+	t.Run("no-change", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"endpoint": pString("example.com/v1")},
+			pMap{"endpoint": pString("example.com/v1")},
+			nil)
+	})
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"endpoint": pString("example.com/v1")},
+			pMap{"endpoint": pString("example.com/v2")},
+			map[string]p.PropertyDiff{"endpoint": {Kind: p.Update}})
+	})
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		test(t,
+			pMap{"endpoint": pString("example.com/v1")},
+			pMap{"endpoint": pString("example.org/v1")},
+			map[string]p.PropertyDiff{"endpoint": {Kind: p.UpdateReplace}})
+	})
+}
@@ -0,0 +1,99 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestDriftDetect(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+
+	olds := m{"region": s("us-east-1"), "size": n(1), "etag": s("1")}
+	inputs := m{"region": s("us-east-1"), "size": n(1)}
+	diff := func(id string, news m) (p.DiffResponse, error) {
+		return provider().Diff(p.DiffRequest{
+			ID:   id,
+			Urn:  urn("Drifted", "drift"),
+			Olds: olds,
+			News: news,
+		})
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		t.Parallel()
+		resp, err := diff("clean", inputs)
+		require.NoError(t, err)
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.DetailedDiff)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		t.Parallel()
+		resp, err := diff("resized", inputs)
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"size": {Kind: p.Update, InputDiff: false},
+			"etag": {Kind: p.Update, InputDiff: false},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("drift on replaceOnChanges property", func(t *testing.T) {
+		t.Parallel()
+		resp, err := diff("moved", inputs)
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"region": {Kind: p.UpdateReplace, InputDiff: false},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("explicit kind", func(t *testing.T) {
+		t.Parallel()
+		resp, err := diff("renamed", inputs)
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"region": {Kind: p.Update, InputDiff: false},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("input diff takes precedence", func(t *testing.T) {
+		t.Parallel()
+		resp, err := diff("renamed", m{"region": s("us-west-2"), "size": n(1)})
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"region": {Kind: p.UpdateReplace},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		_, err := diff("unreachable", inputs)
+		assert.ErrorContains(t, err, "cannot reach unreachable")
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Sprocket     struct{}
+	SprocketArgs struct {
+		Name string `pulumi:"name"`
+		Size int    `pulumi:"size"`
+	}
+	SprocketState struct {
+		Name   string `pulumi:"name"`
+		Size   int    `pulumi:"size"`
+		Serial string `pulumi:"serial"`
+	}
+)
+
+func (*Sprocket) Create(
+	ctx context.Context, name string, inputs SprocketArgs, preview bool,
+) (string, SprocketState, error) {
+	return name, SprocketState{Serial: "s-1"}, nil
+}
+
+func (*Sprocket) Update(
+	ctx context.Context, id string, olds SprocketState, news SprocketArgs, preview bool,
+) (SprocketState, error) {
+	// The size is rounded up, so the output differs from the input.
+	return SprocketState{Size: 10, Serial: olds.Serial}, nil
+}
+
+func TestEmbedInputs(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Sprocket, SprocketArgs, SprocketState](infer.EmbedInputs()))
+
+	create, err := server.Create(p.CreateRequest{
+		Urn: urn("Sprocket", "s"),
+		Properties: resource.PropertyMap{
+			"name": resource.NewStringProperty("cog"),
+			"size": resource.NewNumberProperty(3),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"name":   resource.NewStringProperty("cog"),
+		"size":   resource.NewNumberProperty(3),
+		"serial": resource.NewStringProperty("s-1"),
+	}, create.Properties)
+
+	update, err := server.Update(p.UpdateRequest{
+		ID:   "s",
+		Urn:  urn("Sprocket", "s"),
+		Olds: create.Properties,
+		News: resource.PropertyMap{
+			"name": resource.NewStringProperty("gear"),
+			"size": resource.NewNumberProperty(4),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"name":   resource.NewStringProperty("gear"),
+		"size":   resource.NewNumberProperty(10),
+		"serial": resource.NewStringProperty("s-1"),
+	}, update.Properties)
+}
+
+func TestEmbedInputsPreview(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Sprocket, SprocketArgs, SprocketState](infer.EmbedInputs()))
+
+	create, err := server.Create(p.CreateRequest{
+		Urn: urn("Sprocket", "s"),
+		Properties: resource.PropertyMap{
+			"name": resource.MakeComputed(resource.NewStringProperty("")),
+			"size": resource.NewNumberProperty(3),
+		},
+		Preview: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, create.Properties[resource.PropertyKey("name")].IsComputed())
+	assert.Equal(t, resource.NewNumberProperty(3), create.Properties[resource.PropertyKey("size")])
+}
+
+func TestWithoutEmbedInputs(t *testing.T) {
+	t.Parallel()
+
+	create, err := getterProvider(infer.Resource[*Sprocket, SprocketArgs, SprocketState]()).Create(p.CreateRequest{
+		Urn: urn("Sprocket", "s"),
+		Properties: resource.PropertyMap{
+			"name": resource.NewStringProperty("cog"),
+			"size": resource.NewNumberProperty(3),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty(""), create.Properties[resource.PropertyKey("name")])
+}
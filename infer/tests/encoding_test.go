@@ -0,0 +1,91 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/infer/encoding"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+func init() {
+	encoding.Register(encoding.Text[time.Time]())
+	encoding.Register(encoding.Text[net.IP]())
+}
+
+type (
+	Lease     struct{}
+	LeaseArgs struct {
+		Host  net.IP        `pulumi:"host"`
+		Start time.Time     `pulumi:"start"`
+		For   time.Duration `pulumi:"for"`
+	}
+	LeaseState struct {
+		LeaseArgs
+		Expires *time.Time `pulumi:"expires,optional"`
+	}
+)
+
+func (*Lease) Create(
+	ctx context.Context, name string, inputs LeaseArgs, preview bool,
+) (string, LeaseState, error) {
+	expires := inputs.Start.Add(inputs.For)
+	return name, LeaseState{LeaseArgs: inputs, Expires: &expires}, nil
+}
+
+func TestEncodingCodecs(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Lease, LeaseArgs, LeaseState]())
+
+	spec := schematest.Spec(t, server)
+	schematest.AssertInputProperty(t, spec, "test:index:Lease", "start", schematest.Required, schematest.TypeString)
+	schematest.AssertInputProperty(t, spec, "test:index:Lease", "host", schematest.Required, schematest.TypeString)
+	schematest.AssertProperty(t, spec, "test:index:Lease", "expires", schematest.TypeString)
+	for tk := range spec.Types {
+		assert.NotContains(t, tk, "Time", "time.Time should not be registered as a type")
+	}
+
+	inputs := resource.PropertyMap{
+		"host":  resource.NewStringProperty("192.168.1.10"),
+		"start": resource.NewStringProperty("2024-03-01T12:00:00Z"),
+		"for":   resource.NewNumberProperty(float64(time.Hour)),
+	}
+	check, err := server.Check(p.CheckRequest{Urn: urn("Lease", "l"), News: inputs})
+	require.NoError(t, err)
+	assert.Empty(t, check.Failures)
+
+	created, err := server.Create(p.CreateRequest{Urn: urn("Lease", "l"), Properties: check.Inputs})
+	require.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty("2024-03-01T13:00:00Z"), created.Properties["expires"])
+	assert.Equal(t, inputs["host"], created.Properties["host"])
+
+	bad := inputs.Copy()
+	bad["start"] = resource.NewStringProperty("tomorrow")
+	check, err = server.Check(p.CheckRequest{Urn: urn("Lease", "l"), News: bad})
+	require.NoError(t, err)
+	require.Len(t, check.Failures, 1)
+	assert.Equal(t, "start", check.Failures[0].Property)
+}
@@ -0,0 +1,96 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+// ForeignBucket stands in for a resource from another provider's Go SDK.
+type ForeignBucket struct {
+	pulumi.CustomResourceState
+}
+
+// Website is a component that refers to resources and types from another package.
+type (
+	Website     struct{}
+	WebsiteArgs struct {
+		Bucket  *ForeignBucket        `pulumi:"bucket" provider:"type=aws@6.0.0:s3/bucket:Bucket"`
+		Domain  pulumix.Input[string] `pulumi:"domain"`
+		Regions []string              `pulumi:"regions" provider:"type=aws@6.0.0:index/region:Region"`
+	}
+	WebsiteState struct {
+		pulumi.ResourceState
+		Origin pulumix.Output[*ForeignBucket] `pulumi:"origin" provider:"type=aws@6.0.0:s3/bucket:Bucket"`
+		Region pulumix.Output[string]         `pulumi:"region" provider:"type=aws@6.0.0:index/region:Region"`
+	}
+)
+
+func (*Website) Construct(
+	ctx *pulumi.Context, name, typ string, args WebsiteArgs, opts pulumi.ResourceOption,
+) (*WebsiteState, error) {
+	state := &WebsiteState{}
+	return state, ctx.RegisterComponentResource(typ, name, state, opts)
+}
+
+func TestExternalReferences(t *testing.T) {
+	t.Parallel()
+
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(infer.Options{
+		Components: []infer.InferredComponent{
+			infer.Component[*Website, WebsiteArgs, *WebsiteState](),
+		},
+	}))
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec pschema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+	website := spec.Resources["test:tests:Website"]
+
+	const (
+		bucket = "/aws/v6.0.0/schema.json#/resources/aws:s3/bucket:Bucket"
+		region = "/aws/v6.0.0/schema.json#/types/aws:index/region:Region"
+	)
+	assert.Equal(t, map[string]pschema.TypeSpec{
+		"bucket":  {Ref: bucket},
+		"domain":  {Type: "string"},
+		"regions": {Type: "array", Items: &pschema.TypeSpec{Ref: region}},
+	}, typeSpecs(website.InputProperties))
+	assert.Equal(t, map[string]pschema.TypeSpec{
+		"origin": {Ref: bucket},
+		"region": {Ref: region},
+	}, typeSpecs(website.Properties))
+	assert.Empty(t, spec.Types, "types from other packages aren't copied into the schema")
+}
+
+func typeSpecs(props map[string]pschema.PropertySpec) map[string]pschema.TypeSpec {
+	m := make(map[string]pschema.TypeSpec, len(props))
+	for k, v := range props {
+		m[k] = v.TypeSpec
+	}
+	return m
+}
@@ -0,0 +1,134 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestExternalResource(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+
+	t.Run("read", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Read(p.ReadRequest{
+			ID:  "alice",
+			Urn: urn("Account", "read"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, p.ReadResponse{
+			ID:         "alice",
+			Properties: m{"email": s("alice@example.com")},
+			Inputs:     m{},
+		}, resp)
+	})
+
+	t.Run("read-secret", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Read(p.ReadRequest{
+			ID:         "alice",
+			Urn:        urn("Account", "refresh"),
+			Properties: m{"email": resource.MakeSecret(s("old@example.com"))},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, m{"email": resource.MakeSecret(s("alice@example.com"))}, resp.Properties)
+	})
+
+	t.Run("read-invalid-state", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Read(p.ReadRequest{
+			ID:         "alice",
+			Urn:        urn("Account", "refresh"),
+			Properties: m{"email": resource.NewNumberProperty(1)},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("read-gone", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Read(p.ReadRequest{
+			ID:  "gone",
+			Urn: urn("Account", "gone"),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.ID)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Create(p.CreateRequest{
+			Urn: urn("Account", "create"),
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Update(p.UpdateRequest{
+			ID:  "alice",
+			Urn: urn("Account", "update"),
+		})
+		assert.ErrorContains(t, err, "external resource "+string(urn("Account", "update"))+" cannot be updated")
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Diff(p.DiffRequest{
+			ID:   "alice",
+			Urn:  urn("Account", "diff"),
+			Olds: m{"email": s("alice@example.com")},
+			News: m{},
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.HasChanges)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Delete(p.DeleteRequest{
+			ID:  "alice",
+			Urn: urn("Account", "delete"),
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				Description     string         `json:"description"`
+				InputProperties map[string]any `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		account := spec.Resources["test:index:Account"]
+		assert.Contains(t, account.Description, "managed outside of Pulumi")
+		assert.Empty(t, account.InputProperties)
+	})
+}
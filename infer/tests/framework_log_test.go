@@ -0,0 +1,79 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+func TestFrameworkLog(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+
+	update := func(t *testing.T, topics ...p.FrameworkLogTopic) string {
+		var out bytes.Buffer
+		handler := slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})
+		server := integration.NewServer("test", semver.MustParse("1.0.0"),
+			infer.Provider(providerOpts(nil)).WithFrameworkLog(topics...).WithLogHandler(handler))
+		_, err := server.Update(p.UpdateRequest{
+			ID:  "some-id",
+			Urn: urn("WiredPlus", "test"),
+			Olds: m{
+				"string": s("foo"), "int": n(1),
+				"name": s("(test)"), "stringAndInt": s("foo-1"), "stringPlus": s("foo+"),
+			},
+			News:    m{"string": resource.MakeSecret(s("bar")), "int": n(1)},
+			Preview: true,
+		})
+		require.NoError(t, err)
+		return out.String()
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		assert.NotContains(t, update(t), "[dependencies]")
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		t.Parallel()
+		out := update(t, p.FrameworkLogDependencies)
+		assert.Contains(t, out, `[dependencies] output \"stringPlus\" is unknown: `+
+			`it depends on input \"string\", which changed`)
+		assert.Contains(t, out, `[dependencies] output \"stringAndInt\" is secret: `+
+			`it depends on input \"string\", which is secret`)
+		assert.NotContains(t, out, "[encoding]")
+	})
+
+	t.Run("encoding", func(t *testing.T) {
+		t.Parallel()
+		out := update(t, p.FrameworkLogEncoding)
+		assert.Contains(t, out, `[encoding] decoding tests.WiredInputs: removed secrets at [\"string\"]`)
+		assert.NotContains(t, out, "[dependencies]")
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type (
+	Bucket     struct{}
+	BucketArgs struct {
+		Region string `pulumi:"region"`
+	}
+	BucketState struct {
+		BucketArgs
+		Arn string `pulumi:"arn"`
+	}
+)
+
+func (*Bucket) Create(
+	ctx context.Context, name string, inputs BucketArgs, preview bool,
+) (string, BucketState, error) {
+	return name, BucketState{inputs, "arn:" + name}, nil
+}
+
+func (*Bucket) Read(
+	ctx context.Context, id string, inputs BucketArgs, state BucketState,
+) (string, BucketArgs, BucketState, error) {
+	if id == "missing" {
+		return "", BucketArgs{}, BucketState{}, nil
+	}
+	args := BucketArgs{Region: "us-west-2"}
+	return id, args, BucketState{args, "arn:" + id}, nil
+}
+
+func getterProvider(r infer.InferredResource) integration.Server {
+	opts := providerOpts(nil)
+	opts.Resources = append(opts.Resources, r)
+	return integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+}
+
+func TestGetterSchema(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Bucket, BucketArgs, BucketState](infer.WithGetter()))
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+
+	var spec struct {
+		Functions map[string]struct {
+			Inputs struct {
+				Properties map[string]any `json:"properties"`
+				Required   []string       `json:"required"`
+			} `json:"inputs"`
+			Outputs struct {
+				Properties map[string]any `json:"properties"`
+				Required   []string       `json:"required"`
+			} `json:"outputs"`
+		} `json:"functions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+	fn, ok := spec.Functions["test:index:getBucket"]
+	require.True(t, ok, "missing getBucket")
+	assert.Equal(t, []string{"id"}, fn.Inputs.Required)
+	assert.Len(t, fn.Inputs.Properties, 1)
+	assert.Contains(t, fn.Outputs.Properties, "id")
+	assert.Contains(t, fn.Outputs.Properties, "region")
+	assert.Contains(t, fn.Outputs.Properties, "arn")
+	assert.ElementsMatch(t, []string{"arn", "id", "region"}, fn.Outputs.Required)
+}
+
+func TestGetterDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Bucket, BucketArgs, BucketState]())
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, resp.Schema, "getBucket")
+}
+
+func TestGetterRequiresRead(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Autonamed, AutonamedArgs, AutonamedArgs](infer.WithGetter()))
+	_, err := server.GetSchema(p.GetSchemaRequest{})
+	assert.ErrorContains(t, err, "WithGetter requires *tests.Autonamed to implement CustomRead")
+}
+
+func TestGetterInvoke(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Bucket, BucketArgs, BucketState](infer.WithGetter()))
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:getBucket",
+			Args:  resource.PropertyMap{"id": resource.NewStringProperty("my-bucket")},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, resource.PropertyMap{
+			"id":     resource.NewStringProperty("my-bucket"),
+			"region": resource.NewStringProperty("us-west-2"),
+			"arn":    resource.NewStringProperty("arn:my-bucket"),
+		}, resp.Return)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		_, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:getBucket",
+			Args:  resource.PropertyMap{"id": resource.NewStringProperty("missing")},
+		})
+		assert.ErrorContains(t, err, `Bucket "missing" not found`)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{Token: "test:index:getBucket"})
+		require.NoError(t, err)
+		assert.Equal(t, []p.CheckFailure{{Property: "id", Reason: "missing required property"}}, resp.Failures)
+	})
+}
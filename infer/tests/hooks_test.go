@@ -0,0 +1,105 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	b := resource.NewBoolProperty
+
+	called := func(value string) (string, bool) {
+		v, ok := hookCalls.Load(value)
+		if !ok {
+			return "", false
+		}
+		return v.(string), true
+	}
+
+	t.Run("created", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Hooked", "created"),
+			Properties: m{"value": s("hook-created")},
+		})
+		require.NoError(t, err)
+		call, ok := called("hook-created")
+		require.True(t, ok)
+		assert.Equal(t, "created:created", call)
+	})
+
+	t.Run("created-preview", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Hooked", "preview"),
+			Properties: m{"value": s("hook-created-preview")},
+			Preview:    true,
+		})
+		require.NoError(t, err)
+		_, ok := called("hook-created-preview")
+		assert.False(t, ok)
+	})
+
+	t.Run("created-hook-fails", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Hooked", "fails"),
+			Properties: m{"value": s("hook-created-fails"), "failHook": b(true)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "fails", resp.ID)
+		_, ok := called("hook-created-fails")
+		assert.True(t, ok)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Update(p.UpdateRequest{
+			ID:   "id",
+			Urn:  urn("Hooked", "updated"),
+			Olds: m{"value": s("hook-old")},
+			News: m{"value": s("hook-updated")},
+		})
+		require.NoError(t, err)
+		call, ok := called("hook-updated")
+		require.True(t, ok)
+		assert.Equal(t, "updated:hook-old", call)
+	})
+
+	t.Run("updated-preview", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Update(p.UpdateRequest{
+			ID:      "id",
+			Urn:     urn("Hooked", "updated-preview"),
+			Olds:    m{"value": s("hook-old")},
+			News:    m{"value": s("hook-updated-preview")},
+			Preview: true,
+		})
+		require.NoError(t, err)
+		_, ok := called("hook-updated-preview")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type (
+	WidgetArgs struct {
+		Size int `pulumi:"size"`
+	}
+	WidgetState struct {
+		WidgetArgs
+		Arn string `pulumi:"arn"`
+	}
+
+	NamedWidget  struct{}
+	ArnWidget    struct{}
+	RandomWidget struct{}
+	// MismatchedWidget returns an ID from Create that doesn't match its policy.
+	MismatchedWidget struct{}
+)
+
+func createWidget(name string, inputs WidgetArgs) WidgetState {
+	return WidgetState{inputs, "arn:widget:" + name}
+}
+
+func (*NamedWidget) Create(
+	ctx context.Context, name string, inputs WidgetArgs, preview bool,
+) (string, WidgetState, error) {
+	return "", createWidget(name, inputs), nil
+}
+
+func (*NamedWidget) IDPolicy(*WidgetState) infer.IDPolicy { return infer.IDFromName() }
+
+func (*ArnWidget) Create(
+	ctx context.Context, name string, inputs WidgetArgs, preview bool,
+) (string, WidgetState, error) {
+	return "", createWidget(name, inputs), nil
+}
+
+func (*ArnWidget) Read(
+	ctx context.Context, id string, inputs WidgetArgs, state WidgetState,
+) (string, WidgetArgs, WidgetState, error) {
+	if id == "missing" {
+		return "", WidgetArgs{}, WidgetState{}, nil
+	}
+	// Widgets can be imported by name, but are identified by their ARN.
+	return id, inputs, createWidget(id, inputs), nil
+}
+
+func (*ArnWidget) IDPolicy(state *WidgetState) infer.IDPolicy { return infer.IDFromField(&state.Arn) }
+
+func (*RandomWidget) Create(
+	ctx context.Context, name string, inputs WidgetArgs, preview bool,
+) (string, WidgetState, error) {
+	return "", createWidget(name, inputs), nil
+}
+
+func (*RandomWidget) IDPolicy(*WidgetState) infer.IDPolicy { return infer.IDRandomHex(4) }
+
+func (*MismatchedWidget) Create(
+	ctx context.Context, name string, inputs WidgetArgs, preview bool,
+) (string, WidgetState, error) {
+	return "other", createWidget(name, inputs), nil
+}
+
+func (*MismatchedWidget) IDPolicy(*WidgetState) infer.IDPolicy { return infer.IDFromName() }
+
+func widgetProvider() integration.Server {
+	opts := providerOpts(nil)
+	opts.Resources = append(opts.Resources,
+		infer.Resource[*NamedWidget, WidgetArgs, WidgetState](),
+		infer.Resource[*ArnWidget, WidgetArgs, WidgetState](),
+		infer.Resource[*RandomWidget, WidgetArgs, WidgetState](),
+		infer.Resource[*MismatchedWidget, WidgetArgs, WidgetState](),
+	)
+	return integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(opts))
+}
+
+func TestIDPolicyCreate(t *testing.T) {
+	t.Parallel()
+
+	create := func(typ string, preview bool) (p.CreateResponse, error) {
+		return widgetProvider().Create(p.CreateRequest{
+			Urn:        urn(typ, "my-widget"),
+			Properties: resource.PropertyMap{"size": resource.NewNumberProperty(3)},
+			Preview:    preview,
+		})
+	}
+
+	t.Run("name", func(t *testing.T) {
+		t.Parallel()
+		resp, err := create("NamedWidget", false)
+		require.NoError(t, err)
+		assert.Equal(t, "my-widget", resp.ID)
+	})
+
+	t.Run("field", func(t *testing.T) {
+		t.Parallel()
+		resp, err := create("ArnWidget", false)
+		require.NoError(t, err)
+		assert.Equal(t, "arn:widget:my-widget", resp.ID)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		t.Parallel()
+		resp, err := create("RandomWidget", false)
+		require.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile("^[0-9a-f]{8}$"), resp.ID)
+	})
+
+	t.Run("preview", func(t *testing.T) {
+		t.Parallel()
+		resp, err := create("RandomWidget", true)
+		require.NoError(t, err)
+		assert.Empty(t, resp.ID)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		t.Parallel()
+		const msg = `the ID "other" returned by Create does not match the ID "my-widget" chosen by the ID policy`
+		resp, err := create("MismatchedWidget", false)
+		assert.ErrorContains(t, err, msg)
+
+		// The widget was created, so it is reported as partially created under the ID
+		// Create returned rather than leaked.
+		assert.Equal(t, "other", resp.ID)
+		assert.Equal(t, resource.NewStringProperty("arn:widget:my-widget"), resp.Properties["arn"])
+		require.NotNil(t, resp.PartialState)
+		assert.Equal(t, []string{msg}, resp.PartialState.Reasons)
+	})
+}
+
+func TestIDPolicyRead(t *testing.T) {
+	t.Parallel()
+
+	read := func(t *testing.T, id string) p.ReadResponse {
+		resp, err := widgetProvider().Read(p.ReadRequest{
+			ID:     id,
+			Urn:    urn("ArnWidget", "imported"),
+			Inputs: resource.PropertyMap{"size": resource.NewNumberProperty(3)},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("import", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "arn:widget:my-widget", read(t, "my-widget").ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		assert.Empty(t, read(t, "missing").ID)
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Labeled     struct{}
+	LabeledArgs struct {
+		Tags map[string]string `pulumi:"tags"`
+	}
+	LabeledState struct{ LabeledArgs }
+)
+
+func (*Labeled) Create(
+	ctx context.Context, name string, input LabeledArgs, preview bool,
+) (string, LabeledState, error) {
+	return name, LabeledState{input}, nil
+}
+
+// Diff compares tags one by one, skipping the tags whose changes are ignored.
+func (*Labeled) Diff(
+	ctx context.Context, id string, olds LabeledState, news LabeledArgs,
+) (p.DiffResponse, error) {
+	ignored := map[string]bool{}
+	for _, path := range infer.IgnoreChanges(ctx) {
+		if len(path) == 2 && path[0] == "tags" {
+			ignored[path[1].(string)] = true
+		}
+	}
+	diff := map[string]p.PropertyDiff{}
+	for k, v := range news.Tags {
+		if olds.Tags[k] != v && !ignored[k] {
+			diff["tags."+k] = p.PropertyDiff{Kind: p.Update}
+		}
+	}
+	return p.DiffResponse{HasChanges: len(diff) > 0, DetailedDiff: diff}, nil
+}
+
+func TestIgnoreChanges(t *testing.T) {
+	t.Parallel()
+
+	prov := getterProvider(infer.Resource[*Labeled, LabeledArgs, LabeledState]())
+	tags := func(env, team string) resource.PropertyMap {
+		return resource.PropertyMap{"tags": resource.NewObjectProperty(resource.PropertyMap{
+			"env":  resource.NewStringProperty(env),
+			"team": resource.NewStringProperty(team),
+		})}
+	}
+
+	resp, err := prov.Diff(p.DiffRequest{
+		ID:            "l",
+		Urn:           urn("Labeled", "l"),
+		Olds:          tags("dev", "a"),
+		News:          tags("prod", "b"),
+		IgnoreChanges: []resource.PropertyKey{"tags.env"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]p.PropertyDiff{"tags.team": {Kind: p.Update}}, resp.DetailedDiff)
+
+	resp, err = prov.Diff(p.DiffRequest{
+		ID:            "l",
+		Urn:           urn("Labeled", "l"),
+		Olds:          tags("dev", "a"),
+		News:          tags("prod", "a"),
+		IgnoreChanges: []resource.PropertyKey{`tags["env"]`},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.HasChanges)
+
+	// An empty path ignores nothing.
+	resp, err = prov.Diff(p.DiffRequest{
+		ID:            "l",
+		Urn:           urn("Labeled", "l"),
+		Olds:          tags("dev", "a"),
+		News:          tags("prod", "a"),
+		IgnoreChanges: []resource.PropertyKey{""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]p.PropertyDiff{"tags.env": {Kind: p.Update}}, resp.DetailedDiff)
+}
@@ -0,0 +1,68 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestInputError(t *testing.T) {
+	t.Parallel()
+
+	assertInputError := func(t *testing.T, err error, reason string) {
+		t.Helper()
+		require.Error(t, err)
+		s, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, s.Code())
+		require.Len(t, s.Details(), 1)
+		details, ok := s.Details()[0].(*rpc.InputPropertiesError)
+		require.True(t, ok)
+		require.Len(t, details.Errors, 1)
+		assert.Equal(t, "size", details.Errors[0].PropertyPath)
+		assert.Equal(t, reason, details.Errors[0].Reason)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Create(p.CreateRequest{
+			Urn:        urn("Validated", "create"),
+			Properties: resource.PropertyMap{"size": resource.NewNumberProperty(11)},
+		})
+		assertInputError(t, err, "must be at most 10")
+		assert.ErrorContains(t, err, `invalid value for "size": must be at most 10`)
+	})
+
+	t.Run("update-wrapped", func(t *testing.T) {
+		t.Parallel()
+		_, err := provider().Update(p.UpdateRequest{
+			ID:   "some-id",
+			Urn:  urn("Validated", "update"),
+			Olds: resource.PropertyMap{"size": resource.NewNumberProperty(5)},
+			News: resource.PropertyMap{"size": resource.NewNumberProperty(3)},
+		})
+		assertInputError(t, err, "cannot shrink from 5")
+		assert.ErrorContains(t, err, "resizing some-id")
+	})
+}
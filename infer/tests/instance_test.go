@@ -0,0 +1,91 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+// Stamped is controlled by an instance carrying a clock and a record of deleted IDs, as
+// a resource with an injected client would be.
+type (
+	Stamped struct {
+		now     func() time.Time
+		deleted *[]string
+	}
+	StampedArgs struct {
+		Zone string `pulumi:"zone"`
+	}
+	StampedState struct {
+		StampedArgs
+		Created string `pulumi:"created"`
+	}
+)
+
+func (s Stamped) Check(_ context.Context,
+	_ string, _ resource.PropertyMap, m resource.PropertyMap,
+) (StampedArgs, []p.CheckFailure, error) {
+	zone := s.now().Location().String()
+	if v, ok := m["zone"]; ok && v.IsString() {
+		zone = v.StringValue()
+	}
+	return StampedArgs{Zone: zone}, nil, nil
+}
+
+func (s Stamped) Create(
+	ctx context.Context, name string, inputs StampedArgs, preview bool,
+) (string, StampedState, error) {
+	return name, StampedState{inputs, s.now().Format(time.RFC3339)}, nil
+}
+
+func (s Stamped) Delete(ctx context.Context, id string, props StampedState) error {
+	*s.deleted = append(*s.deleted, id)
+	return nil
+}
+
+func TestResourceInstance(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("test", 0))
+	var deleted []string
+	prov := getterProvider(infer.ResourceInstance[Stamped, StampedArgs, StampedState](Stamped{
+		now:     func() time.Time { return now },
+		deleted: &deleted,
+	}))
+
+	check, err := prov.Check(p.CheckRequest{Urn: urn("Stamped", "s")})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{"zone": resource.NewStringProperty("test")}, check.Inputs)
+
+	create, err := prov.Create(p.CreateRequest{Urn: urn("Stamped", "s"), Properties: check.Inputs})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"zone":    resource.NewStringProperty("test"),
+		"created": resource.NewStringProperty("2024-03-01T12:00:00Z"),
+	}, create.Properties)
+
+	_, err = prov.Delete(p.DeleteRequest{ID: create.ID, Urn: urn("Stamped", "s"), Properties: create.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s"}, deleted)
+}
@@ -102,4 +102,27 @@ func TestInvoke(t *testing.T) {
 		}, resp.Return)
 	})
 
+	t.Run("plain-function", func(t *testing.T) {
+		t.Parallel()
+		prov := provider()
+		resp, err := prov.Invoke(p.InvokeRequest{
+			Token: "test:index:getRepeat",
+			Args: pMap{
+				"value": pString("ab"),
+				"times": resource.NewNumberProperty(3),
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, pMap{"result": pString("ababab")}, resp.Return)
+
+		_, err = prov.Invoke(p.InvokeRequest{
+			Token: "test:index:getRepeat",
+			Args: pMap{
+				"value": pString("ab"),
+				"times": resource.NewNumberProperty(-1),
+			},
+		})
+		assert.ErrorContains(t, err, "times must not be negative")
+	})
 }
@@ -0,0 +1,225 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+type (
+	Topic     struct{}
+	TopicArgs struct {
+		Name string `pulumi:"name"`
+	}
+	TopicState struct {
+		TopicArgs
+		Token string `pulumi:"token" provider:"secret"`
+	}
+	TopicFilters struct {
+		Prefix string `pulumi:"prefix,optional"`
+		Limit  *int   `pulumi:"limit,optional"`
+	}
+)
+
+func (f *TopicFilters) Annotate(a infer.Annotator) {
+	a.SetDefault(&f.Limit, 2)
+}
+
+func (*Topic) Create(
+	ctx context.Context, name string, inputs TopicArgs, preview bool,
+) (string, TopicState, error) {
+	return name, TopicState{inputs, "t-" + inputs.Name}, nil
+}
+
+func (*Topic) List(ctx context.Context, filters TopicFilters) ([]infer.ListItem[TopicState], error) {
+	if filters.Prefix == "fail" {
+		return nil, errors.New("listing failed")
+	}
+	var topics []infer.ListItem[TopicState]
+	for _, name := range []string{"alerts", "audit", "billing"} {
+		if strings.HasPrefix(name, filters.Prefix) && len(topics) < *filters.Limit {
+			topics = append(topics, infer.ListItem[TopicState]{
+				ID:    "id-" + name,
+				State: TopicState{TopicArgs{name}, "t-" + name},
+			})
+		}
+	}
+	return topics, nil
+}
+
+// Queue is a resource whose list function has its token set by an annotation.
+type Queue struct{}
+
+func (*Queue) Annotate(a infer.Annotator) {
+	a.SetListToken("index", "findQueues")
+}
+
+func (*Queue) Create(
+	ctx context.Context, name string, inputs TopicArgs, preview bool,
+) (string, TopicState, error) {
+	return name, TopicState{inputs, "t-" + inputs.Name}, nil
+}
+
+func (*Queue) List(ctx context.Context, filters TopicFilters) ([]infer.ListItem[TopicState], error) {
+	return (*Topic)(nil).List(ctx, filters)
+}
+
+type (
+	BadList     struct{}
+	BadListArgs struct{}
+)
+
+func (*BadList) Create(
+	ctx context.Context, name string, inputs BadListArgs, preview bool,
+) (string, BadListArgs, error) {
+	return name, inputs, nil
+}
+
+func (*BadList) List(ctx context.Context) ([]BadListArgs, error) { return nil, nil }
+
+func TestListSchema(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Topic, TopicArgs, TopicState]())
+	spec := schematest.Spec(t, server)
+
+	fn, ok := spec.Functions["test:index:listTopics"]
+	require.True(t, ok, "missing listTopics")
+	assert.Contains(t, fn.Inputs.Properties, "prefix")
+	assert.Contains(t, fn.Inputs.Properties, "limit")
+	assert.Empty(t, fn.Inputs.Required)
+	require.NotNil(t, fn.ReturnType)
+	outputs := fn.ReturnType.ObjectTypeSpec
+	require.NotNil(t, outputs)
+	assert.Equal(t, []string{"items"}, outputs.Required)
+	items := outputs.Properties["items"]
+	assert.Equal(t, "array", items.Type)
+	require.NotNil(t, items.Items)
+	assert.Equal(t, "#/types/test:index:TopicListItem", items.Items.Ref)
+
+	item, ok := spec.Types["test:index:TopicListItem"]
+	require.True(t, ok, "missing TopicListItem")
+	assert.ElementsMatch(t, []string{"id", "name", "token"}, item.Required)
+	assert.Equal(t, "string", item.Properties["id"].Type)
+	assert.True(t, item.Properties["token"].Secret)
+}
+
+func TestListToken(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Queue, TopicArgs, TopicState]())
+	spec := schematest.Spec(t, server)
+	assert.Contains(t, spec.Functions, "test:index:findQueues")
+	assert.NotContains(t, spec.Functions, "test:index:listQueues")
+
+	resp, err := server.Invoke(p.InvokeRequest{Token: "test:index:findQueues"})
+	require.NoError(t, err)
+	assert.Len(t, resp.Return["items"].ArrayValue(), 2)
+}
+
+func TestListInvoke(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Topic, TopicArgs, TopicState]())
+	topic := func(name string) resource.PropertyValue {
+		return resource.NewObjectProperty(resource.PropertyMap{
+			"id":    resource.NewStringProperty("id-" + name),
+			"name":  resource.NewStringProperty(name),
+			"token": resource.MakeSecret(resource.NewStringProperty("t-" + name)),
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{Token: "test:index:listTopics"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+		assert.Equal(t, resource.PropertyMap{
+			"items": resource.NewArrayProperty([]resource.PropertyValue{topic("alerts"), topic("audit")}),
+		}, resp.Return)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:listTopics",
+			Args:  resource.PropertyMap{"prefix": resource.NewStringProperty("b")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, resource.PropertyMap{
+			"items": resource.NewArrayProperty([]resource.PropertyValue{topic("billing")}),
+		}, resp.Return)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:listTopics",
+			Args:  resource.PropertyMap{"prefix": resource.NewStringProperty("x")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, resource.PropertyMap{
+			"items": resource.NewArrayProperty([]resource.PropertyValue{}),
+		}, resp.Return)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:listTopics",
+			Args:  resource.PropertyMap{"prefix": resource.NewNumberProperty(1)},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "prefix", resp.Failures[0].Property)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		_, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:listTopics",
+			Args:  resource.PropertyMap{"prefix": resource.NewStringProperty("fail")},
+		})
+		assert.ErrorContains(t, err, "listing failed")
+	})
+}
+
+func TestListNotImplemented(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Bucket, BucketArgs, BucketState]())
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	assert.NotContains(t, resp.Schema, "listBuckets")
+}
+
+func TestListWrongSignature(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*BadList, BadListArgs, BadListArgs]())
+	_, err := server.GetSchema(p.GetSchemaRequest{})
+	assert.ErrorContains(t, err, "*tests.BadList has a List method, but it does not implement CustomList")
+}
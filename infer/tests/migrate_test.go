@@ -219,7 +219,7 @@ func TestMigrateDelete(t *testing.T) {
 	t.Parallel()
 
 	testMigrationEquivalentStates(t, func(t *testing.T, state, v2State resource.PropertyMap) {
-		err := migrationServer().Delete(p.DeleteRequest{
+		_, err := migrationServer().Delete(p.DeleteRequest{
 			ID:         "some-id",
 			Urn:        urn("MigrateR", "delete"),
 			Properties: state,
@@ -275,3 +275,81 @@ func (*MigrateR) Diff(_ context.Context, _ string, s MigrateStateV2, _ MigrateSt
 type viaError[T any] struct{ t T }
 
 func (viaError[T]) Error() string { panic("NOT FOR DISPLAY") }
+
+type RenameR struct{}
+
+type RenameInput struct {
+	DisplayName string `pulumi:"displayName" migrate:"display_name,name"`
+}
+
+type RenameState struct {
+	RenameInput
+	Size int `pulumi:"size" migrate:"old_size"`
+}
+
+func (*RenameR) Create(
+	_ context.Context, _ string, input RenameInput, _ bool,
+) (string, RenameState, error) {
+	return "id", RenameState{RenameInput: input}, nil
+}
+
+func (*RenameR) Update(
+	_ context.Context, _ string, olds RenameState, news RenameInput, _ bool,
+) (RenameState, error) {
+	return RenameState{RenameInput: news, Size: olds.Size}, nil
+}
+
+func TestMigrateRenamedFields(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+
+	server := func() integration.Server {
+		return integration.NewServer("test",
+			semver.MustParse("1.0.0"),
+			infer.Provider(infer.Options{
+				Resources: []infer.InferredResource{
+					infer.Resource[*RenameR, RenameInput, RenameState](),
+				},
+				ModuleMap: map[tokens.ModuleName]tokens.ModuleName{"tests": "index"},
+			}))
+	}
+	urn := resource.NewURN("stack", "proj", "", "test:index:RenameR", "name")
+
+	for _, legacy := range []string{"display_name", "name"} {
+		legacy := legacy
+		t.Run(legacy, func(t *testing.T) {
+			t.Parallel()
+			olds := m{resource.PropertyKey(legacy): s("my-name"), "old_size": n(3)}
+
+			diff, err := server().Diff(p.DiffRequest{
+				ID: "id", Urn: urn,
+				Olds: olds,
+				News: m{"displayName": s("my-name")},
+			})
+			require.NoError(t, err)
+			assert.False(t, diff.HasChanges)
+
+			resp, err := server().Update(p.UpdateRequest{
+				ID: "id", Urn: urn,
+				Olds: olds,
+				News: m{"displayName": s("new-name")},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, m{"displayName": s("new-name"), "size": n(3)}, resp.Properties)
+		})
+	}
+
+	t.Run("current-name-wins", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server().Update(p.UpdateRequest{
+			ID: "id", Urn: urn,
+			Olds: m{"displayName": s("a"), "display_name": s("b"), "size": n(1), "old_size": n(2)},
+			News: m{"displayName": s("c")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, m{"displayName": s("c"), "size": n(1)}, resp.Properties)
+	})
+}
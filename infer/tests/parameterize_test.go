@@ -0,0 +1,153 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+// parameterizedProvider serves Salted until it is parameterized with the name of the
+// resources it should serve instead.
+func parameterizedProvider() integration.Server {
+	resources := map[string]infer.InferredResource{
+		"renamed": infer.Resource[*Renamed, RenamedArgs, RenamedArgs](),
+		"resized": infer.Resource[*Resized, ResizedArgs, ResizedArgs](),
+	}
+	parameterize := func(_ context.Context, req p.ParameterizeRequest) (infer.Parameterization, error) {
+		var names []string
+		switch {
+		case req.Args != nil:
+			names = req.Args.Args
+		case req.Value != nil:
+			names = strings.Split(string(req.Value.Value), ",")
+		}
+		params := infer.Parameterization{
+			Name:    "dynamic",
+			Version: semver.MustParse("0.1.0"),
+			Value:   []byte(strings.Join(names, ",")),
+		}
+		for _, name := range names {
+			r, ok := resources[name]
+			if !ok {
+				return infer.Parameterization{}, fmt.Errorf("unknown resource %q", name)
+			}
+			params.Resources = append(params.Resources, r)
+		}
+		return params, nil
+	}
+
+	prov := infer.Provider(infer.Options{
+		Resources:    []infer.InferredResource{infer.Resource[*Salted, SaltedArgs, SaltedArgs]()},
+		ModuleMap:    map[tokens.ModuleName]tokens.ModuleName{"tests": "index"},
+		Parameterize: parameterize,
+	})
+	return integration.NewServer("test", semver.MustParse("1.0.0"), prov)
+}
+
+func TestParameterize(t *testing.T) {
+	t.Parallel()
+
+	create := func(s integration.Server, typ string) error {
+		_, err := s.Create(p.CreateRequest{
+			Urn:        urn(typ, "r"),
+			Properties: resource.PropertyMap{},
+			Preview:    true,
+		})
+		return err
+	}
+
+	t.Run("args", func(t *testing.T) {
+		t.Parallel()
+		s := parameterizedProvider()
+		require.NoError(t, create(s, "Salted"))
+
+		resp, err := s.Parameterize(p.ParameterizeRequest{
+			Args: &p.ParameterizeRequestArgs{Args: []string{"renamed"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, p.ParameterizeResponse{
+			Name:    "dynamic",
+			Version: semver.MustParse("0.1.0"),
+		}, resp)
+
+		require.NoError(t, create(s, "Renamed"))
+		assert.ErrorContains(t, create(s, "Salted"), "not found")
+	})
+
+	t.Run("value", func(t *testing.T) {
+		t.Parallel()
+		s := parameterizedProvider()
+		_, err := s.Parameterize(p.ParameterizeRequest{
+			Value: &p.ParameterizeRequestValue{
+				Name:    "dynamic",
+				Version: semver.MustParse("0.1.0"),
+				Value:   []byte("renamed,resized"),
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, create(s, "Renamed"))
+		assert.ErrorContains(t, create(s, "Salted"), "not found")
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		s := parameterizedProvider()
+		_, err := s.Parameterize(p.ParameterizeRequest{
+			Args: &p.ParameterizeRequestArgs{Args: []string{"renamed", "resized"}},
+		})
+		require.NoError(t, err)
+
+		resp, err := s.GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec pschema.PackageSpec
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+
+		assert.Equal(t, "dynamic", spec.Name)
+		assert.Equal(t, "0.1.0", spec.Version)
+		assert.Equal(t, &pschema.ParameterizationSpec{
+			BaseProvider: pschema.BaseProviderSpec{Name: "test", Version: "1.0.0"},
+			Parameter:    []byte("renamed,resized"),
+		}, spec.Parameterization)
+		assert.Contains(t, spec.Resources, "dynamic:index:Renamed")
+		assert.Contains(t, spec.Resources, "dynamic:index:Resized")
+		assert.NotContains(t, spec.Resources, "dynamic:index:Salted")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		s := parameterizedProvider()
+		_, err := s.Parameterize(p.ParameterizeRequest{
+			Args: &p.ParameterizeRequestArgs{Args: []string{"missing"}},
+		})
+		assert.ErrorContains(t, err, `unknown resource "missing"`)
+		// A failed parameterization leaves the provider unchanged.
+		require.NoError(t, create(s, "Salted"))
+	})
+}
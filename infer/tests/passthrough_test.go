@@ -0,0 +1,95 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+type (
+	Blob     struct{}
+	BlobArgs struct {
+		Body     json.RawMessage `pulumi:"body"`
+		Labels   map[string]any  `pulumi:"labels,optional"`
+		Metadata any             `pulumi:"metadata,optional"`
+	}
+	BlobState struct {
+		BlobArgs
+		Keys int `pulumi:"keys"`
+	}
+)
+
+func (*Blob) Create(
+	ctx context.Context, name string, inputs BlobArgs, preview bool,
+) (string, BlobState, error) {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(inputs.Body, &body); err != nil {
+		return "", BlobState{}, err
+	}
+	return name, BlobState{BlobArgs: inputs, Keys: len(body)}, nil
+}
+
+func TestPassthroughTypes(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Blob, BlobArgs, BlobState]())
+
+	spec := schematest.Spec(t, server)
+	schematest.AssertInputProperty(t, spec, "test:index:Blob", "body",
+		schematest.Required, schematest.Ref("pulumi.json#/Json"))
+	schematest.AssertInputProperty(t, spec, "test:index:Blob", "metadata",
+		schematest.Optional, schematest.Ref("pulumi.json#/Any"))
+	schematest.AssertProperty(t, spec, "test:index:Blob", "body", schematest.Ref("pulumi.json#/Json"))
+	schematest.AssertProperty(t, spec, "test:index:Blob", "labels", schematest.Type("object"))
+
+	body := resource.NewObjectProperty(resource.PropertyMap{
+		"name":  resource.NewStringProperty("doc"),
+		"count": resource.NewNumberProperty(3),
+		"tags": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("a"), resource.NewBoolProperty(true),
+		}),
+		"token":  resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"nested": resource.NewObjectProperty(resource.PropertyMap{}),
+	})
+	inputs := resource.PropertyMap{
+		"body": body,
+		"labels": resource.NewObjectProperty(resource.PropertyMap{
+			"team": resource.NewStringProperty("infra"),
+			"tier": resource.NewNumberProperty(1),
+		}),
+		"metadata": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewStringProperty("x"),
+			resource.NewObjectProperty(resource.PropertyMap{"y": resource.NewNullProperty()}),
+		}),
+	}
+
+	created, err := server.Create(p.CreateRequest{Urn: urn("Blob", "b"), Properties: inputs})
+	require.NoError(t, err)
+	assert.Equal(t, body, created.Properties["body"])
+	assert.Equal(t, inputs["labels"], created.Properties["labels"])
+	assert.Equal(t, inputs["metadata"], created.Properties["metadata"])
+
+	assert.Equal(t, resource.NewNumberProperty(5), created.Properties["keys"])
+}
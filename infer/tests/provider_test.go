@@ -19,7 +19,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/blang/semver"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -344,6 +346,22 @@ func (*GetJoin) Call(ctx context.Context, args JoinArgs) (JoinResult, error) {
 	return JoinResult{strings.Join(args.Elems, *args.Sep)}, nil
 }
 
+type RepeatArgs struct {
+	Value string `pulumi:"value"`
+	Times int    `pulumi:"times,optional"`
+}
+
+type RepeatResult struct {
+	Result string `pulumi:"result"`
+}
+
+func GetRepeat(ctx context.Context, args RepeatArgs) (RepeatResult, error) {
+	if args.Times < 0 {
+		return RepeatResult{}, fmt.Errorf("times must not be negative")
+	}
+	return RepeatResult{strings.Repeat(args.Value, args.Times)}, nil
+}
+
 type ConfigCustom struct {
 	Number  *float64 `pulumi:"number,optional"`
 	Squared float64
@@ -375,6 +393,43 @@ func (*ConfigCustom) Check(ctx context.Context,
 	return &c, nil, nil
 }
 
+// ConfigExclusive accepts either a token or a username and password, but not both.
+type ConfigExclusive struct {
+	Token    *string `pulumi:"token,optional"`
+	Username *string `pulumi:"username,optional"`
+	Password *string `pulumi:"password,optional" provider:"secret"`
+}
+
+var _ = (infer.CustomCheckConfig[*ConfigExclusive])((*ConfigExclusive)(nil))
+
+func (*ConfigExclusive) CheckConfig(
+	ctx context.Context, req p.CheckRequest,
+) (*ConfigExclusive, []p.CheckFailure, error) {
+	var c ConfigExclusive
+	var failures []p.CheckFailure
+	get := func(key resource.PropertyKey) *string {
+		if v, ok := req.News[key]; ok && v.IsString() {
+			s := v.StringValue()
+			return &s
+		}
+		return nil
+	}
+	c.Token, c.Username, c.Password = get("token"), get("username"), get("password")
+	switch {
+	case c.Token != nil && (c.Username != nil || c.Password != nil):
+		failures = append(failures, p.CheckFailure{
+			Property: "token",
+			Reason:   "token cannot be used with username or password",
+		})
+	case c.Token == nil && (c.Username == nil || c.Password == nil):
+		failures = append(failures, p.CheckFailure{
+			Property: "username",
+			Reason:   "either token or both username and password must be set",
+		})
+	}
+	return &c, failures, nil
+}
+
 type ReadConfigCustom struct{}
 type ReadConfigCustomArgs struct{}
 type ReadConfigCustomOutput struct {
@@ -414,6 +469,288 @@ func (w *CustomCheckNoDefaults) Create(
 	return "id", CustomCheckNoDefaultsOutput{inputs}, nil
 }
 
+type (
+	WriteOnly     struct{}
+	WriteOnlyArgs struct {
+		Username        string  `pulumi:"username"`
+		InitialPassword *string `pulumi:"initialPassword,optional" provider:"secret,writeOnly"`
+		Token           *string `pulumi:"token,optional"`
+	}
+	WriteOnlyOutput struct {
+		WriteOnlyArgs
+		PasswordSet bool `pulumi:"passwordSet"`
+	}
+)
+
+func (w *WriteOnlyArgs) Annotate(a infer.Annotator) {
+	a.WriteOnly(&w.Token)
+}
+
+func (*WriteOnly) Create(
+	ctx context.Context, name string, inputs WriteOnlyArgs, preview bool,
+) (string, WriteOnlyOutput, error) {
+	return "id", WriteOnlyOutput{inputs, inputs.InitialPassword != nil}, nil
+}
+
+func (*WriteOnly) Update(
+	ctx context.Context, id string, olds WriteOnlyOutput, news WriteOnlyArgs, preview bool,
+) (WriteOnlyOutput, error) {
+	return WriteOnlyOutput{news, olds.PasswordSet || news.InitialPassword != nil}, nil
+}
+
+type (
+	Hooked     struct{}
+	HookedArgs struct {
+		Value    string `pulumi:"value"`
+		FailHook bool   `pulumi:"failHook,optional"`
+	}
+	HookedState struct {
+		HookedArgs
+	}
+)
+
+// hookCalls records the calls made to Hooked's lifecycle hooks, keyed by the value of
+// the resource.
+var hookCalls sync.Map
+
+func (*Hooked) Create(
+	ctx context.Context, name string, inputs HookedArgs, preview bool,
+) (string, HookedState, error) {
+	return name, HookedState{inputs}, nil
+}
+
+func (*Hooked) Update(
+	ctx context.Context, id string, olds HookedState, news HookedArgs, preview bool,
+) (HookedState, error) {
+	return HookedState{news}, nil
+}
+
+func (*Hooked) OnCreated(ctx context.Context, id string, state HookedState) error {
+	hookCalls.Store(state.Value, "created:"+id)
+	if state.FailHook {
+		return fmt.Errorf("hook failed")
+	}
+	return nil
+}
+
+func (*Hooked) OnUpdated(ctx context.Context, id string, olds, news HookedState) error {
+	hookCalls.Store(news.Value, "updated:"+olds.Value)
+	if news.FailHook {
+		return fmt.Errorf("hook failed")
+	}
+	return nil
+}
+
+type (
+	HangingDelete      struct{}
+	HangingDeleteState struct{}
+)
+
+// releaseHangingDelete unblocks every call to HangingDelete.Delete when closed.
+var releaseHangingDelete = make(chan struct{})
+
+func (*HangingDelete) Create(
+	ctx context.Context, name string, inputs HangingDeleteState, preview bool,
+) (string, HangingDeleteState, error) {
+	return name, inputs, nil
+}
+
+// Delete ignores ctx, simulating a delete that never finishes.
+func (*HangingDelete) Delete(ctx context.Context, id string, props HangingDeleteState) error {
+	<-releaseHangingDelete
+	return nil
+}
+
+type (
+	Autonamed     struct{}
+	AutonamedArgs struct {
+		Name *string `pulumi:"name,optional"`
+	}
+)
+
+func (a *AutonamedArgs) Annotate(an infer.Annotator) {
+	an.Autoname(&a.Name, infer.AutonameOptions{Prefix: "app-", MaxLen: 16, Charset: "xyz"})
+}
+
+func (*Autonamed) Create(
+	ctx context.Context, name string, inputs AutonamedArgs, preview bool,
+) (string, AutonamedArgs, error) {
+	return name, inputs, nil
+}
+
+type (
+	Validated     struct{}
+	ValidatedArgs struct {
+		Size int `pulumi:"size"`
+	}
+)
+
+func (*Validated) Create(
+	ctx context.Context, name string, inputs ValidatedArgs, preview bool,
+) (string, ValidatedArgs, error) {
+	if inputs.Size > 10 {
+		return "", ValidatedArgs{}, infer.InputErrorf("size", "must be at most %d", 10)
+	}
+	return name, inputs, nil
+}
+
+func (*Validated) Update(
+	ctx context.Context, id string, olds, news ValidatedArgs, preview bool,
+) (ValidatedArgs, error) {
+	if news.Size < olds.Size {
+		return ValidatedArgs{}, fmt.Errorf("resizing %s: %w", id,
+			infer.InputErrorf("size", "cannot shrink from %d", olds.Size))
+	}
+	return news, nil
+}
+
+type (
+	Lookup     struct{}
+	LookupArgs struct {
+		Key string `pulumi:"key"`
+	}
+	LookupState struct {
+		LookupArgs
+		Value string `pulumi:"value"`
+	}
+)
+
+func (*Lookup) Read(ctx context.Context, name string, args LookupArgs) (string, LookupState, error) {
+	if args.Key == "missing" {
+		return "", LookupState{}, fmt.Errorf("no value for %q", args.Key)
+	}
+	return "id-" + args.Key, LookupState{args, strings.ToUpper(args.Key)}, nil
+}
+
+type (
+	Account      struct{}
+	AccountState struct {
+		Email string `pulumi:"email"`
+	}
+)
+
+func (*Account) Read(ctx context.Context, id string) (string, AccountState, error) {
+	if id == "gone" {
+		return "", AccountState{}, nil
+	}
+	return id, AccountState{Email: id + "@example.com"}, nil
+}
+
+type (
+	Salted     struct{}
+	SaltedArgs struct {
+		Salt string `pulumi:"salt,optional"`
+	}
+)
+
+func (*Salted) Check(
+	ctx context.Context, name string, olds, news resource.PropertyMap,
+) (SaltedArgs, []p.CheckFailure, error) {
+	args, failures, err := infer.DefaultCheck[SaltedArgs](ctx, news)
+	if args.Salt == "" {
+		args.Salt = strconv.Itoa(infer.NewDeterministicRandom(ctx).Int())
+	}
+	return args, failures, err
+}
+
+func (*Salted) Create(
+	ctx context.Context, name string, inputs SaltedArgs, preview bool,
+) (string, SaltedArgs, error) {
+	return name, inputs, nil
+}
+
+type (
+	Renamed     struct{}
+	RenamedArgs struct {
+		Name    string `pulumi:"name,optional"`
+		OldName string `pulumi:"oldName,optional"`
+	}
+)
+
+func (a *RenamedArgs) Annotate(an infer.Annotator) {
+	an.Deprecate(&a.OldName, "Use name instead.")
+}
+
+func (*Renamed) Create(
+	ctx context.Context, name string, inputs RenamedArgs, preview bool,
+) (string, RenamedArgs, error) {
+	return name, inputs, nil
+}
+
+type (
+	Resized     struct{}
+	ResizedArgs struct {
+		Region string       `pulumi:"region" provider:"replaceOnChanges"`
+		Zone   string       `pulumi:"zone,optional"`
+		Size   int          `pulumi:"size,optional"`
+		Disk   *ResizedDisk `pulumi:"disk,optional"`
+	}
+	ResizedDisk struct {
+		Kind     string `pulumi:"kind,optional" provider:"replaceOnChanges"`
+		Capacity int    `pulumi:"capacity,optional"`
+	}
+)
+
+func (a *ResizedArgs) Annotate(an infer.Annotator) {
+	an.ReplaceOnChanges(&a.Zone)
+}
+
+func (*Resized) Create(
+	ctx context.Context, name string, inputs ResizedArgs, preview bool,
+) (string, ResizedArgs, error) {
+	return name, inputs, nil
+}
+
+func (*Resized) Update(
+	ctx context.Context, id string, olds, news ResizedArgs, preview bool,
+) (ResizedArgs, error) {
+	return news, nil
+}
+
+type (
+	Drifted     struct{}
+	DriftedArgs struct {
+		Region string `pulumi:"region" provider:"replaceOnChanges"`
+		Size   int    `pulumi:"size"`
+	}
+	DriftedState struct {
+		DriftedArgs
+		Etag string `pulumi:"etag"`
+	}
+)
+
+func (*Drifted) Create(
+	ctx context.Context, name string, inputs DriftedArgs, preview bool,
+) (string, DriftedState, error) {
+	return name, DriftedState{inputs, "1"}, nil
+}
+
+func (*Drifted) Update(
+	ctx context.Context, id string, olds DriftedState, news DriftedArgs, preview bool,
+) (DriftedState, error) {
+	return DriftedState{news, olds.Etag + "1"}, nil
+}
+
+// DetectDrift simulates a live resource whose ID describes how it was changed outside of
+// Pulumi.
+func (*Drifted) DetectDrift(ctx context.Context, id string, state DriftedState) (infer.DriftReport, error) {
+	switch id {
+	case "resized":
+		return infer.DriftReport{Properties: []infer.PropertyDrift{
+			{Path: "size", Reason: "resized outside of Pulumi"},
+			{Path: "etag"},
+		}}, nil
+	case "moved":
+		return infer.DriftReport{Properties: []infer.PropertyDrift{{Path: "region"}}}, nil
+	case "renamed":
+		return infer.DriftReport{Properties: []infer.PropertyDrift{{Path: "region", Kind: p.Update}}}, nil
+	case "unreachable":
+		return infer.DriftReport{}, fmt.Errorf("cannot reach %s", id)
+	default:
+		return infer.DriftReport{}, nil
+	}
+}
+
 func providerOpts(config infer.InferredConfig) infer.Options {
 	return infer.Options{
 		Config: config,
@@ -428,9 +765,21 @@ func providerOpts(config infer.InferredConfig) infer.Options {
 			infer.Resource[*ReadConfig, ReadConfigArgs, ReadConfigOutput](),
 			infer.Resource[*ReadConfigCustom, ReadConfigCustomArgs, ReadConfigCustomOutput](),
 			infer.Resource[*CustomCheckNoDefaults, CustomCheckNoDefaultsArgs, CustomCheckNoDefaultsOutput](),
+			infer.Resource[*WriteOnly, WriteOnlyArgs, WriteOnlyOutput](),
+			infer.Resource[*Hooked, HookedArgs, HookedState](),
+			infer.Resource[*HangingDelete, HangingDeleteState, HangingDeleteState](),
+			infer.Resource[*Autonamed, AutonamedArgs, AutonamedArgs](),
+			infer.Resource[*Validated, ValidatedArgs, ValidatedArgs](),
+			infer.DataResource[*Lookup, LookupArgs, LookupState](),
+			infer.ExternalResource[*Account, AccountState](),
+			infer.Resource[*Salted, SaltedArgs, SaltedArgs](),
+			infer.Resource[*Renamed, RenamedArgs, RenamedArgs](),
+			infer.Resource[*Resized, ResizedArgs, ResizedArgs](),
+			infer.Resource[*Drifted, DriftedArgs, DriftedState](),
 		},
 		Functions: []infer.InferredFunction{
 			infer.Function[*GetJoin, JoinArgs, JoinResult](),
+			infer.FunctionF(GetRepeat),
 		},
 		ModuleMap: map[tokens.ModuleName]tokens.ModuleName{"tests": "index"},
 	}
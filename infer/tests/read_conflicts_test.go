@@ -0,0 +1,118 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Volume     struct{}
+	VolumeArgs struct {
+		Name   string  `pulumi:"name"`
+		Region *string `pulumi:"region,optional"`
+	}
+	VolumeState struct {
+		VolumeArgs
+	}
+)
+
+func (*Volume) Create(
+	ctx context.Context, name string, inputs VolumeArgs, preview bool,
+) (string, VolumeState, error) {
+	return name, VolumeState{inputs}, nil
+}
+
+// Read reports the volume's name as the remote API normalizes it, along with the region
+// it was created in.
+func (*Volume) Read(
+	ctx context.Context, id string, inputs VolumeArgs, state VolumeState,
+) (string, VolumeArgs, VolumeState, error) {
+	region := "us-west-2"
+	remote := VolumeArgs{Name: id + "-normalized", Region: &region}
+	return id, remote, VolumeState{remote}, nil
+}
+
+func TestReadConflicts(t *testing.T) {
+	t.Parallel()
+
+	program := resource.PropertyMap{"name": resource.NewStringProperty("my-volume")}
+	read := func(t *testing.T, inputs resource.PropertyMap, opts ...infer.ResourceOption) resource.PropertyMap {
+		resp, err := getterProvider(infer.Resource[*Volume, VolumeArgs, VolumeState](opts...)).Read(p.ReadRequest{
+			ID:         "volume",
+			Urn:        urn("Volume", "b"),
+			Properties: resource.PropertyMap{"name": resource.NewStringProperty("my-volume")},
+			Inputs:     inputs,
+		})
+		require.NoError(t, err)
+		return resp.Inputs
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, resource.PropertyMap{
+			"name":   resource.NewStringProperty("volume-normalized"),
+			"region": resource.NewStringProperty("us-west-2"),
+		}, read(t, program))
+	})
+
+	t.Run("prefer-remote", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, resource.PropertyMap{
+			"name":   resource.NewStringProperty("volume-normalized"),
+			"region": resource.NewStringProperty("us-west-2"),
+		}, read(t, program, infer.ResolveReadConflicts(infer.PreferRemote)))
+	})
+
+	t.Run("prefer-program-inputs", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, resource.PropertyMap{
+			"name":   resource.NewStringProperty("my-volume"),
+			"region": resource.NewStringProperty("us-west-2"),
+		}, read(t, program, infer.ResolveReadConflicts(infer.PreferProgramInputs)))
+	})
+
+	t.Run("prefer-program-inputs-import", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, resource.PropertyMap{
+			"name":   resource.NewStringProperty("volume-normalized"),
+			"region": resource.NewStringProperty("us-west-2"),
+		}, read(t, nil, infer.ResolveReadConflicts(infer.PreferProgramInputs)))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		t.Parallel()
+		var seen resource.PropertyMap
+		got := read(t, program, infer.ResolveReadConflicts(
+			func(_ context.Context, program, remote resource.PropertyMap) resource.PropertyMap {
+				seen = program
+				merged := remote.Copy()
+				delete(merged, "region")
+				return merged
+			}))
+		assert.Equal(t, program, seen)
+		assert.Equal(t, resource.PropertyMap{
+			"name": resource.NewStringProperty("volume-normalized"),
+		}, got)
+	})
+}
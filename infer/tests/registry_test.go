@@ -0,0 +1,96 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	reg := infer.NewRegistry()
+	reg.Register(infer.Resource[*Bucket, BucketArgs, BucketState](infer.WithGetter()))
+	reg.Register(infer.Resource[*Topic, TopicArgs, TopicState]())
+
+	server := integration.NewServer("test", semver.MustParse("1.0.0"),
+		infer.Provider(providerOpts(nil).WithRegistry(reg)))
+
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Schema, `"test:index:Bucket"`)
+	assert.Contains(t, resp.Schema, `"test:index:getBucket"`)
+	assert.Contains(t, resp.Schema, `"test:index:Topic"`)
+	// Resources served directly are kept.
+	assert.Contains(t, resp.Schema, `"test:index:Echo"`)
+
+	created, err := server.Create(p.CreateRequest{
+		Urn:        urn("Bucket", "b"),
+		Properties: resource.PropertyMap{"region": resource.NewStringProperty("us-east-1")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.NewStringProperty("arn:b"), created.Properties["arn"])
+}
+
+func TestRegistryZeroValue(t *testing.T) {
+	t.Parallel()
+
+	var reg infer.Registry
+	reg.Register(infer.Resource[*Topic, TopicArgs, TopicState]())
+
+	spec := schematest.Spec(t, integration.NewServer("test", semver.MustParse("1.0.0"),
+		infer.Provider(providerOpts(nil).WithRegistry(&reg))))
+	assert.Contains(t, spec.Resources, "test:index:Topic")
+}
+
+func TestRegistryConflicts(t *testing.T) {
+	t.Parallel()
+
+	panicValue := func(f func()) (v any) {
+		defer func() { v = recover() }()
+		f()
+		return nil
+	}
+
+	reg := infer.NewRegistry()
+	reg.Register(infer.Resource[*Bucket, BucketArgs, BucketState]())
+
+	v := panicValue(func() {
+		reg.Register(infer.Resource[*Bucket, BucketArgs, BucketState](infer.WithGetter()))
+	})
+	require.IsType(t, "", v)
+	assert.Contains(t, v, "resource pkg:tests:Bucket is registered twice: at ")
+	assert.Contains(t, v, "registry_test.go:")
+
+	v = panicValue(func() { reg.Register(&Bucket{}) })
+	assert.Equal(t, "cannot register *tests.Bucket: expected an InferredResource, "+
+		"InferredComponent or InferredFunction", v)
+
+	reg.Register(infer.Resource[*Echo, EchoInputs, EchoOutputs]())
+	v = panicValue(func() { providerOpts(nil).WithRegistry(reg) })
+	require.IsType(t, "", v)
+	assert.Contains(t, v, "resource pkg:tests:Echo registered at ")
+	assert.Contains(t, v, "is already served by the provider")
+}
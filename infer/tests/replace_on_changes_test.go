@@ -0,0 +1,123 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestReplaceOnChanges(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+
+	olds := m{
+		"region": s("us-east-1"),
+		"zone":   s("a"),
+		"size":   n(1),
+		"disk":   resource.NewObjectProperty(m{"kind": s("ssd"), "capacity": n(10)}),
+	}
+	with := func(k resource.PropertyKey, v resource.PropertyValue) m {
+		news := olds.Copy()
+		news[k] = v
+		return news
+	}
+	diff := func(t *testing.T, typ string, olds, news m, ignoreChanges ...resource.PropertyKey) p.DiffResponse {
+		resp, err := provider().Diff(p.DiffRequest{
+			ID:            "id",
+			Urn:           urn(typ, "diff"),
+			Olds:          olds,
+			News:          news,
+			IgnoreChanges: ignoreChanges,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	tests := []struct {
+		name     string
+		news     m
+		key      string
+		expected p.DiffKind
+	}{
+		{"tag", with("region", s("us-west-2")), "region", p.UpdateReplace},
+		{"annotation", with("zone", s("b")), "zone", p.UpdateReplace},
+		{"unmarked", with("size", n(2)), "size", p.Update},
+		{
+			"nested tag",
+			with("disk", resource.NewObjectProperty(m{"kind": s("hdd"), "capacity": n(10)})),
+			"disk.kind", p.UpdateReplace,
+		},
+		{
+			"nested unmarked",
+			with("disk", resource.NewObjectProperty(m{"kind": s("ssd"), "capacity": n(20)})),
+			"disk.capacity", p.Update,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp := diff(t, "Resized", olds, tt.news)
+			assert.True(t, resp.HasChanges)
+			assert.Equal(t, map[string]p.PropertyDiff{
+				tt.key: {Kind: tt.expected},
+			}, resp.DetailedDiff)
+		})
+	}
+
+	t.Run("ignore changes", func(t *testing.T) {
+		t.Parallel()
+		resp := diff(t, "Resized", olds, with("region", s("us-west-2")), "region")
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.DetailedDiff)
+	})
+
+	t.Run("no update", func(t *testing.T) {
+		t.Parallel()
+		// Without an Update method, every change is a replacement.
+		resp := diff(t, "Renamed", m{"name": s("a")}, m{"name": s("b")})
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"name": {Kind: p.UpdateReplace},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				InputProperties map[string]struct {
+					ReplaceOnChanges bool `json:"replaceOnChanges"`
+				} `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		props := spec.Resources["test:index:Resized"].InputProperties
+		assert.True(t, props["region"].ReplaceOnChanges)
+		assert.True(t, props["zone"].ReplaceOnChanges)
+		assert.False(t, props["size"].ReplaceOnChanges)
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+)
+
+type (
+	Credential     struct{}
+	CredentialArgs struct {
+		User string `pulumi:"user"`
+	}
+	CredentialState struct {
+		CredentialArgs
+		infer.Rotation
+		Password string `pulumi:"password" provider:"secret"`
+	}
+)
+
+func (*Credential) Annotate(a infer.Annotator) {
+	a.SetRotation(24 * time.Hour)
+}
+
+func (*Credential) Create(
+	ctx context.Context, name string, inputs CredentialArgs, preview bool,
+) (string, CredentialState, error) {
+	return name, CredentialState{CredentialArgs: inputs, Password: "pw-" + inputs.User}, nil
+}
+
+func (*Credential) Update(
+	ctx context.Context, id string, olds CredentialState, news CredentialArgs, preview bool,
+) (CredentialState, error) {
+	// The rotation timestamp is carried over by infer.
+	return CredentialState{CredentialArgs: news, Password: olds.Password}, nil
+}
+
+type (
+	Unrotated      struct{}
+	UnrotatedState struct {
+		CredentialArgs
+	}
+)
+
+func (*Unrotated) Annotate(a infer.Annotator) {
+	a.SetRotation(time.Hour)
+}
+
+func (*Unrotated) Create(
+	ctx context.Context, name string, inputs CredentialArgs, preview bool,
+) (string, UnrotatedState, error) {
+	return name, UnrotatedState{inputs}, nil
+}
+
+func TestRotationSchema(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Credential, CredentialArgs, CredentialState]())
+	spec := schematest.Spec(t, server)
+	schematest.AssertProperty(t, spec, "test:index:Credential", "password", schematest.Required)
+	assert.NotContains(t, spec.Resources["test:index:Credential"].Properties, "rotationTimestamp")
+	assert.Contains(t, spec.Functions, "test:index:triggerRotation")
+
+	server = getterProvider(infer.Resource[*Bucket, BucketArgs, BucketState]())
+	spec = schematest.Spec(t, server)
+	assert.NotContains(t, spec.Functions, "test:index:triggerRotation")
+
+	server = getterProvider(infer.Resource[*Unrotated, CredentialArgs, UnrotatedState]())
+	_, err := server.GetSchema(p.GetSchemaRequest{})
+	assert.ErrorContains(t, err, "SetRotation requires tests.UnrotatedState to embed infer.Rotation")
+}
+
+func TestRotation(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Credential, CredentialArgs, CredentialState]())
+	inputs := resource.PropertyMap{"user": resource.NewStringProperty("admin")}
+
+	created, err := server.Create(p.CreateRequest{Urn: urn("Credential", "c"), Properties: inputs})
+	require.NoError(t, err)
+	ts := created.Properties["rotationTimestamp"]
+	require.True(t, ts.IsString(), "missing rotation timestamp")
+	createdAt, err := time.Parse(time.RFC3339, ts.StringValue())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), createdAt, time.Minute)
+
+	t.Run("within window", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Diff(p.DiffRequest{
+			ID: "c", Urn: urn("Credential", "c"), Olds: created.Properties, News: inputs,
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.HasChanges)
+	})
+
+	t.Run("window elapsed", func(t *testing.T) {
+		t.Parallel()
+		olds := created.Properties.Copy()
+		olds["rotationTimestamp"] = resource.NewStringProperty(
+			time.Now().Add(-25 * time.Hour).UTC().Format(time.RFC3339))
+		resp, err := server.Diff(p.DiffRequest{
+			ID: "c", Urn: urn("Credential", "c"), Olds: olds, News: inputs,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"rotationTimestamp": {Kind: p.UpdateReplace},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("update keeps timestamp", func(t *testing.T) {
+		t.Parallel()
+		updated, err := server.Update(p.UpdateRequest{
+			ID: "c", Urn: urn("Credential", "c"), Olds: created.Properties,
+			News: resource.PropertyMap{"user": resource.NewStringProperty("root")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ts, updated.Properties["rotationTimestamp"])
+	})
+
+	t.Run("triggered", func(t *testing.T) {
+		t.Parallel()
+		triggered := urn("Credential", "triggered")
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:triggerRotation",
+			Args:  resource.PropertyMap{"urn": resource.NewStringProperty(string(triggered))},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Failures)
+
+		diff, err := server.Diff(p.DiffRequest{
+			ID: "c", Urn: triggered, Olds: created.Properties, News: inputs,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"rotationTimestamp": {Kind: p.UpdateReplace},
+		}, diff.DetailedDiff)
+
+		// The rotation is only triggered once.
+		diff, err = server.Diff(p.DiffRequest{
+			ID: "c", Urn: triggered, Olds: created.Properties, News: inputs,
+		})
+		require.NoError(t, err)
+		assert.False(t, diff.HasChanges)
+	})
+
+	t.Run("invalid urn", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.Invoke(p.InvokeRequest{
+			Token: "test:index:triggerRotation",
+			Args:  resource.PropertyMap{"urn": resource.NewStringProperty("not-a-urn")},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Failures, 1)
+		assert.Equal(t, "urn", resp.Failures[0].Property)
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type (
+	Unserializable     struct{}
+	UnserializableArgs struct {
+		Name    string   `pulumi:"name"`
+		Updates chan int `pulumi:"updates"`
+	}
+)
+
+func (*Unserializable) Create(
+	ctx context.Context, name string, inputs UnserializableArgs, preview bool,
+) (string, UnserializableArgs, error) {
+	return name, inputs, nil
+}
+
+func TestSchemaErrorPositions(t *testing.T) {
+	t.Parallel()
+
+	getSchema := func(resources ...infer.InferredResource) error {
+		server := integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(infer.Options{
+			Resources: resources,
+		}))
+		_, err := server.GetSchema(p.GetSchemaRequest{})
+		return err
+	}
+
+	t.Run("field", func(t *testing.T) {
+		t.Parallel()
+		err := getSchema(infer.Resource[*Unserializable, UnserializableArgs, UnserializableArgs]())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(),
+			"invalid type 'chan int' on 'tests.UnserializableArgs.Updates' (at schema_position_test.go:34)")
+	})
+
+	t.Run("duplicate token", func(t *testing.T) {
+		t.Parallel()
+		err := getSchema(
+			infer.Resource[*Echo, EchoInputs, EchoOutputs](),
+			infer.Resource[*Echo, EchoInputs, EchoOutputs](),
+		)
+		require.Error(t, err)
+		assert.Regexp(t, `'test:tests:Echo' is defined more than once \(at provider_test\.go:\d+\)`, err.Error())
+	})
+}
@@ -0,0 +1,125 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Firewall     struct{}
+	FirewallArgs struct {
+		Tags  []string       `pulumi:"tags" provider:"set"`
+		Rules []FirewallRule `pulumi:"rules" provider:"setKey=port"`
+	}
+	FirewallRule struct {
+		Port int    `pulumi:"port"`
+		Name string `pulumi:"name"`
+	}
+	FirewallState struct{ FirewallArgs }
+)
+
+func (*Firewall) Create(
+	ctx context.Context, name string, input FirewallArgs, preview bool,
+) (string, FirewallState, error) {
+	return name, FirewallState{input}, nil
+}
+
+func (*Firewall) Update(
+	ctx context.Context, id string, olds FirewallState, news FirewallArgs, preview bool,
+) (FirewallState, error) {
+	return FirewallState{news}, nil
+}
+
+func TestSetDiff(t *testing.T) {
+	t.Parallel()
+
+	prov := getterProvider(infer.Resource[*Firewall, FirewallArgs, FirewallState]())
+	type m = resource.PropertyMap
+	rule := func(port float64, name string) resource.PropertyValue {
+		return resource.NewProperty(m{
+			"port": resource.NewProperty(port),
+			"name": resource.NewProperty(name),
+		})
+	}
+	firewall := func(tags []string, rules ...resource.PropertyValue) m {
+		t := make([]resource.PropertyValue, len(tags))
+		for i, tag := range tags {
+			t[i] = resource.NewProperty(tag)
+		}
+		return m{"tags": resource.NewProperty(t), "rules": resource.NewProperty(rules)}
+	}
+
+	// State holds sets sorted.
+	olds := firewall([]string{"a", "b"}, rule(80, "http"), rule(443, "https"))
+
+	t.Run("reordered", func(t *testing.T) {
+		t.Parallel()
+		resp, err := prov.Diff(p.DiffRequest{
+			ID:   "f",
+			Urn:  urn("Firewall", "f"),
+			Olds: olds,
+			News: firewall([]string{"b", "a"}, rule(443, "https"), rule(80, "http")),
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.DetailedDiff)
+	})
+
+	t.Run("changed element", func(t *testing.T) {
+		t.Parallel()
+		resp, err := prov.Diff(p.DiffRequest{
+			ID:   "f",
+			Urn:  urn("Firewall", "f"),
+			Olds: olds,
+			News: firewall([]string{"b", "a"}, rule(443, "tls"), rule(80, "http")),
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"rules[1].name": {Kind: p.Update},
+		}, resp.DetailedDiff)
+	})
+}
+
+type (
+	BadSetKey     struct{}
+	BadSetKeyArgs struct {
+		Ports []int `pulumi:"ports" provider:"setKey=port"`
+	}
+)
+
+func (*BadSetKey) Create(
+	ctx context.Context, name string, input BadSetKeyArgs, preview bool,
+) (string, BadSetKeyArgs, error) {
+	return name, input, nil
+}
+
+func TestSetKeySchema(t *testing.T) {
+	t.Parallel()
+
+	prov := getterProvider(infer.Resource[*BadSetKey, BadSetKeyArgs, BadSetKeyArgs]())
+	_, err := prov.GetSchema(p.GetSchemaRequest{})
+	assert.ErrorContains(t, err,
+		"`provider:\"setKey=port\"` requires a slice of structs with a \"port\" field, found []int")
+}
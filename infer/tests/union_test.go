@@ -0,0 +1,166 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type Cat struct {
+	Kind  string `pulumi:"kind"`
+	Lives int    `pulumi:"lives"`
+}
+
+func (Cat) Discriminator() (string, string) { return "kind", "cat" }
+
+type Dog struct {
+	Kind  string `pulumi:"kind"`
+	Breed string `pulumi:"breed"`
+}
+
+func (Dog) Discriminator() (string, string) { return "kind", "dog" }
+
+type Owner struct {
+	Name string `pulumi:"name"`
+}
+
+type Pet struct{}
+
+type PetArgs struct {
+	Pet       infer.Union[Cat, Dog]       `pulumi:"pet"`
+	CareTaker *infer.Union[Owner, string] `pulumi:"careTaker,optional"`
+}
+
+type PetState struct {
+	PetArgs
+	Description string `pulumi:"description"`
+}
+
+func (*Pet) Create(_ context.Context, _ string, input PetArgs, _ bool) (string, PetState, error) {
+	state := PetState{PetArgs: input}
+	switch {
+	case input.Pet.A != nil:
+		state.Description = "a cat"
+	case input.Pet.B != nil:
+		state.Description = "a " + input.Pet.B.Breed
+	}
+	return "id", state, nil
+}
+
+func TestUnion(t *testing.T) {
+	t.Parallel()
+
+	server := integration.NewServer("test", semver.MustParse("1.0.0"),
+		infer.Provider(infer.Options{
+			Resources: []infer.InferredResource{infer.Resource[*Pet, PetArgs, PetState]()},
+			ModuleMap: map[tokens.ModuleName]tokens.ModuleName{"tests": "index"},
+		}))
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	n := resource.NewNumberProperty
+	o := resource.NewObjectProperty
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec pschema.PackageSpec
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+
+		res := spec.Resources["test:index:Pet"]
+		assert.Equal(t, pschema.TypeSpec{
+			OneOf: []pschema.TypeSpec{
+				{Ref: "#/types/test:index:Cat"},
+				{Ref: "#/types/test:index:Dog"},
+			},
+			Discriminator: &pschema.DiscriminatorSpec{
+				PropertyName: "kind",
+				Mapping: map[string]string{
+					"cat": "#/types/test:index:Cat",
+					"dog": "#/types/test:index:Dog",
+				},
+			},
+		}, res.InputProperties["pet"].TypeSpec)
+		assert.Equal(t, pschema.TypeSpec{
+			OneOf: []pschema.TypeSpec{
+				{Ref: "#/types/test:index:Owner"},
+				{Type: "string"},
+			},
+		}, res.InputProperties["careTaker"].TypeSpec)
+
+		assert.Contains(t, spec.Types, "test:index:Cat")
+		assert.Contains(t, spec.Types, "test:index:Dog")
+		assert.Contains(t, spec.Types, "test:index:Owner")
+		assert.Len(t, spec.Types, 3)
+	})
+
+	create := func(t *testing.T, props m) m {
+		resp, err := server.Create(p.CreateRequest{
+			Urn:        resource.NewURN("stack", "proj", "", "test:index:Pet", "name"),
+			Properties: props,
+		})
+		require.NoError(t, err)
+		return resp.Properties
+	}
+
+	t.Run("discriminator", func(t *testing.T) {
+		t.Parallel()
+		dog := o(m{"kind": s("dog"), "breed": s("beagle")})
+		assert.Equal(t, m{
+			"pet":         dog,
+			"description": s("a beagle"),
+		}, create(t, m{"pet": dog}))
+	})
+
+	t.Run("shape", func(t *testing.T) {
+		t.Parallel()
+		cat := o(m{"kind": s("cat"), "lives": n(9)})
+		owner := o(m{"name": s("Alice")})
+		assert.Equal(t, m{
+			"pet":         cat,
+			"careTaker":   owner,
+			"description": s("a cat"),
+		}, create(t, m{"pet": cat, "careTaker": owner}))
+
+		assert.Equal(t, m{
+			"pet":         cat,
+			"careTaker":   s("Bob"),
+			"description": s("a cat"),
+		}, create(t, m{"pet": cat, "careTaker": s("Bob")}))
+	})
+
+	t.Run("secret-variant", func(t *testing.T) {
+		t.Parallel()
+		dog := o(m{"kind": s("dog"), "breed": resource.MakeSecret(s("poodle"))})
+		assert.Equal(t, m{
+			"pet":         dog,
+			"description": s("a poodle"),
+		}, create(t, m{"pet": dog}))
+	})
+}
@@ -0,0 +1,153 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Gadget     struct{}
+	GadgetArgs struct {
+		Size int `pulumi:"size"`
+	}
+	GadgetState struct {
+		GadgetArgs
+	}
+)
+
+func (*Gadget) Create(
+	ctx context.Context, name string, inputs GadgetArgs, preview bool,
+) (string, GadgetState, error) {
+	return name, GadgetState{inputs}, nil
+}
+
+func (*Gadget) Update(
+	ctx context.Context, id string, olds GadgetState, news GadgetArgs, preview bool,
+) (GadgetState, error) {
+	return GadgetState{news}, nil
+}
+
+func (*Gadget) Read(
+	ctx context.Context, id string, inputs GadgetArgs, state GadgetState,
+) (string, GadgetArgs, GadgetState, error) {
+	return id, inputs, state, nil
+}
+
+type CheckedGadget struct{ Gadget }
+
+func (*CheckedGadget) Check(
+	ctx context.Context, name string, olds, news resource.PropertyMap,
+) (GadgetArgs, []p.CheckFailure, error) {
+	return infer.DefaultCheck[GadgetArgs](ctx, news)
+}
+
+func gadgetInputs() resource.PropertyMap {
+	return resource.PropertyMap{
+		"size":  resource.NewNumberProperty(3),
+		"color": resource.NewStringProperty("red"),
+	}
+}
+
+func TestPreserveUnknownFieldsCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		typ      string
+		resource infer.InferredResource
+	}{
+		{"default", "Gadget", infer.Resource[*Gadget, GadgetArgs, GadgetState](infer.PreserveUnknownFields())},
+		{"custom", "CheckedGadget",
+			infer.Resource[*CheckedGadget, GadgetArgs, GadgetState](infer.PreserveUnknownFields())},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := getterProvider(tt.resource).Check(p.CheckRequest{
+				Urn:  urn(tt.typ, "g"),
+				News: gadgetInputs(),
+			})
+			require.NoError(t, err)
+			assert.Empty(t, resp.Failures)
+			assert.Equal(t, gadgetInputs(), resp.Inputs)
+		})
+	}
+}
+
+func TestUnknownFieldsFailCheck(t *testing.T) {
+	t.Parallel()
+
+	resp, err := getterProvider(infer.Resource[*Gadget, GadgetArgs, GadgetState]()).Check(p.CheckRequest{
+		Urn:  urn("Gadget", "g"),
+		News: gadgetInputs(),
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Failures, 1)
+	assert.Equal(t, "color", resp.Failures[0].Property)
+}
+
+func TestPreserveUnknownFieldsLifecycle(t *testing.T) {
+	t.Parallel()
+
+	server := getterProvider(infer.Resource[*Gadget, GadgetArgs, GadgetState](infer.PreserveUnknownFields()))
+	state := resource.PropertyMap{"size": resource.NewNumberProperty(3)}
+
+	create, err := server.Create(p.CreateRequest{
+		Urn:        urn("Gadget", "g"),
+		Properties: gadgetInputs(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, state, create.Properties)
+
+	diff, err := server.Diff(p.DiffRequest{
+		ID:   "g",
+		Urn:  urn("Gadget", "g"),
+		Olds: state,
+		News: gadgetInputs(),
+	})
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges)
+
+	news := gadgetInputs()
+	news["size"] = resource.NewNumberProperty(4)
+	update, err := server.Update(p.UpdateRequest{
+		ID:   "g",
+		Urn:  urn("Gadget", "g"),
+		Olds: state,
+		News: news,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{"size": resource.NewNumberProperty(4)}, update.Properties)
+
+	read, err := server.Read(p.ReadRequest{
+		ID:         "g",
+		Urn:        urn("Gadget", "g"),
+		Properties: state,
+		Inputs:     gadgetInputs(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, gadgetInputs(), read.Inputs)
+}
@@ -0,0 +1,93 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Pipe     struct{}
+	PipeArgs struct {
+		Source string `pulumi:"source"`
+		Label  string `pulumi:"label"`
+		Note   string `pulumi:"note"`
+	}
+	PipeState struct {
+		Target string `pulumi:"target"`
+		Added  string `pulumi:"added"`
+		Serial string `pulumi:"serial"`
+	}
+)
+
+func (a *PipeArgs) Annotate(an infer.Annotator) {
+	an.Unwired(&a.Note)
+}
+
+func (s *PipeState) Annotate(a infer.Annotator) {
+	a.Unwired(&s.Serial)
+}
+
+func (*Pipe) Create(ctx context.Context, name string, inputs PipeArgs, preview bool) (string, PipeState, error) {
+	return name, PipeState{Target: inputs.Source, Added: inputs.Label, Serial: "1"}, nil
+}
+
+func (*Pipe) WireDependencies(f infer.FieldSelector, args *PipeArgs, state *PipeState) {
+	f.OutputField(&state.Target).DependsOn(f.InputField(&args.Source))
+}
+
+// TestWarnUnwired replaces the default slog logger, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestWarnUnwired(t *testing.T) {
+	var out bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	server := getterProvider(infer.Resource[*Pipe, PipeArgs, PipeState]())
+	create := func() {
+		_, err := server.Create(p.CreateRequest{
+			Urn: urn("Pipe", "p"),
+			Properties: resource.PropertyMap{
+				"source": resource.NewStringProperty("s"),
+				"label":  resource.NewStringProperty("l"),
+				"note":   resource.NewStringProperty("n"),
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	create()
+	assert.Contains(t, out.String(), "level=WARN")
+	assert.Contains(t, out.String(), "does not wire the inputs label or the outputs added")
+	assert.NotContains(t, out.String(), "note")
+	assert.NotContains(t, out.String(), "serial")
+
+	// The warning is only logged once.
+	create()
+	assert.Equal(t, 1, strings.Count(out.String(), "WireDependencies"))
+}
@@ -0,0 +1,141 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+type (
+	Patched     struct{}
+	PatchedArgs struct {
+		Name string `pulumi:"name"`
+		Size int    `pulumi:"size"`
+	}
+	PatchedState struct {
+		PatchedArgs
+		// Patched lists the properties sent by the last update.
+		Patched []string `pulumi:"patched,optional"`
+	}
+)
+
+// patchedDiffs counts the calls to Patched.Diff.
+var patchedDiffs atomic.Int32
+
+func (*Patched) Create(
+	ctx context.Context, name string, inputs PatchedArgs, preview bool,
+) (string, PatchedState, error) {
+	return name, PatchedState{PatchedArgs: inputs}, nil
+}
+
+func (*Patched) Diff(ctx context.Context, id string, olds PatchedState, news PatchedArgs) (p.DiffResponse, error) {
+	patchedDiffs.Add(1)
+	diff := p.NewDetailedDiff()
+	if olds.Name != news.Name {
+		diff.Update("name")
+	}
+	if olds.Size != news.Size {
+		diff.Update("size")
+	}
+	return diff.Response(), nil
+}
+
+func (*Patched) Update(
+	ctx context.Context, id string, olds PatchedState, news PatchedArgs, preview bool,
+) (PatchedState, error) {
+	diff, err := infer.UpdateDiff(ctx)
+	if err != nil {
+		return olds, err
+	}
+	state := PatchedState{PatchedArgs: news, Patched: []string{}}
+	for k := range diff {
+		state.Patched = append(state.Patched, k)
+	}
+	slices.Sort(state.Patched)
+	return state, nil
+}
+
+//nolint:paralleltest // Uses patchedDiffs.
+func TestUpdateDiff(t *testing.T) {
+	server := getterProvider(infer.Resource[*Patched, PatchedArgs, PatchedState]())
+	olds := resource.PropertyMap{
+		"name": resource.NewStringProperty("a"),
+		"size": resource.NewNumberProperty(1),
+	}
+	news := resource.PropertyMap{
+		"name": resource.NewStringProperty("a"),
+		"size": resource.NewNumberProperty(2),
+	}
+	patched := resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("size")})
+
+	t.Run("reuses diff", func(t *testing.T) {
+		patchedDiffs.Store(0)
+		_, err := server.Diff(p.DiffRequest{ID: "a", Urn: urn("Patched", "reuse"), Olds: olds, News: news})
+		require.NoError(t, err)
+		resp, err := server.Update(p.UpdateRequest{ID: "a", Urn: urn("Patched", "reuse"), Olds: olds, News: news})
+		require.NoError(t, err)
+		assert.Equal(t, patched, resp.Properties["patched"])
+		assert.Equal(t, int32(1), patchedDiffs.Load())
+	})
+
+	t.Run("recomputes diff", func(t *testing.T) {
+		patchedDiffs.Store(0)
+		// The inputs have changed since the diff was computed.
+		_, err := server.Diff(p.DiffRequest{ID: "a", Urn: urn("Patched", "stale"), Olds: olds, News: olds})
+		require.NoError(t, err)
+		resp, err := server.Update(p.UpdateRequest{ID: "a", Urn: urn("Patched", "stale"), Olds: olds, News: news})
+		require.NoError(t, err)
+		assert.Equal(t, patched, resp.Properties["patched"])
+		assert.Equal(t, int32(2), patchedDiffs.Load())
+	})
+
+	t.Run("reuses diff once", func(t *testing.T) {
+		patchedDiffs.Store(0)
+		_, err := server.Diff(p.DiffRequest{ID: "a", Urn: urn("Patched", "once"), Olds: olds, News: news})
+		require.NoError(t, err)
+		for i := 0; i < 2; i++ {
+			_, err = server.Update(p.UpdateRequest{ID: "a", Urn: urn("Patched", "once"), Olds: olds, News: news})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(2), patchedDiffs.Load())
+	})
+
+	t.Run("evicts diff on create", func(t *testing.T) {
+		patchedDiffs.Store(0)
+		// A replacing diff is followed by a create, not an update.
+		_, err := server.Diff(p.DiffRequest{ID: "a", Urn: urn("Patched", "replace"), Olds: olds, News: news})
+		require.NoError(t, err)
+		_, err = server.Create(p.CreateRequest{Urn: urn("Patched", "replace"), Properties: news})
+		require.NoError(t, err)
+		_, err = server.Update(p.UpdateRequest{ID: "a", Urn: urn("Patched", "replace"), Olds: olds, News: news})
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), patchedDiffs.Load())
+	})
+
+	t.Run("outside update", func(t *testing.T) {
+		_, err := infer.UpdateDiff(context.Background())
+		assert.ErrorContains(t, err, "must be called from the Update method")
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestWriteOnly(t *testing.T) {
+	t.Parallel()
+
+	type m = resource.PropertyMap
+	s := resource.NewStringProperty
+	b := resource.NewBoolProperty
+
+	t.Run("create", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Create(p.CreateRequest{
+			Urn: urn("WriteOnly", "create"),
+			Properties: m{
+				"username":        s("admin"),
+				"initialPassword": resource.MakeSecret(s("hunter2")),
+				"token":           s("abc"),
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, m{
+			"username":    s("admin"),
+			"passwordSet": b(true),
+		}, resp.Properties)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Update(p.UpdateRequest{
+			ID:   "id",
+			Urn:  urn("WriteOnly", "update"),
+			Olds: m{"username": s("admin"), "passwordSet": b(true)},
+			News: m{"username": s("root"), "token": s("abc")},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, m{
+			"username":    s("root"),
+			"passwordSet": b(true),
+		}, resp.Properties)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().Diff(p.DiffRequest{
+			ID:   "id",
+			Urn:  urn("WriteOnly", "diff"),
+			Olds: m{"username": s("admin"), "passwordSet": b(true)},
+			News: m{
+				"username":        s("admin"),
+				"initialPassword": resource.MakeSecret(s("changed")),
+				"token":           s("abc"),
+			},
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.DetailedDiff)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := provider().GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		var spec struct {
+			Resources map[string]struct {
+				Properties      map[string]any `json:"properties"`
+				InputProperties map[string]any `json:"inputProperties"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		res := spec.Resources["test:index:WriteOnly"]
+		assert.Contains(t, res.InputProperties, "initialPassword")
+		assert.Contains(t, res.InputProperties, "token")
+		assert.NotContains(t, res.Properties, "initialPassword")
+		assert.NotContains(t, res.Properties, "token")
+		assert.Contains(t, res.Properties, "username")
+	})
+}
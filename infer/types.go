@@ -24,6 +24,7 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
 	"github.com/pulumi/pulumi-go-provider/infer/types"
 	"github.com/pulumi/pulumi-go-provider/internal/introspect"
 	"github.com/pulumi/pulumi-go-provider/middleware/schema"
@@ -156,8 +157,11 @@ type Crawler func(
 // crawlTypes recursively crawls T, calling the crawler on each new type it finds.
 func crawlTypes[T any](crawler Crawler) error {
 	var i T
-	t := reflect.TypeOf(i)
+	return crawlTypesOf(reflect.TypeOf(i), crawler)
+}
 
+// crawlTypesOf is [crawlTypes] for a type only known at runtime.
+func crawlTypesOf(t reflect.Type, crawler Crawler) error {
 	// Prohibit top-level "id" or "urn" fields.
 	if t.Kind() == reflect.Struct {
 		for _, f := range reflect.VisibleFields(t) {
@@ -174,6 +178,10 @@ func crawlTypes[T any](crawler Crawler) error {
 	// Drill will walk the types, calling crawl on types it finds.
 	var drill func(reflect.Type, bool, *introspect.FieldTag) error
 	drill = func(t reflect.Type, isReference bool, fieldInfo *introspect.FieldTag) error {
+		if _, ok := ende.CodecFor(t); ok {
+			// Values with a codec are represented by the codec's type.
+			return nil
+		}
 		nT, inputty, err := underlyingType(t)
 		if err != nil {
 			return err
@@ -206,6 +214,9 @@ func crawlTypes[T any](crawler Crawler) error {
 
 				typ := f.Type
 				for done := false; !done; {
+					if _, ok := ende.CodecFor(typ); ok {
+						continue field
+					}
 					switch typ.Kind() {
 					case reflect.Pointer, reflect.Array, reflect.Map, reflect.Slice:
 						// Could hold a reference to other types
@@ -247,6 +258,12 @@ func crawlTypes[T any](crawler Crawler) error {
 
 // registerTypes recursively examines fields of T, calling reg on the schematized type when appropriate.
 func registerTypes[T any](reg schema.RegisterDerivativeType) error {
+	var i T
+	return registerTypesOf(reflect.TypeOf(i), reg)
+}
+
+// registerTypesOf is [registerTypes] for a type only known at runtime.
+func registerTypesOf(typ reflect.Type, reg schema.RegisterDerivativeType) error {
 	crawler := func(
 		t reflect.Type, isReference bool, info *introspect.FieldTag,
 		parent, field string,
@@ -264,6 +281,10 @@ func registerTypes[T any](reg schema.RegisterDerivativeType) error {
 		if t == reflect.TypeOf(types.AssetOrArchive{}) {
 			return false, nil
 		}
+		// Unions are not types in the schema, but their variants might be.
+		if _, ok := ende.UnionVariants(t); ok {
+			return true, nil
+		}
 		if enum, ok := isEnum(t); ok {
 			if info != nil && info.Optional && !isReference {
 				return false, optionalNeedsPointerError{
@@ -309,11 +330,16 @@ func registerTypes[T any](reg schema.RegisterDerivativeType) error {
 				}
 			}
 
-			return reg(tk, pschema.ComplexTypeSpec{ObjectTypeSpec: *spec}), nil
+			typ := pschema.ComplexTypeSpec{ObjectTypeSpec: *spec}
+			if !reg(tk, typ) {
+				return false, nil
+			}
+			registerTypeAliases(reg, t, tk, typ)
+			return true, nil
 		}
 		return true, nil
 	}
-	return crawlTypes[T](crawler)
+	return crawlTypesOf(typ, crawler)
 }
 
 type optionalNeedsPointerError struct {
@@ -0,0 +1,71 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"reflect"
+
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+)
+
+// Union is a value that is either an A or a B. Exactly one of A and B is non-nil.
+//
+// Union properties are projected into the schema as a `oneOf` type. Unions of more than
+// two types can be expressed by nesting: Union[A, Union[B, C]].
+//
+// When decoding, the first variant that the value matches is chosen. A struct variant
+// matches an object when every key of the object is a property of the struct and every
+// required property is present. Variants can instead implement [Discriminated] to be
+// selected by the value of a property:
+//
+//	type Cat struct {
+//		Kind  string `pulumi:"kind"`
+//		Lives int    `pulumi:"lives"`
+//	}
+//
+//	func (Cat) Discriminator() (string, string) { return "kind", "cat" }
+//
+//	type Dog struct {
+//		Kind  string `pulumi:"kind"`
+//		Breed string `pulumi:"breed"`
+//	}
+//
+//	func (Dog) Discriminator() (string, string) { return "kind", "dog" }
+//
+//	type PetArgs struct {
+//		Pet infer.Union[Cat, Dog] `pulumi:"pet"`
+//	}
+type Union[A, B any] struct {
+	A *A `pulumi:"14241ebf4485779f2e2801abee70c4c6,optional"`
+	B *B `pulumi:"7c9d7083ba42db7aa0c2fb74039a9536,optional"`
+}
+
+// UnionVariants returns the types of A and B.
+//
+// UnionVariants is used by infer to recognize union types.
+func (Union[A, B]) UnionVariants() []reflect.Type {
+	return []reflect.Type{typeFor[A](), typeFor[B]()}
+}
+
+// Discriminated can be implemented by the variants of a [Union] to be selected by the
+// value of a property, instead of by their shape.
+//
+// Discriminator is called on the zero value of the variant. It returns the name of the
+// discriminating property and the value that identifies this variant.
+type Discriminated interface {
+	Discriminator() (property, value string)
+}
+
+var _ ende.Union = Union[int, string]{}
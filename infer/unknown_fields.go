@@ -0,0 +1,104 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/mapper"
+
+	"github.com/pulumi/pulumi-go-provider/infer/internal/ende"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// PreserveUnknownFields passes top-level inputs that are not declared on the resource's
+// inputs through Check, instead of reporting them as check failures.
+//
+// This keeps an older provider working with programs written against a newer SDK, which
+// may send inputs the provider doesn't know about yet. Unknown inputs are kept in the
+// checked inputs, including by [DefaultCheck], but they are not visible to the resource:
+// they are ignored when decoding inputs and never cause a diff.
+func PreserveUnknownFields() ResourceOption {
+	return func(o *resourceOptions) { o.preserveUnknown = true }
+}
+
+type preserveUnknownFieldsKey struct{}
+
+// withResourceOptions records the options of a resource that need to reach shared code
+// paths, such as [check] and [DefaultCheck], in ctx.
+func withResourceOptions(ctx context.Context, opts resourceOptions) context.Context {
+	if !opts.preserveUnknown {
+		return ctx
+	}
+	return context.WithValue(ctx, preserveUnknownFieldsKey{}, true)
+}
+
+func preservesUnknownFields(ctx context.Context) bool {
+	preserve, _ := ctx.Value(preserveUnknownFieldsKey{}).(bool)
+	return preserve
+}
+
+// decodeInputs decodes m into I, ignoring unknown fields if the resource asked for it
+// with [PreserveUnknownFields].
+func decodeInputs[I any](ctx context.Context, m resource.PropertyMap) (ende.Encoder, I, mapper.MappingError) {
+	enc, i, err := ende.Decode[I](knownFields[I](ctx, m))
+	logRemoved(ctx, typeFor[I](), enc)
+	return enc, i, err
+}
+
+// knownFields returns m without its unknown fields, if the resource asked for it with
+// [PreserveUnknownFields].
+func knownFields[I any](ctx context.Context, m resource.PropertyMap) resource.PropertyMap {
+	if !preservesUnknownFields(ctx) {
+		return m
+	}
+	known, _ := splitFields[I](m)
+	return known
+}
+
+// splitFields splits m into the fields that are declared as properties of I and those
+// that are not.
+func splitFields[I any](m resource.PropertyMap) (known, unknown resource.PropertyMap) {
+	props, err := introspect.FindProperties(typeFor[I]())
+	contract.AssertNoErrorf(err, "inputs have already been validated by schema generation")
+	known, unknown = resource.PropertyMap{}, resource.PropertyMap{}
+	for k, v := range m {
+		if _, ok := props[string(k)]; ok {
+			known[k] = v
+		} else {
+			unknown[k] = v
+		}
+	}
+	return known, unknown
+}
+
+// restoreUnknownFields copies the unknown fields of from into to, if the resource asked
+// for it with [PreserveUnknownFields].
+func restoreUnknownFields[I any](ctx context.Context, from, to resource.PropertyMap) resource.PropertyMap {
+	if !preservesUnknownFields(ctx) {
+		return to
+	}
+	_, unknown := splitFields[I](from)
+	if len(unknown) == 0 {
+		return to
+	}
+	to = to.Copy()
+	for k, v := range unknown {
+		to[k] = v
+	}
+	return to
+}
@@ -0,0 +1,91 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// warnedUnwired records the resources that have already been checked by [warnUnwired].
+var warnedUnwired sync.Map
+
+// warnUnwired logs the fields of I and O that the WireDependencies of R left unwired.
+//
+// Wiring depends on the values passed to WireDependencies, so this can't be checked ahead
+// of time. It is checked on the first wiring of each resource instead.
+func warnUnwired[R, I, O any](ctx context.Context, g *fieldGenerator) {
+	if _, checked := warnedUnwired.LoadOrStore(typeFor[R](), struct{}{}); checked {
+		return
+	}
+	inputs, outputs := g.unwired(
+		getAnnotated(typeFor[I]()).UnwiredFields,
+		getAnnotated(typeFor[O]()).UnwiredFields,
+	)
+	var unwired []string
+	if len(inputs) > 0 {
+		unwired = append(unwired, "inputs "+strings.Join(inputs, ", "))
+	}
+	if len(outputs) > 0 {
+		unwired = append(unwired, "outputs "+strings.Join(outputs, ", "))
+	}
+	if len(unwired) == 0 {
+		return
+	}
+	p.GetLogger(ctx).Warningf("%s: WireDependencies does not wire the %s, so their secretness "+
+		"and computedness don't flow; mark fields that are unwired on purpose with Annotator.Unwired",
+		typeFor[R](), strings.Join(unwired, " or the "))
+}
+
+// unwired returns the input fields that no output depends on, and the output fields that
+// have no wiring, excluding the fields marked as unwired.
+func (g *fieldGenerator) unwired(inputsOK, outputsOK map[string]bool) (inputs, outputs []string) {
+	used := map[string]bool{}
+	for _, f := range g.fields {
+		for _, dep := range f.deps {
+			used[dep.name] = true
+		}
+	}
+
+	fields := func(matcher introspect.FieldMatcher, v any) []introspect.FieldTag {
+		fields, ok, err := matcher.TargetStructFields(v)
+		if !ok || err != nil {
+			return nil
+		}
+		return fields
+	}
+	for _, f := range fields(g.argsMatcher, g.args) {
+		if !f.Internal && !used[f.Name] && !inputsOK[f.Name] {
+			inputs = append(inputs, f.Name)
+		}
+	}
+	for _, f := range fields(g.stateMatcher, g.state) {
+		if f.Internal || outputsOK[f.Name] {
+			continue
+		}
+		if w, ok := g.fields[f.Name]; !ok ||
+			(len(w.deps) == 0 && !w.alwaysSecret && !w.neverSecret && !w.known) {
+			outputs = append(outputs, f.Name)
+		}
+	}
+	sort.Strings(inputs)
+	sort.Strings(outputs)
+	return inputs, outputs
+}
@@ -0,0 +1,103 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// UpdateDiff returns the detailed diff of the resource being updated, keyed by property
+// path as in [p.DiffResponse.DetailedDiff].
+//
+// It lets Update send only the fields that changed to the upstream API, without deriving
+// the diff again:
+//
+//	func (*Bucket) Update(ctx context.Context, id string, olds BucketState, news BucketArgs,
+//		preview bool) (BucketState, error) {
+//		diff, err := infer.UpdateDiff(ctx)
+//		if err != nil {
+//			return olds, err
+//		}
+//		patch := api.BucketPatch{}
+//		if _, ok := diff["versioning"]; ok {
+//			patch.Versioning = &news.Versioning
+//		}
+//		...
+//	}
+//
+// The diff computed by Diff earlier in the same deployment is reused when it was computed
+// from the same state and inputs. Otherwise the diff is computed again, in the same way
+// Diff computes it: with [CustomDiff] if the resource implements it.
+//
+// UpdateDiff must be called from the Update method of a resource served by [Resource].
+// Otherwise it returns an error.
+func UpdateDiff(ctx context.Context) (map[string]p.PropertyDiff, error) {
+	get, ok := ctx.Value(updateDiffKey{}).(func() (p.DiffResponse, error))
+	if !ok {
+		return nil, errors.New("UpdateDiff must be called from the Update method of a resource")
+	}
+	resp, err := get()
+	return resp.DetailedDiff, err
+}
+
+type updateDiffKey struct{}
+
+// withUpdateDiff makes the diff of an update available to [UpdateDiff]. compute is only
+// called if the diff is needed, and at most once.
+func withUpdateDiff(ctx context.Context, compute func() (p.DiffResponse, error)) context.Context {
+	return context.WithValue(ctx, updateDiffKey{}, sync.OnceValues(compute))
+}
+
+// diffCache holds the last diff computed for each resource, so it can be reused by the
+// update that follows it.
+//
+// A diff that is not followed by an update, such as one that replaces the resource, is
+// evicted by the next Create, Read or Delete of the resource.
+type diffCache struct{ m sync.Map }
+
+type cachedDiff struct {
+	olds, news resource.PropertyMap
+	resp       p.DiffResponse
+}
+
+// store records resp as the diff of olds and news for urn.
+func (c *diffCache) store(urn resource.URN, olds, news resource.PropertyMap, resp p.DiffResponse) {
+	c.m.Store(urn, cachedDiff{olds.Copy(), news.Copy(), resp})
+}
+
+// load returns the diff recorded for urn, if it was computed from olds and news. The
+// recorded diff is removed whether or not it matches, so it is only ever returned once.
+func (c *diffCache) load(urn resource.URN, olds, news resource.PropertyMap) (p.DiffResponse, bool) {
+	v, ok := c.m.LoadAndDelete(urn)
+	if !ok {
+		return p.DiffResponse{}, false
+	}
+	cached := v.(cachedDiff)
+	if !cached.olds.DeepEquals(olds) || !cached.news.DeepEquals(news) {
+		return p.DiffResponse{}, false
+	}
+	return cached.resp, true
+}
+
+// evict removes the diff recorded for urn, if any.
+func (c *diffCache) evict(urn resource.URN) {
+	c.m.Delete(urn)
+}
@@ -0,0 +1,68 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
+)
+
+// writeOnlyProperties returns the top level properties of I that are write-only, either
+// through the `provider:"writeOnly"` tag or [Annotator.WriteOnly].
+//
+// Write-only properties are omitted from state, so they must be optional for the state
+// to be decoded back into the output type.
+func writeOnlyProperties[I any]() (map[resource.PropertyKey]struct{}, error) {
+	typ := typeFor[I]()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	props, err := introspect.FindProperties(typ)
+	if err != nil {
+		return nil, err
+	}
+	annotated := getAnnotated(typ).WriteOnlyFields
+
+	writeOnly := map[resource.PropertyKey]struct{}{}
+	for name, prop := range props {
+		if !prop.WriteOnly && !annotated[name] {
+			continue
+		}
+		if !prop.Optional {
+			return nil, fmt.Errorf("write-only property %q must be optional", name)
+		}
+		writeOnly[resource.PropertyKey(name)] = struct{}{}
+	}
+	return writeOnly, nil
+}
+
+// stripWriteOnly removes the write-only properties of I from m.
+func stripWriteOnly[I any](m resource.PropertyMap) error {
+	writeOnly, err := writeOnlyProperties[I]()
+	if err != nil {
+		return err
+	}
+	for k := range writeOnly {
+		delete(m, k)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	mwrpc "github.com/pulumi/pulumi-go-provider/middleware/rpc"
+)
+
+// ServerOption configures a [Server] created by [NewServer].
+type ServerOption func(*engine)
+
+// engine describes the engine simulated by a [Server].
+type engine struct {
+	// simulate is set when any option is given. The provider is then served over gRPC,
+	// so requests and responses are marshaled as they are for a real engine.
+	simulate      bool
+	acceptSecrets bool
+	capabilities  *p.Capabilities
+}
+
+// WithoutSecrets simulates an engine that doesn't accept secrets, such as versions of the
+// Pulumi CLI before 2.0.0.
+//
+// The provider is told so in Configure, and returns secret values as plain values from
+// then on.
+func WithoutSecrets() ServerOption {
+	return func(e *engine) {
+		e.simulate = true
+		e.acceptSecrets = false
+	}
+}
+
+// WithConfigureResponse makes the simulated engine act as if the provider advertised
+// capabilities in response to Configure, instead of the provider's own.
+//
+// This exercises the down-grades the engine makes for providers with fewer
+// capabilities: secrets are sent as plain values without AcceptSecrets, output values
+// are flattened without AcceptOutputs, and Create and Update are not called during
+// preview without SupportsPreview.
+func WithConfigureResponse(capabilities p.Capabilities) ServerOption {
+	return func(e *engine) {
+		e.simulate = true
+		e.capabilities = &capabilities
+	}
+}
+
+// wrap serves provider over gRPC to the simulated engine, if there is one.
+//
+// The engine learns the provider's capabilities from Configure, so tests should call
+// Configure before other requests, as the engine does. Construct and Parameterize are not
+// supported over gRPC here, so they are always sent to provider directly.
+func (e engine) wrap(pkg, version string, provider p.Provider) p.Provider {
+	if !e.simulate {
+		return provider
+	}
+	server, err := p.RawServer(pkg, version, provider)(nil)
+	contract.AssertNoErrorf(err, "failed to serve the provider over gRPC")
+	wrapped := mwrpc.Provider(&engineServer{server, e})
+	wrapped.Construct = provider.Construct
+	wrapped.Parameterize = provider.Parameterize
+	return wrapped
+}
+
+// engineServer negotiates the simulated engine's features in Configure.
+type engineServer struct {
+	rpc.ResourceProviderServer
+	engine engine
+}
+
+func (s *engineServer) Configure(
+	ctx context.Context, req *rpc.ConfigureRequest,
+) (*rpc.ConfigureResponse, error) {
+	req.AcceptSecrets = s.engine.acceptSecrets
+	resp, err := s.ResourceProviderServer.Configure(ctx, req)
+	if err != nil || s.engine.capabilities == nil {
+		return resp, err
+	}
+	c := s.engine.capabilities
+	return &rpc.ConfigureResponse{
+		AcceptSecrets:                   c.AcceptSecrets,
+		AcceptResources:                 c.AcceptResources,
+		AcceptOutputs:                   c.AcceptOutputs,
+		SupportsPreview:                 c.SupportsPreview,
+		SupportsAutonamingConfiguration: c.SupportsAutonamingConfiguration,
+	}, nil
+}
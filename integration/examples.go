@@ -0,0 +1,300 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Example is a minimal program that creates a single resource.
+//
+// Examples are generated from a provider's schema by [Examples]. They set each required
+// input, using the input's default or constant value when the schema has one, so that
+// every resource has at least one program that exercises it end to end.
+type Example struct {
+	// The token of the resource the example creates.
+	Resource tokens.Type
+	// The inputs of the resource.
+	Inputs presource.PropertyMap
+}
+
+// Examples generates an [Example] for each custom resource in the schema of server.
+//
+// Inputs that can't be generated, such as references to types from other packages, are
+// reported in the returned error. Examples are still returned for every other resource.
+func Examples(server Server) (map[tokens.Type]Example, error) {
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	if err != nil {
+		return nil, err
+	}
+	var spec pschema.PackageSpec
+	if err := json.Unmarshal([]byte(resp.Schema), &spec); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return ExamplesFromSchema(spec)
+}
+
+// ExamplesFromSchema generates an [Example] for each custom resource in spec.
+//
+// See [Examples] for details.
+func ExamplesFromSchema(spec pschema.PackageSpec) (map[tokens.Type]Example, error) {
+	examples := map[tokens.Type]Example{}
+	var errs []error
+	for tk, r := range spec.Resources {
+		if r.IsComponent {
+			continue
+		}
+		g := exampleGenerator{spec: spec, visiting: map[string]bool{}}
+		inputs, err := g.object(r.InputProperties, r.RequiredInputs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tk, err))
+			continue
+		}
+		examples[tokens.Type(tk)] = Example{
+			Resource: tokens.Type(tk),
+			Inputs:   inputs,
+		}
+	}
+	return examples, errors.Join(errs...)
+}
+
+// YAML renders e as a Pulumi YAML program.
+//
+// The program can be translated to other languages with `pulumi convert`:
+//
+//	pulumi convert --from yaml --language go --out example-go
+func (e Example) YAML() (string, error) {
+	name := e.Resource.Name().String()
+	type resource struct {
+		Type       string         `yaml:"type"`
+		Properties map[string]any `yaml:"properties,omitempty"`
+	}
+	program := struct {
+		Name      string              `yaml:"name"`
+		Runtime   string              `yaml:"runtime"`
+		Resources map[string]resource `yaml:"resources"`
+	}{
+		Name:    "example-" + strings.ToLower(name),
+		Runtime: "yaml",
+		Resources: map[string]resource{
+			strings.ToLower(name[:1]) + name[1:]: {
+				Type:       e.Resource.String(),
+				Properties: yamlObject(e.Inputs),
+			},
+		},
+	}
+	out, err := yaml.Marshal(program)
+	return string(out), err
+}
+
+// LifeCycleTest returns a test that creates and then deletes the resource of e.
+func (e Example) LifeCycleTest() LifeCycleTest {
+	return LifeCycleTest{
+		Resource: e.Resource,
+		Create:   Operation{Inputs: e.Inputs.Copy()},
+	}
+}
+
+// RunExamples runs the [LifeCycleTest] of each of the [Examples] of server as a subtest
+// named after the resource.
+//
+// Resources that an example could not be generated for fail the test.
+func RunExamples(t *testing.T, server Server) {
+	t.Helper()
+	examples, err := Examples(server)
+	if examples == nil {
+		require.NoError(t, err, "failed to generate examples")
+	}
+	if err != nil {
+		t.Errorf("failed to generate examples: %v", err)
+	}
+
+	resources := make([]string, 0, len(examples))
+	for tk := range examples {
+		resources = append(resources, string(tk))
+	}
+	sort.Strings(resources)
+	for _, tk := range resources {
+		example := examples[tokens.Type(tk)]
+		t.Run(tk, func(t *testing.T) {
+			example.LifeCycleTest().Run(t, server)
+		})
+	}
+}
+
+// exampleGenerator generates example values from a schema.
+type exampleGenerator struct {
+	spec pschema.PackageSpec
+	// The object types being generated, which can't be used again without recursing
+	// forever.
+	visiting map[string]bool
+}
+
+// object generates the required properties of an object.
+func (g exampleGenerator) object(
+	props map[string]pschema.PropertySpec, required []string,
+) (presource.PropertyMap, error) {
+	m := presource.PropertyMap{}
+	for _, name := range required {
+		prop, ok := props[name]
+		if !ok {
+			return nil, fmt.Errorf("required property %q is not defined", name)
+		}
+		v, err := g.property(prop)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		m[presource.PropertyKey(name)] = v
+	}
+	return m, nil
+}
+
+func (g exampleGenerator) property(prop pschema.PropertySpec) (presource.PropertyValue, error) {
+	switch {
+	case prop.Const != nil:
+		return presource.NewPropertyValue(prop.Const), nil
+	case prop.Default != nil:
+		return presource.NewPropertyValue(prop.Default), nil
+	}
+	return g.value(prop.TypeSpec)
+}
+
+func (g exampleGenerator) value(typ pschema.TypeSpec) (presource.PropertyValue, error) {
+	if len(typ.OneOf) > 0 {
+		return g.value(typ.OneOf[0])
+	}
+	if typ.Ref != "" {
+		return g.ref(typ.Ref)
+	}
+	switch typ.Type {
+	case "string":
+		return presource.NewStringProperty("example"), nil
+	case "integer", "number":
+		return presource.NewNumberProperty(1), nil
+	case "boolean":
+		return presource.NewBoolProperty(true), nil
+	case "array":
+		if typ.Items == nil {
+			return presource.NewArrayProperty(nil), nil
+		}
+		el, err := g.value(*typ.Items)
+		if err != nil {
+			return presource.PropertyValue{}, err
+		}
+		return presource.NewArrayProperty([]presource.PropertyValue{el}), nil
+	case "object":
+		if typ.AdditionalProperties == nil {
+			return presource.NewObjectProperty(presource.PropertyMap{}), nil
+		}
+		el, err := g.value(*typ.AdditionalProperties)
+		if err != nil {
+			return presource.PropertyValue{}, err
+		}
+		return presource.NewObjectProperty(presource.PropertyMap{"example": el}), nil
+	default:
+		return presource.PropertyValue{}, fmt.Errorf("unknown type %q", typ.Type)
+	}
+}
+
+func (g exampleGenerator) ref(ref string) (presource.PropertyValue, error) {
+	switch ref {
+	case "pulumi.json#/Any":
+		return presource.NewStringProperty("example"), nil
+	case "pulumi.json#/Json":
+		return presource.NewObjectProperty(presource.PropertyMap{}), nil
+	case "pulumi.json#/Asset":
+		return presource.NewAssetProperty(&presource.Asset{Text: "example"}), nil
+	case "pulumi.json#/Archive":
+		return presource.NewArchiveProperty(&presource.Archive{Assets: map[string]any{
+			"example.txt": &presource.Asset{Text: "example"},
+		}}), nil
+	}
+
+	tk, ok := strings.CutPrefix(ref, "#/types/")
+	if !ok {
+		return presource.PropertyValue{}, fmt.Errorf("cannot generate a value for %q", ref)
+	}
+	typ, ok := g.spec.Types[tk]
+	if !ok {
+		return presource.PropertyValue{}, fmt.Errorf("type %q is not defined", tk)
+	}
+	if len(typ.Enum) > 0 {
+		return presource.NewPropertyValue(typ.Enum[0].Value), nil
+	}
+	if g.visiting[tk] {
+		return presource.PropertyValue{}, fmt.Errorf("type %q requires itself", tk)
+	}
+	g.visiting[tk] = true
+	defer delete(g.visiting, tk)
+	m, err := g.object(typ.Properties, typ.Required)
+	if err != nil {
+		return presource.PropertyValue{}, fmt.Errorf("%s: %w", tk, err)
+	}
+	return presource.NewObjectProperty(m), nil
+}
+
+// yamlObject converts m to the values of a Pulumi YAML program.
+func yamlObject(m presource.PropertyMap) map[string]any {
+	if len(m) == 0 {
+		return nil
+	}
+	obj := make(map[string]any, len(m))
+	for k, v := range m {
+		obj[string(k)] = yamlValue(v)
+	}
+	return obj
+}
+
+func yamlValue(v presource.PropertyValue) any {
+	switch {
+	case v.IsAsset():
+		return map[string]any{"fn::stringAsset": v.AssetValue().Text}
+	case v.IsArchive():
+		assets := map[string]any{}
+		for name, a := range v.ArchiveValue().Assets {
+			if asset, ok := a.(*presource.Asset); ok {
+				assets[name] = map[string]any{"fn::stringAsset": asset.Text}
+			}
+		}
+		return map[string]any{"fn::assetArchive": assets}
+	case v.IsArray():
+		arr := make([]any, len(v.ArrayValue()))
+		for i, el := range v.ArrayValue() {
+			arr[i] = yamlValue(el)
+		}
+		return arr
+	case v.IsObject():
+		obj := yamlObject(v.ObjectValue())
+		if obj == nil {
+			return map[string]any{}
+		}
+		return obj
+	default:
+		return v.Mappable()
+	}
+}
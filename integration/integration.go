@@ -21,6 +21,7 @@ package integration
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/blang/semver"
@@ -29,11 +30,14 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal"
 	"github.com/pulumi/pulumi-go-provider/internal/key"
 )
 
 type Server interface {
 	GetSchema(p.GetSchemaRequest) (p.GetSchemaResponse, error)
+	Parameterize(p.ParameterizeRequest) (p.ParameterizeResponse, error)
+	GetMapping(p.GetMappingRequest) (p.GetMappingResponse, error)
 	Cancel() error
 	CheckConfig(p.CheckRequest) (p.CheckResponse, error)
 	DiffConfig(p.DiffRequest) (p.DiffResponse, error)
@@ -44,35 +48,74 @@ type Server interface {
 	Create(p.CreateRequest) (p.CreateResponse, error)
 	Read(p.ReadRequest) (p.ReadResponse, error)
 	Update(p.UpdateRequest) (p.UpdateResponse, error)
-	Delete(p.DeleteRequest) error
+	Delete(p.DeleteRequest) (p.DeleteResponse, error)
 	Construct(p.ConstructRequest) (p.ConstructResponse, error)
 }
 
-func NewServer(pkg string, version semver.Version, provider p.Provider) Server {
-	return NewServerWithContext(context.Background(), pkg, version, provider)
+// NewServer creates a [Server] that sends requests directly to provider.
+//
+// Options simulate an engine with fewer features than the current Pulumi CLI, such as
+// [WithoutSecrets] and [WithConfigureResponse]. Requests are then sent over gRPC, as they
+// are by the engine.
+func NewServer(pkg string, version semver.Version, provider p.Provider, opts ...ServerOption) Server {
+	return NewServerWithContext(context.Background(), pkg, version, provider, opts...)
 }
 
-func NewServerWithContext(ctx context.Context, pkg string, version semver.Version, provider p.Provider) Server {
-	return &server{p.RunInfo{
-		PackageName: pkg,
-		Version:     version.String(),
-	}, provider.WithDefaults(), ctx}
+// NewServerWithContext is like [NewServer], but each request is made with a context
+// derived from ctx.
+func NewServerWithContext(
+	ctx context.Context, pkg string, version semver.Version, provider p.Provider, opts ...ServerOption,
+) Server {
+	e := engine{acceptSecrets: true}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return &server{
+		runInfo: p.RunInfo{
+			PackageName: pkg,
+			Version:     version.String(),
+		},
+		p:       e.wrap(pkg, version.String(), provider).WithDefaults(),
+		context: ctx,
+	}
 }
 
 type server struct {
+	m       sync.Mutex // Guards runInfo, which is updated on Configure.
 	runInfo p.RunInfo
 	p       p.Provider
 	context context.Context
 }
 
-func (s *server) ctx(presource.URN) context.Context {
-	return context.WithValue(s.context, key.RuntimeInfo, s.runInfo)
+func (s *server) ctx(urn presource.URN) context.Context {
+	ctx := s.context
+	if urn.IsValid() {
+		ctx = context.WithValue(ctx, key.URN, urn)
+		ctx = context.WithValue(ctx, key.Token, urn.Type())
+	}
+	if s.p.LogHandler != nil {
+		ctx = context.WithValue(ctx, key.LogHandler, s.p.LogHandler)
+	}
+	if len(s.p.FrameworkLog) > 0 {
+		ctx = context.WithValue(ctx, key.FrameworkLog, s.p.FrameworkLog)
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	return context.WithValue(ctx, key.RuntimeInfo, s.runInfo)
 }
 
 func (s *server) GetSchema(req p.GetSchemaRequest) (p.GetSchemaResponse, error) {
 	return s.p.GetSchema(s.ctx(""), req)
 }
 
+func (s *server) Parameterize(req p.ParameterizeRequest) (p.ParameterizeResponse, error) {
+	return s.p.Parameterize(s.ctx(""), req)
+}
+
+func (s *server) GetMapping(req p.GetMappingRequest) (p.GetMappingResponse, error) {
+	return s.p.GetMapping(s.ctx(""), req)
+}
+
 func (s *server) Cancel() error {
 	return s.p.Cancel(s.ctx(""))
 }
@@ -86,11 +129,26 @@ func (s *server) DiffConfig(req p.DiffRequest) (p.DiffResponse, error) {
 }
 
 func (s *server) Configure(req p.ConfigureRequest) error {
+	// The engine accepts secrets and resource references, so the provider's own
+	// capabilities are in effect.
+	capabilities := p.DefaultCapabilities()
+	if s.p.Capabilities != nil {
+		capabilities = *s.p.Capabilities
+	}
+	s.m.Lock()
+	s.runInfo.Configured = true
+	s.runInfo.Capabilities = capabilities
+	s.runInfo.MarshalOptions = p.MarshalOptions{
+		KeepSecrets:      true,
+		KeepResources:    capabilities.AcceptResources,
+		KeepOutputValues: capabilities.AcceptOutputs,
+	}
+	s.m.Unlock()
 	return s.p.Configure(s.ctx(""), req)
 }
 
 func (s *server) Invoke(req p.InvokeRequest) (p.InvokeResponse, error) {
-	return s.p.Invoke(s.ctx(presource.URN(req.Token)), req)
+	return s.p.Invoke(context.WithValue(s.ctx(""), key.Token, req.Token), req)
 }
 
 func (s *server) Check(req p.CheckRequest) (p.CheckResponse, error) {
@@ -102,7 +160,7 @@ func (s *server) Diff(req p.DiffRequest) (p.DiffResponse, error) {
 }
 
 func (s *server) Create(req p.CreateRequest) (p.CreateResponse, error) {
-	return s.p.Create(s.ctx(req.Urn), req)
+	return s.p.Create(internal.WithTimeout(s.ctx(req.Urn), req.Timeout), req)
 }
 
 func (s *server) Read(req p.ReadRequest) (p.ReadResponse, error) {
@@ -110,11 +168,11 @@ func (s *server) Read(req p.ReadRequest) (p.ReadResponse, error) {
 }
 
 func (s *server) Update(req p.UpdateRequest) (p.UpdateResponse, error) {
-	return s.p.Update(s.ctx(req.Urn), req)
+	return s.p.Update(internal.WithTimeout(s.ctx(req.Urn), req.Timeout), req)
 }
 
-func (s *server) Delete(req p.DeleteRequest) error {
-	return s.p.Delete(s.ctx(req.Urn), req)
+func (s *server) Delete(req p.DeleteRequest) (p.DeleteResponse, error) {
+	return s.p.Delete(internal.WithTimeout(s.ctx(req.Urn), req.Timeout), req)
 }
 
 func (s *server) Construct(req p.ConstructRequest) (p.ConstructResponse, error) {
@@ -250,7 +308,7 @@ func (l LifeCycleTest) Run(t *testing.T, server Server) {
 		}
 		if isDelete {
 			runDelete := func() {
-				err = server.Delete(p.DeleteRequest{
+				_, err = server.Delete(p.DeleteRequest{
 					ID:         id,
 					Urn:        urn,
 					Properties: olds,
@@ -310,7 +368,7 @@ func (l LifeCycleTest) Run(t *testing.T, server Server) {
 			olds = result.Properties
 		}
 	}
-	err := server.Delete(p.DeleteRequest{
+	_, err := server.Delete(p.DeleteRequest{
 		ID:         id,
 		Urn:        urn,
 		Properties: olds,
@@ -0,0 +1,149 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+// MockResourceMonitor fabricates plausible outputs for the resources registered by a
+// component, so that component logic can be tested without an engine or the providers of
+// its child resources.
+//
+// By default, each custom resource is given the ID "<name>-id" and its inputs as its
+// outputs. Resources from the random provider additionally get a deterministic
+// "result" (or "id" for RandomPet) derived from their name.
+//
+// MockResourceMonitor is safe for concurrent use. Use it with [RunComponent].
+type MockResourceMonitor struct {
+	// NewResourceF overrides the outputs of registered resources.
+	//
+	// If NewResourceF returns ok=false, the default behavior is used.
+	NewResourceF func(args pulumi.MockResourceArgs) (id string, outputs presource.PropertyMap, ok bool, err error)
+
+	// CallF handles invokes made by the component. If CallF is nil, invokes return their
+	// arguments.
+	CallF func(args pulumi.MockCallArgs) (presource.PropertyMap, error)
+
+	m          sync.Mutex
+	registered []pulumi.MockResourceArgs
+}
+
+// Registered returns every resource registered so far, in the order they were registered.
+func (m *MockResourceMonitor) Registered() []pulumi.MockResourceArgs {
+	m.m.Lock()
+	defer m.m.Unlock()
+	return append([]pulumi.MockResourceArgs(nil), m.registered...)
+}
+
+// NewResource implements [pulumi.MockResourceMonitor].
+func (m *MockResourceMonitor) NewResource(args pulumi.MockResourceArgs) (string, presource.PropertyMap, error) {
+	m.m.Lock()
+	m.registered = append(m.registered, args)
+	m.m.Unlock()
+
+	if m.NewResourceF != nil {
+		id, outputs, ok, err := m.NewResourceF(args)
+		if err != nil || ok {
+			return id, outputs, err
+		}
+	}
+
+	outputs := args.Inputs.Copy()
+	id := args.ID
+	if id == "" {
+		id = args.Name + "-id"
+	}
+	if result, ok := fakeRandomResult(args); ok {
+		outputs[result.key] = result.value
+	}
+	return id, outputs, nil
+}
+
+// Call implements [pulumi.MockResourceMonitor].
+func (m *MockResourceMonitor) Call(args pulumi.MockCallArgs) (presource.PropertyMap, error) {
+	if m.CallF != nil {
+		return m.CallF(args)
+	}
+	return args.Args, nil
+}
+
+type fakeOutput struct {
+	key   presource.PropertyKey
+	value presource.PropertyValue
+}
+
+// maxFakeLength is the longest string that fakeRandomResult will produce.
+const maxFakeLength = 4096
+
+// fakeRandomResult computes a deterministic stand-in for the computed output of a
+// resource from the random provider.
+func fakeRandomResult(args pulumi.MockResourceArgs) (fakeOutput, bool) {
+	sum := sha256.Sum256([]byte(args.Name))
+	seed := hex.EncodeToString(sum[:])
+	length := 16
+	if l, ok := args.Inputs["length"]; ok && l.IsNumber() {
+		// The mocked program may ask for any length, so keep it within bounds that
+		// can be sliced from seed without a large allocation.
+		length = int(max(0, min(l.NumberValue(), maxFakeLength)))
+	}
+	for len(seed) < length {
+		seed += seed
+	}
+
+	switch args.TypeToken {
+	case "random:index/randomString:RandomString", "random:index/randomPassword:RandomPassword":
+		return fakeOutput{"result", presource.NewStringProperty(seed[:length])}, true
+	case "random:index/randomPet:RandomPet":
+		return fakeOutput{"id", presource.NewStringProperty(args.Name + "-pet")}, true
+	case "random:index/randomInteger:RandomInteger":
+		result := float64(0)
+		if min, ok := args.Inputs["min"]; ok && min.IsNumber() {
+			result = min.NumberValue()
+		}
+		return fakeOutput{"result", presource.NewNumberProperty(result)}, true
+	case "random:index/randomUuid:RandomUuid":
+		return fakeOutput{"result", presource.NewStringProperty(fmt.Sprintf("%s-%s-%s-%s-%s",
+			seed[0:8], seed[8:12], seed[12:16], seed[16:20], seed[20:32]))}, true
+	default:
+		return fakeOutput{}, false
+	}
+}
+
+// RunComponent runs body, which should construct one or more components, against mocks.
+//
+// This allows testing the Construct method of a component directly:
+//
+//	mocks := &integration.MockResourceMonitor{}
+//	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+//		_, err := (&MyComponent{}).Construct(ctx, "name", "pkg:index:MyComponent", args, nil)
+//		return err
+//	}, mocks)
+//	require.NoError(t, err)
+//	assert.Len(t, mocks.Registered(), 2)
+//
+// If mocks is nil, a zero value [MockResourceMonitor] is used.
+func RunComponent(body pulumi.RunFunc, mocks *MockResourceMonitor) error {
+	if mocks == nil {
+		mocks = &MockResourceMonitor{}
+	}
+	return pulumi.RunErr(body, pulumi.WithMocks("project", "stack", mocks))
+}
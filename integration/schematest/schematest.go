@@ -0,0 +1,299 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schematest asserts facts about individual properties of a Pulumi schema.
+//
+// Comparing whole schemas (see [integration.SchemaSnapshot]) catches every change, but
+// it also fails on every change. The helpers in this package check only what a test
+// cares about, and read well in table-driven tests:
+//
+//	spec := schematest.Spec(t, server)
+//	schematest.AssertProperty(t, spec, "file:index:File", "content",
+//		schematest.Required, schematest.TypeString, schematest.HasDescription)
+package schematest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+// TestingT is the subset of [testing.TB] used by this package.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+type helper interface {
+	Helper()
+}
+
+// Spec returns the schema served by server. It fails the test if the schema can't be
+// retrieved.
+func Spec(t TestingT, server integration.Server) pschema.PackageSpec {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	var spec pschema.PackageSpec
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	if err != nil {
+		t.Errorf("failed to get schema: %v", err)
+		return spec
+	}
+	if err := json.Unmarshal([]byte(resp.Schema), &spec); err != nil {
+		t.Errorf("schema is not valid JSON: %v", err)
+	}
+	return spec
+}
+
+// Property is a property of an object in a schema, as seen by an [Assertion].
+type Property struct {
+	pschema.PropertySpec
+
+	// Required is true if the object that owns the property lists it as required.
+	Required bool
+}
+
+// Assertion checks a single fact about a property. It returns a description of the
+// problem, or nil if the fact holds.
+type Assertion func(prop Property) error
+
+// AssertProperty asserts that the property named property of token in spec satisfies each
+// of assertions. It reports whether every assertion held.
+//
+// token may name a resource, a type, a function or the provider resource
+// ("pulumi:providers:<package>"). For resources and the provider, the output properties
+// are checked; for functions, the properties of the result are checked. Use
+// [AssertInputProperty] to check input properties.
+func AssertProperty(t TestingT, spec pschema.PackageSpec, token, property string, assertions ...Assertion) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	obj, err := outputs(spec, token)
+	return assertProperty(t, obj, err, token, property, assertions)
+}
+
+// AssertInputProperty is like [AssertProperty], but checks the input properties of a
+// resource or the provider, or the arguments of a function.
+func AssertInputProperty(t TestingT, spec pschema.PackageSpec, token, property string, assertions ...Assertion) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	obj, err := inputs(spec, token)
+	return assertProperty(t, obj, err, token, property, assertions)
+}
+
+// object is the set of properties checked by an assertion.
+type object struct {
+	properties map[string]pschema.PropertySpec
+	required   []string
+}
+
+func assertProperty(
+	t TestingT, obj object, err error, token, property string, assertions []Assertion,
+) bool {
+	if h, ok := t.(helper); ok {
+		h.Helper()
+	}
+	if err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	spec, ok := obj.properties[property]
+	if !ok {
+		t.Errorf("%s has no property %q", token, property)
+		return false
+	}
+	prop := Property{spec, slices.Contains(obj.required, property)}
+	ok = true
+	for _, a := range assertions {
+		if err := a(prop); err != nil {
+			t.Errorf("%s.%s: %v", token, property, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+func outputs(spec pschema.PackageSpec, token string) (object, error) {
+	if token == providerToken(spec) {
+		return object{spec.Provider.Properties, spec.Provider.Required}, nil
+	}
+	if r, ok := spec.Resources[token]; ok {
+		return object{r.Properties, r.Required}, nil
+	}
+	if typ, ok := spec.Types[token]; ok {
+		return object{typ.Properties, typ.Required}, nil
+	}
+	if fn, ok := spec.Functions[token]; ok {
+		// Unmarshaling a schema moves the outputs of a function into its return type.
+		switch {
+		case fn.Outputs != nil:
+			return object{fn.Outputs.Properties, fn.Outputs.Required}, nil
+		case fn.ReturnType != nil && fn.ReturnType.ObjectTypeSpec != nil:
+			return object{fn.ReturnType.ObjectTypeSpec.Properties, fn.ReturnType.ObjectTypeSpec.Required}, nil
+		default:
+			return object{}, fmt.Errorf("function %s does not return an object", token)
+		}
+	}
+	return object{}, fmt.Errorf("%s is not in the schema", token)
+}
+
+func inputs(spec pschema.PackageSpec, token string) (object, error) {
+	if token == providerToken(spec) {
+		return object{spec.Provider.InputProperties, spec.Provider.RequiredInputs}, nil
+	}
+	if r, ok := spec.Resources[token]; ok {
+		return object{r.InputProperties, r.RequiredInputs}, nil
+	}
+	if typ, ok := spec.Types[token]; ok {
+		return object{typ.Properties, typ.Required}, nil
+	}
+	if fn, ok := spec.Functions[token]; ok {
+		if fn.Inputs == nil {
+			return object{}, fmt.Errorf("function %s takes no arguments", token)
+		}
+		return object{fn.Inputs.Properties, fn.Inputs.Required}, nil
+	}
+	return object{}, fmt.Errorf("%s is not in the schema", token)
+}
+
+func providerToken(spec pschema.PackageSpec) string {
+	return "pulumi:providers:" + spec.Name
+}
+
+// Required asserts that the property is required.
+func Required(prop Property) error {
+	if !prop.Required {
+		return errors.New("expected property to be required")
+	}
+	return nil
+}
+
+// Optional asserts that the property is not required.
+func Optional(prop Property) error {
+	if prop.Required {
+		return errors.New("expected property to be optional")
+	}
+	return nil
+}
+
+// Secret asserts that the property is marked as secret.
+func Secret(prop Property) error {
+	if !prop.Secret {
+		return errors.New("expected property to be secret")
+	}
+	return nil
+}
+
+// ReplaceOnChanges asserts that changing the property replaces the resource.
+func ReplaceOnChanges(prop Property) error {
+	if !prop.ReplaceOnChanges {
+		return errors.New("expected property to replace on changes")
+	}
+	return nil
+}
+
+// HasDescription asserts that the property has a description.
+func HasDescription(prop Property) error {
+	if prop.Description == "" {
+		return errors.New("expected property to have a description")
+	}
+	return nil
+}
+
+// Deprecated asserts that the property is deprecated.
+func Deprecated(prop Property) error {
+	if prop.DeprecationMessage == "" {
+		return errors.New("expected property to be deprecated")
+	}
+	return nil
+}
+
+var (
+	// TypeString asserts that the property is a string.
+	TypeString = Type("string")
+	// TypeInteger asserts that the property is an integer.
+	TypeInteger = Type("integer")
+	// TypeNumber asserts that the property is a number.
+	TypeNumber = Type("number")
+	// TypeBoolean asserts that the property is a boolean.
+	TypeBoolean = Type("boolean")
+	// TypeArray asserts that the property is an array.
+	TypeArray = Type("array")
+	// TypeObject asserts that the property is an object, such as a map. References to
+	// object types are checked with [Ref].
+	TypeObject = Type("object")
+)
+
+// Type asserts that the property has the primitive type typ.
+func Type(typ string) Assertion {
+	return func(prop Property) error {
+		if prop.Type != typ {
+			return fmt.Errorf("expected type %q, got %s", typ, describeType(prop.TypeSpec))
+		}
+		return nil
+	}
+}
+
+// Ref asserts that the property refers to the type ref, such as
+// "#/types/pkg:index:Config".
+func Ref(ref string) Assertion {
+	return func(prop Property) error {
+		if prop.Ref != ref {
+			return fmt.Errorf("expected a reference to %q, got %s", ref, describeType(prop.TypeSpec))
+		}
+		return nil
+	}
+}
+
+// Description asserts that the description of the property is description.
+func Description(description string) Assertion {
+	return func(prop Property) error {
+		if prop.Description != description {
+			return fmt.Errorf("expected description %q, got %q", description, prop.Description)
+		}
+		return nil
+	}
+}
+
+// Default asserts that the default value of the property is value. Values are compared
+// as decoded from JSON, so numbers must be given as float64.
+func Default(value any) Assertion {
+	return func(prop Property) error {
+		if !reflect.DeepEqual(prop.Default, value) {
+			return fmt.Errorf("expected default %#v, got %#v", value, prop.Default)
+		}
+		return nil
+	}
+}
+
+func describeType(typ pschema.TypeSpec) string {
+	switch {
+	case typ.Ref != "":
+		return fmt.Sprintf("a reference to %q", typ.Ref)
+	case typ.Type != "":
+		return fmt.Sprintf("%q", typ.Type)
+	case len(typ.OneOf) > 0:
+		return "a union"
+	default:
+		return "no type"
+	}
+}
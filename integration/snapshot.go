@@ -0,0 +1,105 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// acceptEnv is the environment variable that instructs [SchemaSnapshot] to rewrite golden
+// files instead of asserting against them.
+const acceptEnv = "PULUMI_ACCEPT"
+
+// shouldUpdateGoldenFiles reports whether golden files should be rewritten. Besides
+// acceptEnv, the -update flag is respected when the test binary defines one itself. The
+// flag is looked up lazily, since a library can't define it without clashing with test
+// binaries that do.
+func shouldUpdateGoldenFiles() bool {
+	if f := flag.Lookup("update"); f != nil && f.Value.String() == "true" {
+		return true
+	}
+	accept, _ := strconv.ParseBool(os.Getenv(acceptEnv))
+	return accept
+}
+
+// SchemaSnapshot asserts that the schema served by server matches the golden file at
+// path.
+//
+// The schema is rendered deterministically: object keys are sorted and the output is
+// indented, so the golden file produces readable diffs when checked in. The package name
+// and version are taken from server, so they are stable for a given test.
+//
+// When PULUMI_ACCEPT is set to true, the golden file is (re)written instead of compared
+// against:
+//
+//	PULUMI_ACCEPT=true go test ./...
+//
+// Test binaries that define their own -update flag may also pass it.
+func SchemaSnapshot(t *testing.T, server Server, path string) {
+	t.Helper()
+
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err, "failed to get schema")
+
+	actual, err := canonicalJSON([]byte(resp.Schema))
+	require.NoError(t, err, "schema is not valid JSON")
+
+	if shouldUpdateGoldenFiles() {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, actual, 0o600))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		require.Failf(t, "missing golden file",
+			"%q does not exist; run the test with %s=true to create it", path, acceptEnv)
+	}
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(actual),
+		"schema does not match %q; run the test with %s=true to accept the new schema", path, acceptEnv)
+}
+
+// canonicalJSON re-renders a JSON document with sorted keys and stable indentation.
+func canonicalJSON(raw []byte) ([]byte, error) {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	// [json.Encoder] sorts map keys, which gives us a deterministic ordering. HTML is not
+	// escaped, so descriptions keep characters such as "<" and "&" as written.
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,300 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/blang/semver"
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Resource is the state of a resource recorded by a [StatefulServer].
+type Resource struct {
+	// The ID of the resource.
+	ID string
+	// The inputs the resource was last created or updated with, as returned by Check.
+	Inputs presource.PropertyMap
+	// The state of the resource, as returned by the provider.
+	Outputs presource.PropertyMap
+}
+
+// StatefulServer is a [Server] that remembers the resources it creates, as the engine
+// does in a stack's state.
+//
+// Requests that omit the ID or old state of a recorded resource are filled in from the
+// recorded state, so tests only need to say what changes:
+//
+//	server := integration.NewStatefulServer("file", semver.MustParse("1.0.0"), provider())
+//	_, err := server.Up(urn, resource.PropertyMap{"path": resource.NewStringProperty("a")})
+//	_, err = server.Up(urn, resource.PropertyMap{"path": resource.NewStringProperty("b")})
+//	err = server.Destroy(urn)
+//
+// Requests made with a preview don't change the recorded state.
+type StatefulServer struct {
+	Server
+
+	m         sync.Mutex
+	resources map[presource.URN]Resource
+}
+
+// NewStatefulServer creates a [StatefulServer] that sends requests directly to provider.
+// See [NewServer] for the options.
+func NewStatefulServer(pkg string, version semver.Version, provider p.Provider, opts ...ServerOption) *StatefulServer {
+	return NewStatefulServerWithContext(context.Background(), pkg, version, provider, opts...)
+}
+
+// NewStatefulServerWithContext is like [NewStatefulServer], but each request is made with a
+// context derived from ctx.
+func NewStatefulServerWithContext(
+	ctx context.Context, pkg string, version semver.Version, provider p.Provider, opts ...ServerOption,
+) *StatefulServer {
+	return &StatefulServer{
+		Server:    NewServerWithContext(ctx, pkg, version, provider, opts...),
+		resources: map[presource.URN]Resource{},
+	}
+}
+
+// Get returns the recorded state of the resource with urn.
+func (s *StatefulServer) Get(urn presource.URN) (Resource, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	r, ok := s.resources[urn]
+	return r, ok
+}
+
+// Resources returns the recorded state of every resource, by URN.
+func (s *StatefulServer) Resources() map[presource.URN]Resource {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return maps.Clone(s.resources)
+}
+
+func (s *StatefulServer) set(urn presource.URN, r Resource) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.resources[urn] = r
+}
+
+func (s *StatefulServer) remove(urn presource.URN) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.resources, urn)
+}
+
+func (s *StatefulServer) Check(req p.CheckRequest) (p.CheckResponse, error) {
+	if r, ok := s.Get(req.Urn); ok && req.Olds == nil {
+		req.Olds = r.Inputs
+	}
+	return s.Server.Check(req)
+}
+
+func (s *StatefulServer) Diff(req p.DiffRequest) (p.DiffResponse, error) {
+	if r, ok := s.Get(req.Urn); ok {
+		if req.ID == "" {
+			req.ID = r.ID
+		}
+		if req.Olds == nil {
+			req.Olds = r.Outputs
+		}
+	}
+	return s.Server.Diff(req)
+}
+
+func (s *StatefulServer) Create(req p.CreateRequest) (p.CreateResponse, error) {
+	resp, err := s.Server.Create(req)
+	if !req.Preview {
+		s.record(req.Urn, resp.ID, req.Properties, resp.Properties, resp.PartialState, err)
+	}
+	return resp, err
+}
+
+func (s *StatefulServer) Read(req p.ReadRequest) (p.ReadResponse, error) {
+	r, ok := s.Get(req.Urn)
+	if ok {
+		if req.ID == "" {
+			req.ID = r.ID
+		}
+		if req.Properties == nil {
+			req.Properties = r.Outputs
+		}
+		if req.Inputs == nil {
+			req.Inputs = r.Inputs
+		}
+	}
+	resp, err := s.Server.Read(req)
+	switch {
+	case err == nil && resp.ID == "":
+		// The resource no longer exists.
+		s.remove(req.Urn)
+	case err == nil:
+		inputs := resp.Inputs
+		if inputs == nil {
+			inputs = req.Inputs
+		}
+		s.set(req.Urn, Resource{ID: resp.ID, Inputs: inputs, Outputs: resp.Properties})
+	}
+	return resp, err
+}
+
+func (s *StatefulServer) Update(req p.UpdateRequest) (p.UpdateResponse, error) {
+	if r, ok := s.Get(req.Urn); ok {
+		if req.ID == "" {
+			req.ID = r.ID
+		}
+		if req.Olds == nil {
+			req.Olds = r.Outputs
+		}
+	}
+	resp, err := s.Server.Update(req)
+	if !req.Preview {
+		s.record(req.Urn, req.ID, req.News, resp.Properties, resp.PartialState, err)
+	}
+	return resp, err
+}
+
+func (s *StatefulServer) Delete(req p.DeleteRequest) (p.DeleteResponse, error) {
+	if r, ok := s.Get(req.Urn); ok {
+		if req.ID == "" {
+			req.ID = r.ID
+		}
+		if req.Properties == nil {
+			req.Properties = r.Outputs
+		}
+	}
+	resp, err := s.Server.Delete(req)
+	if err == nil {
+		s.remove(req.Urn)
+	}
+	return resp, err
+}
+
+// record stores the result of a create or update. As with the engine, a resource that
+// failed to initialize is kept with its partial state.
+func (s *StatefulServer) record(
+	urn presource.URN, id string, inputs, outputs presource.PropertyMap,
+	partial *p.InitializationFailed, err error,
+) {
+	if err != nil && (partial == nil || id == "") {
+		return
+	}
+	s.set(urn, Resource{ID: id, Inputs: inputs, Outputs: outputs})
+}
+
+// Up brings the resource with urn to inputs, as `pulumi up` would.
+//
+// The inputs are checked against the recorded inputs. A resource that isn't recorded is
+// created. Otherwise it is diffed, and then updated or replaced as the diff requires,
+// deleting the old resource before or after creating its replacement. Up returns the
+// recorded state of the resource.
+func (s *StatefulServer) Up(urn presource.URN, inputs presource.PropertyMap) (Resource, error) {
+	check, err := s.Check(p.CheckRequest{Urn: urn, News: inputs})
+	if err != nil {
+		return Resource{}, err
+	}
+	if len(check.Failures) > 0 {
+		errs := make([]error, len(check.Failures))
+		for i, f := range check.Failures {
+			errs[i] = fmt.Errorf("%s: %s", f.Property, f.Reason)
+		}
+		return Resource{}, fmt.Errorf("check failed for %s: %w", urn, errors.Join(errs...))
+	}
+
+	old, ok := s.Get(urn)
+	if !ok {
+		if _, err := s.Create(p.CreateRequest{Urn: urn, Properties: check.Inputs}); err != nil {
+			return Resource{}, err
+		}
+		r, _ := s.Get(urn)
+		return r, nil
+	}
+
+	diff, err := s.Diff(p.DiffRequest{Urn: urn, News: check.Inputs})
+	if err != nil {
+		return Resource{}, err
+	}
+	if !diff.HasChanges {
+		return old, nil
+	}
+	if !replaces(diff) {
+		if _, err := s.Update(p.UpdateRequest{Urn: urn, News: check.Inputs}); err != nil {
+			return Resource{}, err
+		}
+		r, _ := s.Get(urn)
+		return r, nil
+	}
+
+	if diff.DeleteBeforeReplace {
+		if err := s.Destroy(urn); err != nil {
+			return Resource{}, err
+		}
+	}
+	resp, err := s.Server.Create(p.CreateRequest{Urn: urn, Properties: check.Inputs})
+	if err != nil {
+		return Resource{}, err
+	}
+	if !diff.DeleteBeforeReplace {
+		_, err := s.Server.Delete(p.DeleteRequest{ID: old.ID, Urn: urn, Properties: old.Outputs})
+		if err != nil {
+			return Resource{}, err
+		}
+	}
+	r := Resource{ID: resp.ID, Inputs: check.Inputs, Outputs: resp.Properties}
+	s.set(urn, r)
+	return r, nil
+}
+
+// Rename moves the recorded state of the resource with from to the URN to, as the engine
+// does when a resource is renamed with an alias. The provider isn't called.
+func (s *StatefulServer) Rename(from, to presource.URN) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	r, ok := s.resources[from]
+	if !ok {
+		return fmt.Errorf("no resource %s", from)
+	}
+	if _, ok := s.resources[to]; ok {
+		return fmt.Errorf("resource %s already exists", to)
+	}
+	delete(s.resources, from)
+	s.resources[to] = r
+	return nil
+}
+
+// Destroy deletes the resource with urn, as `pulumi destroy` would.
+func (s *StatefulServer) Destroy(urn presource.URN) error {
+	if _, ok := s.Get(urn); !ok {
+		return fmt.Errorf("no resource %s", urn)
+	}
+	_, err := s.Delete(p.DeleteRequest{Urn: urn})
+	return err
+}
+
+// replaces reports whether diff requires the resource to be replaced.
+func replaces(diff p.DiffResponse) bool {
+	for _, v := range diff.DetailedDiff {
+		switch v.Kind {
+		case p.AddReplace, p.DeleteReplace, p.UpdateReplace:
+			return true
+		}
+	}
+	return false
+}
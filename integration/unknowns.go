@@ -0,0 +1,94 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"strconv"
+	"strings"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+)
+
+// UnknownPlaceholder is the string that unknown values are rendered as by
+// [RenderUnknowns], and that [ExpandUnknowns] replaces with the wire sentinel.
+const UnknownPlaceholder = "<unknown>"
+
+// RenderUnknowns returns a copy of m where every unknown value is replaced with the
+// string [UnknownPlaceholder].
+//
+// Unknowns are held in a number of shapes (computed values, unknown outputs and the UUID
+// sentinel used on the wire), which makes them awkward to compare against expected
+// values or golden files. After RenderUnknowns, they compare equal to
+// presource.NewProperty(UnknownPlaceholder).
+//
+// Secrets and known outputs are preserved, with their elements rendered.
+func RenderUnknowns(m presource.PropertyMap) presource.PropertyMap {
+	if m == nil {
+		return nil
+	}
+	out := make(presource.PropertyMap, len(m))
+	for k, v := range m {
+		out[k] = renderUnknown(v)
+	}
+	return out
+}
+
+func renderUnknown(v presource.PropertyValue) presource.PropertyValue {
+	placeholder := presource.NewProperty(UnknownPlaceholder)
+	switch {
+	case v.IsComputed():
+		return placeholder
+	case v.IsString() && v.StringValue() == plugin.UnknownStringValue:
+		return placeholder
+	case v.IsOutput():
+		o := v.OutputValue()
+		if !o.Known {
+			return placeholder
+		}
+		o.Element = renderUnknown(o.Element)
+		return presource.NewProperty(o)
+	case v.IsSecret():
+		return presource.MakeSecret(renderUnknown(v.SecretValue().Element))
+	case v.IsArray():
+		arr := v.ArrayValue()
+		out := make([]presource.PropertyValue, len(arr))
+		for i, e := range arr {
+			out[i] = renderUnknown(e)
+		}
+		return presource.NewProperty(out)
+	case v.IsObject():
+		return presource.NewProperty(RenderUnknowns(v.ObjectValue()))
+	default:
+		return v
+	}
+}
+
+// ExpandUnknowns replaces each quoted [UnknownPlaceholder] in the JSON document s with
+// the sentinel that the Pulumi wire protocol uses for unknown values.
+//
+// This lets replay tests written against raw gRPC payloads spell unknowns as
+// "<unknown>" instead of a magic UUID:
+//
+//	replay.Replay(t, server, integration.ExpandUnknowns(`{
+//	    "method": "/pulumirpc.ResourceProvider/Call",
+//	    ...
+//	    "response": {"return": {"id": "<unknown>"}}
+//	}`))
+func ExpandUnknowns(s string) string {
+	return strings.ReplaceAll(s,
+		strconv.Quote(UnknownPlaceholder),
+		strconv.Quote(plugin.UnknownStringValue))
+}
@@ -0,0 +1,33 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/pulumi/pulumi-go-provider/internal/key"
+)
+
+// WithTimeout records the deadline implied by a request timeout of seconds on ctx, for
+// [github.com/pulumi/pulumi-go-provider.Deadline]. A timeout of 0 means that the request
+// has no timeout.
+func WithTimeout(ctx context.Context, seconds float64) context.Context {
+	if seconds <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, key.Deadline,
+		time.Now().Add(time.Duration(seconds*float64(time.Second))))
+}
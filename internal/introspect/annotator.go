@@ -16,28 +16,48 @@ package introspect
 
 import (
 	"fmt"
+	"io/fs"
 	"reflect"
+	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 )
 
 func NewAnnotator(resource any) Annotator {
 	return Annotator{
-		Descriptions: map[string]string{},
-		Defaults:     map[string]any{},
-		DefaultEnvs:  map[string][]string{},
-		matcher:      NewFieldMatcher(resource),
+		Descriptions:           map[string]string{},
+		Defaults:               map[string]any{},
+		DefaultEnvs:            map[string][]string{},
+		WriteOnlyFields:        map[string]bool{},
+		AutonameFields:         map[string]AutonameOptions{},
+		DeprecatedFields:       map[string]string{},
+		ReplaceOnChangesFields: map[string]bool{},
+		UnwiredFields:          map[string]bool{},
+		DefaultFromNameFields:  map[string]bool{},
+		matcher:                NewFieldMatcher(resource),
 	}
 }
 
 // Annotator implements the Annotator interface as defined in resource/resource.go.
 type Annotator struct {
-	Descriptions       map[string]string
-	Defaults           map[string]any
-	DefaultEnvs        map[string][]string
-	Token              string
-	Aliases            []string
-	DeprecationMessage string
+	Descriptions           map[string]string
+	Defaults               map[string]any
+	DefaultEnvs            map[string][]string
+	WriteOnlyFields        map[string]bool
+	AutonameFields         map[string]AutonameOptions
+	DeprecatedFields       map[string]string
+	ReplaceOnChangesFields map[string]bool
+	UnwiredFields          map[string]bool
+	DefaultFromNameFields  map[string]bool
+	Token                  string
+	ListToken              string
+	Aliases                []string
+	DeprecationMessage     string
+	Replacement            string
+	Category               string
+	Keywords               []string
+	RotationWindow         time.Duration
 
 	matcher FieldMatcher
 }
@@ -81,6 +101,16 @@ func (a *Annotator) Describe(i any, description string) {
 	a.Descriptions[field.Name] = description
 }
 
+// DescribeFromFile annotates a struct field, or the struct itself, with the contents of
+// the file name in fsys.
+func (a *Annotator) DescribeFromFile(i any, fsys fs.FS, name string) {
+	description, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		panic(fmt.Sprintf("could not read description: %s", err.Error()))
+	}
+	a.Describe(i, strings.TrimSpace(string(description)))
+}
+
 // SetDefault annotates a struct field with a default value. The default value must be a
 // primitive type in the pulumi type system.
 func (a *Annotator) SetDefault(i any, defaultValue any, env ...string) {
@@ -89,10 +119,62 @@ func (a *Annotator) SetDefault(i any, defaultValue any, env ...string) {
 	a.DefaultEnvs[field.Name] = append(a.DefaultEnvs[field.Name], env...)
 }
 
+// WriteOnly marks a struct field as write-only.
+func (a *Annotator) WriteOnly(i any) {
+	field := a.mustGetField(i)
+	a.WriteOnlyFields[field.Name] = true
+}
+
+// Autoname marks a struct field as auto-named.
+func (a *Annotator) Autoname(i any, opts AutonameOptions) {
+	field := a.mustGetField(i)
+	a.AutonameFields[field.Name] = opts
+}
+
+// DefaultFromName marks a struct field as defaulting to the name of the resource.
+func (a *Annotator) DefaultFromName(i any) {
+	field := a.mustGetField(i)
+	a.DefaultFromNameFields[field.Name] = true
+}
+
+// ReplaceOnChanges marks a struct field as requiring a replacement when it changes.
+func (a *Annotator) ReplaceOnChanges(i any) {
+	field := a.mustGetField(i)
+	a.ReplaceOnChangesFields[field.Name] = true
+}
+
+// Deprecate marks a struct field as deprecated with message.
+func (a *Annotator) Deprecate(i any, message string) {
+	field := a.mustGetField(i)
+	a.DeprecatedFields[field.Name] = message
+}
+
+// Unwired marks a struct field as intentionally left out of WireDependencies.
+func (a *Annotator) Unwired(i any) {
+	field := a.mustGetField(i)
+	a.UnwiredFields[field.Name] = true
+}
+
+// AutonameOptions control how a name is generated for an auto-named field.
+type AutonameOptions struct {
+	// Prefix is prepended to the resource name when generating a name.
+	Prefix string
+	// MaxLen is the maximum length of a generated name. If MaxLen is zero, generated
+	// names are not limited.
+	MaxLen int
+	// Charset is the set of characters used for the random suffix of a generated name.
+	// If Charset is empty, lowercase hexadecimal digits are used.
+	Charset string
+}
+
 func (a *Annotator) SetToken(module tokens.ModuleName, token tokens.TypeName) {
 	a.Token = formatToken(module, token)
 }
 
+func (a *Annotator) SetListToken(module tokens.ModuleName, token tokens.TypeName) {
+	a.ListToken = formatToken(module, token)
+}
+
 func (a *Annotator) AddAlias(module tokens.ModuleName, token tokens.TypeName) {
 	a.Aliases = append(a.Aliases, formatToken(module, token))
 }
@@ -101,6 +183,38 @@ func (a *Annotator) SetResourceDeprecationMessage(message string) {
 	a.DeprecationMessage = message
 }
 
+// DeprecateWithReplacement marks the annotated struct itself as deprecated in favor of
+// replacement.
+func (a *Annotator) DeprecateWithReplacement(i any, replacement string) {
+	typ := reflect.TypeOf(i)
+	if typ != nil && typ.Kind() == reflect.Pointer && typ.Elem().Kind() == reflect.Pointer {
+		i = reflect.ValueOf(i).Elem().Interface()
+	}
+	if a.matcher.value.Addr().Interface() != i {
+		panic("could not deprecate: DeprecateWithReplacement must be called on the annotated struct")
+	}
+	if _, err := tokens.ParseTypeToken(replacement); err != nil {
+		panic(fmt.Sprintf("invalid replacement: %s", err.Error()))
+	}
+	a.Replacement = replacement
+}
+
+func (a *Annotator) SetCategory(category string) {
+	a.Category = category
+}
+
+func (a *Annotator) AddKeywords(keywords ...string) {
+	a.Keywords = append(a.Keywords, keywords...)
+}
+
+// SetRotation sets how long a resource is kept before it is replaced.
+func (a *Annotator) SetRotation(window time.Duration) {
+	if window <= 0 {
+		panic(fmt.Sprintf("invalid rotation window: %s must be positive", window))
+	}
+	a.RotationWindow = window
+}
+
 // formatToken formats a (module, token) pair into a valid token string.
 //
 // Panics when module or token are invalid.
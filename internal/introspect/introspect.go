@@ -128,6 +128,7 @@ func ParseTag(field reflect.StructField) (FieldTag, error) {
 	}
 
 	var explRef *ExplicitType
+	var setKey string
 	provider := map[string]bool{}
 	providerArray := strings.Split(providerTag, ",")
 	if hasProviderTag {
@@ -162,15 +163,34 @@ func ParseTag(field reflect.StructField) (FieldTag, error) {
 				}
 				continue
 			}
+			if strings.HasPrefix(item, "setKey=") {
+				setKey = strings.TrimPrefix(item, "setKey=")
+				if setKey == "" {
+					return FieldTag{}, fmt.Errorf(`expected "setKey=" value of the name of a field`)
+				}
+				continue
+			}
 			provider[item] = true
 		}
 	}
 
+	var legacyNames []string
+	if migrateTag, ok := field.Tag.Lookup("migrate"); ok && migrateTag != "" {
+		legacyNames = strings.Split(migrateTag, ",")
+	}
+
 	return FieldTag{
 		Name:             name,
 		Optional:         pulumi["optional"],
 		Secret:           provider["secret"],
 		ReplaceOnChanges: provider["replaceOnChanges"],
+		ForceNew:         provider["forceNew"],
+		WriteOnly:        provider["writeOnly"],
+		Set:              provider["set"] || setKey != "",
+		SetKey:           setKey,
+		Autoname:         provider["autoname"],
+		Output:           provider["output"],
+		LegacyNames:      legacyNames,
 		ExplicitRef:      explRef,
 	}, nil
 }
@@ -191,6 +211,25 @@ type FieldTag struct {
 	ExplicitRef *ExplicitType // The name and version of the external type consumed in the field.
 	// NOTE: ReplaceOnChanges will only be obeyed when the default diff implementation is used.
 	ReplaceOnChanges bool // If changes in the field should force a replacement.
+	// ForceNew is only obeyed on provider configuration, where changes to the field
+	// will replace the provider.
+	ForceNew bool
+	// WriteOnly fields are accepted as inputs but never returned as outputs.
+	WriteOnly bool
+	// Set fields are collections whose order is not significant. Their elements are
+	// sorted before being returned as outputs, and before inputs are diffed.
+	Set bool
+	// SetKey is the name of the field that identifies the elements of a Set field of
+	// objects, taken from `provider:"setKey=name"`. Elements are sorted by it.
+	SetKey string
+	// LegacyNames are names the field was previously persisted under in state, taken
+	// from the `migrate` tag.
+	LegacyNames []string
+	// Autoname fields are given a generated name during Check when they are not set.
+	Autoname bool
+	// Output is only obeyed on provider configuration, where the field is computed by
+	// the provider and exposed as an output of the provider resource.
+	Output bool
 }
 
 func NewFieldMatcher(i any) FieldMatcher {
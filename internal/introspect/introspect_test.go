@@ -16,7 +16,9 @@ package introspect_test
 
 import (
 	"fmt"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +35,9 @@ type MyStruct struct {
 	Bar     int    `provider:"secret"`
 	Fizz    *int   `pulumi:"fizz"`
 	ExtType string `pulumi:"typ" provider:"type=example@1.2.3:m1:m2"`
+	Rules   []struct {
+		Port int `pulumi:"port"`
+	} `pulumi:"rules" provider:"setKey=port"`
 }
 
 func (m *MyStruct) Annotate(a infer.Annotator) {
@@ -59,6 +64,7 @@ func TestParseTag(t *testing.T) {
 				Name:     "foo",
 				Optional: true,
 				Secret:   true,
+				Output:   true,
 			},
 		},
 		{
@@ -83,6 +89,14 @@ func TestParseTag(t *testing.T) {
 				},
 			},
 		},
+		{
+			Field: "Rules",
+			Expected: introspect.FieldTag{
+				Name:   "rules",
+				Set:    true,
+				SetKey: "port",
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -196,3 +210,49 @@ func TestAllFieldsMiss(t *testing.T) {
 	require.False(t, ok)
 	assert.NoError(t, err)
 }
+
+type Embedding struct {
+	*MyStruct
+	Own string `pulumi:"own"`
+}
+
+func (Embedding) Method() {}
+
+func TestPosition(t *testing.T) {
+	t.Parallel()
+
+	// line returns the position of the first line of introspect_test.go that starts with
+	// prefix.
+	line := func(t *testing.T, prefix string) string {
+		src, err := os.ReadFile("introspect_test.go")
+		require.NoError(t, err)
+		for i, l := range strings.Split(string(src), "\n") {
+			if strings.HasPrefix(l, prefix) {
+				return fmt.Sprintf("introspect_test.go:%d", i+1)
+			}
+		}
+		require.Failf(t, "missing line", "no line starts with %q", prefix)
+		return ""
+	}
+
+	typ := reflect.TypeOf(Embedding{})
+	assert.Equal(t, line(t, "type Embedding struct"), introspect.TypePosition(typ))
+	assert.Equal(t, line(t, "type MyStruct struct"), introspect.TypePosition(reflect.TypeOf(&MyStruct{})))
+
+	fields := map[string]reflect.StructField{}
+	for _, f := range reflect.VisibleFields(typ) {
+		fields[f.Name] = f
+	}
+	assert.Equal(t, line(t, "\tOwn string"), introspect.FieldPosition(typ, fields["Own"]))
+	assert.Equal(t, line(t, "\t*MyStruct"), introspect.FieldPosition(typ, fields["MyStruct"]))
+	// Promoted fields are found where they are declared.
+	assert.Equal(t, line(t, "\tFizz    *int"), introspect.FieldPosition(typ, fields["Fizz"]))
+
+	// Types without source, such as those declared in functions, have no position.
+	type local struct{ A string }
+	assert.Empty(t, introspect.TypePosition(reflect.TypeOf(local{})))
+	assert.Empty(t, introspect.TypePosition(reflect.TypeOf("")))
+
+	assert.Equal(t, " (at a.go:1)", introspect.At("a.go:1"))
+	assert.Empty(t, introspect.At(""))
+}
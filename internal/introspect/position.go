@@ -0,0 +1,218 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package introspect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// TypePosition returns where typ is declared in Go source, such as "provider/bucket.go:42".
+//
+// Reflection doesn't record positions, so the source of the package of typ is parsed. The
+// package is found from the methods of typ, or else with the go command. TypePosition
+// returns "" if the source can't be found, such as when the provider was built on
+// another machine.
+func TypePosition(typ reflect.Type) string {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	spec := typeSpec(typ)
+	if spec == nil {
+		return ""
+	}
+	return spec.pos(spec.Name.Pos())
+}
+
+// FieldPosition returns where field, a field of typ as returned by
+// [reflect.VisibleFields], is declared in Go source. See [TypePosition].
+func FieldPosition(typ reflect.Type, field reflect.StructField) string {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	// A promoted field is declared by the struct it is embedded from.
+	if len(field.Index) > 1 {
+		typ = typ.FieldByIndex(field.Index[:len(field.Index)-1]).Type
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+	}
+	spec := typeSpec(typ)
+	if spec == nil {
+		return ""
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return ""
+	}
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 && embeddedName(f.Type) == field.Name {
+			return spec.pos(f.Pos())
+		}
+		for _, name := range f.Names {
+			if name.Name == field.Name {
+				return spec.pos(name.Pos())
+			}
+		}
+	}
+	return ""
+}
+
+// At formats pos as the suffix of an error message, such as " (at provider/bucket.go:42)".
+// It returns "" if pos is empty.
+func At(pos string) string {
+	if pos == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (at %s)", pos)
+}
+
+type sourceSpec struct {
+	*ast.TypeSpec
+	fset *token.FileSet
+}
+
+func (s sourceSpec) pos(p token.Pos) string {
+	position := s.fset.Position(p)
+	file := position.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, position.Line)
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return embeddedName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(expr.X)
+	case *ast.IndexListExpr:
+		return embeddedName(expr.X)
+	default:
+		return ""
+	}
+}
+
+func typeSpec(typ reflect.Type) *sourceSpec {
+	if typ == nil || typ.Name() == "" || typ.PkgPath() == "" {
+		return nil
+	}
+	name, _, _ := strings.Cut(typ.Name(), "[") // Generic types are named with their arguments.
+	pkg := packageSource(typ)
+	if pkg == nil {
+		return nil
+	}
+	spec, ok := pkg.types[name]
+	if !ok {
+		return nil
+	}
+	return &sourceSpec{spec, pkg.fset}
+}
+
+type packageSpecs struct {
+	fset  *token.FileSet
+	types map[string]*ast.TypeSpec
+}
+
+// packages caches the parsed source of each package, by package path. Packages whose
+// source can't be found are stored as nil.
+var packages sync.Map
+
+func packageSource(typ reflect.Type) *packageSpecs {
+	if pkg, ok := packages.Load(typ.PkgPath()); ok {
+		return pkg.(*packageSpecs)
+	}
+	var pkg *packageSpecs
+	if dir := packageDir(typ); dir != "" {
+		pkg = parsePackage(dir)
+	}
+	packages.Store(typ.PkgPath(), pkg)
+	return pkg
+}
+
+// packageDir returns the directory holding the source of the package of typ, or "".
+func packageDir(typ reflect.Type) string {
+	for _, t := range []reflect.Type{typ, reflect.PointerTo(typ)} {
+		for i := 0; i < t.NumMethod(); i++ {
+			fn := runtime.FuncForPC(t.Method(i).Func.Pointer())
+			if fn == nil || !strings.HasPrefix(fn.Name(), typ.PkgPath()+".") {
+				// Promoted methods are declared in another package.
+				continue
+			}
+			if file, _ := fn.FileLine(fn.Entry()); strings.HasSuffix(file, ".go") {
+				return filepath.Dir(file)
+			}
+		}
+	}
+	if typ.PkgPath() == "main" {
+		return ""
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	bp, err := build.Default.Import(typ.PkgPath(), wd, build.FindOnly)
+	if err != nil {
+		return ""
+	}
+	return bp.Dir
+}
+
+func parsePackage(dir string) *packageSpecs {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	pkg := &packageSpecs{fset: token.NewFileSet(), types: map[string]*ast.TypeSpec{}}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(pkg.fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		// Types declared in functions can't be told apart by name, so only package
+		// level types are found.
+		for _, decl := range f.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				pkg.types[spec.Name.Name] = spec
+			}
+		}
+	}
+	return pkg
+}
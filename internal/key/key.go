@@ -19,9 +19,16 @@
 package key
 
 type (
-	runtimeInfoType struct{}
-	logType         struct{}
-	urnType         struct{}
+	runtimeInfoType  struct{}
+	logType          struct{}
+	urnType          struct{}
+	stackType        struct{}
+	tokenType        struct{}
+	requestIDType    struct{}
+	logHandlerType   struct{}
+	frameworkLogType struct{}
+	deadlineType     struct{}
+	cancelCallType   struct{}
 )
 
 var (
@@ -31,6 +38,26 @@ var (
 	Logger = logType{}
 	// URN is used to retrieve an URN from ctx.
 	URN = urnType{}
+	// Stack is used to retrieve the stack metadata of the current deployment from ctx.
+	Stack = stackType{}
+	// Token is used to retrieve the resource type or function token of the current
+	// request from ctx.
+	Token = tokenType{}
+	// RequestID is used to retrieve the ID of the current request from ctx.
+	RequestID = requestIDType{}
+	// LogHandler is used to retrieve the [log/slog.Handler] set with
+	// [github.com/pulumi/pulumi-go-provider.Provider.WithLogHandler] from ctx.
+	LogHandler = logHandlerType{}
+	// FrameworkLog is used to retrieve the
+	// [github.com/pulumi/pulumi-go-provider.FrameworkLogTopic]s enabled for the current
+	// request from ctx.
+	FrameworkLog = frameworkLogType{}
+	// Deadline is used to retrieve the deadline set by the timeout of the current request
+	// from ctx.
+	Deadline = deadlineType{}
+	// CancelCall is used to retrieve the [context.CancelFunc] that cancels the context of
+	// the [github.com/pulumi/pulumi/sdk/v3/go/pulumi.Context] passed to Call.
+	CancelCall = cancelCallType{}
 )
 
 // ForceNoDetailedDiff acts as a side-channel in
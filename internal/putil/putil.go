@@ -16,6 +16,8 @@
 package putil
 
 import (
+	"slices"
+
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
@@ -204,3 +206,43 @@ search:
 		Secret:  secret,
 	})
 }
+
+// Dependencies returns the URNs of the resources each property of m depends on, as
+// recorded by the output values within it. Properties without dependencies are not in
+// the returned map.
+func Dependencies(m resource.PropertyMap) map[resource.PropertyKey][]resource.URN {
+	deps := map[resource.PropertyKey][]resource.URN{}
+	for k, v := range m {
+		if urns := appendDependencies(nil, v); len(urns) > 0 {
+			deps[k] = urns
+		}
+	}
+	return deps
+}
+
+// appendDependencies appends the dependencies of the output values in v to urns,
+// skipping URNs already in urns.
+func appendDependencies(urns []resource.URN, v resource.PropertyValue) []resource.URN {
+	switch {
+	case v.IsOutput():
+		for _, urn := range v.OutputValue().Dependencies {
+			if !slices.Contains(urns, urn) {
+				urns = append(urns, urn)
+			}
+		}
+		return appendDependencies(urns, v.OutputValue().Element)
+	case v.IsSecret():
+		return appendDependencies(urns, v.SecretValue().Element)
+	case v.IsComputed():
+		return appendDependencies(urns, v.Input().Element)
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			urns = appendDependencies(urns, e)
+		}
+	case v.IsObject():
+		for _, k := range v.ObjectValue().StableKeys() {
+			urns = appendDependencies(urns, v.ObjectValue()[k])
+		}
+	}
+	return urns
+}
@@ -0,0 +1,158 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8s surfaces Kubernetes custom resources as Pulumi resources.
+//
+// [NewResource] converts the OpenAPI schema of a CustomResourceDefinition into a Pulumi
+// schema, and serves the resource by talking to the cluster with client-go. This lets the
+// resources of a Kubernetes operator be used from every Pulumi language with typed SDKs:
+//
+//	crd, err := k8s.LoadCRD(crdYAML)
+//	if err != nil {
+//		return err
+//	}
+//	resources, err := k8s.Resources(crd, k8s.Options{Client: client})
+//	if err != nil {
+//		return err
+//	}
+//	return p.RunProvider("my-operator", version, infer.Provider(infer.Options{
+//		Resources: resources,
+//	}))
+//
+// Since the resources are built at runtime, they can also be derived from a CRD passed
+// to `pulumi package add` with [infer.Options.Parameterize].
+//
+// Package k8s is a separate Go module, so that providers which don't use it don't depend
+// on client-go.
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+// Options configures the resources created by [NewResource] and [Resources].
+type Options struct {
+	// Client returns the client used to reach the cluster. It is required.
+	//
+	// Client is called for each operation that reaches the cluster, and never during
+	// previews, so it may depend on the provider's configuration (see [infer.GetConfig]).
+	Client func(ctx context.Context) (dynamic.Interface, error)
+
+	// Module overrides the module of the resource tokens.
+	//
+	// By default, the module is derived from the group and version of the CRD, so that
+	// the version v1 of the group stable.example.com has the module "stable/v1".
+	Module tokens.ModuleName
+}
+
+// LoadCRD parses a CustomResourceDefinition from its YAML or JSON manifest.
+func LoadCRD(manifest []byte) (*apiextv1.CustomResourceDefinition, error) {
+	var crd apiextv1.CustomResourceDefinition
+	if err := yaml.UnmarshalStrict(manifest, &crd); err != nil {
+		return nil, fmt.Errorf("parsing CustomResourceDefinition: %w", err)
+	}
+	if crd.Kind != "" && crd.Kind != "CustomResourceDefinition" {
+		return nil, fmt.Errorf("expected a CustomResourceDefinition, found a %s", crd.Kind)
+	}
+	return &crd, nil
+}
+
+// Resources returns a resource for each served version of crd. See [NewResource].
+func Resources(crd *apiextv1.CustomResourceDefinition, opts Options) ([]infer.InferredResource, error) {
+	var resources []infer.InferredResource
+	for _, v := range crd.Spec.Versions {
+		if !v.Served {
+			continue
+		}
+		r, err := NewResource(crd, v.Name, opts)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// NewResource returns a resource for the given version of crd.
+//
+// The inputs of the resource are its metadata and each top level property of the CRD's
+// schema, such as spec. Its outputs add the status reported by the cluster. The resource
+// is replaced when its name or namespace changes, and updated in place otherwise.
+//
+// The resource's name defaults to the name of the Pulumi resource and, for namespaced
+// resources, its namespace defaults to "default".
+func NewResource(
+	crd *apiextv1.CustomResourceDefinition, version string, opts Options,
+) (infer.InferredResource, error) {
+	if opts.Client == nil {
+		return nil, fmt.Errorf("k8s.Options.Client is required")
+	}
+	var def *apiextv1.CustomResourceDefinitionVersion
+	for i, v := range crd.Spec.Versions {
+		if v.Name == version {
+			def = &crd.Spec.Versions[i]
+		}
+	}
+	if def == nil {
+		return nil, fmt.Errorf("%s has no version %q", crd.Name, version)
+	}
+	if def.Schema == nil || def.Schema.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("%s/%s has no OpenAPI schema", crd.Name, version)
+	}
+
+	module := opts.Module
+	if module == "" {
+		group, _, _ := strings.Cut(crd.Spec.Group, ".")
+		module = tokens.ModuleName(group + "/" + version)
+	}
+	kind := crd.Spec.Names.Kind
+	builder := schemaBuilder{
+		module: tokens.NewModuleToken("pkg", module),
+		types:  map[tokens.Type]pschema.ComplexTypeSpec{},
+	}
+	spec := builder.resourceSpec(kind, *def.Schema.OpenAPIV3Schema)
+
+	inputs := make([]string, 0, len(spec.InputProperties))
+	for k := range spec.InputProperties {
+		if k != "metadata" {
+			inputs = append(inputs, k)
+		}
+	}
+
+	return infer.RawResource(&controller{
+		gvr: schema.GroupVersionResource{
+			Group:    crd.Spec.Group,
+			Version:  version,
+			Resource: crd.Spec.Names.Plural,
+		},
+		kind:       kind,
+		namespaced: crd.Spec.Scope == apiextv1.NamespaceScoped,
+		token:      tokens.NewTypeToken(builder.module, tokens.TypeName(kind)),
+		spec:       spec,
+		types:      builder.types,
+		inputs:     inputs,
+		client:     opts.Client,
+	}), nil
+}
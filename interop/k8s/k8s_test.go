@@ -0,0 +1,228 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+const crdManifest = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  scope: Namespaced
+  names:
+    plural: crontabs
+    singular: crontab
+    kind: CronTab
+  versions:
+    - name: v1
+      served: true
+      storage: true
+      schema:
+        openAPIV3Schema:
+          type: object
+          description: A CronTab runs a container on a schedule.
+          required: [spec]
+          properties:
+            spec:
+              type: object
+              required: [cronSpec]
+              properties:
+                cronSpec:
+                  type: string
+                  description: The schedule, in cron format.
+                replicas:
+                  type: integer
+                port:
+                  x-kubernetes-int-or-string: true
+                env:
+                  type: array
+                  items:
+                    type: object
+                    properties:
+                      name:
+                        type: string
+                      value:
+                        type: string
+                selector:
+                  type: object
+                  additionalProperties:
+                    type: string
+            status:
+              type: object
+              properties:
+                lastRun:
+                  type: string
+    - name: v1beta1
+      served: false
+      storage: false
+      schema:
+        openAPIV3Schema:
+          type: object
+`
+
+var gvr = schema.GroupVersionResource{Group: "stable.example.com", Version: "v1", Resource: "crontabs"}
+
+func server(t *testing.T) (integration.Server, dynamic.Interface) {
+	crd, err := LoadCRD([]byte(crdManifest))
+	require.NoError(t, err)
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "CronTabList"})
+	resources, err := Resources(crd, Options{
+		Client: func(context.Context) (dynamic.Interface, error) { return client, nil },
+	})
+	require.NoError(t, err)
+	require.Len(t, resources, 1, "unserved versions are skipped")
+
+	prov := infer.Provider(infer.Options{Resources: resources})
+	return integration.NewServer("example", semver.MustParse("1.0.0"), prov), client
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	s, _ := server(t)
+
+	resp, err := s.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec pschema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+
+	res, ok := spec.Resources["example:stable/v1:CronTab"]
+	require.True(t, ok)
+	assert.Equal(t, "A CronTab runs a container on a schedule.", res.Description)
+	assert.Equal(t, []string{"spec"}, res.RequiredInputs)
+	assert.Equal(t, "#/types/example:meta/v1:ObjectMeta", res.InputProperties["metadata"].Ref)
+	assert.Equal(t, "#/types/example:stable/v1:CronTabSpec", res.InputProperties["spec"].Ref)
+	assert.NotContains(t, res.InputProperties, "status")
+	assert.Equal(t, "#/types/example:stable/v1:CronTabStatus", res.Properties["status"].Ref)
+
+	cronSpec := spec.Types["example:stable/v1:CronTabSpec"]
+	assert.Equal(t, []string{"cronSpec"}, cronSpec.Required)
+	assert.Equal(t, "The schedule, in cron format.", cronSpec.Properties["cronSpec"].Description)
+	assert.Equal(t, "integer", cronSpec.Properties["replicas"].Type)
+	assert.Len(t, cronSpec.Properties["port"].OneOf, 2)
+	assert.Equal(t, "#/types/example:stable/v1:CronTabSpecEnv", cronSpec.Properties["env"].Items.Ref)
+	assert.Equal(t, "string", cronSpec.Properties["selector"].AdditionalProperties.Type)
+	assert.Contains(t, spec.Types, "example:stable/v1:CronTabSpecEnv")
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+	s, client := server(t)
+	urn := resource.NewURN("stack", "proj", "", "example:stable/v1:CronTab", "nightly")
+
+	type m = resource.PropertyMap
+	str := resource.NewStringProperty
+	obj := resource.NewObjectProperty
+
+	check, err := s.Check(p.CheckRequest{
+		Urn:  urn,
+		News: m{"spec": obj(m{"cronSpec": str("0 0 * * *"), "replicas": resource.NewNumberProperty(2)})},
+	})
+	require.NoError(t, err)
+	require.Empty(t, check.Failures)
+	assert.Equal(t, obj(m{"name": str("nightly"), "namespace": str("default")}), check.Inputs["metadata"],
+		"the name and namespace default")
+
+	created, err := s.Create(p.CreateRequest{Urn: urn, Properties: check.Inputs})
+	require.NoError(t, err)
+	assert.Equal(t, "default/nightly", created.ID)
+	assert.Equal(t, str("stable.example.com/v1"), created.Properties["apiVersion"])
+
+	stored, err := client.Resource(gvr).Namespace("default").Get(context.Background(), "nightly", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stored.Object["spec"].(map[string]any)["replicas"])
+
+	// The cluster reports a status, which Read surfaces as an output.
+	stored.Object["status"] = map[string]any{"lastRun": "yesterday"}
+	_, err = client.Resource(gvr).Namespace("default").Update(context.Background(), stored, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	read, err := s.Read(p.ReadRequest{ID: created.ID, Urn: urn})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, read.ID)
+	assert.Equal(t, obj(m{"lastRun": str("yesterday")}), read.Properties["status"])
+	assert.NotContains(t, read.Inputs, resource.PropertyKey("status"))
+
+	news := check.Inputs.Copy()
+	news["spec"] = obj(m{"cronSpec": str("0 1 * * *")})
+	diff, err := s.Diff(p.DiffRequest{ID: created.ID, Urn: urn, Olds: read.Properties, News: news})
+	require.NoError(t, err)
+	assert.True(t, diff.HasChanges)
+	assert.Equal(t, p.Update, diff.DetailedDiff["spec.cronSpec"].Kind)
+	assert.Equal(t, p.Delete, diff.DetailedDiff["spec.replicas"].Kind)
+
+	renamed := check.Inputs.Copy()
+	renamed["metadata"] = obj(m{"name": str("weekly"), "namespace": str("default")})
+	diff, err = s.Diff(p.DiffRequest{ID: created.ID, Urn: urn, Olds: read.Properties, News: renamed})
+	require.NoError(t, err)
+	assert.Equal(t, p.UpdateReplace, diff.DetailedDiff["metadata.name"].Kind)
+
+	updated, err := s.Update(p.UpdateRequest{ID: created.ID, Urn: urn, Olds: read.Properties, News: news})
+	require.NoError(t, err)
+	assert.Equal(t, obj(m{"cronSpec": str("0 1 * * *")}), updated.Properties["spec"])
+	assert.Equal(t, obj(m{"lastRun": str("yesterday")}), updated.Properties["status"],
+		"the status set by the cluster is kept")
+
+	_, err = s.Delete(p.DeleteRequest{ID: created.ID, Urn: urn, Properties: updated.Properties})
+	require.NoError(t, err)
+	read, err = s.Read(p.ReadRequest{ID: created.ID, Urn: urn})
+	require.NoError(t, err)
+	assert.Empty(t, read.ID)
+	_, err = s.Delete(p.DeleteRequest{ID: created.ID, Urn: urn})
+	require.NoError(t, err, "deleting a missing object succeeds")
+}
+
+func TestCheckFailures(t *testing.T) {
+	t.Parallel()
+	s, _ := server(t)
+	resp, err := s.Check(p.CheckRequest{
+		Urn:  resource.NewURN("stack", "proj", "", "example:stable/v1:CronTab", "r"),
+		News: resource.PropertyMap{"metadata": resource.NewStringProperty("oops")},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []p.CheckFailure{
+		{Property: "metadata", Reason: "must be an object"},
+		{Property: "spec", Reason: "missing required property"},
+	}, resp.Failures)
+}
+
+func TestLoadCRD(t *testing.T) {
+	t.Parallel()
+	_, err := LoadCRD([]byte("apiVersion: v1\nkind: ConfigMap\n"))
+	assert.ErrorContains(t, err, "expected a CustomResourceDefinition, found a ConfigMap")
+}
@@ -0,0 +1,359 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	mschema "github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+// controller serves a single version of a CRD.
+type controller struct {
+	gvr        schema.GroupVersionResource
+	kind       string
+	namespaced bool
+
+	token tokens.Type
+	spec  pschema.ResourceSpec
+	types map[tokens.Type]pschema.ComplexTypeSpec
+	// inputs are the top level input properties, other than metadata.
+	inputs []string
+
+	client func(context.Context) (dynamic.Interface, error)
+}
+
+func (c *controller) GetToken() (tokens.Type, error) { return c.token, nil }
+
+func (c *controller) GetSchema(reg mschema.RegisterDerivativeType) (pschema.ResourceSpec, error) {
+	for tk, typ := range c.types {
+		reg(tk, typ)
+	}
+	return c.spec, nil
+}
+
+func (c *controller) Check(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+	news := req.News.Copy()
+	var failures []p.CheckFailure
+
+	meta := resource.PropertyMap{}
+	if v, ok := news["metadata"]; ok && !v.IsNull() {
+		switch {
+		case v.IsObject():
+			meta = v.ObjectValue().Copy()
+		case v.IsComputed() || v.IsOutput():
+			// The metadata will be checked once it is known.
+			return p.CheckResponse{Inputs: news}, nil
+		default:
+			failures = append(failures, p.CheckFailure{Property: "metadata", Reason: "must be an object"})
+		}
+	}
+	if _, ok := meta["name"]; !ok {
+		meta["name"] = resource.NewStringProperty(req.Urn.Name())
+	}
+	if _, ok := meta["namespace"]; !ok && c.namespaced {
+		meta["namespace"] = resource.NewStringProperty(metav1.NamespaceDefault)
+	}
+	news["metadata"] = resource.NewObjectProperty(meta)
+
+	for _, k := range c.spec.RequiredInputs {
+		if v, ok := news[resource.PropertyKey(k)]; !ok || v.IsNull() {
+			failures = append(failures, p.CheckFailure{Property: k, Reason: "missing required property"})
+		}
+	}
+	return p.CheckResponse{Inputs: news, Failures: failures}, nil
+}
+
+func (c *controller) Diff(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+	olds := c.inputsOf(req.Olds)
+	news := req.News.Copy()
+	for _, k := range req.IgnoreChanges {
+		news[k] = olds[k]
+	}
+
+	objDiff := olds.Diff(news)
+	diff := map[string]p.PropertyDiff{}
+	for k, v := range plugin.NewDetailedDiffFromObjectDiff(objDiff, false) {
+		// Kubernetes objects are identified by their name and namespace.
+		if k == "metadata" || k == "metadata.name" || k == "metadata.namespace" {
+			v.Kind = v.Kind.AsReplace()
+		}
+		diff[k] = p.PropertyDiff{Kind: diffKind(v.Kind), InputDiff: v.InputDiff}
+	}
+	return p.DiffResponse{
+		HasChanges:   objDiff.AnyChanges(),
+		DetailedDiff: diff,
+	}, nil
+}
+
+func diffKind(kind plugin.DiffKind) p.DiffKind {
+	switch kind {
+	case plugin.DiffAdd:
+		return p.Add
+	case plugin.DiffAddReplace:
+		return p.AddReplace
+	case plugin.DiffDelete:
+		return p.Delete
+	case plugin.DiffDeleteReplace:
+		return p.DeleteReplace
+	case plugin.DiffUpdateReplace:
+		return p.UpdateReplace
+	default:
+		return p.Update
+	}
+}
+
+func (c *controller) Create(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+	if req.Preview {
+		return p.CreateResponse{Properties: c.previewOutputs(req.Properties, nil)}, nil
+	}
+	obj, err := c.toObject(req.Properties)
+	if err != nil {
+		return p.CreateResponse{}, err
+	}
+	client, err := c.resourceClient(ctx, obj.GetNamespace())
+	if err != nil {
+		return p.CreateResponse{}, err
+	}
+	created, err := client.Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		return p.CreateResponse{}, fmt.Errorf("creating %s %q: %w", c.kind, obj.GetName(), err)
+	}
+	return p.CreateResponse{
+		ID:         c.id(created),
+		Properties: c.outputsOf(created),
+	}, nil
+}
+
+func (c *controller) Read(ctx context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+	namespace, name := c.parseID(req.ID)
+	client, err := c.resourceClient(ctx, namespace)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	obj, err := client.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The object is gone, which we signal with an empty ID.
+		return p.ReadResponse{}, nil
+	}
+	if err != nil {
+		return p.ReadResponse{}, fmt.Errorf("reading %s %q: %w", c.kind, req.ID, err)
+	}
+	outputs := c.outputsOf(obj)
+	return p.ReadResponse{
+		ID:         c.id(obj),
+		Properties: outputs,
+		Inputs:     c.inputsOf(outputs),
+	}, nil
+}
+
+func (c *controller) Update(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+	if req.Preview {
+		return p.UpdateResponse{Properties: c.previewOutputs(req.News, req.Olds)}, nil
+	}
+	desired, err := c.toObject(req.News)
+	if err != nil {
+		return p.UpdateResponse{}, err
+	}
+	client, err := c.resourceClient(ctx, desired.GetNamespace())
+	if err != nil {
+		return p.UpdateResponse{}, err
+	}
+	current, err := client.Get(ctx, desired.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return p.UpdateResponse{}, fmt.Errorf("reading %s %q: %w", c.kind, req.ID, err)
+	}
+
+	// Only replace the fields that Pulumi manages, so that fields set by the cluster,
+	// such as status, are preserved.
+	for _, k := range c.inputs {
+		if v, ok := desired.Object[k]; ok {
+			current.Object[k] = v
+		} else {
+			delete(current.Object, k)
+		}
+	}
+	current.SetLabels(desired.GetLabels())
+	current.SetAnnotations(desired.GetAnnotations())
+
+	updated, err := client.Update(ctx, current, metav1.UpdateOptions{})
+	if err != nil {
+		return p.UpdateResponse{}, fmt.Errorf("updating %s %q: %w", c.kind, req.ID, err)
+	}
+	return p.UpdateResponse{Properties: c.outputsOf(updated)}, nil
+}
+
+func (c *controller) Delete(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+	namespace, name := c.parseID(req.ID)
+	client, err := c.resourceClient(ctx, namespace)
+	if err != nil {
+		return p.DeleteResponse{}, err
+	}
+	err = client.Delete(ctx, name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return p.DeleteResponse{}, nil
+	}
+	if err != nil {
+		return p.DeleteResponse{}, fmt.Errorf("deleting %s %q: %w", c.kind, req.ID, err)
+	}
+	return p.DeleteResponse{}, nil
+}
+
+func (c *controller) resourceClient(ctx context.Context, namespace string) (dynamic.ResourceInterface, error) {
+	client, err := c.client(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the cluster: %w", err)
+	}
+	if c.namespaced {
+		return client.Resource(c.gvr).Namespace(namespace), nil
+	}
+	return client.Resource(c.gvr), nil
+}
+
+// id returns the ID of obj: "namespace/name" for namespaced objects, and "name"
+// otherwise.
+func (c *controller) id(obj *unstructured.Unstructured) string {
+	if c.namespaced {
+		return obj.GetNamespace() + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
+
+func (c *controller) parseID(id string) (namespace, name string) {
+	if !c.namespaced {
+		return "", id
+	}
+	namespace, name, ok := strings.Cut(id, "/")
+	if !ok {
+		return metav1.NamespaceDefault, id
+	}
+	return namespace, name
+}
+
+// inputsOf returns the inputs held in outputs.
+func (c *controller) inputsOf(outputs resource.PropertyMap) resource.PropertyMap {
+	inputs := resource.PropertyMap{}
+	for _, k := range c.inputs {
+		if v, ok := outputs[resource.PropertyKey(k)]; ok {
+			inputs[resource.PropertyKey(k)] = v
+		}
+	}
+	if meta, ok := outputs["metadata"]; ok && meta.IsObject() {
+		inputs["metadata"] = resource.NewObjectProperty(
+			pick(meta.ObjectValue(), metadataInputs))
+	}
+	return inputs
+}
+
+// outputsOf returns the outputs of the resource for obj.
+func (c *controller) outputsOf(obj *unstructured.Unstructured) resource.PropertyMap {
+	outputs := resource.NewPropertyMapFromMap(obj.Object)
+	if meta, ok := outputs["metadata"]; ok && meta.IsObject() {
+		outputs["metadata"] = resource.NewObjectProperty(pick(meta.ObjectValue(), metadataFields))
+	}
+	for k := range outputs {
+		if _, ok := c.spec.Properties[string(k)]; !ok {
+			delete(outputs, k)
+		}
+	}
+	return outputs
+}
+
+// previewOutputs returns the expected outputs of the resource for inputs, keeping the
+// status from olds if there is one.
+func (c *controller) previewOutputs(inputs, olds resource.PropertyMap) resource.PropertyMap {
+	outputs := inputs.Copy()
+	outputs["apiVersion"] = resource.NewStringProperty(c.gvr.GroupVersion().String())
+	outputs["kind"] = resource.NewStringProperty(c.kind)
+	if status, ok := olds["status"]; ok {
+		outputs["status"] = status
+	}
+	return outputs
+}
+
+func pick(m resource.PropertyMap, keys []string) resource.PropertyMap {
+	picked := resource.PropertyMap{}
+	for k, v := range m {
+		if slices.Contains(keys, string(k)) {
+			picked[k] = v
+		}
+	}
+	return picked
+}
+
+// toObject converts the inputs of the resource into a Kubernetes object.
+func (c *controller) toObject(inputs resource.PropertyMap) (*unstructured.Unstructured, error) {
+	if inputs.ContainsUnknowns() {
+		return nil, fmt.Errorf("cannot send unknown values to the cluster")
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	for k, v := range inputs {
+		obj.Object[string(k)] = plainValue(v)
+	}
+	obj.SetAPIVersion(c.gvr.GroupVersion().String())
+	obj.SetKind(c.kind)
+	return obj, nil
+}
+
+// plainValue converts v into the JSON compatible value expected by client-go. Secrets are
+// revealed, since the cluster does not understand them.
+func plainValue(v resource.PropertyValue) any {
+	switch {
+	case v.IsSecret():
+		return plainValue(v.SecretValue().Element)
+	case v.IsOutput():
+		return plainValue(v.OutputValue().Element)
+	case v.IsBool():
+		return v.BoolValue()
+	case v.IsNumber():
+		n := v.NumberValue()
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			// Kubernetes distinguishes integers from floats.
+			return int64(n)
+		}
+		return n
+	case v.IsString():
+		return v.StringValue()
+	case v.IsArray():
+		arr := make([]any, len(v.ArrayValue()))
+		for i, e := range v.ArrayValue() {
+			arr[i] = plainValue(e)
+		}
+		return arr
+	case v.IsObject():
+		obj := make(map[string]any, len(v.ObjectValue()))
+		for k, e := range v.ObjectValue() {
+			obj[string(k)] = plainValue(e)
+		}
+		return obj
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// metaModule is the module of the types shared by every CRD.
+const metaModule tokens.ModuleName = "meta/v1"
+
+var (
+	anyType = pschema.TypeSpec{Ref: "pulumi.json#/Any"}
+
+	objectMetaToken = tokens.NewTypeToken(tokens.NewModuleToken("pkg", metaModule), "ObjectMeta")
+
+	// objectMeta is the subset of Kubernetes object metadata that is surfaced in Pulumi.
+	objectMeta = pschema.ComplexTypeSpec{ObjectTypeSpec: pschema.ObjectTypeSpec{
+		Type:        "object",
+		Description: "Standard Kubernetes object metadata.",
+		Properties: map[string]pschema.PropertySpec{
+			"name": {
+				TypeSpec: pschema.TypeSpec{Type: "string"},
+				Description: "The name of the object, unique within its namespace. " +
+					"Defaults to the name of the Pulumi resource.",
+			},
+			"namespace": {
+				TypeSpec:    pschema.TypeSpec{Type: "string"},
+				Description: "The namespace of the object. Defaults to \"default\" for namespaced objects.",
+			},
+			"labels": {
+				TypeSpec:    pschema.TypeSpec{Type: "object", AdditionalProperties: &pschema.TypeSpec{Type: "string"}},
+				Description: "Labels used to organize and select objects.",
+			},
+			"annotations": {
+				TypeSpec:    pschema.TypeSpec{Type: "object", AdditionalProperties: &pschema.TypeSpec{Type: "string"}},
+				Description: "Unstructured metadata attached to the object.",
+			},
+			"uid": {
+				TypeSpec:    pschema.TypeSpec{Type: "string"},
+				Description: "The unique ID assigned to the object by the cluster. Output only.",
+			},
+			"resourceVersion": {
+				TypeSpec:    pschema.TypeSpec{Type: "string"},
+				Description: "The version of the object, which changes on each write. Output only.",
+			},
+		},
+	}}
+)
+
+// metadataFields are the fields of Kubernetes object metadata that are surfaced in
+// Pulumi, as described by objectMeta.
+var metadataFields = []string{"name", "namespace", "labels", "annotations", "uid", "resourceVersion"}
+
+// metadataInputs are the metadata fields that can be set by a program.
+var metadataInputs = []string{"name", "namespace", "labels", "annotations"}
+
+// reservedFields are the top level fields of a Kubernetes object that are not described
+// by the CRD's spec, or are not inputs.
+var reservedFields = []string{"apiVersion", "kind", "metadata", "status"}
+
+// schemaBuilder converts the OpenAPI v3 schema of a CRD version into a Pulumi schema.
+type schemaBuilder struct {
+	module tokens.Module
+	types  map[tokens.Type]pschema.ComplexTypeSpec
+}
+
+// resourceSpec returns the schema of a resource of kind whose objects are described by s.
+//
+// Each top level property of s other than apiVersion, kind, metadata and status is an
+// input of the resource.
+func (b *schemaBuilder) resourceSpec(kind string, s apiextv1.JSONSchemaProps) pschema.ResourceSpec {
+	b.types[objectMetaToken] = objectMeta
+	metadata := pschema.PropertySpec{
+		TypeSpec:    pschema.TypeSpec{Ref: "#/types/" + string(objectMetaToken)},
+		Description: "Standard object metadata.",
+	}
+
+	inputs := map[string]pschema.PropertySpec{"metadata": metadata}
+	outputs := map[string]pschema.PropertySpec{
+		"metadata":   metadata,
+		"apiVersion": {TypeSpec: pschema.TypeSpec{Type: "string"}, Description: "The API version of the object."},
+		"kind":       {TypeSpec: pschema.TypeSpec{Type: "string"}, Description: "The kind of the object."},
+	}
+	for name, prop := range s.Properties {
+		if slices.Contains(reservedFields, name) && name != "status" {
+			continue
+		}
+		spec := pschema.PropertySpec{
+			TypeSpec:    b.typeSpec(kind+typeName(name), prop),
+			Description: prop.Description,
+		}
+		outputs[name] = spec
+		if name != "status" {
+			inputs[name] = spec
+		}
+	}
+
+	var required []string
+	for _, name := range s.Required {
+		if !slices.Contains(reservedFields, name) {
+			required = append(required, name)
+		}
+	}
+	slices.Sort(required)
+
+	return pschema.ResourceSpec{
+		ObjectTypeSpec: pschema.ObjectTypeSpec{
+			Type:        "object",
+			Description: s.Description,
+			Properties:  outputs,
+			Required:    []string{"apiVersion", "kind", "metadata"},
+		},
+		InputProperties: inputs,
+		RequiredInputs:  required,
+	}
+}
+
+// typeSpec returns the type of a property described by s. Objects with properties become
+// named types, whose names start with name.
+func (b *schemaBuilder) typeSpec(name string, s apiextv1.JSONSchemaProps) pschema.TypeSpec {
+	if s.XIntOrString {
+		return pschema.TypeSpec{OneOf: []pschema.TypeSpec{{Type: "integer"}, {Type: "string"}}}
+	}
+	if s.XEmbeddedResource {
+		return anyType
+	}
+
+	switch s.Type {
+	case "string", "integer", "number", "boolean":
+		return pschema.TypeSpec{Type: s.Type}
+	case "array":
+		items := anyType
+		if s.Items != nil && s.Items.Schema != nil {
+			items = b.typeSpec(name, *s.Items.Schema)
+		}
+		return pschema.TypeSpec{Type: "array", Items: &items}
+	case "object":
+		if len(s.Properties) > 0 {
+			if s.XPreserveUnknownFields != nil && *s.XPreserveUnknownFields {
+				// Fields beyond the known properties are allowed, and a typed object
+				// would drop them.
+				return anyType
+			}
+			tk := tokens.NewTypeToken(b.module, tokens.TypeName(name))
+			b.types[tk] = pschema.ComplexTypeSpec{ObjectTypeSpec: b.objectSpec(name, s)}
+			return pschema.TypeSpec{Ref: "#/types/" + string(tk)}
+		}
+		values := anyType
+		if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+			values = b.typeSpec(name, *s.AdditionalProperties.Schema)
+		}
+		return pschema.TypeSpec{Type: "object", AdditionalProperties: &values}
+	default:
+		return anyType
+	}
+}
+
+func (b *schemaBuilder) objectSpec(name string, s apiextv1.JSONSchemaProps) pschema.ObjectTypeSpec {
+	props := make(map[string]pschema.PropertySpec, len(s.Properties))
+	for k, v := range s.Properties {
+		props[k] = pschema.PropertySpec{
+			TypeSpec:    b.typeSpec(name+typeName(k), v),
+			Description: v.Description,
+		}
+	}
+	required := slices.Clone(s.Required)
+	slices.Sort(required)
+	return pschema.ObjectTypeSpec{
+		Type:        "object",
+		Description: s.Description,
+		Properties:  props,
+		Required:    required,
+	}
+}
+
+// typeName converts a property name, such as "tls-config", into a part of a type name,
+// such as "TlsConfig".
+func typeName(property string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range property {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,190 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package localfile provides utilities for providers that manage files on the local
+// machine.
+//
+// The engine runs resource operations in parallel (see `pulumi up --parallel`), so two
+// operations may touch the same file at once. [WithLock] serializes access to a file across
+// goroutines and processes, and [WriteFile] replaces a file's content atomically, so
+// readers never observe a partial write:
+//
+//	func (*File) Update(ctx context.Context, id string, olds FileState, news FileArgs, preview bool) (FileState, error) {
+//		if !preview {
+//			err := localfile.WithLock(ctx, news.Path, func() error {
+//				return localfile.WriteFile(news.Path, []byte(news.Content), 0o644)
+//			})
+//			if err != nil {
+//				return FileState{}, err
+//			}
+//		}
+//		return FileState(news), nil
+//	}
+package localfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockSuffix is appended to the path of a file to get the path of its lock file.
+const LockSuffix = ".lock"
+
+// Lock is a held advisory lock on a file. See [Acquire].
+type Lock struct {
+	f *os.File
+}
+
+// Acquire takes an exclusive advisory lock on path, waiting until the lock is free or ctx
+// is done.
+//
+// The lock is held on a separate lock file, path + [LockSuffix], so path itself may be
+// created, replaced or removed while the lock is held. The lock file is left in place
+// when the lock is released, so that it can be reused. Use [Remove] to remove path along
+// with its lock file.
+//
+// Locks are advisory. They only exclude other callers of Acquire, in this process or
+// another one, and do not prevent other programs from accessing path.
+func Acquire(ctx context.Context, path string) (*Lock, error) {
+	lockPath := path + LockSuffix
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait with a capped exponential backoff, so that short critical sections are
+	// resolved quickly without spinning on long ones.
+	const maxWait = 100 * time.Millisecond
+	wait := time.Millisecond
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("locking %q: %w", path, err), f.Close())
+		}
+		if ok {
+			if current(f, lockPath) {
+				return &Lock{f: f}, nil
+			}
+			// The lock file was removed by [Remove] while we waited, so the lock we
+			// hold no longer excludes anyone. Start again with a new lock file.
+			if err := errors.Join(unlock(f), f.Close()); err != nil {
+				return nil, err
+			}
+			return Acquire(ctx, path)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(fmt.Errorf("locking %q: %w", path, ctx.Err()), f.Close())
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, maxWait)
+	}
+}
+
+// current reports whether f is still the file at lockPath.
+func current(f *os.File, lockPath string) bool {
+	held, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	onDisk, err := os.Stat(lockPath)
+	return err == nil && os.SameFile(held, onDisk)
+}
+
+// Release the lock. Release is safe to call more than once.
+func (l *Lock) Release() error {
+	if l == nil || l.f == nil {
+		return nil
+	}
+	f := l.f
+	l.f = nil
+	return errors.Join(unlock(f), f.Close())
+}
+
+// WithLock calls f while holding the lock on path. See [Acquire].
+func WithLock(ctx context.Context, path string, f func() error) (err error) {
+	lock, err := Acquire(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if releaseErr := lock.Release(); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+	}()
+	return f()
+}
+
+// Remove removes path and its lock file while holding the lock on path, so that a
+// deleted file does not leave its lock file behind.
+//
+// Callers waiting on the lock when it is removed take the lock on a new lock file. Where
+// the platform does not allow a locked file to be removed, such as on Windows, the lock
+// file is left in place.
+//
+// As with [os.Remove], the error satisfies errors.Is(err, fs.ErrNotExist) if path does
+// not exist. The lock file is removed either way.
+func Remove(ctx context.Context, path string) error {
+	lock, err := Acquire(ctx, path)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	// Removing the lock file is best effort: a lock file left behind is harmless.
+	_ = os.Remove(lock.f.Name())
+	return errors.Join(err, lock.Release())
+}
+
+// WriteFile writes data to path atomically: path either keeps its previous content or
+// holds all of data, even if the provider is interrupted.
+//
+// The data is written to a temporary file next to path, which is then renamed over path.
+// If path already exists, perm is ignored and its permissions are kept.
+func WriteFile(path string, data []byte, perm os.FileMode) (err error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			// Don't leave a partially written file behind.
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Sync(); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,140 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localfile
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockExcludes(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "counter")
+	require.NoError(t, os.WriteFile(path, []byte("0"), 0o600))
+
+	// Each goroutine increments the counter with a read-modify-write, which loses
+	// updates unless the lock excludes the other goroutines.
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := WithLock(context.Background(), path, func() error {
+				b, err := os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				i, err := strconv.Atoi(string(b))
+				if err != nil {
+					return err
+				}
+				return WriteFile(path, []byte(strconv.Itoa(i+1)), 0o600)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(n), string(b))
+}
+
+func TestAcquireCanceled(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "file")
+
+	held, err := Acquire(context.Background(), path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = Acquire(ctx, path)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, held.Release())
+	require.NoError(t, held.Release(), "Release is idempotent")
+
+	lock, err := Acquire(context.Background(), path)
+	require.NoError(t, err)
+	assert.NoError(t, lock.Release())
+}
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, WriteFile(path, []byte("content"), 0o600))
+
+	// A caller waiting on the lock while the file is removed still gets the lock.
+	held, err := Acquire(context.Background(), path)
+	require.NoError(t, err)
+	removed := make(chan error)
+	go func() { removed <- Remove(context.Background(), path) }()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, held.Release())
+	require.NoError(t, <-removed)
+
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	if runtime.GOOS != "windows" {
+		_, err = os.Stat(path + LockSuffix)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+
+	// Removing a file that doesn't exist reports it, and still cleans up the lock file.
+	assert.ErrorIs(t, Remove(context.Background(), path), fs.ErrNotExist)
+	if runtime.GOOS != "windows" {
+		_, err = os.Stat(path + LockSuffix)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+
+	lock, err := Acquire(context.Background(), path)
+	require.NoError(t, err)
+	assert.NoError(t, lock.Release())
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	require.NoError(t, WriteFile(path, []byte("first"), 0o600))
+	require.NoError(t, WriteFile(path, []byte("second"), 0o644))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(b))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "existing permissions are kept")
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
@@ -18,10 +18,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	pprovider "github.com/pulumi/pulumi/pkg/v3/resource/provider"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 
 	"github.com/pulumi/pulumi-go-provider/internal/key"
 )
@@ -33,10 +38,30 @@ type Logger struct {
 	ctx   context.Context
 	inner logSink
 	urn   resource.URN
+	scope string // The prefix of each message, ending in ": " if non-empty.
+}
+
+// Scoped returns a logger that prefixes each message with name, for messages about a
+// sub-operation of the request, such as "waiting for DNS: 2 of 3 records ready".
+//
+// Scopes nest: l.Scoped("a").Scoped("b") prefixes messages with "a: b: ".
+func (l Logger) Scoped(name string) Logger {
+	if name != "" {
+		l.scope += name + ": "
+	}
+	return l
+}
+
+func (l Logger) log(severity diag.Severity, msg string, attrs ...slog.Attr) {
+	l.inner.Log(l.ctx, l.urn, severity, l.scope+msg, attrs...)
+}
+
+func (l Logger) logStatus(severity diag.Severity, msg string) {
+	l.inner.LogStatus(l.ctx, l.urn, severity, l.scope+msg)
 }
 
 // Debug logs a debug message visible to the user.
-func (l Logger) Debug(msg string) { l.inner.Log(l.ctx, l.urn, diag.Debug, msg) }
+func (l Logger) Debug(msg string) { l.log(diag.Debug, msg) }
 
 // Debugf logs a debug message visible to the user, formatting it with [fmt.Sprintf].
 func (l Logger) Debugf(msg string, a ...any) { l.Debug(fmt.Sprintf(msg, a...)) }
@@ -44,95 +69,254 @@ func (l Logger) Debugf(msg string, a ...any) { l.Debug(fmt.Sprintf(msg, a...)) }
 // DebugStatus logs a debug message visible to the user.
 //
 // The message will only be displayed while it is the latest message.
-func (l Logger) DebugStatus(msg string) { l.inner.LogStatus(l.ctx, l.urn, diag.Debug, msg) }
+func (l Logger) DebugStatus(msg string) { l.logStatus(diag.Debug, msg) }
 
 // DebugStatusf logs a debug message visible to the user, formatting it with [fmt.Sprintf].
 //
 // The message will only be displayed while it is the latest message.
 func (l Logger) DebugStatusf(msg string, a ...any) { l.DebugStatus(fmt.Sprintf(msg, a...)) }
 
-func (l Logger) Info(msg string)                  { l.inner.Log(l.ctx, l.urn, diag.Info, msg) }
+func (l Logger) Info(msg string)                  { l.log(diag.Info, msg) }
 func (l Logger) Infof(msg string, a ...any)       { l.Info(fmt.Sprintf(msg, a...)) }
-func (l Logger) InfoStatus(msg string)            { l.inner.LogStatus(l.ctx, l.urn, diag.Info, msg) }
+func (l Logger) InfoStatus(msg string)            { l.logStatus(diag.Info, msg) }
 func (l Logger) InfoStatusf(msg string, a ...any) { l.InfoStatus(fmt.Sprintf(msg, a...)) }
 
-func (l Logger) Warning(msg string)                  { l.inner.Log(l.ctx, l.urn, diag.Warning, msg) }
+func (l Logger) Warning(msg string)                  { l.log(diag.Warning, msg) }
 func (l Logger) Warningf(msg string, a ...any)       { l.Warning(fmt.Sprintf(msg, a...)) }
-func (l Logger) WarningStatus(msg string)            { l.inner.LogStatus(l.ctx, l.urn, diag.Warning, msg) }
+func (l Logger) WarningStatus(msg string)            { l.logStatus(diag.Warning, msg) }
 func (l Logger) WarningStatusf(msg string, a ...any) { l.WarningStatus(fmt.Sprintf(msg, a...)) }
 
-func (l Logger) Error(msg string)                  { l.inner.Log(l.ctx, l.urn, diag.Error, msg) }
+func (l Logger) Error(msg string)                  { l.log(diag.Error, msg) }
 func (l Logger) Errorf(msg string, a ...any)       { l.Error(fmt.Sprintf(msg, a...)) }
-func (l Logger) ErrorStatus(msg string)            { l.inner.LogStatus(l.ctx, l.urn, diag.Error, msg) }
+func (l Logger) ErrorStatus(msg string)            { l.logStatus(diag.Error, msg) }
 func (l Logger) ErrorStatusf(msg string, a ...any) { l.ErrorStatus(fmt.Sprintf(msg, a...)) }
 
+// Slog returns a [slog.Logger] that logs through l.
+//
+// Records are shown to the user at the severity matching their level, associated with the
+// same URN and scope as l. Attributes are appended to the message as `key=value` pairs when it is
+// sent to the engine, and kept structured when it is sent to a [slog.Handler].
+func (l Logger) Slog() *slog.Logger { return slog.New(&loggerHandler{l: l}) }
+
+// WithLogHandler returns a provider that sends every message logged with [GetLogger] to
+// handler, such as an adapter for the provider's existing logging library. It does not
+// mutate its receiver.
+//
+// Messages are still shown to the user by the engine. Each record carries the "urn",
+// "token" and "requestId" of the request it was logged from, when they are known.
+func (d Provider) WithLogHandler(handler slog.Handler) Provider {
+	d.LogHandler = handler
+	return d
+}
+
 type logSink interface {
 	// Log logs a global message, including errors and warnings.
-	Log(context.Context, resource.URN, diag.Severity, string)
+	Log(context.Context, resource.URN, diag.Severity, string, ...slog.Attr)
 	// LogStatus logs a global status message, including errors and warnings. Status messages will
 	// appear in the `Info` column of the progress display, but not in the final output.
-	LogStatus(context.Context, resource.URN, diag.Severity, string)
+	LogStatus(context.Context, resource.URN, diag.Severity, string, ...slog.Attr)
 }
 
+// GetLogger returns a logger for the current request.
+//
+// Messages are shown to the user by the engine, and sent to the handler set with
+// [Provider.WithLogHandler]. Outside of the engine, such as in unit tests, messages are
+// sent to that handler or to [slog.Default].
 func GetLogger(ctx context.Context) Logger {
 	var (
 		sink logSink = slogSink{}
 		urn  resource.URN
 	)
+	handler, _ := ctx.Value(key.LogHandler).(slog.Handler)
+	if handler != nil {
+		sink = slogSink{handler}
+	}
 	if v := ctx.Value(key.Logger); v != nil {
-		sink = v.(logSink)
+		if handler != nil {
+			sink = teeSink{v.(logSink), sink}
+		} else {
+			sink = v.(logSink)
+		}
 	}
 	if v := ctx.Value(key.URN); v != nil {
 		urn = v.(resource.URN)
 	}
-	return Logger{ctx, sink, urn}
+	return Logger{ctx: ctx, inner: sink, urn: urn}
 }
 
 var (
 	_ logSink = (*hostSink)(nil)
 	_ logSink = (*slogSink)(nil)
+	_ logSink = (*teeSink)(nil)
 )
 
-type hostSink struct{ host *pprovider.HostClient }
+// hostLogTimeout bounds how long a message may take to reach the engine.
+const hostLogTimeout = 5 * time.Second
+
+type hostSink struct {
+	host    *pprovider.HostClient
+	pending *pendingLogs
+}
+
+// sendContext returns the context to send a message logged with ctx. Messages are sent
+// even once ctx is canceled, so that errors logged as a request is canceled or abandoned
+// still reach the engine.
+func (h hostSink) sendContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), hostLogTimeout)
+}
 
-func (h hostSink) Log(ctx context.Context, urn resource.URN, severity diag.Severity, msg string) {
-	err := h.host.Log(ctx, severity, urn, msg)
+func (h hostSink) Log(ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr) {
+	defer h.pending.add()()
+	sendCtx, cancel := h.sendContext(ctx)
+	defer cancel()
+	err := h.host.Log(sendCtx, severity, urn, appendAttrs(msg, attrs))
 	if err != nil {
 		slog := slog.Default().With("hostLogFailed", err.Error())
-		slogSink{}.log(ctx, slog, urn, severity, msg)
+		slogSink{}.log(ctx, slog, urn, severity, msg, attrs)
 	}
 }
 
-func (h hostSink) LogStatus(ctx context.Context, urn resource.URN, severity diag.Severity, msg string) {
-	err := h.host.LogStatus(ctx, severity, urn, msg)
+func (h hostSink) LogStatus(
+	ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr,
+) {
+	defer h.pending.add()()
+	sendCtx, cancel := h.sendContext(ctx)
+	defer cancel()
+	err := h.host.LogStatus(sendCtx, severity, urn, appendAttrs(msg, attrs))
 	if err != nil {
 		slog := slog.Default().With(
 			"hostLogFailed", err.Error(),
 			"kind", "status",
 		)
-		slogSink{}.log(ctx, slog, urn, severity, msg)
+		slogSink{}.log(ctx, slog, urn, severity, msg, attrs)
+	}
+}
+
+// appendAttrs renders attrs as `key=value` pairs after msg, for sinks that only accept
+// text.
+func appendAttrs(msg string, attrs []slog.Attr) string {
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, a := range attrs {
+		v := a.Value.Resolve().String()
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = strconv.Quote(v)
+		}
+		fmt.Fprintf(&b, " %s=%s", a.Key, v)
+	}
+	return b.String()
+}
+
+// slogSink logs to handler, or to [slog.Default] if handler is nil.
+type slogSink struct{ handler slog.Handler }
+
+func (s slogSink) logger() *slog.Logger {
+	if s.handler == nil {
+		return slog.Default()
+	}
+	return slog.New(s.handler)
+}
+
+func (slogSink) log(
+	ctx context.Context, logger *slog.Logger, urn resource.URN, severity diag.Severity, msg string,
+	attrs []slog.Attr,
+) {
+	base := []slog.Attr{{Key: "urn", Value: slog.StringValue(string(urn))}}
+	if tk, ok := ctx.Value(key.Token).(tokens.Type); ok && tk != "" {
+		base = append(base, slog.String("token", string(tk)))
+	}
+	if id, ok := ctx.Value(key.RequestID).(string); ok {
+		base = append(base, slog.String("requestId", id))
 	}
+	logger.LogAttrs(ctx, levelOf(severity), msg, append(base, attrs...)...)
+}
+
+func (s slogSink) Log(ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr) {
+	s.log(ctx, s.logger(), urn, severity, msg, attrs)
+}
+
+func (s slogSink) LogStatus(
+	ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr,
+) {
+	s.log(ctx, s.logger().With("kind", "status"), urn, severity, msg, attrs)
+}
+
+// teeSink logs to both of its sinks.
+type teeSink struct{ a, b logSink }
+
+func (t teeSink) Log(ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr) {
+	t.a.Log(ctx, urn, severity, msg, attrs...)
+	t.b.Log(ctx, urn, severity, msg, attrs...)
 }
 
-type slogSink struct{}
+func (t teeSink) LogStatus(
+	ctx context.Context, urn resource.URN, severity diag.Severity, msg string, attrs ...slog.Attr,
+) {
+	t.a.LogStatus(ctx, urn, severity, msg, attrs...)
+	t.b.LogStatus(ctx, urn, severity, msg, attrs...)
+}
 
-func (slogSink) log(ctx context.Context, slog *slog.Logger, urn resource.URN, severity diag.Severity, msg string) {
-	log := slog.InfoContext // We default to Info as the log level
+func levelOf(severity diag.Severity) slog.Level {
 	switch severity {
 	case diag.Debug:
-		log = slog.DebugContext
+		return slog.LevelDebug
 	case diag.Warning:
-		log = slog.WarnContext
+		return slog.LevelWarn
 	case diag.Error:
-		log = slog.ErrorContext
+		return slog.LevelError
+	default:
+		// We default to Info as the log level
+		return slog.LevelInfo
+	}
+}
+
+func severityOf(level slog.Level) diag.Severity {
+	switch {
+	case level < slog.LevelInfo:
+		return diag.Debug
+	case level < slog.LevelWarn:
+		return diag.Info
+	case level < slog.LevelError:
+		return diag.Warning
+	default:
+		return diag.Error
 	}
-	log(ctx, msg, "urn", string(urn))
 }
 
-func (s slogSink) Log(ctx context.Context, urn resource.URN, severity diag.Severity, msg string) {
-	s.log(ctx, slog.Default(), urn, severity, msg)
+// loggerHandler is the [slog.Handler] returned by [Logger.Slog].
+type loggerHandler struct {
+	l     Logger
+	attrs []slog.Attr
+	group string // The prefix of attribute keys, ending in "." if non-empty.
+}
+
+// Enabled always returns true, since the engine decides which severities to show.
+func (*loggerHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *loggerHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := slices.Clone(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, slog.Attr{Key: h.group + a.Key, Value: a.Value})
+		return true
+	})
+	h.l.log(severityOf(r.Level), r.Message, attrs...)
+	return nil
+}
+
+func (h *loggerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = slices.Clone(h.attrs)
+	for _, a := range attrs {
+		c.attrs = append(c.attrs, slog.Attr{Key: h.group + a.Key, Value: a.Value})
+	}
+	return &c
 }
 
-func (s slogSink) LogStatus(ctx context.Context, urn resource.URN, severity diag.Severity, msg string) {
-	s.log(ctx, slog.Default().With("kind", "status"), urn, severity, msg)
+func (h *loggerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group = h.group + name + "."
+	return &c
 }
@@ -24,6 +24,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/key"
 	"github.com/pulumi/pulumi-go-provider/middleware/cancel/internal/evict"
 )
 
@@ -35,6 +36,12 @@ import (
 // 2. When `Cancel` is called, all outstanding gRPC methods have their associated contexts
 // canceled.
 //
+// Cancellation also reaches the [github.com/pulumi/pulumi/sdk/v3/go/pulumi.Context] passed
+// to components in Construct and to resource methods in Call: its Context method returns
+// a context that is canceled with the request, and any RegisterResource, Invoke or Call
+// made through it that has not completed fails. Component code that blocks should select
+// on `ctx.Context().Done()` and return promptly once it is closed.
+//
 // A `Wrap`ped provider will still call the `Cancel` method on the underlying provider. If
 // NotImplemented is returned, it will be swallowed.
 func Wrap(provider p.Provider) p.Provider {
@@ -46,7 +53,7 @@ func Wrap(provider p.Provider) p.Provider {
 		if timeout == noTimeout {
 			ctx, cancel = context.WithCancel(ctx)
 		} else {
-			ctx, cancel = context.WithTimeout(ctx, time.Second*time.Duration(timeout))
+			ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout*float64(time.Second)))
 		}
 
 		handle := cancelFuncs.Insert(cancel)
@@ -73,6 +80,7 @@ func Wrap(provider p.Provider) p.Provider {
 	// Wrap each gRPC method to transform a cancel call into a cancel on
 	// context.Cancel.
 	wrapper.GetSchema = setCancel2(cancel, provider.GetSchema, nil)
+	wrapper.GetMapping = setCancel2(cancel, provider.GetMapping, nil)
 	wrapper.CheckConfig = setCancel2(cancel, provider.CheckConfig, nil)
 	wrapper.DiffConfig = setCancel2(cancel, provider.DiffConfig, nil)
 	wrapper.Configure = setCancel1(cancel, provider.Configure, nil)
@@ -86,13 +94,33 @@ func Wrap(provider p.Provider) p.Provider {
 	wrapper.Update = setCancel2(cancel, provider.Update, func(r p.UpdateRequest) float64 {
 		return r.Timeout
 	})
-	wrapper.Delete = setCancel1(cancel, provider.Delete, func(r p.DeleteRequest) float64 {
+	wrapper.Delete = setCancel2(cancel, provider.Delete, func(r p.DeleteRequest) float64 {
 		return r.Timeout
 	})
 	wrapper.Construct = setCancel2(cancel, provider.Construct, nil)
+	wrapper.Call = setCancel2(cancel, cancelCall(provider.Call), nil)
 	return wrapper
 }
 
+// cancelCall ties the pulumi.Context of each call to f to the context of the call.
+//
+// The pulumi.Context of a [p.CallRequest] is created before the request reaches the
+// middleware, so it doesn't inherit the cancellation of ctx.
+func cancelCall(
+	f func(context.Context, p.CallRequest) (p.CallResponse, error),
+) func(context.Context, p.CallRequest) (p.CallResponse, error) {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req p.CallRequest) (p.CallResponse, error) {
+		if cancel, ok := ctx.Value(key.CancelCall).(context.CancelFunc); ok {
+			stop := context.AfterFunc(ctx, cancel)
+			defer stop()
+		}
+		return f(ctx, req)
+	}
+}
+
 func setCancel1[
 	Req any,
 	F func(context.Context, Req) error,
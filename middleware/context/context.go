@@ -31,6 +31,7 @@ func Wrap(provider p.Provider, wrapper Wrapper) p.Provider {
 	return p.Provider{
 		GetSchema:   delegateIO(wrapper, provider.GetSchema),
 		Cancel:      delegate(wrapper, provider.Cancel),
+		GetMapping:  delegateIO(wrapper, provider.GetMapping),
 		CheckConfig: delegateIO(wrapper, provider.CheckConfig),
 		DiffConfig:  delegateIO(wrapper, provider.DiffConfig),
 		Configure:   delegateI(wrapper, provider.Configure),
@@ -40,8 +41,15 @@ func Wrap(provider p.Provider, wrapper Wrapper) p.Provider {
 		Create:      delegateIO(wrapper, provider.Create),
 		Read:        delegateIO(wrapper, provider.Read),
 		Update:      delegateIO(wrapper, provider.Update),
-		Delete:      delegateI(wrapper, provider.Delete),
+		Delete:      delegateIO(wrapper, provider.Delete),
 		Construct:   delegateIO(wrapper, provider.Construct),
+
+		Capabilities:         provider.Capabilities,
+		LogHandler:           provider.LogHandler,
+		FrameworkLog:         provider.FrameworkLog,
+		ShutdownGracePeriod:  provider.ShutdownGracePeriod,
+		MinimumPulumiVersion: provider.MinimumPulumiVersion,
+		OnShutdown:           provider.OnShutdown,
 	}
 }
 
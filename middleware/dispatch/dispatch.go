@@ -115,7 +115,7 @@ func Wrap(provider p.Provider, opts Options) p.Provider {
 			}
 			return p.UpdateResponse{}, notFound(tk)
 		}
-		wrapper.Delete = func(ctx context.Context, req p.DeleteRequest) error {
+		wrapper.Delete = func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
 			tk := fix(req.Urn.Type())
 			r, ok := customs[tk]
 			if ok {
@@ -123,7 +123,7 @@ func Wrap(provider p.Provider, opts Options) p.Provider {
 			} else if provider.Delete != nil {
 				return provider.Delete(ctx, req)
 			}
-			return notFound(tk)
+			return p.DeleteResponse{}, notFound(tk)
 		}
 	}
 	if len(opts.Components) > 0 {
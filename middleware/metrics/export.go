@@ -0,0 +1,136 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// ServeHTTP serves the recorded metrics in the Prometheus text exposition format:
+//
+//   - pulumi_provider_rpc_total counts calls by method, token and status code.
+//   - pulumi_provider_rpc_duration_seconds is a histogram of call latency by method,
+//     token and status code.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.writePrometheus(w)
+}
+
+func (m *Metrics) writePrometheus(w io.Writer) {
+	m.m.Lock()
+	keys := make([]seriesKey, 0, len(m.series))
+	snapshot := make(map[seriesKey]series, len(m.series))
+	for k, s := range m.series {
+		keys = append(keys, k)
+		snapshot[k] = *s
+	}
+	m.m.Unlock()
+
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		return cmp.Or(
+			cmp.Compare(a.method, b.method),
+			cmp.Compare(a.token, b.token),
+			cmp.Compare(a.code, b.code))
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP pulumi_provider_rpc_total The number of calls into the provider.\n")
+	b.WriteString("# TYPE pulumi_provider_rpc_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&b, "pulumi_provider_rpc_total{%s} %d\n", k.labels(), snapshot[k].count)
+	}
+
+	b.WriteString("# HELP pulumi_provider_rpc_duration_seconds The latency of calls into the provider.\n")
+	b.WriteString("# TYPE pulumi_provider_rpc_duration_seconds histogram\n")
+	for _, k := range keys {
+		s, labels := snapshot[k], k.labels()
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(&b, "pulumi_provider_rpc_duration_seconds_bucket{%s,le=%q} %d\n",
+				labels, seconds(bound), s.buckets[i])
+		}
+		fmt.Fprintf(&b, "pulumi_provider_rpc_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n",
+			labels, s.count)
+		fmt.Fprintf(&b, "pulumi_provider_rpc_duration_seconds_sum{%s} %s\n", labels, seconds(s.sum))
+		fmt.Fprintf(&b, "pulumi_provider_rpc_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	contract.IgnoreError(err)
+}
+
+func (k seriesKey) labels() string {
+	return fmt.Sprintf("method=%q,token=%q,code=%q", k.method, k.token, k.code)
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// SendToStatsd sends each call recorded after SendToStatsd returns to the statsd server
+// at addr, such as "localhost:8125".
+//
+// Each call is sent as a pulumi_provider.rpc counter and a pulumi_provider.rpc.duration
+// timer, tagged with its method, token and status code in the DogStatsD format. Metrics
+// are sent over UDP, so a missing server does not slow the provider down. Call
+// [Metrics.Close] to stop sending metrics.
+func (m *Metrics) SendToStatsd(addr string) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return err
+	}
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.statsd != nil {
+		contract.IgnoreClose(m.statsd)
+	}
+	m.statsd = conn
+	return nil
+}
+
+// Close stops sending metrics to the statsd server set by [Metrics.SendToStatsd], and
+// closes the connection to it. Calls are still recorded for [Metrics.ServeHTTP].
+func (m *Metrics) Close() error {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.statsd == nil {
+		return nil
+	}
+	err := m.statsd.Close()
+	m.statsd = nil
+	return err
+}
+
+// sendStatsd sends a single call to m.statsd. It must be called with m.m held.
+func (m *Metrics) sendStatsd(key seriesKey, elapsed time.Duration) {
+	tags := fmt.Sprintf("method:%s,code:%s", key.method, key.code)
+	if key.token != "" {
+		tags += ",token:" + string(key.token)
+	}
+	msg := fmt.Sprintf("pulumi_provider.rpc:1|c|#%s\npulumi_provider.rpc.duration:%d|ms|#%s\n",
+		tags, elapsed.Milliseconds(), tags)
+	// Metrics are best effort: a lost packet is not worth failing a call over.
+	_, err := m.statsd.Write([]byte(msg))
+	contract.IgnoreError(err)
+}
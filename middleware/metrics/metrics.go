@@ -0,0 +1,226 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides a middleware that counts the calls into a provider and
+// measures their latency, for operational visibility into large providers.
+//
+// Metrics are served in the Prometheus text format (see [Metrics.ServeHTTP]) or sent to
+// a statsd server (see [Metrics.SendToStatsd]). Most providers will use [FromEnv], which
+// only records metrics when PULUMI_PROVIDER_METRICS is set:
+//
+//	provider, err := metrics.FromEnv(provider)
+//	if err != nil {
+//		return err
+//	}
+//	return p.RunProvider("my-provider", version, provider)
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// EnvVar is the environment variable read by [FromEnv].
+const EnvVar = "PULUMI_PROVIDER_METRICS"
+
+// FromEnv wraps provider with a new [Metrics] when PULUMI_PROVIDER_METRICS is set, and
+// returns provider unchanged otherwise.
+//
+// PULUMI_PROVIDER_METRICS selects where metrics are exported:
+//
+//   - A port ("9090") or an address ("localhost:9090") serves Prometheus metrics over
+//     HTTP at /metrics. A bare port is served on localhost only. If the address can't be
+//     listened on, for example because another provider process already serves metrics
+//     on it, a warning is written to stderr and provider is returned unchanged. The
+//     server is closed when the provider shuts down (see [p.Provider.OnShutdown]).
+//   - "statsd://host:port" sends each call to the statsd server at host:port. The
+//     connection is closed when the provider shuts down.
+func FromEnv(provider p.Provider) (p.Provider, error) {
+	target := os.Getenv(EnvVar)
+	if target == "" {
+		return provider, nil
+	}
+	m := &Metrics{}
+	if addr, ok := strings.CutPrefix(target, "statsd://"); ok {
+		if err := m.SendToStatsd(addr); err != nil {
+			return p.Provider{}, fmt.Errorf("%s: %w", EnvVar, err)
+		}
+		return m.Wrap(provider).WithOnShutdown(func(context.Context) {
+			_ = m.Close()
+		}), nil
+	}
+
+	addr := strings.TrimPrefix(target, "http://")
+	if !strings.Contains(addr, ":") {
+		addr = "localhost:" + addr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		// Metrics are for visibility only, so they never stop the provider from starting.
+		fmt.Fprintf(os.Stderr, "%s: continuing without metrics: %s\n", EnvVar, err)
+		return provider, nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	return m.Wrap(provider).WithOnShutdown(func(context.Context) {
+		_ = server.Close()
+	}), nil
+}
+
+// Metrics records the number and latency of the calls made to the providers it wraps.
+//
+// The zero value is ready to use. A Metrics may wrap several providers, whose calls are
+// recorded together.
+type Metrics struct {
+	m      sync.Mutex // Guards the fields below.
+	series map[seriesKey]*series
+	statsd net.Conn
+}
+
+// seriesKey identifies the calls that are recorded together.
+type seriesKey struct {
+	// method is the name of the gRPC method, such as "Create".
+	method string
+	// token is the resource type or function token of the call, if any.
+	token tokens.Type
+	// code is the gRPC status code of the result, such as "OK".
+	code string
+}
+
+type series struct {
+	count int
+	// buckets holds the number of calls that took at most each of latencyBuckets.
+	buckets [len(latencyBuckets)]int
+	sum     time.Duration
+}
+
+// latencyBuckets are the upper bounds of the latency histogram. Resource operations can
+// take minutes, so the buckets extend well beyond the usual RPC latencies.
+var latencyBuckets = [...]time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+}
+
+// Wrap provider so that each of its calls is recorded in m.
+func (m *Metrics) Wrap(provider p.Provider) p.Provider {
+	provider.GetSchema = wrapIO(m, "GetSchema", provider.GetSchema, nil)
+	provider.Parameterize = wrapIO(m, "Parameterize", provider.Parameterize, nil)
+	provider.CheckConfig = wrapIO(m, "CheckConfig", provider.CheckConfig, nil)
+	provider.DiffConfig = wrapIO(m, "DiffConfig", provider.DiffConfig, nil)
+	provider.GetMapping = wrapIO(m, "GetMapping", provider.GetMapping, nil)
+	provider.Configure = wrapI(m, "Configure", provider.Configure, nil)
+	provider.Invoke = wrapIO(m, "Invoke", provider.Invoke,
+		func(r p.InvokeRequest) tokens.Type { return r.Token })
+	provider.Check = wrapIO(m, "Check", provider.Check,
+		func(r p.CheckRequest) tokens.Type { return r.Urn.Type() })
+	provider.Diff = wrapIO(m, "Diff", provider.Diff,
+		func(r p.DiffRequest) tokens.Type { return r.Urn.Type() })
+	provider.Create = wrapIO(m, "Create", provider.Create,
+		func(r p.CreateRequest) tokens.Type { return r.Urn.Type() })
+	provider.Read = wrapIO(m, "Read", provider.Read,
+		func(r p.ReadRequest) tokens.Type { return r.Urn.Type() })
+	provider.Update = wrapIO(m, "Update", provider.Update,
+		func(r p.UpdateRequest) tokens.Type { return r.Urn.Type() })
+	provider.Delete = wrapIO(m, "Delete", provider.Delete,
+		func(r p.DeleteRequest) tokens.Type { return r.Urn.Type() })
+	provider.Call = wrapIO(m, "Call", provider.Call,
+		func(r p.CallRequest) tokens.Type { return tokens.Type(r.Tok) })
+	provider.Construct = wrapIO(m, "Construct", provider.Construct,
+		func(r p.ConstructRequest) tokens.Type { return r.URN.Type() })
+	return provider
+}
+
+// wrapIO records calls to f. token returns the token of a request, and may be nil for
+// methods that are not specific to a resource or function.
+func wrapIO[I, O any, F func(context.Context, I) (O, error)](
+	m *Metrics, method string, f F, token func(I) tokens.Type,
+) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (O, error) {
+		start := time.Now()
+		resp, err := f(ctx, req)
+		m.record(method, tokenOf(token, req), err, time.Since(start))
+		return resp, err
+	}
+}
+
+func wrapI[I any, F func(context.Context, I) error](
+	m *Metrics, method string, f F, token func(I) tokens.Type,
+) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) error {
+		start := time.Now()
+		err := f(ctx, req)
+		m.record(method, tokenOf(token, req), err, time.Since(start))
+		return err
+	}
+}
+
+func tokenOf[I any](token func(I) tokens.Type, req I) tokens.Type {
+	if token == nil {
+		return ""
+	}
+	return token(req)
+}
+
+func (m *Metrics) record(method string, token tokens.Type, err error, elapsed time.Duration) {
+	key := seriesKey{method: method, token: token, code: status.Code(err).String()}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.series == nil {
+		m.series = map[seriesKey]*series{}
+	}
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{}
+		m.series[key] = s
+	}
+	s.count++
+	s.sum += elapsed
+	for i, bound := range latencyBuckets {
+		if elapsed <= bound {
+			s.buckets[i]++
+		}
+	}
+
+	if m.statsd != nil {
+		m.sendStatsd(key, elapsed)
+	}
+}
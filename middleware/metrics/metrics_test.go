@@ -0,0 +1,155 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+var urn = resource.URN("urn:pulumi:stack::proj::test:index:A::a")
+
+func testProvider() p.Provider {
+	return p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id"}, nil
+		},
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			return p.DeleteResponse{}, errors.New("failed")
+		},
+		GetMapping: func(context.Context, p.GetMappingRequest) (p.GetMappingResponse, error) {
+			return p.GetMappingResponse{}, nil
+		},
+	}
+}
+
+func TestPrometheus(t *testing.T) {
+	t.Parallel()
+
+	m := &Metrics{}
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), m.Wrap(testProvider()))
+	for i := 0; i < 2; i++ {
+		_, err := server.Create(p.CreateRequest{Urn: urn})
+		require.NoError(t, err)
+	}
+	_, err := server.Delete(p.DeleteRequest{Urn: urn})
+	require.Error(t, err)
+	_, err = server.GetMapping(p.GetMappingRequest{Key: "terraform"})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	assert.Contains(t, body, "# TYPE pulumi_provider_rpc_total counter\n"+
+		`pulumi_provider_rpc_total{method="Create",token="test:index:A",code="OK"} 2`+"\n"+
+		`pulumi_provider_rpc_total{method="Delete",token="test:index:A",code="Unknown"} 1`+"\n"+
+		`pulumi_provider_rpc_total{method="GetMapping",token="",code="OK"} 1`+"\n")
+	assert.Contains(t, body,
+		`pulumi_provider_rpc_duration_seconds_bucket{method="Create",token="test:index:A",code="OK",le="300"} 2`)
+	assert.Contains(t, body,
+		`pulumi_provider_rpc_duration_seconds_bucket{method="Create",token="test:index:A",code="OK",le="+Inf"} 2`)
+	assert.Contains(t, body,
+		`pulumi_provider_rpc_duration_seconds_count{method="Delete",token="test:index:A",code="Unknown"} 1`)
+}
+
+func TestStatsd(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	m := &Metrics{}
+	require.NoError(t, m.SendToStatsd(conn.LocalAddr().String()))
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), m.Wrap(testProvider()))
+	_, err = server.Create(p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Regexp(t, `^pulumi_provider.rpc:1\|c\|#method:Create,code:OK,token:test:index:A
+pulumi_provider.rpc.duration:\d+\|ms\|#method:Create,code:OK,token:test:index:A
+$`, string(buf[:n]))
+
+	// After Close, calls are no longer sent.
+	require.NoError(t, m.Close())
+	_, err = server.Create(p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+	_, _, err = conn.ReadFrom(buf)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+// TestFromEnv sets an environment variable, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestFromEnv(t *testing.T) {
+	ctx := context.Background()
+
+	// The port is in use, so the provider runs without metrics.
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Setenv(EnvVar, busy.Addr().String())
+	provider, err := FromEnv(testProvider())
+	require.NoError(t, err)
+	assert.Nil(t, provider.OnShutdown)
+	_, err = provider.Create(ctx, p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+
+	// The port is free, so metrics are served until the provider shuts down.
+	addr := busy.Addr().String()
+	require.NoError(t, busy.Close())
+	provider, err = FromEnv(testProvider())
+	require.NoError(t, err)
+	_, err = provider.Create(ctx, p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Contains(t, string(body), `pulumi_provider_rpc_total{method="Create",token="test:index:A",code="OK"} 1`)
+
+	require.NotNil(t, provider.OnShutdown)
+	provider.OnShutdown(ctx)
+	_, err = http.Get("http://" + addr + "/metrics") //nolint:bodyclose // The request fails.
+	assert.Error(t, err)
+
+	// The statsd connection is closed when the provider shuts down.
+	t.Setenv(EnvVar, "statsd://127.0.0.1:8125")
+	provider, err = FromEnv(testProvider())
+	require.NoError(t, err)
+	require.NotNil(t, provider.OnShutdown)
+	provider.OnShutdown(ctx)
+}
@@ -0,0 +1,167 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package perf provides a middleware that logs the payload size and latency of each call
+// into a provider, to help diagnose slow operations. See [Wrap].
+package perf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"google.golang.org/protobuf/proto"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Options controls which calls are logged and where.
+type Options struct {
+	// SlowThreshold is the minimum duration of a call that is logged. If SlowThreshold is
+	// zero, calls are not logged for being slow.
+	SlowThreshold time.Duration
+	// SizeThreshold is the minimum size in bytes of a request or response that is logged.
+	// If SizeThreshold is zero, calls are not logged for their size, and sizes are not
+	// computed.
+	//
+	// Sizes are estimated from the property maps and strings in the request or response.
+	SizeThreshold int
+	// Output receives log lines, such as os.Stderr. If Output is nil, calls are logged to
+	// the engine at the info level.
+	Output io.Writer
+}
+
+// Wrap provider so that calls which take at least opts.SlowThreshold, or with a request or
+// response of at least opts.SizeThreshold bytes, are logged along with their duration and
+// payload sizes.
+//
+// The zero value of Options logs nothing.
+func Wrap(provider p.Provider, opts Options) p.Provider {
+	l := &logger{Options: opts}
+	provider.GetSchema = wrapIO(l, "GetSchema", provider.GetSchema)
+	provider.Parameterize = wrapIO(l, "Parameterize", provider.Parameterize)
+	provider.CheckConfig = wrapIO(l, "CheckConfig", provider.CheckConfig)
+	provider.DiffConfig = wrapIO(l, "DiffConfig", provider.DiffConfig)
+	provider.Configure = wrapI(l, "Configure", provider.Configure)
+	provider.Invoke = wrapIO(l, "Invoke", provider.Invoke)
+	provider.Check = wrapIO(l, "Check", provider.Check)
+	provider.Diff = wrapIO(l, "Diff", provider.Diff)
+	provider.Create = wrapIO(l, "Create", provider.Create)
+	provider.Read = wrapIO(l, "Read", provider.Read)
+	provider.Update = wrapIO(l, "Update", provider.Update)
+	provider.Delete = wrapIO(l, "Delete", provider.Delete)
+	provider.Call = wrapIO(l, "Call", provider.Call)
+	provider.Construct = wrapIO(l, "Construct", provider.Construct)
+	return provider
+}
+
+type logger struct {
+	Options
+	m sync.Mutex // Guards writes to Output
+}
+
+func (l *logger) record(
+	ctx context.Context, method string, start time.Time, req, resp any,
+) {
+	elapsed := time.Since(start)
+	slow := l.SlowThreshold > 0 && elapsed >= l.SlowThreshold
+
+	msg := fmt.Sprintf("%s took %s", method, elapsed)
+	if l.SizeThreshold > 0 {
+		// Estimating sizes marshals every property map, so it is only done when sizes
+		// are reported.
+		reqSize, respSize := payloadSize(req), payloadSize(resp)
+		if !slow && reqSize < l.SizeThreshold && respSize < l.SizeThreshold {
+			return
+		}
+		msg += fmt.Sprintf(" (request %d bytes, response %d bytes)", reqSize, respSize)
+	} else if !slow {
+		return
+	}
+
+	if l.Output == nil {
+		p.GetLogger(ctx).Info(msg)
+		return
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	_, err := fmt.Fprintln(l.Output, msg)
+	contract.IgnoreError(err)
+}
+
+func wrapIO[I, O any, F func(context.Context, I) (O, error)](l *logger, method string, f F) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (O, error) {
+		start := time.Now()
+		resp, err := f(ctx, req)
+		l.record(ctx, method, start, req, resp)
+		return resp, err
+	}
+}
+
+func wrapI[I any, F func(context.Context, I) error](l *logger, method string, f F) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) error {
+		start := time.Now()
+		err := f(ctx, req)
+		l.record(ctx, method, start, req, nil)
+		return err
+	}
+}
+
+var propertyMapType = reflect.TypeOf(resource.PropertyMap{})
+
+// payloadSize estimates the wire size of v, by summing the encoded size of each top level
+// [resource.PropertyMap] field and the length of each top level string field.
+func payloadSize(v any) int {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() || value.Kind() != reflect.Struct {
+		return 0
+	}
+	var size int
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if !value.Type().Field(i).IsExported() {
+			continue
+		}
+		if field.Kind() == reflect.String {
+			size += field.Len()
+			continue
+		}
+		if field.Type() != propertyMapType || field.IsNil() {
+			continue
+		}
+		s, err := plugin.MarshalProperties(field.Interface().(resource.PropertyMap),
+			plugin.MarshalOptions{
+				KeepUnknowns:  true,
+				KeepSecrets:   true,
+				KeepResources: true,
+			})
+		if err != nil {
+			continue
+		}
+		size += proto.Size(s)
+	}
+	return size
+}
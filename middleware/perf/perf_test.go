@@ -0,0 +1,121 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perf
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	server := integration.NewServer("perf", semver.MustParse("1.0.0"), Wrap(p.Provider{
+		Create: func(_ context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id", Properties: req.Properties}, nil
+		},
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			time.Sleep(20 * time.Millisecond)
+			return p.DeleteResponse{}, nil
+		},
+	}, Options{
+		SlowThreshold: 10 * time.Millisecond,
+		SizeThreshold: 1024,
+		Output:        &out,
+	}))
+
+	// Small and fast, so not logged.
+	_, err := server.Create(p.CreateRequest{
+		Properties: resource.PropertyMap{"small": resource.NewStringProperty("value")},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, out.String())
+
+	// Large, so logged.
+	_, err = server.Create(p.CreateRequest{
+		Properties: resource.PropertyMap{
+			"large": resource.NewStringProperty(strings.Repeat("x", 2048)),
+		},
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, `^Create took \S+ \(request 20\d\d bytes, response 20\d\d bytes\)\n$`,
+		out.String())
+
+	// Slow, so logged.
+	out.Reset()
+	_, err = server.Delete(p.DeleteRequest{ID: "id"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^Delete took \S+ \(request 2 bytes, response 0 bytes\)\n$`, out.String())
+}
+
+func TestWrapThresholds(t *testing.T) {
+	t.Parallel()
+
+	create := func(_ context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+		time.Sleep(20 * time.Millisecond)
+		return p.CreateResponse{ID: "id", Properties: req.Properties}, nil
+	}
+	req := p.CreateRequest{
+		Properties: resource.PropertyMap{
+			"large": resource.NewStringProperty(strings.Repeat("x", 2048)),
+		},
+	}
+
+	t.Run("zero", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		server := integration.NewServer("perf", semver.MustParse("1.0.0"),
+			Wrap(p.Provider{Create: create}, Options{Output: &out}))
+
+		_, err := server.Create(req)
+		require.NoError(t, err)
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("slow only", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		server := integration.NewServer("perf", semver.MustParse("1.0.0"),
+			Wrap(p.Provider{Create: create}, Options{SlowThreshold: time.Millisecond, Output: &out}))
+
+		_, err := server.Create(req)
+		require.NoError(t, err)
+		assert.Regexp(t, `^Create took \S+\n$`, out.String())
+	})
+
+	t.Run("size only", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		server := integration.NewServer("perf", semver.MustParse("1.0.0"),
+			Wrap(p.Provider{Create: create}, Options{SizeThreshold: 1024, Output: &out}))
+
+		_, err := server.Create(req)
+		require.NoError(t, err)
+		assert.Regexp(t, `^Create took \S+ \(request 20\d\d bytes, response 20\d\d bytes\)\n$`,
+			out.String())
+	})
+}
@@ -0,0 +1,235 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit provides a middleware that limits how many Create, Update and Delete
+// calls a provider serves at once, and how quickly they start. See [Wrap].
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Options controls how calls are limited. A zero value for any limit means that the
+// limit is not enforced.
+type Options struct {
+	// MaxConcurrent is the maximum number of calls that may run at once across the
+	// provider.
+	MaxConcurrent int
+	// MaxConcurrentPerToken is the maximum number of calls that may run at once for each
+	// resource type.
+	MaxConcurrentPerToken int
+	// Rate is the number of calls that may start each second, with bursts of up to Burst
+	// calls. If Burst is zero, bursts of a single call are allowed.
+	Rate  float64
+	Burst int
+
+	// Config names provider configuration properties that override these options when
+	// the provider is configured.
+	Config ConfigKeys
+}
+
+// ConfigKeys name the provider configuration properties that set the field of the same
+// name in [Options]. Empty names and unset properties are ignored.
+//
+// Each property should be a number in the provider's schema.
+type ConfigKeys struct {
+	MaxConcurrent         string
+	MaxConcurrentPerToken string
+	Rate                  string
+	Burst                 string
+}
+
+// Wrap provider so that its Create, Update and Delete calls are subject to the limits in
+// opts. Create and Update calls made during a preview are not limited, since they don't
+// call the provider's upstream API.
+//
+// Calls wait until they are within every limit. A call that is canceled while waiting
+// returns the error of its context without reaching provider.
+func Wrap(provider p.Provider, opts Options) p.Provider {
+	l := &limiter{}
+	l.set(opts)
+
+	if opts.Config != (ConfigKeys{}) {
+		configure := provider.Configure
+		provider.Configure = func(ctx context.Context, req p.ConfigureRequest) error {
+			if configure != nil {
+				if err := configure(ctx, req); err != nil {
+					return err
+				}
+			}
+			configured, err := fromConfig(opts, req.Args)
+			if err != nil {
+				return err
+			}
+			l.set(configured)
+			return nil
+		}
+	}
+
+	provider.Create = wrapIO(l, provider.Create,
+		func(r p.CreateRequest) presource.URN { return r.Urn },
+		func(r p.CreateRequest) bool { return r.Preview })
+	provider.Update = wrapIO(l, provider.Update,
+		func(r p.UpdateRequest) presource.URN { return r.Urn },
+		func(r p.UpdateRequest) bool { return r.Preview })
+	provider.Delete = wrapIO(l, provider.Delete,
+		func(r p.DeleteRequest) presource.URN { return r.Urn }, nil)
+	return provider
+}
+
+// wrapIO limits calls to f. preview reports whether a request is made during a preview,
+// and may be nil for methods that are never called during a preview.
+func wrapIO[I, O any, F func(context.Context, I) (O, error)](
+	l *limiter, f F, urn func(I) presource.URN, preview func(I) bool,
+) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (O, error) {
+		if preview != nil && preview(req) {
+			return f(ctx, req)
+		}
+		release, err := l.acquire(ctx, urn(req).Type())
+		if err != nil {
+			var o O
+			return o, err
+		}
+		defer release()
+		return f(ctx, req)
+	}
+}
+
+// fromConfig overrides opts with the values of opts.Config in args.
+func fromConfig(opts Options, args presource.PropertyMap) (Options, error) {
+	number := func(key string, dst func(float64)) error {
+		if key == "" {
+			return nil
+		}
+		v, ok := args[presource.PropertyKey(key)]
+		if !ok || v.IsNull() || v.ContainsUnknowns() {
+			return nil
+		}
+		for v.IsSecret() {
+			v = v.SecretValue().Element
+		}
+		switch {
+		case v.IsNumber():
+			dst(v.NumberValue())
+		case v.IsString():
+			// Config values that were not typed in the schema are sent as strings.
+			f, err := strconv.ParseFloat(v.StringValue(), 64)
+			if err != nil {
+				return fmt.Errorf("invalid value for %q: %w", key, err)
+			}
+			dst(f)
+		default:
+			return fmt.Errorf("invalid value for %q: expected a number, found %s", key, v.TypeString())
+		}
+		return nil
+	}
+	toInt := func(dst *int) func(float64) {
+		return func(f float64) { *dst = int(math.Round(f)) }
+	}
+
+	if err := number(opts.Config.MaxConcurrent, toInt(&opts.MaxConcurrent)); err != nil {
+		return opts, err
+	}
+	if err := number(opts.Config.MaxConcurrentPerToken, toInt(&opts.MaxConcurrentPerToken)); err != nil {
+		return opts, err
+	}
+	if err := number(opts.Config.Rate, func(f float64) { opts.Rate = f }); err != nil {
+		return opts, err
+	}
+	if err := number(opts.Config.Burst, toInt(&opts.Burst)); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+type limiter struct {
+	m sync.Mutex // Guards the fields below, which are replaced on Configure.
+
+	opts     Options
+	global   *semaphore.Weighted
+	perToken map[tokens.Type]*semaphore.Weighted
+	rate     *rate.Limiter
+}
+
+func (l *limiter) set(opts Options) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.opts = opts
+	l.global = nil
+	if opts.MaxConcurrent > 0 {
+		l.global = semaphore.NewWeighted(int64(opts.MaxConcurrent))
+	}
+	l.perToken = map[tokens.Type]*semaphore.Weighted{}
+	l.rate = nil
+	if opts.Rate > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(opts.Rate), max(opts.Burst, 1))
+	}
+}
+
+// acquire waits until a call for a resource of type tk is within every limit. Each
+// successful acquire must be followed by a call to release.
+func (l *limiter) acquire(ctx context.Context, tk tokens.Type) (release func(), err error) {
+	l.m.Lock()
+	global, rateLimit := l.global, l.rate
+	var local *semaphore.Weighted
+	if l.opts.MaxConcurrentPerToken > 0 {
+		local = l.perToken[tk]
+		if local == nil {
+			local = semaphore.NewWeighted(int64(l.opts.MaxConcurrentPerToken))
+			l.perToken[tk] = local
+		}
+	}
+	l.m.Unlock()
+
+	var held []*semaphore.Weighted
+	release = func() {
+		for _, s := range held {
+			s.Release(1)
+		}
+	}
+	// The per-token semaphore is taken first, so calls waiting on a busy resource type
+	// don't hold a global slot that calls for other types could use.
+	for _, s := range []*semaphore.Weighted{local, global} {
+		if s == nil {
+			continue
+		}
+		if err := s.Acquire(ctx, 1); err != nil {
+			release()
+			return nil, err
+		}
+		held = append(held, s)
+	}
+	if rateLimit != nil {
+		if err := rateLimit.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
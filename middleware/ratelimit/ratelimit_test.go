@@ -0,0 +1,187 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// tracker records the peak number of concurrent calls to its provider.
+type tracker struct {
+	running, peak atomic.Int32
+}
+
+func (tr *tracker) provider() p.Provider {
+	return p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			n := tr.running.Add(1)
+			defer tr.running.Add(-1)
+			for {
+				peak := tr.peak.Load()
+				if n <= peak || tr.peak.CompareAndSwap(peak, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return p.CreateResponse{ID: "id"}, nil
+		},
+	}
+}
+
+// createAll calls Create concurrently for each urn, and waits for every call to finish.
+func createAll(t *testing.T, provider p.Provider, urns ...presource.URN) {
+	var wg sync.WaitGroup
+	for _, urn := range urns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := provider.Create(context.Background(), p.CreateRequest{Urn: urn})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+const (
+	urnA presource.URN = "urn:pulumi:dev::proj::test:index:A::a"
+	urnB presource.URN = "urn:pulumi:dev::proj::test:index:B::b"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	t.Parallel()
+
+	var tr tracker
+	createAll(t, Wrap(tr.provider(), Options{MaxConcurrent: 2}),
+		urnA, urnA, urnA, urnB, urnB, urnB)
+	assert.Equal(t, int32(2), tr.peak.Load())
+}
+
+func TestMaxConcurrentPerToken(t *testing.T) {
+	t.Parallel()
+
+	var tr tracker
+	createAll(t, Wrap(tr.provider(), Options{MaxConcurrentPerToken: 1}),
+		urnA, urnA, urnA, urnB, urnB, urnB)
+	// One call of each type may run at once.
+	assert.Equal(t, int32(2), tr.peak.Load())
+}
+
+func TestRate(t *testing.T) {
+	t.Parallel()
+
+	var tr tracker
+	start := time.Now()
+	createAll(t, Wrap(tr.provider(), Options{Rate: 50}), urnA, urnA, urnA)
+	// After the first call, each call waits for 1/50th of a second.
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	var tr tracker
+	provider := Wrap(tr.provider(), Options{
+		MaxConcurrent: 10,
+		Config:        ConfigKeys{MaxConcurrent: "maxConcurrency"},
+	})
+
+	err := provider.Configure(context.Background(), p.ConfigureRequest{
+		Args: presource.PropertyMap{
+			"maxConcurrency": presource.MakeSecret(presource.NewNumberProperty(1)),
+		},
+	})
+	require.NoError(t, err)
+
+	createAll(t, provider, urnA, urnA, urnB)
+	assert.Equal(t, int32(1), tr.peak.Load())
+
+	err = provider.Configure(context.Background(), p.ConfigureRequest{
+		Args: presource.PropertyMap{"maxConcurrency": presource.NewBoolProperty(true)},
+	})
+	assert.ErrorContains(t, err, `invalid value for "maxConcurrency"`)
+}
+
+func TestCancelWhileWaiting(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	provider := Wrap(p.Provider{
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			close(started)
+			<-release
+			return p.DeleteResponse{}, nil
+		},
+	}, Options{MaxConcurrent: 1})
+
+	go func() {
+		_, err := provider.Delete(context.Background(), p.DeleteRequest{Urn: urnA})
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := provider.Delete(ctx, p.DeleteRequest{Urn: urnB})
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+}
+
+func TestPreviewNotLimited(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	provider := Wrap(p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id"}, nil
+		},
+		Update: func(context.Context, p.UpdateRequest) (p.UpdateResponse, error) {
+			return p.UpdateResponse{}, nil
+		},
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			close(started)
+			<-release
+			return p.DeleteResponse{}, nil
+		},
+	}, Options{MaxConcurrent: 1})
+
+	go func() {
+		_, err := provider.Delete(context.Background(), p.DeleteRequest{Urn: urnA})
+		assert.NoError(t, err)
+	}()
+	<-started
+	defer close(release)
+
+	// The only slot is taken, so a limited call would return the error of its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := provider.Create(ctx, p.CreateRequest{Urn: urnB, Preview: true})
+	assert.NoError(t, err)
+	_, err = provider.Update(ctx, p.UpdateRequest{Urn: urnB, Preview: true})
+	assert.NoError(t, err)
+	_, err = provider.Create(ctx, p.CreateRequest{Urn: urnB})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,269 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remap provides a middleware that exposes the resources and functions of a
+// provider under different tokens.
+//
+// It is intended for wrapping existing providers (such as a Terraform bridged provider
+// projected with [github.com/pulumi/pulumi-go-provider/middleware/rpc.Provider]) so they
+// can be served as part of a new package:
+//
+//	inner := rpc.Provider(bridgedServer)
+//	inner = remap.Wrap(inner, remap.Options{
+//		Tokens: map[tokens.Type]tokens.Type{
+//			"aws:s3/bucket:Bucket": "mypkg:storage:Bucket",
+//		},
+//	})
+//	provider := infer.Wrap(inner, infer.Options{ /* new resources */ })
+//
+// The entry point for this package is [Wrap].
+package remap
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Options configures [Wrap].
+type Options struct {
+	// Tokens maps tokens understood by the wrapped provider to the tokens they should be
+	// exposed as.
+	//
+	// Tokens that are not present in the map are passed through unchanged.
+	Tokens map[tokens.Type]tokens.Type
+}
+
+// Wrap a provider so that its resources and functions are exposed under the tokens
+// described by opts.
+//
+// Incoming requests, including method calls, are rewritten from the exposed token to the
+// wrapped token, and the schema returned by GetSchema is rewritten from the wrapped token
+// to the exposed token. StreamInvoke is not part of [p.Provider], so it is not remapped.
+func Wrap(provider p.Provider, opts Options) p.Provider {
+	if len(opts.Tokens) == 0 {
+		return provider
+	}
+	m := newMapping(opts.Tokens)
+	wrapper := provider
+
+	if provider.GetSchema != nil {
+		wrapper.GetSchema = func(ctx context.Context, req p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+			resp, err := provider.GetSchema(ctx, req)
+			if err != nil || resp.Schema == "" {
+				return resp, err
+			}
+			resp.Schema, err = m.schema(resp.Schema)
+			return resp, err
+		}
+	}
+	if provider.Invoke != nil {
+		wrapper.Invoke = func(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			req.Token = m.inner(req.Token)
+			return provider.Invoke(ctx, req)
+		}
+	}
+	if provider.Call != nil {
+		wrapper.Call = func(ctx context.Context, req p.CallRequest) (p.CallResponse, error) {
+			req.Tok = tokens.ModuleMember(m.inner(tokens.Type(req.Tok)))
+			return provider.Call(ctx, req)
+		}
+	}
+	if provider.Check != nil {
+		wrapper.Check = func(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Check(ctx, req)
+		}
+	}
+	if provider.Diff != nil {
+		wrapper.Diff = func(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Diff(ctx, req)
+		}
+	}
+	if provider.Create != nil {
+		wrapper.Create = func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Create(ctx, req)
+		}
+	}
+	if provider.Read != nil {
+		wrapper.Read = func(ctx context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Read(ctx, req)
+		}
+	}
+	if provider.Update != nil {
+		wrapper.Update = func(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Update(ctx, req)
+		}
+	}
+	if provider.Delete != nil {
+		wrapper.Delete = func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+			req.Urn = m.innerURN(req.Urn)
+			return provider.Delete(ctx, req)
+		}
+	}
+	if provider.Construct != nil {
+		wrapper.Construct = func(ctx context.Context, req p.ConstructRequest) (p.ConstructResponse, error) {
+			req.URN = m.innerURN(req.URN)
+			return provider.Construct(ctx, req)
+		}
+	}
+	return wrapper
+}
+
+type mapping struct {
+	toOuter map[string]string
+	toInner map[string]string
+}
+
+func newMapping(m map[tokens.Type]tokens.Type) mapping {
+	r := mapping{
+		toOuter: make(map[string]string, len(m)),
+		toInner: make(map[string]string, len(m)),
+	}
+	for inner, outer := range m {
+		r.toOuter[string(inner)] = string(outer)
+		r.toInner[string(outer)] = string(inner)
+	}
+	return r
+}
+
+// inner returns the token tk is exposed for. Method tokens, such as
+// "mypkg:storage:Bucket/getPolicy", follow the token of their resource.
+func (m mapping) inner(tk tokens.Type) tokens.Type {
+	return tokens.Type(remapToken(m.toInner, string(tk)))
+}
+
+// outer returns the token tk is exposed as.
+func (m mapping) outer(tk string) string { return remapToken(m.toOuter, tk) }
+
+func remapToken(to map[string]string, tk string) string {
+	if mapped, ok := to[tk]; ok {
+		return mapped
+	}
+	// The module of a token may itself contain "/", so only the part after the name
+	// separator is considered for a method.
+	i := strings.LastIndex(tk, ":")
+	if j := strings.LastIndex(tk, "/"); i >= 0 && j > i {
+		if mapped, ok := to[tk[:j]]; ok {
+			return mapped + tk[j:]
+		}
+	}
+	return tk
+}
+
+// innerURN rewrites the type of urn, leaving any parent types as is.
+func (m mapping) innerURN(urn presource.URN) presource.URN {
+	if urn == "" {
+		return urn
+	}
+	typ := urn.Type()
+	inner := m.inner(typ)
+	if inner == typ {
+		return urn
+	}
+	var parent tokens.Type
+	qualified := string(urn.QualifiedType())
+	if i := strings.LastIndex(qualified, presource.URNTypeDelimiter); i >= 0 {
+		parent = tokens.Type(qualified[:i])
+	}
+	return presource.NewURN(urn.Stack(), urn.Project(), parent, inner, urn.Name())
+}
+
+// schema rewrites the tokens in a marshaled schema from the inner tokens to the outer
+// tokens.
+func (m mapping) schema(s string) (string, error) {
+	var spec map[string]any
+	if err := json.Unmarshal([]byte(s), &spec); err != nil {
+		return "", err
+	}
+	for _, section := range []string{"resources", "functions", "types"} {
+		elements, ok := spec[section].(map[string]any)
+		if !ok {
+			continue
+		}
+		renamed := make(map[string]any, len(elements))
+		for tk, v := range elements {
+			renamed[m.outer(tk)] = v
+		}
+		spec[section] = renamed
+	}
+	// Methods name the functions that implement them.
+	if resources, ok := spec["resources"].(map[string]any); ok {
+		for _, r := range resources {
+			r, _ := r.(map[string]any)
+			methods, ok := r["methods"].(map[string]any)
+			if !ok {
+				continue
+			}
+			for name, tk := range methods {
+				if tk, ok := tk.(string); ok {
+					methods[name] = m.outer(tk)
+				}
+			}
+		}
+	}
+	m.rewriteRefs(spec)
+
+	bytes, err := json.Marshal(spec)
+	return string(bytes), err
+}
+
+func (m mapping) rewriteRefs(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			if ref, ok := e.(string); ok && k == "$ref" {
+				v[k] = m.ref(ref)
+				continue
+			}
+			m.rewriteRefs(e)
+		}
+	case []any:
+		for _, e := range v {
+			m.rewriteRefs(e)
+		}
+	}
+}
+
+// ref rewrites a local reference to a resource or type. The module of the token in a
+// reference may be escaped, as in "#/types/pkg:mod%2Fsub:Type".
+func (m mapping) ref(ref string) string {
+	for _, kind := range []string{"#/resources/", "#/types/"} {
+		escaped, ok := strings.CutPrefix(ref, kind)
+		if !ok {
+			continue
+		}
+		tk, err := url.PathUnescape(escaped)
+		if err != nil {
+			return ref
+		}
+		if outer, ok := m.toOuter[tk]; ok {
+			if escaped != tk {
+				outer = strings.ReplaceAll(outer, "/", "%2F")
+			}
+			return kind + outer
+		}
+	}
+	return ref
+}
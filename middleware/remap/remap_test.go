@@ -0,0 +1,116 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+var testTokens = map[tokens.Type]tokens.Type{
+	"aws:s3/bucket:Bucket":       "mypkg:storage:Bucket",
+	"aws:s3/bucketPolicy:Policy": "mypkg:storage/policy:Policy",
+	"aws:s3/getBucket:getBucket": "mypkg:storage:getBucket",
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+
+	provider := Wrap(p.Provider{
+		GetSchema: func(context.Context, p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+			return p.GetSchemaResponse{Schema: `{
+				"resources": {
+					"aws:s3/bucket:Bucket": {
+						"properties": {
+							"policy": {"$ref": "#/types/aws:s3%2FbucketPolicy:Policy"},
+							"self": {"$ref": "#/resources/aws:s3/bucket:Bucket"}
+						},
+						"methods": {"getPolicy": "aws:s3/bucket:Bucket/getPolicy"}
+					}
+				},
+				"functions": {
+					"aws:s3/bucket:Bucket/getPolicy": {},
+					"aws:s3/getBucket:getBucket": {}
+				},
+				"types": {"aws:s3/bucketPolicy:Policy": {}}
+			}`}, nil
+		},
+	}, Options{Tokens: testTokens})
+
+	resp, err := provider.GetSchema(context.Background(), p.GetSchemaRequest{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"resources": {
+			"mypkg:storage:Bucket": {
+				"properties": {
+					"policy": {"$ref": "#/types/mypkg:storage%2Fpolicy:Policy"},
+					"self": {"$ref": "#/resources/mypkg:storage:Bucket"}
+				},
+				"methods": {"getPolicy": "mypkg:storage:Bucket/getPolicy"}
+			}
+		},
+		"functions": {
+			"mypkg:storage:Bucket/getPolicy": {},
+			"mypkg:storage:getBucket": {}
+		},
+		"types": {"mypkg:storage/policy:Policy": {}}
+	}`, resp.Schema)
+}
+
+func TestRequests(t *testing.T) {
+	t.Parallel()
+
+	var seen []string
+	provider := Wrap(p.Provider{
+		Create: func(_ context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			seen = append(seen, string(req.Urn))
+			return p.CreateResponse{}, nil
+		},
+		Invoke: func(_ context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			seen = append(seen, string(req.Token))
+			return p.InvokeResponse{}, nil
+		},
+		Call: func(_ context.Context, req p.CallRequest) (p.CallResponse, error) {
+			seen = append(seen, string(req.Tok))
+			return p.CallResponse{}, nil
+		},
+	}, Options{Tokens: testTokens})
+	ctx := context.Background()
+
+	_, err := provider.Create(ctx, p.CreateRequest{
+		Urn: resource.NewURN("dev", "proj", "my:index:Parent", "mypkg:storage:Bucket", "b"),
+	})
+	require.NoError(t, err)
+	_, err = provider.Invoke(ctx, p.InvokeRequest{Token: "mypkg:storage:getBucket"})
+	require.NoError(t, err)
+	_, err = provider.Call(ctx, p.CallRequest{Tok: "mypkg:storage:Bucket/getPolicy"})
+	require.NoError(t, err)
+	_, err = provider.Call(ctx, p.CallRequest{Tok: "other:index:Thing/method"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"urn:pulumi:dev::proj::my:index:Parent$aws:s3/bucket:Bucket::b",
+		"aws:s3/getBucket:getBucket",
+		"aws:s3/bucket:Bucket/getPolicy",
+		"other:index:Thing/method",
+	}, seen)
+}
@@ -23,12 +23,12 @@ import (
 	"fmt"
 	"math"
 
-	structpb "github.com/golang/protobuf/ptypes/struct"
 	"github.com/pulumi/pulumi-go-provider/internal/key"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	rpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	p "github.com/pulumi/pulumi-go-provider"
 )
@@ -64,6 +64,16 @@ func Provider(server rpc.ResourceProviderServer) p.Provider {
 			_, err := server.Cancel(ctx, &emptypb.Empty{})
 			return err
 		},
+		GetMapping: func(ctx context.Context, req p.GetMappingRequest) (p.GetMappingResponse, error) {
+			resp, err := server.GetMapping(ctx, &rpc.GetMappingRequest{
+				Key:      req.Key,
+				Provider: req.Provider,
+			})
+			return p.GetMappingResponse{
+				Provider: resp.GetProvider(),
+				Data:     resp.GetData(),
+			}, err
+		},
 		CheckConfig: func(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
 			olds, err := runtime.propertyToRPC(req.Olds)
 			if err != nil {
@@ -146,11 +156,19 @@ func Provider(server rpc.ResourceProviderServer) p.Provider {
 				return p.CheckResponse{}, err
 			}
 
+			var autonaming *rpc.CheckRequest_AutonamingOptions
+			if req.Autonaming != nil {
+				autonaming = &rpc.CheckRequest_AutonamingOptions{
+					ProposedName: req.Autonaming.ProposedName,
+					Mode:         rpc.CheckRequest_AutonamingOptions_Mode(req.Autonaming.Mode),
+				}
+			}
 			return checkResponse(server.Check(ctx, &rpc.CheckRequest{
 				Urn:        string(req.Urn),
 				Olds:       olds,
 				News:       news,
 				RandomSeed: req.RandomSeed,
+				Autonaming: autonaming,
 			}))
 		},
 		Diff: func(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
@@ -256,10 +274,10 @@ func Provider(server rpc.ResourceProviderServer) p.Provider {
 				Properties: properties,
 			}, err
 		},
-		Delete: func(ctx context.Context, req p.DeleteRequest) error {
+		Delete: func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
 			properties, err := runtime.propertyToRPC(req.Properties)
 			if err != nil {
-				return err
+				return p.DeleteResponse{}, err
 			}
 			_, err = server.Delete(ctx, &rpc.DeleteRequest{
 				Id:         req.ID,
@@ -267,7 +285,7 @@ func Provider(server rpc.ResourceProviderServer) p.Provider {
 				Properties: properties,
 				Timeout:    req.Timeout,
 			})
-			return err
+			return p.DeleteResponse{}, err
 		},
 	}
 }
@@ -0,0 +1,65 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// RegistryLanguage is the key of the registry's metadata in the Language section of a
+// schema.
+//
+// The package's Language section holds a [RegistryPackage], and the Language section of
+// each resource holds a [RegistryResource]. SDK generators ignore the key, so the
+// metadata is only read by the registry and other docs tooling.
+const RegistryLanguage = "registry"
+
+// RegistryPackage is the registry's metadata for a package.
+type RegistryPackage struct {
+	// Modules describes the modules of the package, keyed by module name.
+	Modules map[string]RegistryModule `json:"modules,omitempty"`
+}
+
+// RegistryModule is the registry's metadata for a module.
+type RegistryModule struct {
+	// Description is the Markdown description of the module, shown on its docs page.
+	Description string `json:"description,omitempty"`
+}
+
+// RegistryResource is the registry's metadata for a resource.
+type RegistryResource struct {
+	// Category is the category the resource is listed under, such as "Networking".
+	Category string `json:"category,omitempty"`
+	// Keywords are search terms for the resource.
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// registryPackage returns the Language entry describing modules, with each module name
+// mapped by modMap.
+func registryPackage(
+	modules map[tokens.ModuleName]string, modMap map[tokens.ModuleName]tokens.ModuleName,
+) (schema.RawMessage, error) {
+	info := RegistryPackage{Modules: make(map[string]RegistryModule, len(modules))}
+	for mod, description := range modules {
+		if m, ok := modMap[mod]; ok {
+			mod = m
+		}
+		info.Modules[mod.String()] = RegistryModule{Description: description}
+	}
+	return json.Marshal(info)
+}
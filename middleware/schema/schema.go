@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -33,6 +34,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/internal/introspect"
 )
 
 // RegisterDerivativeType registers a type for the schema being generated.
@@ -92,7 +94,9 @@ type state struct {
 	schema         *cache
 	lowerSchema    *cache
 	combinedSchema *cache
-	innerGetSchema func(ctx context.Context, req p.GetSchemaRequest) (p.GetSchemaResponse, error)
+	// Schemas generated by Options.Versions, by version.
+	versionedSchemas map[int]*cache
+	innerGetSchema   func(ctx context.Context, req p.GetSchemaRequest) (p.GetSchemaResponse, error)
 
 	m sync.Mutex
 }
@@ -112,8 +116,25 @@ type Options struct {
 	// For example, with the map {"foo": "bar"}, the token "pkg:foo:Name" would be present in
 	// the schema as "pkg:bar:Name".
 	ModuleMap map[tokens.ModuleName]tokens.ModuleName
+
+	// Versions registers schema generators for specific values of
+	// [p.GetSchemaRequest.Version].
+	//
+	// If Versions is empty, the requested version is ignored and the schema derived from
+	// Resources, Invokes and Provider is always returned. Otherwise, requests for
+	// DefaultVersion return the derived schema, requests for a version in Versions are
+	// served by its generator and requests for any other version fail.
+	Versions map[int]Generator
+	// DefaultVersion is the schema version derived from Resources, Invokes and Provider.
+	DefaultVersion int
 }
 
+// Generator generates a complete schema for a specific schema version.
+//
+// The returned schema is served as is: it is not merged with the schema of the wrapped
+// provider and its tokens are not renamed.
+type Generator func(ctx context.Context) (schema.PackageSpec, error)
+
 // Metadata describes additional metadata to embed in the generated Pulumi Schema.
 type Metadata struct {
 	// LanguageMap corresponds to the [schema.PackageSpec.Language] section of the
@@ -169,6 +190,15 @@ type Metadata struct {
 	License string
 	// PluginDownloadURL sets the [schema.PackageSpec.PluginDownloadURL] field.
 	PluginDownloadURL string
+	// DeprecationMessage sets the [schema.ResourceSpec.DeprecationMessage] field of the
+	// package's provider, marking the whole package as deprecated.
+	DeprecationMessage string
+	// ModuleDescriptions describes the modules of the package for the registry, keyed by
+	// module name. Module names are mapped by [Options.ModuleMap], like tokens are.
+	//
+	// The descriptions are recorded as a [RegistryPackage] under the [RegistryLanguage]
+	// key of [schema.PackageSpec.Language], replacing any value set in LanguageMap.
+	ModuleDescriptions map[tokens.ModuleName]string
 }
 
 // Wrap a provider with the facilities to serve GetSchema.
@@ -185,6 +215,10 @@ func (s *state) GetSchema(ctx context.Context, req p.GetSchemaRequest) (p.GetSch
 	s.m.Lock()
 	defer s.m.Unlock()
 
+	if len(s.Versions) > 0 && req.Version != s.DefaultVersion {
+		return s.getVersionedSchema(ctx, req.Version)
+	}
+
 	if s.schema.isEmpty() {
 		spec, err := s.generateSchema(ctx)
 		if err != nil {
@@ -226,6 +260,35 @@ func (s *state) GetSchema(ctx context.Context, req p.GetSchemaRequest) (p.GetSch
 	}, nil
 }
 
+func (s *state) getVersionedSchema(ctx context.Context, version int) (p.GetSchemaResponse, error) {
+	if c, ok := s.versionedSchemas[version]; ok {
+		return p.GetSchemaResponse{Schema: c.marshaled}, nil
+	}
+	generate, ok := s.Versions[version]
+	if !ok {
+		supported := []int{s.DefaultVersion}
+		for v := range s.Versions {
+			supported = append(supported, v)
+		}
+		sort.Ints(supported)
+		return p.GetSchemaResponse{}, status.Errorf(codes.InvalidArgument,
+			"unsupported schema version %d: supported versions are %v", version, supported)
+	}
+	spec, err := generate(ctx)
+	if err != nil {
+		return p.GetSchemaResponse{}, fmt.Errorf("generating schema version %d: %w", version, err)
+	}
+	c, err := newCacheFromSpec(spec)
+	if err != nil {
+		return p.GetSchemaResponse{}, err
+	}
+	if s.versionedSchemas == nil {
+		s.versionedSchemas = map[int]*cache{}
+	}
+	s.versionedSchemas[version] = c
+	return p.GetSchemaResponse{Schema: c.marshaled}, nil
+}
+
 func (s *state) mergeSchemas() error {
 	contract.Assertf(!s.schema.isEmpty(), "we must have our own schema")
 	if s.combinedSchema != nil {
@@ -309,6 +372,13 @@ func (s *state) generateSchema(ctx context.Context) (schema.PackageSpec, error)
 		}
 		pkg.Language[k] = bytes
 	}
+	if len(s.ModuleDescriptions) > 0 {
+		bytes, err := registryPackage(s.ModuleDescriptions, s.ModuleMap)
+		if err != nil {
+			return schema.PackageSpec{}, err
+		}
+		pkg.Language[RegistryLanguage] = bytes
+	}
 	registerDerivative := func(tk tokens.Type, t schema.ComplexTypeSpec) bool {
 		tkString := assignTo(tk, info.PackageName, s.ModuleMap).String()
 		_, ok := pkg.Types[tkString]
@@ -334,6 +404,9 @@ func (s *state) generateSchema(ctx context.Context) (schema.PackageSpec, error)
 			Required:  prov.RequiredInputs,
 		}
 	}
+	if s.DeprecationMessage != "" {
+		pkg.Provider.DeprecationMessage = s.DeprecationMessage
+	}
 	if err := errs.ErrorOrNil(); err != nil {
 		return schema.PackageSpec{}, err
 	}
@@ -355,6 +428,10 @@ func addElements[T canGetSchema[S], S any](els []T, m map[string]S,
 			errs.Errors = append(errs.Errors, err)
 			continue
 		}
+		if _, ok := m[tk.String()]; ok {
+			errs.Errors = append(errs.Errors, fmt.Errorf("'%s' is defined more than once%s", tk, at(f)))
+			continue
+		}
 		m[tk.String()] = element
 	}
 	return errs
@@ -420,6 +497,13 @@ func renamePackage[T any](typ T, pkg string, modMap map[tokens.ModuleName]tokens
 				rewritten := fixReference(field.String(), pkg, modMap)
 				field.SetString(rewritten)
 			}
+			if v.Type() == reflect.TypeOf(schema.DiscriminatorSpec{}) {
+				mapping := v.Addr().Interface().(*schema.DiscriminatorSpec).Mapping
+				for k, ref := range mapping {
+					mapping[k] = fixReference(ref, pkg, modMap)
+				}
+				return
+			}
 			for _, f := range reflect.VisibleFields(v.Type()) {
 				f := v.FieldByIndex(f.Index)
 				rename(f)
@@ -447,3 +531,15 @@ func renamePackage[T any](typ T, pkg string, modMap map[tokens.ModuleName]tokens
 	rename(v)
 	return *t
 }
+
+// at locates the Go source of el for an error message, if el implements
+//
+//	interface{ SourcePosition() string }
+//
+// as the resources and functions of [github.com/pulumi/pulumi-go-provider/infer] do.
+func at(el any) string {
+	if el, ok := el.(interface{ SourcePosition() string }); ok {
+		return introspect.At(el.SourcePosition())
+	}
+	return ""
+}
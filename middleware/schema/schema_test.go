@@ -15,11 +15,18 @@
 package schema
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/blang/semver"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
 )
 
 func TestRenamePacakge(t *testing.T) {
@@ -54,3 +61,63 @@ func TestRenamePacakge(t *testing.T) {
 	arr = renamePackage(arr, "buzz", map[tokens.ModuleName]tokens.ModuleName{})
 	assert.Equal(t, "#/resources/buzz:fizz:Buzz", arr[1].Ref)
 }
+
+func TestSchemaVersions(t *testing.T) {
+	t.Parallel()
+
+	server := integration.NewServer("pkg", semver.MustParse("1.2.3"),
+		Wrap(p.Provider{}, Options{
+			Versions: map[int]Generator{
+				1: func(context.Context) (schema.PackageSpec, error) {
+					return schema.PackageSpec{Name: "pkg", Version: "legacy"}, nil
+				},
+			},
+		}))
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.GetSchema(p.GetSchemaRequest{})
+		require.NoError(t, err)
+		assert.Contains(t, resp.Schema, `"version":"1.2.3"`)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server.GetSchema(p.GetSchemaRequest{Version: 1})
+		require.NoError(t, err)
+		assert.Contains(t, resp.Schema, `"version":"legacy"`)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		t.Parallel()
+		_, err := server.GetSchema(p.GetSchemaRequest{Version: 2})
+		assert.ErrorContains(t, err, "unsupported schema version 2: supported versions are [0 1]")
+	})
+}
+
+func TestModuleDescriptions(t *testing.T) {
+	t.Parallel()
+
+	server := integration.NewServer("pkg", semver.MustParse("1.2.3"),
+		Wrap(p.Provider{}, Options{
+			Metadata: Metadata{
+				ModuleDescriptions: map[tokens.ModuleName]string{
+					"net":     "Networking resources.",
+					"storage": "Storage resources.",
+				},
+			},
+			ModuleMap: map[tokens.ModuleName]tokens.ModuleName{"net": "network"},
+		}))
+
+	resp, err := server.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec schema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+
+	var registry RegistryPackage
+	require.NoError(t, json.Unmarshal(spec.Language[RegistryLanguage], &registry))
+	assert.Equal(t, RegistryPackage{Modules: map[string]RegistryModule{
+		"network": {Description: "Networking resources."},
+		"storage": {Description: "Storage resources."},
+	}}, registry)
+}
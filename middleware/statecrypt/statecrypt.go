@@ -0,0 +1,314 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package statecrypt provides a middleware that encrypts designated state properties with
+// a key managed by the provider, such as a KMS key. See [Wrap].
+//
+// Pulumi secrets are encrypted by the stack's secrets provider, which is chosen by the
+// user. Some compliance regimes forbid storing certain data even in that form. Properties
+// encrypted by statecrypt are only ever stored as ciphertext produced by the provider's
+// own key, and are only decrypted inside the provider.
+package statecrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Cipher encrypts and decrypts state properties. Implementations usually call a key
+// management service, so that the key never leaves it.
+type Cipher interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// Options controls which properties are encrypted.
+type Options struct {
+	// Cipher encrypts and decrypts the properties.
+	Cipher Cipher
+	// Properties are the top level state properties to encrypt, keyed by the token of
+	// their resource.
+	//
+	// Only output properties can be encrypted. The engine stores the inputs of a resource
+	// as the program sent them, so an input is never kept from the state.
+	Properties map[tokens.Type][]presource.PropertyKey
+}
+
+// prefix marks an encrypted property. The version allows the format to change.
+const prefix = "statecrypt:v1:"
+
+// Wrap provider so that the properties designated in opts are encrypted in the state it
+// returns from Create, Read and Update, and decrypted in the state it is sent by Diff,
+// Read, Update and Delete.
+//
+// Encrypted properties are stored as strings, so they show up as changed in the diff of
+// outputs. A property whose value has not changed keeps its ciphertext, so that a refresh
+// or an update that leaves it alone does not show a diff. Properties that are not
+// encrypted, such as those in state written before the provider was wrapped, are passed
+// through as is and encrypted when the state is next returned. Unknown values, which are
+// only seen during a preview, are never encrypted.
+//
+// Create and Update fail if a designated property is sent as an input, and Read drops
+// designated properties from the inputs it returns.
+func Wrap(provider p.Provider, opts Options) p.Provider {
+	c := crypter(opts)
+	if create := provider.Create; create != nil {
+		provider.Create = func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			if err := c.checkInputs(req.Urn.Type(), req.Properties); err != nil {
+				return p.CreateResponse{}, err
+			}
+			resp, err := create(ctx, req)
+			if resp.Properties != nil {
+				var encErr error
+				resp.Properties, encErr = c.encrypt(ctx, req.Urn.Type(), resp.Properties, nil, nil)
+				err = errors.Join(err, encErr)
+			}
+			return resp, err
+		}
+	}
+	if diff := provider.Diff; diff != nil {
+		provider.Diff = func(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+			olds, err := c.decrypt(ctx, req.Urn.Type(), req.Olds)
+			if err != nil {
+				return p.DiffResponse{}, err
+			}
+			req.Olds = olds
+			return diff(ctx, req)
+		}
+	}
+	if read := provider.Read; read != nil {
+		provider.Read = func(ctx context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+			sealed := req.Properties
+			props, err := c.decrypt(ctx, req.Urn.Type(), sealed)
+			if err != nil {
+				return p.ReadResponse{}, err
+			}
+			req.Properties = props
+			resp, err := read(ctx, req)
+			if resp.Properties != nil {
+				var encErr error
+				resp.Properties, encErr = c.encrypt(ctx, req.Urn.Type(), resp.Properties, sealed, props)
+				err = errors.Join(err, encErr)
+			}
+			resp.Inputs = c.dropInputs(req.Urn.Type(), resp.Inputs)
+			return resp, err
+		}
+	}
+	if update := provider.Update; update != nil {
+		provider.Update = func(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+			if err := c.checkInputs(req.Urn.Type(), req.News); err != nil {
+				return p.UpdateResponse{}, err
+			}
+			sealed := req.Olds
+			olds, err := c.decrypt(ctx, req.Urn.Type(), sealed)
+			if err != nil {
+				return p.UpdateResponse{}, err
+			}
+			req.Olds = olds
+			resp, err := update(ctx, req)
+			if resp.Properties != nil {
+				var encErr error
+				resp.Properties, encErr = c.encrypt(ctx, req.Urn.Type(), resp.Properties, sealed, olds)
+				err = errors.Join(err, encErr)
+			}
+			return resp, err
+		}
+	}
+	if del := provider.Delete; del != nil {
+		provider.Delete = func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+			props, err := c.decrypt(ctx, req.Urn.Type(), req.Properties)
+			if err != nil {
+				return p.DeleteResponse{}, err
+			}
+			req.Properties = props
+			return del(ctx, req)
+		}
+	}
+	return provider
+}
+
+type crypter Options
+
+// checkInputs returns an error if inputs holds a property designated for tk.
+func (c crypter) checkInputs(tk tokens.Type, inputs presource.PropertyMap) error {
+	for _, k := range c.Properties[tk] {
+		if _, ok := inputs[k]; ok {
+			return fmt.Errorf("%q is an input of %s, but only outputs can be encrypted", k, tk)
+		}
+	}
+	return nil
+}
+
+// dropInputs returns a copy of inputs without the properties designated for tk.
+func (c crypter) dropInputs(tk tokens.Type, inputs presource.PropertyMap) presource.PropertyMap {
+	keys := c.Properties[tk]
+	if len(keys) == 0 || inputs == nil {
+		return inputs
+	}
+	inputs = inputs.Copy()
+	for _, k := range keys {
+		delete(inputs, k)
+	}
+	return inputs
+}
+
+// encrypt returns a copy of m in which the properties designated for tk are encrypted.
+//
+// sealed is the stored state that m was derived from, if any, and opened is sealed after
+// decryption. A property whose value is the same in opened keeps its ciphertext from
+// sealed, since encrypting it again would change the state without changing the value.
+func (c crypter) encrypt(
+	ctx context.Context, tk tokens.Type, m, sealed, opened presource.PropertyMap,
+) (presource.PropertyMap, error) {
+	keys := c.Properties[tk]
+	if len(keys) == 0 {
+		return m, nil
+	}
+	m = m.Copy()
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok || v.IsNull() || v.ContainsUnknowns() || isEncrypted(v) {
+			continue
+		}
+		if old, ok := sealed[k]; ok && isEncrypted(old) && opened[k].DeepEquals(v) {
+			m[k] = old
+			continue
+		}
+		plaintext, err := marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting %q: %w", k, err)
+		}
+		ciphertext, err := c.Cipher.Encrypt(ctx, plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting %q: %w", k, err)
+		}
+		m[k] = presource.NewStringProperty(prefix + base64.StdEncoding.EncodeToString(ciphertext))
+	}
+	return m, nil
+}
+
+// decrypt returns a copy of m in which the encrypted properties designated for tk are
+// decrypted.
+func (c crypter) decrypt(
+	ctx context.Context, tk tokens.Type, m presource.PropertyMap,
+) (presource.PropertyMap, error) {
+	keys := c.Properties[tk]
+	if len(keys) == 0 {
+		return m, nil
+	}
+	m = m.Copy()
+	for _, k := range keys {
+		v, ok := m[k]
+		if !ok || !isEncrypted(v) {
+			continue
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(v.StringValue(), prefix))
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %q: %w", k, err)
+		}
+		plaintext, err := c.Cipher.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %q: %w", k, err)
+		}
+		m[k], err = unmarshal(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %q: %w", k, err)
+		}
+	}
+	return m, nil
+}
+
+func isEncrypted(v presource.PropertyValue) bool {
+	return v.IsString() && strings.HasPrefix(v.StringValue(), prefix)
+}
+
+var marshalOptions = plugin.MarshalOptions{
+	KeepSecrets:      true,
+	KeepResources:    true,
+	KeepOutputValues: true,
+}
+
+// marshal serializes v, keeping secrets and resource references.
+func marshal(v presource.PropertyValue) ([]byte, error) {
+	pb, err := plugin.MarshalPropertyValue("", v, marshalOptions)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(pb)
+}
+
+func unmarshal(b []byte) (presource.PropertyValue, error) {
+	var pb structpb.Value
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		return presource.PropertyValue{}, err
+	}
+	v, err := plugin.UnmarshalPropertyValue("", &pb, marshalOptions)
+	if err != nil {
+		return presource.PropertyValue{}, err
+	}
+	if v == nil {
+		return presource.NewNullProperty(), nil
+	}
+	return *v, nil
+}
+
+// AESGCM returns a [Cipher] that encrypts with AES-GCM under key, which must be 16, 24
+// or 32 bytes long.
+//
+// It is suited to keys the provider fetches from a secret store. Prefer a [Cipher] backed
+// by a key management service when one is available, so the key is never held by the
+// provider.
+func AESGCM(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return aesGCM{aead}, nil
+}
+
+type aesGCM struct{ aead cipher.AEAD }
+
+func (c aesGCM) Encrypt(_ context.Context, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (c aesGCM) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	size := c.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, errors.New("ciphertext is too short")
+	}
+	return c.aead.Open(nil, ciphertext[:size], ciphertext[size:], nil)
+}
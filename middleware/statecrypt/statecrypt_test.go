@@ -0,0 +1,208 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statecrypt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+var urn = presource.URN("urn:pulumi:stack::proj::test:index:Database::db")
+
+func TestStateEncryption(t *testing.T) {
+	t.Parallel()
+
+	cipher, err := AESGCM([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	// seen records the state the provider was sent by each method.
+	seen := map[string]presource.PropertyMap{}
+	state := presource.PropertyMap{
+		"name":             presource.NewStringProperty("db"),
+		"connectionString": presource.MakeSecret(presource.NewStringProperty("postgres://user:pw@host")),
+		"recoveryCodes": presource.NewArrayProperty([]presource.PropertyValue{
+			presource.NewStringProperty("a"), presource.NewStringProperty("b"),
+		}),
+	}
+	provider := Wrap(p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id", Properties: state}, nil
+		},
+		Diff: func(_ context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+			seen["diff"] = req.Olds
+			return p.DiffResponse{}, nil
+		},
+		Read: func(_ context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+			seen["read"] = req.Properties
+			return p.ReadResponse{ID: req.ID, Properties: req.Properties}, nil
+		},
+		Update: func(_ context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+			seen["update"] = req.Olds
+			return p.UpdateResponse{Properties: req.Olds}, nil
+		},
+		Delete: func(_ context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+			seen["delete"] = req.Properties
+			return p.DeleteResponse{}, nil
+		},
+	}, Options{
+		Cipher: cipher,
+		Properties: map[tokens.Type][]presource.PropertyKey{
+			"test:index:Database": {"connectionString", "recoveryCodes"},
+		},
+	})
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), provider)
+
+	assertEncrypted := func(t *testing.T, m presource.PropertyMap) {
+		t.Helper()
+		assert.Equal(t, state["name"], m["name"])
+		for _, k := range []presource.PropertyKey{"connectionString", "recoveryCodes"} {
+			v := m[k]
+			if assert.True(t, v.IsString(), "%s should be encrypted", k) {
+				assert.True(t, strings.HasPrefix(v.StringValue(), prefix))
+				assert.NotContains(t, v.StringValue(), "postgres")
+			}
+		}
+	}
+
+	create, err := server.Create(p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+	assertEncrypted(t, create.Properties)
+
+	_, err = server.Diff(p.DiffRequest{ID: "id", Urn: urn, Olds: create.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, state, seen["diff"])
+
+	read, err := server.Read(p.ReadRequest{ID: "id", Urn: urn, Properties: create.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, state, seen["read"])
+	assertEncrypted(t, read.Properties)
+	// The values did not change, so neither did the ciphertext.
+	assert.Equal(t, create.Properties, read.Properties)
+
+	update, err := server.Update(p.UpdateRequest{ID: "id", Urn: urn, Olds: read.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, state, seen["update"])
+	assertEncrypted(t, update.Properties)
+	assert.Equal(t, read.Properties, update.Properties)
+
+	_, err = server.Delete(p.DeleteRequest{ID: "id", Urn: urn, Properties: update.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, state, seen["delete"])
+
+	// State written before the provider was wrapped is passed through, and encrypted
+	// when it is next returned.
+	read, err = server.Read(p.ReadRequest{ID: "id", Urn: urn, Properties: state})
+	require.NoError(t, err)
+	assert.Equal(t, state, seen["read"])
+	assertEncrypted(t, read.Properties)
+}
+
+func TestStateEncryptionWrongKey(t *testing.T) {
+	t.Parallel()
+
+	encrypt, err := AESGCM([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	decrypt, err := AESGCM([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+
+	opts := Options{Properties: map[tokens.Type][]presource.PropertyKey{
+		"test:index:Database": {"password"},
+	}}
+	opts.Cipher = encrypt
+	encrypted, err := crypter(opts).encrypt(context.Background(), "test:index:Database",
+		presource.PropertyMap{"password": presource.NewStringProperty("hunter2")}, nil, nil)
+	require.NoError(t, err)
+
+	opts.Cipher = decrypt
+	_, err = crypter(opts).decrypt(context.Background(), "test:index:Database", encrypted)
+	assert.ErrorContains(t, err, `decrypting "password"`)
+}
+
+func TestStateEncryptionChangedValue(t *testing.T) {
+	t.Parallel()
+
+	cipher, err := AESGCM([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	c := crypter{Cipher: cipher, Properties: map[tokens.Type][]presource.PropertyKey{
+		"test:index:Database": {"password"},
+	}}
+	ctx := context.Background()
+
+	opened := presource.PropertyMap{"password": presource.NewStringProperty("hunter2")}
+	sealed, err := c.encrypt(ctx, "test:index:Database", opened, nil, nil)
+	require.NoError(t, err)
+
+	same, err := c.encrypt(ctx, "test:index:Database", opened, sealed, opened)
+	require.NoError(t, err)
+	assert.Equal(t, sealed, same)
+
+	changed := presource.PropertyMap{"password": presource.NewStringProperty("hunter3")}
+	m, err := c.encrypt(ctx, "test:index:Database", changed, sealed, opened)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, m)
+	m, err = c.decrypt(ctx, "test:index:Database", m)
+	require.NoError(t, err)
+	assert.Equal(t, changed, m)
+}
+
+func TestStateEncryptionInputs(t *testing.T) {
+	t.Parallel()
+
+	cipher, err := AESGCM([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	state := presource.PropertyMap{
+		"name":     presource.NewStringProperty("db"),
+		"password": presource.NewStringProperty("hunter2"),
+	}
+	provider := Wrap(p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id", Properties: state}, nil
+		},
+		Read: func(_ context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+			// Inputs derived from the state hold the output-only password.
+			return p.ReadResponse{ID: req.ID, Properties: req.Properties, Inputs: req.Properties}, nil
+		},
+		Update: func(_ context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+			return p.UpdateResponse{Properties: req.Olds}, nil
+		},
+	}, Options{
+		Cipher: cipher,
+		Properties: map[tokens.Type][]presource.PropertyKey{
+			"test:index:Database": {"password"},
+		},
+	})
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), provider)
+
+	read, err := server.Read(p.ReadRequest{ID: "id", Urn: urn, Properties: state})
+	require.NoError(t, err)
+	assert.Equal(t, presource.PropertyMap{"name": state["name"]}, read.Inputs)
+
+	_, err = server.Create(p.CreateRequest{Urn: urn, Properties: state})
+	assert.ErrorContains(t, err, `"password" is an input of test:index:Database`)
+
+	_, err = server.Update(p.UpdateRequest{ID: "id", Urn: urn, Olds: read.Properties, News: state})
+	assert.ErrorContains(t, err, `"password" is an input of test:index:Database`)
+}
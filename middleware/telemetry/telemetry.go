@@ -0,0 +1,146 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package telemetry provides an opt-in middleware that counts which resources and
+// functions of a provider are used, so that the teams who build a provider can understand
+// its adoption. See [Wrap].
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	presource "github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// Reporter receives the usage of a provider. Providers implement Reporter to forward
+// usage to their own telemetry system.
+type Reporter interface {
+	// Report is called with the usage of the provider since the previous report.
+	//
+	// Report is called when the engine calls Cancel and when the provider process shuts
+	// down. It should return promptly, and must handle its own errors: telemetry never
+	// fails an operation.
+	Report(ctx context.Context, usage Usage)
+}
+
+// Usage is an anonymized summary of the calls made to a provider during a Pulumi
+// operation.
+//
+// Usage only holds type tokens and counts. It never holds resource names, IDs, URNs or
+// property values.
+type Usage struct {
+	// Resources maps each resource type that was used to the number of distinct
+	// resources of that type. Component resources are included.
+	Resources map[tokens.Type]int
+	// Functions maps each function that was invoked or called to the number of calls.
+	Functions map[tokens.Type]int
+}
+
+// Wrap provider so that reporter receives the [Usage] of provider.
+//
+// Usage is reported when the engine calls Cancel, and when the provider shuts down (see
+// [p.Provider.OnShutdown]), so that usage is not lost when the engine stops the provider
+// without calling Cancel. Each call is reported once, and nothing is reported if the
+// provider was not used since the previous report.
+func Wrap(provider p.Provider, reporter Reporter) p.Provider {
+	c := &counter{}
+	c.reset()
+
+	provider.Invoke = wrap(provider.Invoke, func(r p.InvokeRequest) { c.function(r.Token) })
+	provider.Call = wrap(provider.Call, func(r p.CallRequest) { c.function(tokens.Type(r.Tok)) })
+	provider.Check = wrap(provider.Check, func(r p.CheckRequest) { c.resource(r.Urn) })
+	provider.Diff = wrap(provider.Diff, func(r p.DiffRequest) { c.resource(r.Urn) })
+	provider.Create = wrap(provider.Create, func(r p.CreateRequest) { c.resource(r.Urn) })
+	provider.Read = wrap(provider.Read, func(r p.ReadRequest) { c.resource(r.Urn) })
+	provider.Update = wrap(provider.Update, func(r p.UpdateRequest) { c.resource(r.Urn) })
+	provider.Construct = wrap(provider.Construct, func(r p.ConstructRequest) { c.resource(r.URN) })
+	provider.Delete = wrap(provider.Delete, func(r p.DeleteRequest) { c.resource(r.Urn) })
+
+	report := func(ctx context.Context) {
+		if usage, ok := c.flush(); ok {
+			reporter.Report(ctx, usage)
+		}
+	}
+	cancel := provider.Cancel
+	provider.Cancel = func(ctx context.Context) error {
+		report(ctx)
+		if cancel == nil {
+			return nil
+		}
+		return cancel(ctx)
+	}
+	return provider.WithOnShutdown(report)
+}
+
+func wrap[I, O any, F func(context.Context, I) (O, error)](f F, count func(I)) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (O, error) {
+		count(req)
+		return f(ctx, req)
+	}
+}
+
+type counter struct {
+	m sync.Mutex // Guards the fields below.
+
+	// urns holds the resources seen since the last flush, so that each resource is
+	// counted once however many calls are made for it. The URNs are never reported.
+	urns      map[presource.URN]struct{}
+	resources map[tokens.Type]int
+	functions map[tokens.Type]int
+}
+
+func (c *counter) reset() {
+	c.urns = map[presource.URN]struct{}{}
+	c.resources = map[tokens.Type]int{}
+	c.functions = map[tokens.Type]int{}
+}
+
+func (c *counter) resource(urn presource.URN) {
+	if !urn.IsValid() {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if _, ok := c.urns[urn]; ok {
+		return
+	}
+	c.urns[urn] = struct{}{}
+	c.resources[urn.Type()]++
+}
+
+func (c *counter) function(tk tokens.Type) {
+	if tk == "" {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.functions[tk]++
+}
+
+// flush returns the usage since the last flush, and whether there was any.
+func (c *counter) flush() (Usage, bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	usage := Usage{Resources: c.resources, Functions: c.functions}
+	used := len(c.resources) > 0 || len(c.functions) > 0
+	c.reset()
+	return usage, used
+}
@@ -0,0 +1,121 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type reporter []Usage
+
+func (r *reporter) Report(_ context.Context, usage Usage) { *r = append(*r, usage) }
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	var reports reporter
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), Wrap(p.Provider{
+		Check: func(_ context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			return p.CheckResponse{Inputs: req.News}, nil
+		},
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			return p.CreateResponse{ID: "id"}, nil
+		},
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) { return p.DeleteResponse{}, nil },
+		Invoke: func(context.Context, p.InvokeRequest) (p.InvokeResponse, error) {
+			return p.InvokeResponse{}, nil
+		},
+	}, &reports))
+
+	urn := func(typ, name string) resource.URN {
+		return resource.NewURN("stack", "proj", "", tokens.Type(typ), name)
+	}
+
+	// Nothing is reported before the provider is used.
+	require.NoError(t, server.Cancel())
+	assert.Empty(t, reports)
+
+	for _, u := range []resource.URN{urn("test:index:A", "a1"), urn("test:index:A", "a2")} {
+		_, err := server.Check(p.CheckRequest{Urn: u})
+		require.NoError(t, err)
+		_, err = server.Create(p.CreateRequest{Urn: u})
+		require.NoError(t, err)
+	}
+	_, err := server.Delete(p.DeleteRequest{Urn: urn("test:index:B", "b")})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := server.Invoke(p.InvokeRequest{Token: "test:index:getC"})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, server.Cancel())
+	assert.Equal(t, reporter{{
+		Resources: map[tokens.Type]int{"test:index:A": 2, "test:index:B": 1},
+		Functions: map[tokens.Type]int{"test:index:getC": 3},
+	}}, reports)
+
+	// Usage is reset after each report.
+	_, err = server.Create(p.CreateRequest{Urn: urn("test:index:A", "a1")})
+	require.NoError(t, err)
+	require.NoError(t, server.Cancel())
+	require.Len(t, reports, 2)
+	assert.Equal(t, Usage{
+		Resources: map[tokens.Type]int{"test:index:A": 1},
+		Functions: map[tokens.Type]int{},
+	}, reports[1])
+}
+
+func TestWrapReportsOnShutdown(t *testing.T) {
+	t.Parallel()
+
+	var reports reporter
+	var shutdown bool
+	provider := Wrap(p.Provider{
+		Invoke: func(context.Context, p.InvokeRequest) (p.InvokeResponse, error) {
+			return p.InvokeResponse{}, nil
+		},
+		OnShutdown: func(context.Context) { shutdown = true },
+	}, &reports)
+
+	ctx := context.Background()
+	_, err := provider.Invoke(ctx, p.InvokeRequest{Token: "test:index:getC"})
+	require.NoError(t, err)
+
+	// Usage is reported when the process shuts down, without a call to Cancel, and the
+	// provider's own hook still runs.
+	provider.OnShutdown(ctx)
+	assert.True(t, shutdown)
+	assert.Equal(t, reporter{{
+		Resources: map[tokens.Type]int{},
+		Functions: map[tokens.Type]int{"test:index:getC": 1},
+	}}, reports)
+
+	// Usage already reported by Cancel is not reported again.
+	_, err = provider.Invoke(ctx, p.InvokeRequest{Token: "test:index:getC"})
+	require.NoError(t, err)
+	require.NoError(t, provider.Cancel(ctx))
+	provider.OnShutdown(ctx)
+	assert.Len(t, reports, 2)
+}
@@ -0,0 +1,151 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfshim
+
+import (
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// proposedNew computes the proposed new state that Terraform sends with
+// PlanResourceChange: the configuration, with the values of computed attributes that
+// aren't configured carried over from the prior state.
+//
+// This is a simplified version of Terraform's objchange.ProposedNew. Elements of lists
+// and maps are matched by index and key, and sets are taken from the configuration.
+func proposedNew(block *tfprotov6.SchemaBlock, prior, config tftypes.Value) (tftypes.Value, error) {
+	if config.IsNull() || !config.IsKnown() {
+		return config, nil
+	}
+	var priorAttrs, configAttrs map[string]tftypes.Value
+	if prior.IsKnown() && !prior.IsNull() {
+		if err := prior.As(&priorAttrs); err != nil {
+			return tftypes.Value{}, err
+		}
+	}
+	if err := config.As(&configAttrs); err != nil {
+		return tftypes.Value{}, err
+	}
+	typ := config.Type().(tftypes.Object)
+	get := func(m map[string]tftypes.Value, name string) tftypes.Value {
+		if v, ok := m[name]; ok {
+			return v
+		}
+		return tftypes.NewValue(typ.AttributeTypes[name], nil)
+	}
+
+	out := make(map[string]tftypes.Value, len(configAttrs))
+	for k, v := range configAttrs {
+		out[k] = v
+	}
+	for _, attr := range block.Attributes {
+		p, c := get(priorAttrs, attr.Name), get(configAttrs, attr.Name)
+		var err error
+		switch {
+		case attr.Computed && c.IsNull():
+			out[attr.Name] = p
+		case attr.NestedType != nil:
+			nested := &tfprotov6.SchemaBlock{Attributes: attr.NestedType.Attributes}
+			switch attr.NestedType.Nesting {
+			case tfprotov6.SchemaObjectNestingModeSingle:
+				out[attr.Name], err = proposedNew(nested, p, c)
+			case tfprotov6.SchemaObjectNestingModeList, tfprotov6.SchemaObjectNestingModeMap:
+				out[attr.Name], err = pairElements(p, c, func(p, c tftypes.Value) (tftypes.Value, error) {
+					return proposedNew(nested, p, c)
+				})
+			}
+		}
+		if err != nil {
+			return tftypes.Value{}, err
+		}
+	}
+	for _, b := range block.BlockTypes {
+		p, c := get(priorAttrs, b.TypeName), get(configAttrs, b.TypeName)
+		var err error
+		switch b.Nesting {
+		case tfprotov6.SchemaNestedBlockNestingModeSingle, tfprotov6.SchemaNestedBlockNestingModeGroup:
+			out[b.TypeName], err = proposedNew(b.Block, p, c)
+		case tfprotov6.SchemaNestedBlockNestingModeList, tfprotov6.SchemaNestedBlockNestingModeMap:
+			out[b.TypeName], err = pairElements(p, c, func(p, c tftypes.Value) (tftypes.Value, error) {
+				return proposedNew(b.Block, p, c)
+			})
+		}
+		if err != nil {
+			return tftypes.Value{}, err
+		}
+	}
+	return tftypes.NewValue(typ, out), nil
+}
+
+// pairElements applies f to the elements of the list or map config, paired with the
+// element of prior at the same index or key. Elements without a prior counterpart are
+// paired with null.
+func pairElements(
+	prior, config tftypes.Value, f func(prior, config tftypes.Value) (tftypes.Value, error),
+) (tftypes.Value, error) {
+	if config.IsNull() || !config.IsKnown() {
+		return config, nil
+	}
+	priorKnown := prior.IsKnown() && !prior.IsNull()
+
+	switch typ := config.Type().(type) {
+	case tftypes.List:
+		var priorElems, configElems []tftypes.Value
+		if priorKnown {
+			if err := prior.As(&priorElems); err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		if err := config.As(&configElems); err != nil {
+			return tftypes.Value{}, err
+		}
+		out := make([]tftypes.Value, len(configElems))
+		for i, c := range configElems {
+			p := tftypes.NewValue(typ.ElementType, nil)
+			if i < len(priorElems) {
+				p = priorElems[i]
+			}
+			var err error
+			if out[i], err = f(p, c); err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		return tftypes.NewValue(typ, out), nil
+	case tftypes.Map:
+		var priorElems, configElems map[string]tftypes.Value
+		if priorKnown {
+			if err := prior.As(&priorElems); err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		if err := config.As(&configElems); err != nil {
+			return tftypes.Value{}, err
+		}
+		out := make(map[string]tftypes.Value, len(configElems))
+		for k, c := range configElems {
+			p, ok := priorElems[k]
+			if !ok {
+				p = tftypes.NewValue(typ.ElementType, nil)
+			}
+			var err error
+			if out[k], err = f(p, c); err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		return tftypes.NewValue(typ, out), nil
+	default:
+		return config, nil
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfshim
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+var anyType = pschema.TypeSpec{Ref: "pulumi.json#/Any"}
+
+// schemaBuilder converts Terraform schemas into a Pulumi schema.
+type schemaBuilder struct {
+	pkg   string
+	types map[string]pschema.ComplexTypeSpec
+}
+
+// token returns the Pulumi token of m in the package being built.
+func (b *schemaBuilder) token(m member) string {
+	return b.pkg + ":" + m.module + ":" + m.name
+}
+
+// resource returns the schema of the Terraform resource described by block, exposed as m.
+func (b *schemaBuilder) resource(m member, block *tfprotov6.SchemaBlock) pschema.ResourceSpec {
+	outputs := b.object(m, block, false)
+	inputs := b.object(m, block, true)
+	spec := pschema.ResourceSpec{
+		ObjectTypeSpec:  outputs,
+		InputProperties: inputs.Properties,
+		RequiredInputs:  inputs.Required,
+	}
+	// "id" is the ID of every Pulumi resource, and can't be a property.
+	delete(spec.Properties, "id")
+	delete(spec.InputProperties, "id")
+	spec.Required = slices.DeleteFunc(spec.Required, func(s string) bool { return s == "id" })
+	spec.RequiredInputs = slices.DeleteFunc(spec.RequiredInputs, func(s string) bool { return s == "id" })
+	if block.Deprecated {
+		spec.DeprecationMessage = "This resource is deprecated."
+	}
+	return spec
+}
+
+// function returns the schema of the Terraform data source described by block, exposed
+// as m.
+func (b *schemaBuilder) function(m member, block *tfprotov6.SchemaBlock) pschema.FunctionSpec {
+	inputs := b.object(m, block, true)
+	outputs := b.object(m, block, false)
+	spec := pschema.FunctionSpec{
+		Description: block.Description,
+		Inputs:      &inputs,
+		Outputs:     &outputs,
+	}
+	if block.Deprecated {
+		spec.DeprecationMessage = "This function is deprecated."
+	}
+	return spec
+}
+
+// object returns the object type described by block. Named types for nested objects are
+// prefixed with the name of m.
+//
+// When inputs is true, only the attributes of block that can be configured are included.
+// Nested objects always include every attribute, so that a single type serves as both
+// the input and the output of the property.
+func (b *schemaBuilder) object(m member, block *tfprotov6.SchemaBlock, inputs bool) pschema.ObjectTypeSpec {
+	spec := pschema.ObjectTypeSpec{
+		Type:        "object",
+		Description: block.Description,
+		Properties:  map[string]pschema.PropertySpec{},
+	}
+	for _, attr := range block.Attributes {
+		if inputs && !attr.Required && !attr.Optional {
+			continue
+		}
+		name := propertyName(attr.Name)
+		nested := m.nested(attr.Name)
+		var typ pschema.TypeSpec
+		if attr.NestedType != nil {
+			obj := b.object(nested, &tfprotov6.SchemaBlock{Attributes: attr.NestedType.Attributes}, false)
+			typ = b.wrap(b.named(nested, obj), tfprotov6.SchemaNestedBlockNestingMode(attr.NestedType.Nesting))
+		} else {
+			typ = b.typeSpec(nested, attr.Type)
+		}
+		prop := pschema.PropertySpec{
+			TypeSpec:    typ,
+			Description: attr.Description,
+			Secret:      attr.Sensitive,
+		}
+		if attr.Deprecated {
+			prop.DeprecationMessage = "Deprecated."
+		}
+		spec.Properties[name] = prop
+		if attr.Required {
+			spec.Required = append(spec.Required, name)
+		}
+	}
+	for _, nb := range block.BlockTypes {
+		name := propertyName(nb.TypeName)
+		nested := m.nested(nb.TypeName)
+		obj := b.object(nested, nb.Block, false)
+		spec.Properties[name] = pschema.PropertySpec{
+			TypeSpec:    b.wrap(b.named(nested, obj), nb.Nesting),
+			Description: nb.Block.Description,
+		}
+		if nb.MinItems > 0 {
+			spec.Required = append(spec.Required, name)
+		}
+	}
+	slices.Sort(spec.Required)
+	return spec
+}
+
+// named registers obj as the type m, and returns a reference to it.
+func (b *schemaBuilder) named(m member, obj pschema.ObjectTypeSpec) pschema.TypeSpec {
+	tk := b.token(m)
+	b.types[tk] = pschema.ComplexTypeSpec{ObjectTypeSpec: obj}
+	return pschema.TypeSpec{Ref: "#/types/" + tk}
+}
+
+// wrap returns the type of a nested block of elem nested with mode.
+func (b *schemaBuilder) wrap(elem pschema.TypeSpec, mode tfprotov6.SchemaNestedBlockNestingMode) pschema.TypeSpec {
+	switch mode {
+	case tfprotov6.SchemaNestedBlockNestingModeList, tfprotov6.SchemaNestedBlockNestingModeSet:
+		return pschema.TypeSpec{Type: "array", Items: &elem}
+	case tfprotov6.SchemaNestedBlockNestingModeMap:
+		return pschema.TypeSpec{Type: "object", AdditionalProperties: &elem}
+	default:
+		return elem
+	}
+}
+
+// typeSpec returns the Pulumi type of a Terraform attribute of type typ.
+func (b *schemaBuilder) typeSpec(m member, typ tftypes.Type) pschema.TypeSpec {
+	switch typ := typ.(type) {
+	case tftypes.List:
+		items := b.typeSpec(m, typ.ElementType)
+		return pschema.TypeSpec{Type: "array", Items: &items}
+	case tftypes.Set:
+		items := b.typeSpec(m, typ.ElementType)
+		return pschema.TypeSpec{Type: "array", Items: &items}
+	case tftypes.Map:
+		values := b.typeSpec(m, typ.ElementType)
+		return pschema.TypeSpec{Type: "object", AdditionalProperties: &values}
+	case tftypes.Object:
+		obj := pschema.ObjectTypeSpec{Type: "object", Properties: map[string]pschema.PropertySpec{}}
+		for name, t := range typ.AttributeTypes {
+			obj.Properties[propertyName(name)] = pschema.PropertySpec{
+				TypeSpec: b.typeSpec(m.nested(name), t),
+			}
+		}
+		return b.named(m, obj)
+	case tftypes.Tuple:
+		return pschema.TypeSpec{Type: "array", Items: &anyType}
+	}
+	switch {
+	case typ.Is(tftypes.String):
+		return pschema.TypeSpec{Type: "string"}
+	case typ.Is(tftypes.Number):
+		return pschema.TypeSpec{Type: "number"}
+	case typ.Is(tftypes.Bool):
+		return pschema.TypeSpec{Type: "boolean"}
+	default:
+		return anyType
+	}
+}
+
+// member is the module and name of a Pulumi token, without its package.
+type member struct{ module, name string }
+
+// parseMember parses a token of the form "module:Name".
+func parseMember(s string) (member, bool) {
+	module, name, ok := strings.Cut(s, ":")
+	return member{module, name}, ok && module != "" && name != ""
+}
+
+// nested returns the member used to name the type of the attribute of m named attribute.
+func (m member) nested(attribute string) member {
+	return member{m.module, pascal(m.name) + pascal(attribute)}
+}
+
+// pascal converts a Terraform name, such as "min_length", into PascalCase, such as
+// "MinLength".
+func pascal(name string) string {
+	s := propertyName(name)
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
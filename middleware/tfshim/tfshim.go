@@ -0,0 +1,792 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tfshim allows projecting a Terraform provider server, as implemented with
+// terraform-plugin-go (protocol version 6), into a [p.Provider].
+//
+// The entry point for this package is [Provider]. Like
+// [github.com/pulumi/pulumi-go-provider/middleware/rpc], it is intended to let existing
+// Terraform resource implementations be reused while a provider is gradually ported to
+// pulumi-go-provider. Providers built with terraform-plugin-framework can obtain a server
+// with providerserver.NewProtocol6.
+//
+// Package tfshim is a separate Go module, so that providers which don't use it don't
+// depend on terraform-plugin-go.
+package tfshim
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// privateKey is the output that holds the private state Terraform providers attach to a
+// resource, base64 encoded. It is not part of the schema.
+const privateKey = "__private"
+
+// Options configures how the resources of a Terraform provider are exposed.
+type Options struct {
+	// Tokens overrides the tokens of individual resources and data sources, keyed by
+	// their Terraform type name. Tokens are given without their package, such as
+	// "storage:Bucket" for a resource or "storage:getBucket" for a data source.
+	//
+	// By default, the Terraform provider prefix is removed from the type name, and the
+	// rest converted to PascalCase in the "index" module: "example_storage_bucket" becomes
+	// "index:StorageBucket". Data sources are prefixed with "get", such as
+	// "index:getStorageBucket".
+	Tokens map[string]string
+}
+
+// Provider projects a Terraform [tfprotov6.ProviderServer] into a [p.Provider].
+//
+// Resources become Pulumi custom resources and data sources become functions. Terraform
+// attribute names are converted to camelCase, so "min_length" becomes "minLength". The
+// "id" attribute of a resource is its Pulumi ID.
+//
+// Diff, Create and Update plan the change with PlanResourceChange, and Create, Update
+// and Delete apply it with ApplyResourceChange. Read refreshes a resource with
+// ReadResource, and imports it with ImportResourceState when its state isn't known.
+//
+// Provider functions, ephemeral resources, resource state upgrades and moves are not
+// supported.
+func Provider(server tfprotov6.ProviderServer, opts Options) p.Provider {
+	s := &shim{server: server, opts: opts}
+	return p.Provider{
+		GetSchema:   s.getSchema,
+		Cancel:      s.cancel,
+		CheckConfig: s.checkConfig,
+		Configure:   s.configure,
+		Invoke:      s.invoke,
+		Check:       s.check,
+		Diff:        s.diff,
+		Create:      s.create,
+		Read:        s.read,
+		Update:      s.update,
+		Delete:      s.delete,
+	}
+}
+
+type shim struct {
+	server tfprotov6.ProviderServer
+	opts   Options
+
+	once   sync.Once
+	schema *providerSchema
+	err    error
+}
+
+// providerSchema is the schema of the Terraform provider, indexed by Pulumi token.
+type providerSchema struct {
+	provider    *tfprotov6.Schema
+	resources   map[member]entry
+	dataSources map[member]entry
+}
+
+// entry is a Terraform resource or data source.
+type entry struct {
+	typeName string
+	schema   *tfprotov6.Schema
+}
+
+func (e entry) block() *tfprotov6.SchemaBlock {
+	if e.schema == nil || e.schema.Block == nil {
+		return &tfprotov6.SchemaBlock{}
+	}
+	return e.schema.Block
+}
+
+func (e entry) valueType() tftypes.Type { return e.block().ValueType() }
+
+// load fetches the schema of the Terraform provider, the first time it is called.
+func (s *shim) load(ctx context.Context) (*providerSchema, error) {
+	s.once.Do(func() {
+		resp, err := s.server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+		if err == nil {
+			err = diagnostics(ctx, resp.Diagnostics)
+		}
+		if err != nil {
+			s.err = fmt.Errorf("fetching the Terraform provider schema: %w", err)
+			return
+		}
+		schema := &providerSchema{
+			provider:    resp.Provider,
+			resources:   make(map[member]entry, len(resp.ResourceSchemas)),
+			dataSources: make(map[member]entry, len(resp.DataSourceSchemas)),
+		}
+		for name, rs := range resp.ResourceSchemas {
+			m, err := s.member(name, false)
+			if err != nil {
+				s.err = err
+				return
+			}
+			schema.resources[m] = entry{name, rs}
+		}
+		for name, ds := range resp.DataSourceSchemas {
+			m, err := s.member(name, true)
+			if err != nil {
+				s.err = err
+				return
+			}
+			schema.dataSources[m] = entry{name, ds}
+		}
+		s.schema = schema
+	})
+	return s.schema, s.err
+}
+
+// member returns the token of the Terraform resource or data source typeName.
+func (s *shim) member(typeName string, dataSource bool) (member, error) {
+	if tk, ok := s.opts.Tokens[typeName]; ok {
+		m, ok := parseMember(tk)
+		if !ok {
+			return member{}, fmt.Errorf("invalid token %q for %s: expected \"module:Name\"", tk, typeName)
+		}
+		return m, nil
+	}
+	name := typeName
+	if _, rest, ok := strings.Cut(typeName, "_"); ok {
+		name = rest
+	}
+	if dataSource {
+		return member{"index", "get" + pascal(name)}, nil
+	}
+	return member{"index", pascal(name)}, nil
+}
+
+// memberOf returns the module and name of tk, ignoring its package.
+func memberOf(tk tokens.Type) member {
+	return member{string(tk.Module().Name()), string(tk.Name())}
+}
+
+func (s *shim) resource(ctx context.Context, urn resource.URN) (entry, error) {
+	schema, err := s.load(ctx)
+	if err != nil {
+		return entry{}, err
+	}
+	m := memberOf(urn.Type())
+	r, ok := schema.resources[m]
+	if !ok {
+		return entry{}, fmt.Errorf("unknown resource type %q", urn.Type())
+	}
+	return r, nil
+}
+
+func (s *shim) getSchema(ctx context.Context, _ p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+	schema, err := s.load(ctx)
+	if err != nil {
+		return p.GetSchemaResponse{}, err
+	}
+	info := p.GetRunInfo(ctx)
+	b := schemaBuilder{pkg: info.PackageName, types: map[string]pschema.ComplexTypeSpec{}}
+	spec := pschema.PackageSpec{
+		Name:      info.PackageName,
+		Version:   info.Version,
+		Resources: map[string]pschema.ResourceSpec{},
+		Functions: map[string]pschema.FunctionSpec{},
+	}
+	if schema.provider != nil && schema.provider.Block != nil {
+		config := b.object(member{"index", "Provider"}, schema.provider.Block, true)
+		spec.Provider = pschema.ResourceSpec{
+			ObjectTypeSpec:  pschema.ObjectTypeSpec{Description: config.Description, Type: "object"},
+			InputProperties: config.Properties,
+			RequiredInputs:  config.Required,
+		}
+		spec.Config = pschema.ConfigSpec{Variables: config.Properties, Required: config.Required}
+	}
+	for m, r := range schema.resources {
+		spec.Resources[b.token(m)] = b.resource(m, r.block())
+	}
+	for m, ds := range schema.dataSources {
+		spec.Functions[b.token(m)] = b.function(m, ds.block())
+	}
+	spec.Types = b.types
+
+	bytes, err := json.Marshal(spec)
+	return p.GetSchemaResponse{Schema: string(bytes)}, err
+}
+
+func (s *shim) cancel(ctx context.Context) error {
+	resp, err := s.server.StopProvider(ctx, &tfprotov6.StopProviderRequest{})
+	if err == nil && resp.Error != "" {
+		err = errors.New(resp.Error)
+	}
+	return err
+}
+
+func (s *shim) providerConfig(ctx context.Context, m resource.PropertyMap) (*tfprotov6.DynamicValue, error) {
+	schema, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	config, err := toValue(m, schema.provider.ValueType())
+	if err != nil {
+		return nil, err
+	}
+	return newDynamicValue(config)
+}
+
+func (s *shim) checkConfig(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+	config, err := s.providerConfig(ctx, req.News)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	resp, err := s.server.ValidateProviderConfig(ctx, &tfprotov6.ValidateProviderConfigRequest{Config: config})
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	failures, err := checkFailures(ctx, resp.Diagnostics)
+	return p.CheckResponse{Inputs: req.News, Failures: failures}, err
+}
+
+func (s *shim) configure(ctx context.Context, req p.ConfigureRequest) error {
+	config, err := s.providerConfig(ctx, req.Args)
+	if err != nil {
+		return err
+	}
+	resp, err := s.server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: config})
+	if err != nil {
+		return err
+	}
+	return diagnostics(ctx, resp.Diagnostics)
+}
+
+func (s *shim) invoke(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+	schema, err := s.load(ctx)
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	ds, ok := schema.dataSources[memberOf(req.Token)]
+	if !ok {
+		return p.InvokeResponse{}, fmt.Errorf("unknown function %q", req.Token)
+	}
+	config, err := toValue(req.Args, ds.valueType())
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	dv, err := newDynamicValue(config)
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	validate, err := s.server.ValidateDataResourceConfig(ctx, &tfprotov6.ValidateDataResourceConfigRequest{
+		TypeName: ds.typeName,
+		Config:   dv,
+	})
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	if failures, err := checkFailures(ctx, validate.Diagnostics); err != nil || len(failures) > 0 {
+		return p.InvokeResponse{Failures: failures}, err
+	}
+
+	resp, err := s.server.ReadDataSource(ctx, &tfprotov6.ReadDataSourceRequest{
+		TypeName: ds.typeName,
+		Config:   dv,
+	})
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	if err := diagnostics(ctx, resp.Diagnostics); err != nil {
+		return p.InvokeResponse{}, err
+	}
+	state, err := decode(resp.State, ds.valueType())
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	ret, err := fromValue(state)
+	if err != nil {
+		return p.InvokeResponse{}, err
+	}
+	markSecrets(ret, ds.block())
+	return p.InvokeResponse{Return: ret}, nil
+}
+
+func (s *shim) check(ctx context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	config, err := toValue(req.News, r.valueType())
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	dv, err := newDynamicValue(config)
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	resp, err := s.server.ValidateResourceConfig(ctx, &tfprotov6.ValidateResourceConfigRequest{
+		TypeName: r.typeName,
+		Config:   dv,
+	})
+	if err != nil {
+		return p.CheckResponse{}, err
+	}
+	failures, err := checkFailures(ctx, resp.Diagnostics)
+	return p.CheckResponse{Inputs: req.News, Failures: failures}, err
+}
+
+// change is a change to a resource planned by PlanResourceChange.
+type change struct {
+	prior, config, planned tftypes.Value
+
+	plannedPrivate  []byte
+	requiresReplace []*tftypes.AttributePath
+}
+
+// plan plans the change of r from the state olds, with the ID id, to the inputs news.
+// olds is nil when the resource is being created.
+func (s *shim) plan(ctx context.Context, r entry, id string, olds, news resource.PropertyMap) (change, error) {
+	typ := r.valueType()
+	prior := tftypes.NewValue(typ, nil)
+	var priorPrivate []byte
+	if olds != nil {
+		var err error
+		if prior, err = state(olds, id, typ); err != nil {
+			return change{}, err
+		}
+		if priorPrivate, err = private(olds); err != nil {
+			return change{}, err
+		}
+	}
+	config, err := toValue(news, typ)
+	if err != nil {
+		return change{}, err
+	}
+	proposed, err := proposedNew(r.block(), prior, config)
+	if err != nil {
+		return change{}, err
+	}
+
+	var dvs [3]*tfprotov6.DynamicValue
+	for i, v := range []tftypes.Value{prior, proposed, config} {
+		if dvs[i], err = newDynamicValue(v); err != nil {
+			return change{}, err
+		}
+	}
+	resp, err := s.server.PlanResourceChange(ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         r.typeName,
+		PriorState:       dvs[0],
+		ProposedNewState: dvs[1],
+		Config:           dvs[2],
+		PriorPrivate:     priorPrivate,
+	})
+	if err != nil {
+		return change{}, err
+	}
+	if err := diagnostics(ctx, resp.Diagnostics); err != nil {
+		return change{}, err
+	}
+	planned, err := decode(resp.PlannedState, typ)
+	if err != nil {
+		return change{}, err
+	}
+	return change{
+		prior:           prior,
+		config:          config,
+		planned:         planned,
+		plannedPrivate:  resp.PlannedPrivate,
+		requiresReplace: resp.RequiresReplace,
+	}, nil
+}
+
+// apply applies c to r, and returns the new state of r. The state is null if r was
+// deleted.
+func (s *shim) apply(ctx context.Context, r entry, c change) (tftypes.Value, []byte, error) {
+	var dvs [3]*tfprotov6.DynamicValue
+	for i, v := range []tftypes.Value{c.prior, c.planned, c.config} {
+		var err error
+		if dvs[i], err = newDynamicValue(v); err != nil {
+			return tftypes.Value{}, nil, err
+		}
+	}
+	resp, err := s.server.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       r.typeName,
+		PriorState:     dvs[0],
+		PlannedState:   dvs[1],
+		Config:         dvs[2],
+		PlannedPrivate: c.plannedPrivate,
+	})
+	if err != nil {
+		return tftypes.Value{}, nil, err
+	}
+	newState, decodeErr := decode(resp.NewState, r.valueType())
+	return newState, resp.Private, errors.Join(diagnostics(ctx, resp.Diagnostics), decodeErr)
+}
+
+func (s *shim) diff(ctx context.Context, req p.DiffRequest) (p.DiffResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+	news := req.News.Copy()
+	for _, k := range req.IgnoreChanges {
+		if v, ok := req.Olds[k]; ok {
+			news[k] = v
+		} else {
+			delete(news, k)
+		}
+	}
+	c, err := s.plan(ctx, r, req.ID, req.Olds, news)
+	if err != nil {
+		return p.DiffResponse{}, err
+	}
+
+	var prior, planned map[string]tftypes.Value
+	if err := c.prior.As(&prior); err != nil {
+		return p.DiffResponse{}, err
+	}
+	if err := c.planned.As(&planned); err != nil {
+		return p.DiffResponse{}, err
+	}
+	replace := map[string]bool{}
+	for _, path := range c.requiresReplace {
+		if steps := path.Steps(); len(steps) > 0 {
+			if name, ok := steps[0].(tftypes.AttributeName); ok {
+				replace[string(name)] = true
+			}
+		}
+	}
+
+	detailed := map[string]p.PropertyDiff{}
+	for name, old := range prior {
+		next := planned[name]
+		if name == "id" || old.Equal(next) {
+			continue
+		}
+		kind := p.Update
+		switch {
+		case old.IsNull():
+			kind = p.Add
+		case next.IsNull():
+			kind = p.Delete
+		}
+		if replace[name] {
+			kind = map[p.DiffKind]p.DiffKind{
+				p.Add:    p.AddReplace,
+				p.Delete: p.DeleteReplace,
+				p.Update: p.UpdateReplace,
+			}[kind]
+		}
+		detailed[propertyName(name)] = p.PropertyDiff{Kind: kind}
+	}
+	return p.DiffResponse{
+		HasChanges:   len(detailed) > 0,
+		DetailedDiff: detailed,
+	}, nil
+}
+
+func (s *shim) create(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.CreateResponse{}, err
+	}
+	c, err := s.plan(ctx, r, "", nil, req.Properties)
+	if err != nil {
+		return p.CreateResponse{}, err
+	}
+	if req.Preview {
+		props, err := outputs(c.planned, r, nil)
+		return p.CreateResponse{Properties: props}, err
+	}
+
+	newState, priv, err := s.apply(ctx, r, c)
+	if newState.IsNull() {
+		if err == nil {
+			err = fmt.Errorf("%s was not created", r.typeName)
+		}
+		return p.CreateResponse{}, err
+	}
+	props, convErr := outputs(newState, r, priv)
+	if convErr != nil {
+		return p.CreateResponse{}, errors.Join(err, convErr)
+	}
+	resp := p.CreateResponse{ID: idOf(newState), Properties: props}
+	if err != nil {
+		resp.PartialState = &p.InitializationFailed{Reasons: []string{err.Error()}}
+	}
+	return resp, err
+}
+
+func (s *shim) read(ctx context.Context, req p.ReadRequest) (p.ReadResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+
+	var current tftypes.Value
+	var priv []byte
+	if len(req.Properties) == 0 {
+		// The resource is being imported, so its state is not known.
+		if current, priv, err = s.importState(ctx, r, req.ID); err != nil {
+			return p.ReadResponse{}, err
+		}
+	} else {
+		if current, err = state(req.Properties, req.ID, r.valueType()); err != nil {
+			return p.ReadResponse{}, err
+		}
+		if priv, err = private(req.Properties); err != nil {
+			return p.ReadResponse{}, err
+		}
+	}
+	dv, err := newDynamicValue(current)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+
+	resp, err := s.server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     r.typeName,
+		CurrentState: dv,
+		Private:      priv,
+	})
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	if err := diagnostics(ctx, resp.Diagnostics); err != nil {
+		return p.ReadResponse{}, err
+	}
+	newState, err := decode(resp.NewState, r.valueType())
+	if err != nil || newState.IsNull() {
+		// A null state means that the resource no longer exists.
+		return p.ReadResponse{}, err
+	}
+	props, err := outputs(newState, r, resp.Private)
+	if err != nil {
+		return p.ReadResponse{}, err
+	}
+	return p.ReadResponse{
+		ID:         idOf(newState),
+		Properties: props,
+		Inputs:     inputs(props, r.block()),
+	}, nil
+}
+
+// importState returns the state of the resource r with the ID id.
+func (s *shim) importState(ctx context.Context, r entry, id string) (tftypes.Value, []byte, error) {
+	resp, err := s.server.ImportResourceState(ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: r.typeName,
+		ID:       id,
+	})
+	if err != nil {
+		return tftypes.Value{}, nil, err
+	}
+	if err := diagnostics(ctx, resp.Diagnostics); err != nil {
+		return tftypes.Value{}, nil, err
+	}
+	for _, imported := range resp.ImportedResources {
+		if imported.TypeName == r.typeName {
+			v, err := decode(imported.State, r.valueType())
+			return v, imported.Private, err
+		}
+	}
+	return tftypes.Value{}, nil, fmt.Errorf("%s does not support import", r.typeName)
+}
+
+func (s *shim) update(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.UpdateResponse{}, err
+	}
+	c, err := s.plan(ctx, r, req.ID, req.Olds, req.News)
+	if err != nil {
+		return p.UpdateResponse{}, err
+	}
+	if req.Preview {
+		props, err := outputs(c.planned, r, c.plannedPrivate)
+		return p.UpdateResponse{Properties: props}, err
+	}
+
+	newState, priv, err := s.apply(ctx, r, c)
+	if newState.IsNull() {
+		if err == nil {
+			err = fmt.Errorf("%s was deleted during update", r.typeName)
+		}
+		return p.UpdateResponse{}, err
+	}
+	props, convErr := outputs(newState, r, priv)
+	if convErr != nil {
+		return p.UpdateResponse{}, errors.Join(err, convErr)
+	}
+	resp := p.UpdateResponse{Properties: props}
+	if err != nil {
+		resp.PartialState = &p.InitializationFailed{Reasons: []string{err.Error()}}
+	}
+	return resp, err
+}
+
+func (s *shim) delete(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+	r, err := s.resource(ctx, req.Urn)
+	if err != nil {
+		return p.DeleteResponse{}, err
+	}
+	typ := r.valueType()
+	prior, err := state(req.Properties, req.ID, typ)
+	if err != nil {
+		return p.DeleteResponse{}, err
+	}
+	priv, err := private(req.Properties)
+	if err != nil {
+		return p.DeleteResponse{}, err
+	}
+	_, _, err = s.apply(ctx, r, change{
+		prior:          prior,
+		config:         tftypes.NewValue(typ, nil),
+		planned:        tftypes.NewValue(typ, nil),
+		plannedPrivate: priv,
+	})
+	return p.DeleteResponse{}, err
+}
+
+// state converts the Pulumi state of a resource with the ID id into Terraform state.
+func state(m resource.PropertyMap, id string, typ tftypes.Type) (tftypes.Value, error) {
+	if obj, ok := typ.(tftypes.Object); ok && id != "" {
+		if _, hasID := obj.AttributeTypes["id"]; hasID && !m.HasValue("id") {
+			m = m.Copy()
+			m["id"] = resource.NewStringProperty(id)
+		}
+	}
+	return toValue(m, typ)
+}
+
+// outputs converts the Terraform state of r into Pulumi outputs, recording its private
+// state.
+func outputs(v tftypes.Value, r entry, priv []byte) (resource.PropertyMap, error) {
+	m, err := fromValue(v)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = resource.PropertyMap{}
+	}
+	delete(m, "id")
+	markSecrets(m, r.block())
+	if len(priv) > 0 {
+		m[privateKey] = resource.NewStringProperty(base64.StdEncoding.EncodeToString(priv))
+	}
+	return m, nil
+}
+
+// private returns the Terraform private state recorded in m by [outputs].
+func private(m resource.PropertyMap) ([]byte, error) {
+	v, ok := m[privateKey]
+	if !ok || !v.IsString() {
+		return nil, nil
+	}
+	return base64.StdEncoding.DecodeString(v.StringValue())
+}
+
+// inputs returns the properties of outputs that can be configured.
+func inputs(outputs resource.PropertyMap, block *tfprotov6.SchemaBlock) resource.PropertyMap {
+	m := resource.PropertyMap{}
+	for _, attr := range block.Attributes {
+		k := resource.PropertyKey(propertyName(attr.Name))
+		if v, ok := outputs[k]; ok && (attr.Required || attr.Optional) {
+			m[k] = v
+		}
+	}
+	for _, b := range block.BlockTypes {
+		k := resource.PropertyKey(propertyName(b.TypeName))
+		if v, ok := outputs[k]; ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// idOf returns the value of the "id" attribute of the Terraform state v.
+func idOf(v tftypes.Value) string {
+	var attrs map[string]tftypes.Value
+	if err := v.As(&attrs); err != nil {
+		return ""
+	}
+	var id string
+	if attr, ok := attrs["id"]; ok && attr.IsKnown() && !attr.IsNull() {
+		_ = attr.As(&id)
+	}
+	return id
+}
+
+// diagnostics logs the warnings in diags, and returns their errors.
+func diagnostics(ctx context.Context, diags []*tfprotov6.Diagnostic) error {
+	var errs []error
+	for _, d := range diags {
+		msg := diagnosticMessage(d)
+		if d.Attribute != nil {
+			msg = fmt.Sprintf("%s: %s", propertyPath(d.Attribute), msg)
+		}
+		if d.Severity == tfprotov6.DiagnosticSeverityWarning {
+			p.GetLogger(ctx).Warning(msg)
+			continue
+		}
+		errs = append(errs, errors.New(msg))
+	}
+	return errors.Join(errs...)
+}
+
+// checkFailures converts the errors in diags that refer to an attribute into check
+// failures. Warnings are logged, and other errors returned.
+func checkFailures(ctx context.Context, diags []*tfprotov6.Diagnostic) ([]p.CheckFailure, error) {
+	var failures []p.CheckFailure
+	var rest []*tfprotov6.Diagnostic
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError && d.Attribute != nil {
+			failures = append(failures, p.CheckFailure{
+				Property: propertyPath(d.Attribute),
+				Reason:   diagnosticMessage(d),
+			})
+			continue
+		}
+		rest = append(rest, d)
+	}
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Property < failures[j].Property })
+	return failures, diagnostics(ctx, rest)
+}
+
+func diagnosticMessage(d *tfprotov6.Diagnostic) string {
+	if d.Detail == "" {
+		return d.Summary
+	}
+	return d.Summary + ": " + d.Detail
+}
+
+// propertyPath converts a Terraform attribute path into a Pulumi property path, such as
+// "rules[0].fromPort".
+func propertyPath(path *tftypes.AttributePath) string {
+	var b strings.Builder
+	for _, step := range path.Steps() {
+		switch step := step.(type) {
+		case tftypes.AttributeName:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(propertyName(string(step)))
+		case tftypes.ElementKeyInt:
+			fmt.Fprintf(&b, "[%d]", int64(step))
+		case tftypes.ElementKeyString:
+			fmt.Fprintf(&b, "[%q]", string(step))
+		}
+	}
+	return b.String()
+}
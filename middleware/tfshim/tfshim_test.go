@@ -0,0 +1,419 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfshim
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+var widgetSchema = &tfprotov6.Schema{Block: &tfprotov6.SchemaBlock{
+	Description: "A widget.",
+	Attributes: []*tfprotov6.SchemaAttribute{
+		{Name: "id", Type: tftypes.String, Computed: true},
+		{Name: "display_name", Type: tftypes.String, Required: true, Description: "The name of the widget."},
+		{Name: "size", Type: tftypes.Number, Optional: true},
+		{Name: "zone", Type: tftypes.String, Optional: true},
+		{Name: "token", Type: tftypes.String, Computed: true, Sensitive: true},
+	},
+	BlockTypes: []*tfprotov6.SchemaNestedBlock{{
+		TypeName: "label",
+		Nesting:  tfprotov6.SchemaNestedBlockNestingModeList,
+		Block: &tfprotov6.SchemaBlock{Attributes: []*tfprotov6.SchemaAttribute{
+			{Name: "key", Type: tftypes.String, Required: true},
+		}},
+	}},
+}}
+
+var lookupSchema = &tfprotov6.Schema{Block: &tfprotov6.SchemaBlock{
+	Attributes: []*tfprotov6.SchemaAttribute{
+		{Name: "key", Type: tftypes.String, Required: true},
+		{Name: "value", Type: tftypes.String, Computed: true},
+	},
+}}
+
+// fakeServer is a Terraform provider with a single resource, example_widget, and a single
+// data source, example_lookup.
+type fakeServer struct {
+	m       sync.Mutex
+	region  string
+	widgets map[string]tftypes.Value
+}
+
+func (s *fakeServer) GetMetadata(
+	context.Context, *tfprotov6.GetMetadataRequest,
+) (*tfprotov6.GetMetadataResponse, error) {
+	return &tfprotov6.GetMetadataResponse{}, nil
+}
+
+func (s *fakeServer) GetProviderSchema(
+	context.Context, *tfprotov6.GetProviderSchemaRequest,
+) (*tfprotov6.GetProviderSchemaResponse, error) {
+	return &tfprotov6.GetProviderSchemaResponse{
+		Provider: &tfprotov6.Schema{Block: &tfprotov6.SchemaBlock{
+			Attributes: []*tfprotov6.SchemaAttribute{{Name: "region", Type: tftypes.String, Optional: true}},
+		}},
+		ResourceSchemas:   map[string]*tfprotov6.Schema{"example_widget": widgetSchema},
+		DataSourceSchemas: map[string]*tfprotov6.Schema{"example_lookup": lookupSchema},
+	}, nil
+}
+
+func (s *fakeServer) ValidateProviderConfig(
+	_ context.Context, req *tfprotov6.ValidateProviderConfigRequest,
+) (*tfprotov6.ValidateProviderConfigResponse, error) {
+	return &tfprotov6.ValidateProviderConfigResponse{PreparedConfig: req.Config}, nil
+}
+
+func (s *fakeServer) ConfigureProvider(
+	_ context.Context, req *tfprotov6.ConfigureProviderRequest,
+) (*tfprotov6.ConfigureProviderResponse, error) {
+	attrs := s.attrs(req.Config, (&tfprotov6.Schema{Block: &tfprotov6.SchemaBlock{
+		Attributes: []*tfprotov6.SchemaAttribute{{Name: "region", Type: tftypes.String, Optional: true}},
+	}}).ValueType())
+	s.m.Lock()
+	defer s.m.Unlock()
+	_ = attrs["region"].As(&s.region)
+	return &tfprotov6.ConfigureProviderResponse{}, nil
+}
+
+func (s *fakeServer) StopProvider(
+	context.Context, *tfprotov6.StopProviderRequest,
+) (*tfprotov6.StopProviderResponse, error) {
+	return &tfprotov6.StopProviderResponse{}, nil
+}
+
+func (s *fakeServer) ValidateResourceConfig(
+	_ context.Context, req *tfprotov6.ValidateResourceConfigRequest,
+) (*tfprotov6.ValidateResourceConfigResponse, error) {
+	attrs := s.attrs(req.Config, widgetSchema.ValueType())
+	var name string
+	_ = attrs["display_name"].As(&name)
+	resp := &tfprotov6.ValidateResourceConfigResponse{}
+	if strings.Contains(name, " ") {
+		resp.Diagnostics = append(resp.Diagnostics, &tfprotov6.Diagnostic{
+			Severity:  tfprotov6.DiagnosticSeverityError,
+			Summary:   "Invalid name",
+			Detail:    "names may not contain spaces",
+			Attribute: tftypes.NewAttributePath().WithAttributeName("display_name"),
+		})
+	}
+	return resp, nil
+}
+
+func (s *fakeServer) UpgradeResourceState(
+	context.Context, *tfprotov6.UpgradeResourceStateRequest,
+) (*tfprotov6.UpgradeResourceStateResponse, error) {
+	panic("not implemented")
+}
+
+func (s *fakeServer) ReadResource(
+	_ context.Context, req *tfprotov6.ReadResourceRequest,
+) (*tfprotov6.ReadResourceResponse, error) {
+	var id string
+	_ = s.attrs(req.CurrentState, widgetSchema.ValueType())["id"].As(&id)
+	s.m.Lock()
+	defer s.m.Unlock()
+	v, ok := s.widgets[id]
+	if !ok {
+		v = tftypes.NewValue(widgetSchema.ValueType(), nil)
+	}
+	state, err := tfprotov6.NewDynamicValue(widgetSchema.ValueType(), v)
+	return &tfprotov6.ReadResourceResponse{NewState: &state, Private: req.Private}, err
+}
+
+func (s *fakeServer) PlanResourceChange(
+	_ context.Context, req *tfprotov6.PlanResourceChangeRequest,
+) (*tfprotov6.PlanResourceChangeResponse, error) {
+	typ := widgetSchema.ValueType()
+	prior := s.attrs(req.PriorState, typ)
+	proposed := s.attrs(req.ProposedNewState, typ)
+	resp := &tfprotov6.PlanResourceChangeResponse{PlannedPrivate: []byte("planned")}
+	if prior == nil {
+		proposed["id"] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+		proposed["token"] = tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	} else if !prior["zone"].Equal(proposed["zone"]) {
+		resp.RequiresReplace = append(resp.RequiresReplace, tftypes.NewAttributePath().WithAttributeName("zone"))
+	}
+	planned, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, proposed))
+	resp.PlannedState = &planned
+	return resp, err
+}
+
+func (s *fakeServer) ApplyResourceChange(
+	_ context.Context, req *tfprotov6.ApplyResourceChangeRequest,
+) (*tfprotov6.ApplyResourceChangeResponse, error) {
+	typ := widgetSchema.ValueType()
+	planned := s.attrs(req.PlannedState, typ)
+	s.m.Lock()
+	defer s.m.Unlock()
+	if planned == nil {
+		var id string
+		_ = s.attrs(req.PriorState, typ)["id"].As(&id)
+		delete(s.widgets, id)
+		return &tfprotov6.ApplyResourceChangeResponse{NewState: req.PlannedState}, nil
+	}
+	var name string
+	_ = planned["display_name"].As(&name)
+	planned["id"] = tftypes.NewValue(tftypes.String, "w-"+name)
+	planned["token"] = tftypes.NewValue(tftypes.String, "secret-"+name)
+	v := tftypes.NewValue(typ, planned)
+	s.widgets["w-"+name] = v
+	state, err := tfprotov6.NewDynamicValue(typ, v)
+	return &tfprotov6.ApplyResourceChangeResponse{NewState: &state, Private: req.PlannedPrivate}, err
+}
+
+func (s *fakeServer) ImportResourceState(
+	_ context.Context, req *tfprotov6.ImportResourceStateRequest,
+) (*tfprotov6.ImportResourceStateResponse, error) {
+	typ := widgetSchema.ValueType()
+	attrs := map[string]tftypes.Value{}
+	for name, t := range typ.(tftypes.Object).AttributeTypes {
+		attrs[name] = tftypes.NewValue(t, nil)
+	}
+	attrs["id"] = tftypes.NewValue(tftypes.String, req.ID)
+	state, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, attrs))
+	return &tfprotov6.ImportResourceStateResponse{ImportedResources: []*tfprotov6.ImportedResource{
+		{TypeName: req.TypeName, State: &state},
+	}}, err
+}
+
+func (s *fakeServer) MoveResourceState(
+	context.Context, *tfprotov6.MoveResourceStateRequest,
+) (*tfprotov6.MoveResourceStateResponse, error) {
+	panic("not implemented")
+}
+
+func (s *fakeServer) ValidateDataResourceConfig(
+	context.Context, *tfprotov6.ValidateDataResourceConfigRequest,
+) (*tfprotov6.ValidateDataResourceConfigResponse, error) {
+	return &tfprotov6.ValidateDataResourceConfigResponse{}, nil
+}
+
+func (s *fakeServer) ReadDataSource(
+	_ context.Context, req *tfprotov6.ReadDataSourceRequest,
+) (*tfprotov6.ReadDataSourceResponse, error) {
+	typ := lookupSchema.ValueType()
+	attrs := s.attrs(req.Config, typ)
+	var key string
+	_ = attrs["key"].As(&key)
+	s.m.Lock()
+	attrs["value"] = tftypes.NewValue(tftypes.String, strings.ToUpper(key)+"@"+s.region)
+	s.m.Unlock()
+	state, err := tfprotov6.NewDynamicValue(typ, tftypes.NewValue(typ, attrs))
+	return &tfprotov6.ReadDataSourceResponse{State: &state}, err
+}
+
+func (s *fakeServer) CallFunction(
+	context.Context, *tfprotov6.CallFunctionRequest,
+) (*tfprotov6.CallFunctionResponse, error) {
+	panic("not implemented")
+}
+
+func (s *fakeServer) GetFunctions(
+	context.Context, *tfprotov6.GetFunctionsRequest,
+) (*tfprotov6.GetFunctionsResponse, error) {
+	panic("not implemented")
+}
+
+// attrs decodes the object dv, returning nil if it is null.
+func (s *fakeServer) attrs(dv *tfprotov6.DynamicValue, typ tftypes.Type) map[string]tftypes.Value {
+	v, err := dv.Unmarshal(typ)
+	if err != nil {
+		panic(err)
+	}
+	if v.IsNull() {
+		return nil
+	}
+	var attrs map[string]tftypes.Value
+	if err := v.As(&attrs); err != nil {
+		panic(err)
+	}
+	return attrs
+}
+
+func server() (integration.Server, *fakeServer) {
+	fake := &fakeServer{widgets: map[string]tftypes.Value{}}
+	return integration.NewServer("example", semver.MustParse("1.0.0"), Provider(fake, Options{})), fake
+}
+
+func TestSchema(t *testing.T) {
+	t.Parallel()
+	s, _ := server()
+
+	resp, err := s.GetSchema(p.GetSchemaRequest{})
+	require.NoError(t, err)
+	var spec pschema.PackageSpec
+	require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+
+	widget, ok := spec.Resources["example:index:Widget"]
+	require.True(t, ok)
+	assert.Equal(t, "A widget.", widget.Description)
+	assert.Equal(t, []string{"displayName"}, widget.RequiredInputs)
+	assert.Equal(t, "The name of the widget.", widget.InputProperties["displayName"].Description)
+	assert.NotContains(t, widget.InputProperties, "token", "computed attributes are not inputs")
+	assert.True(t, widget.Properties["token"].Secret)
+	assert.NotContains(t, widget.Properties, "id")
+	assert.Equal(t, "#/types/example:index:WidgetLabel", widget.Properties["label"].Items.Ref)
+	assert.Contains(t, spec.Types, "example:index:WidgetLabel")
+
+	lookup, ok := spec.Functions["example:index:getLookup"]
+	require.True(t, ok)
+	assert.Equal(t, []string{"key"}, lookup.Inputs.Required)
+	assert.Contains(t, lookup.ReturnType.ObjectTypeSpec.Properties, "value")
+
+	assert.Contains(t, spec.Provider.InputProperties, "region")
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+	s, fake := server()
+	urn := resource.NewURN("stack", "proj", "", "example:index:Widget", "w")
+
+	type m = resource.PropertyMap
+	str := resource.NewStringProperty
+
+	check, err := s.Check(p.CheckRequest{Urn: urn, News: m{"displayName": str("has space")}})
+	require.NoError(t, err)
+	assert.Equal(t, []p.CheckFailure{{
+		Property: "displayName",
+		Reason:   "Invalid name: names may not contain spaces",
+	}}, check.Failures)
+
+	inputs := m{
+		"displayName": str("alpha"),
+		"size":        resource.NewNumberProperty(3),
+		"label":       resource.NewArrayProperty([]resource.PropertyValue{resource.NewObjectProperty(m{"key": str("k")})}),
+	}
+	preview, err := s.Create(p.CreateRequest{Urn: urn, Properties: inputs, Preview: true})
+	require.NoError(t, err)
+	assert.True(t, preview.Properties["token"].SecretValue().Element.IsComputed())
+	assert.Empty(t, fake.widgets, "previews don't apply changes")
+
+	created, err := s.Create(p.CreateRequest{Urn: urn, Properties: inputs})
+	require.NoError(t, err)
+	assert.Equal(t, "w-alpha", created.ID)
+	assert.Equal(t, resource.MakeSecret(str("secret-alpha")), created.Properties["token"])
+	assert.Equal(t, str("alpha"), created.Properties["displayName"])
+	assert.Equal(t, inputs["label"], created.Properties["label"])
+	assert.NotContains(t, created.Properties, resource.PropertyKey("id"))
+	assert.Contains(t, created.Properties, resource.PropertyKey(privateKey))
+
+	read, err := s.Read(p.ReadRequest{ID: created.ID, Urn: urn, Properties: created.Properties})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, read.ID)
+	assert.Equal(t, created.Properties, read.Properties)
+	assert.NotContains(t, read.Inputs, resource.PropertyKey("token"))
+
+	imported, err := s.Read(p.ReadRequest{ID: created.ID, Urn: urn})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, imported.ID)
+	assert.Equal(t, str("alpha"), imported.Inputs["displayName"])
+
+	resized := inputs.Copy()
+	resized["size"] = resource.NewNumberProperty(4)
+	diff, err := s.Diff(p.DiffRequest{ID: created.ID, Urn: urn, Olds: created.Properties, News: resized})
+	require.NoError(t, err)
+	assert.True(t, diff.HasChanges)
+	assert.Equal(t, map[string]p.PropertyDiff{"size": {Kind: p.Update}}, diff.DetailedDiff)
+
+	diff, err = s.Diff(p.DiffRequest{ID: created.ID, Urn: urn, Olds: created.Properties, News: inputs})
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges, "computed attributes are carried over from the prior state")
+
+	moved := inputs.Copy()
+	moved["zone"] = str("b")
+	diff, err = s.Diff(p.DiffRequest{ID: created.ID, Urn: urn, Olds: created.Properties, News: moved})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]p.PropertyDiff{"zone": {Kind: p.AddReplace}}, diff.DetailedDiff)
+
+	diff, err = s.Diff(p.DiffRequest{
+		ID: created.ID, Urn: urn, Olds: created.Properties, News: moved, IgnoreChanges: []resource.PropertyKey{"zone"},
+	})
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges)
+
+	updated, err := s.Update(p.UpdateRequest{ID: created.ID, Urn: urn, Olds: created.Properties, News: resized})
+	require.NoError(t, err)
+	assert.Equal(t, resource.NewNumberProperty(4), updated.Properties["size"])
+
+	_, err = s.Delete(p.DeleteRequest{ID: created.ID, Urn: urn, Properties: updated.Properties})
+	require.NoError(t, err)
+	assert.Empty(t, fake.widgets)
+
+	read, err = s.Read(p.ReadRequest{ID: created.ID, Urn: urn, Properties: updated.Properties})
+	require.NoError(t, err)
+	assert.Empty(t, read.ID, "a deleted resource reads as missing")
+}
+
+func TestInvoke(t *testing.T) {
+	t.Parallel()
+	s, _ := server()
+
+	require.NoError(t, s.Configure(p.ConfigureRequest{
+		Args: resource.PropertyMap{"region": resource.NewStringProperty("eu")},
+	}))
+	resp, err := s.Invoke(p.InvokeRequest{
+		Token: "example:index:getLookup",
+		Args:  resource.PropertyMap{"key": resource.NewStringProperty("abc")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"key":   resource.NewStringProperty("abc"),
+		"value": resource.NewStringProperty("ABC@eu"),
+	}, resp.Return)
+}
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+	fake := &fakeServer{widgets: map[string]tftypes.Value{}}
+	s := integration.NewServer("example", semver.MustParse("1.0.0"), Provider(fake, Options{
+		Tokens: map[string]string{"example_widget": "things:Gadget"},
+	}))
+
+	_, err := s.Create(p.CreateRequest{
+		Urn:        resource.NewURN("stack", "proj", "", "example:things:Gadget", "g"),
+		Properties: resource.PropertyMap{"displayName": resource.NewStringProperty("g")},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, fake.widgets, "w-g")
+
+	_, err = s.Create(p.CreateRequest{
+		Urn: resource.NewURN("stack", "proj", "", "example:index:Widget", "w"),
+	})
+	assert.ErrorContains(t, err, `unknown resource type "example:index:Widget"`)
+}
+
+func TestPropertyNames(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "minLength", propertyName("min_length"))
+	assert.Equal(t, "id", propertyName("id"))
+	assert.Equal(t, "MinLength", pascal("min_length"))
+	assert.Equal(t, "rules[0].fromPort", propertyPath(
+		tftypes.NewAttributePath().WithAttributeName("rules").WithElementKeyInt(0).WithAttributeName("from_port")))
+}
@@ -0,0 +1,292 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfshim
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// propertyName converts a Terraform attribute name, such as "min_length", into a Pulumi
+// property name, such as "minLength".
+func propertyName(attribute string) string {
+	parts := strings.Split(attribute, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// toValue converts a Pulumi property map into a Terraform object of type typ.
+//
+// Properties that typ doesn't describe are ignored, and attributes missing from m are
+// null.
+func toValue(m resource.PropertyMap, typ tftypes.Type) (tftypes.Value, error) {
+	return toTerraform(resource.NewObjectProperty(m), typ, tftypes.NewAttributePath())
+}
+
+func toTerraform(v resource.PropertyValue, typ tftypes.Type, path *tftypes.AttributePath) (tftypes.Value, error) {
+	switch {
+	case v.IsSecret():
+		return toTerraform(v.SecretValue().Element, typ, path)
+	case v.IsComputed():
+		return tftypes.NewValue(typ, tftypes.UnknownValue), nil
+	case v.IsOutput():
+		if !v.OutputValue().Known {
+			return tftypes.NewValue(typ, tftypes.UnknownValue), nil
+		}
+		return toTerraform(v.OutputValue().Element, typ, path)
+	case v.IsNull():
+		return tftypes.NewValue(typ, nil), nil
+	}
+
+	switch {
+	case typ.Is(tftypes.String):
+		switch {
+		case v.IsString():
+			return tftypes.NewValue(typ, v.StringValue()), nil
+		case v.IsNumber():
+			return tftypes.NewValue(typ, strconv.FormatFloat(v.NumberValue(), 'f', -1, 64)), nil
+		case v.IsBool():
+			return tftypes.NewValue(typ, strconv.FormatBool(v.BoolValue())), nil
+		}
+	case typ.Is(tftypes.Number):
+		switch {
+		case v.IsNumber():
+			return tftypes.NewValue(typ, big.NewFloat(v.NumberValue())), nil
+		case v.IsString():
+			f, _, err := big.ParseFloat(v.StringValue(), 10, 512, big.ToNearestEven)
+			if err != nil {
+				return tftypes.Value{}, path.NewErrorf("expected a number, found %q", v.StringValue())
+			}
+			return tftypes.NewValue(typ, f), nil
+		}
+	case typ.Is(tftypes.Bool):
+		switch {
+		case v.IsBool():
+			return tftypes.NewValue(typ, v.BoolValue()), nil
+		case v.IsString():
+			b, err := strconv.ParseBool(v.StringValue())
+			if err != nil {
+				return tftypes.Value{}, path.NewErrorf("expected a bool, found %q", v.StringValue())
+			}
+			return tftypes.NewValue(typ, b), nil
+		}
+	case typ.Is(tftypes.List{}), typ.Is(tftypes.Set{}):
+		if !v.IsArray() {
+			break
+		}
+		var elem tftypes.Type
+		if l, ok := typ.(tftypes.List); ok {
+			elem = l.ElementType
+		} else {
+			elem = typ.(tftypes.Set).ElementType
+		}
+		elems := make([]tftypes.Value, len(v.ArrayValue()))
+		for i, e := range v.ArrayValue() {
+			var err error
+			elems[i], err = toTerraform(e, elem, path.WithElementKeyInt(i))
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		return tftypes.NewValue(typ, elems), nil
+	case typ.Is(tftypes.Map{}):
+		if !v.IsObject() {
+			break
+		}
+		elem := typ.(tftypes.Map).ElementType
+		elems := make(map[string]tftypes.Value, len(v.ObjectValue()))
+		for k, e := range v.ObjectValue() {
+			var err error
+			elems[string(k)], err = toTerraform(e, elem, path.WithElementKeyString(string(k)))
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		return tftypes.NewValue(typ, elems), nil
+	case typ.Is(tftypes.Object{}):
+		if !v.IsObject() {
+			break
+		}
+		obj := typ.(tftypes.Object)
+		attrs := make(map[string]tftypes.Value, len(obj.AttributeTypes))
+		for name, t := range obj.AttributeTypes {
+			var err error
+			attrs[name], err = toTerraform(v.ObjectValue()[resource.PropertyKey(propertyName(name))],
+				t, path.WithAttributeName(name))
+			if err != nil {
+				return tftypes.Value{}, err
+			}
+		}
+		return tftypes.NewValue(typ, attrs), nil
+	case typ.Is(tftypes.DynamicPseudoType):
+		return tftypes.Value{}, path.NewErrorf("dynamic attributes are not supported")
+	}
+	return tftypes.Value{}, path.NewErrorf("expected a %s, found a %s", typ, v.TypeString())
+}
+
+// fromValue converts a Terraform object into a Pulumi property map. Null attributes are
+// omitted.
+func fromValue(v tftypes.Value) (resource.PropertyMap, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	pv, err := fromTerraform(v)
+	if err != nil {
+		return nil, err
+	}
+	if !pv.IsObject() {
+		return nil, fmt.Errorf("expected an object, found %s", v.Type())
+	}
+	return pv.ObjectValue(), nil
+}
+
+func fromTerraform(v tftypes.Value) (resource.PropertyValue, error) {
+	if !v.IsKnown() {
+		return resource.MakeComputed(resource.NewStringProperty("")), nil
+	}
+	if v.IsNull() {
+		return resource.NewNullProperty(), nil
+	}
+
+	typ := v.Type()
+	switch {
+	case typ.Is(tftypes.String):
+		var s string
+		err := v.As(&s)
+		return resource.NewStringProperty(s), err
+	case typ.Is(tftypes.Number):
+		var f big.Float
+		if err := v.As(&f); err != nil {
+			return resource.PropertyValue{}, err
+		}
+		n, _ := f.Float64()
+		return resource.NewNumberProperty(n), nil
+	case typ.Is(tftypes.Bool):
+		var b bool
+		err := v.As(&b)
+		return resource.NewBoolProperty(b), err
+	case typ.Is(tftypes.List{}), typ.Is(tftypes.Set{}), typ.Is(tftypes.Tuple{}):
+		var elems []tftypes.Value
+		if err := v.As(&elems); err != nil {
+			return resource.PropertyValue{}, err
+		}
+		arr := make([]resource.PropertyValue, len(elems))
+		for i, e := range elems {
+			var err error
+			if arr[i], err = fromTerraform(e); err != nil {
+				return resource.PropertyValue{}, err
+			}
+		}
+		return resource.NewArrayProperty(arr), nil
+	case typ.Is(tftypes.Map{}), typ.Is(tftypes.Object{}):
+		var elems map[string]tftypes.Value
+		if err := v.As(&elems); err != nil {
+			return resource.PropertyValue{}, err
+		}
+		_, isObject := typ.(tftypes.Object)
+		m := make(resource.PropertyMap, len(elems))
+		for k, e := range elems {
+			if isObject {
+				if e.IsNull() {
+					continue
+				}
+				k = propertyName(k)
+			}
+			var err error
+			if m[resource.PropertyKey(k)], err = fromTerraform(e); err != nil {
+				return resource.PropertyValue{}, err
+			}
+		}
+		return resource.NewObjectProperty(m), nil
+	}
+	return resource.PropertyValue{}, fmt.Errorf("unsupported Terraform type %s", typ)
+}
+
+// markSecrets marks the properties of m that block declares sensitive as secret.
+func markSecrets(m resource.PropertyMap, block *tfprotov6.SchemaBlock) {
+	if block == nil {
+		return
+	}
+	for _, attr := range block.Attributes {
+		k := resource.PropertyKey(propertyName(attr.Name))
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		if attr.Sensitive {
+			m[k] = resource.MakeSecret(v)
+			continue
+		}
+		if attr.NestedType != nil {
+			nested := &tfprotov6.SchemaBlock{Attributes: attr.NestedType.Attributes}
+			forEachObject(v, attr.NestedType.Nesting == tfprotov6.SchemaObjectNestingModeMap,
+				func(m resource.PropertyMap) { markSecrets(m, nested) })
+		}
+	}
+	for _, b := range block.BlockTypes {
+		if v, ok := m[resource.PropertyKey(propertyName(b.TypeName))]; ok {
+			forEachObject(v, b.Nesting == tfprotov6.SchemaNestedBlockNestingModeMap,
+				func(m resource.PropertyMap) { markSecrets(m, b.Block) })
+		}
+	}
+}
+
+// forEachObject calls f on each object held by v: v itself when it is an object, or each
+// element of v when it is an array, or a map of objects when isMap is true.
+func forEachObject(v resource.PropertyValue, isMap bool, f func(resource.PropertyMap)) {
+	switch {
+	case v.IsObject() && isMap:
+		for _, e := range v.ObjectValue() {
+			if e.IsObject() {
+				f(e.ObjectValue())
+			}
+		}
+	case v.IsObject():
+		f(v.ObjectValue())
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			if e.IsObject() {
+				f(e.ObjectValue())
+			}
+		}
+	}
+}
+
+// newDynamicValue encodes v as a [tfprotov6.DynamicValue].
+func newDynamicValue(v tftypes.Value) (*tfprotov6.DynamicValue, error) {
+	dv, err := tfprotov6.NewDynamicValue(v.Type(), v)
+	if err != nil {
+		return nil, err
+	}
+	return &dv, nil
+}
+
+// decode decodes a [tfprotov6.DynamicValue] of type typ. A nil value decodes as null.
+func decode(dv *tfprotov6.DynamicValue, typ tftypes.Type) (tftypes.Value, error) {
+	if dv == nil {
+		return tftypes.NewValue(typ, nil), nil
+	}
+	return dv.Unmarshal(typ)
+}
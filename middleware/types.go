@@ -27,7 +27,7 @@ type CustomResource interface {
 	Create(context.Context, p.CreateRequest) (p.CreateResponse, error)
 	Read(context.Context, p.ReadRequest) (p.ReadResponse, error)
 	Update(context.Context, p.UpdateRequest) (p.UpdateResponse, error)
-	Delete(context.Context, p.DeleteRequest) error
+	Delete(context.Context, p.DeleteRequest) (p.DeleteResponse, error)
 }
 
 // ComponentResource provides a shared definition of a Pulumi component resource for
@@ -0,0 +1,159 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watchdog provides a middleware that decides what happens when a provider hits
+// an internal error: a panic in one of its calls, such as a failed contract.Assertf.
+//
+// After an internal error the provider may be left in an undefined state. By default the
+// provider exits, so that no further calls are served from that state and the engine
+// starts a fresh provider for the next deployment. Providers that would rather report
+// the error and keep serving can recover instead. The policy can be chosen per
+// deployment environment with PULUMI_PROVIDER_ON_INTERNAL_ERROR (see [FromEnv]):
+//
+//	provider, err := watchdog.FromEnv(provider, watchdog.Options{Policy: watchdog.Exit})
+//	if err != nil {
+//		return err
+//	}
+//	return p.RunProvider("my-provider", version, provider)
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// EnvVar is the environment variable read by [FromEnv].
+const EnvVar = "PULUMI_PROVIDER_ON_INTERNAL_ERROR"
+
+// Policy is what a provider does when one of its calls panics.
+type Policy string
+
+const (
+	// Exit writes the internal error and its stack trace to stderr, and exits the
+	// provider process.
+	Exit Policy = "exit"
+	// Recover fails the call that panicked with a codes.Internal error, and keeps
+	// serving other calls.
+	Recover Policy = "recover"
+)
+
+// Options controls how internal errors are handled.
+type Options struct {
+	// Policy is the policy applied to internal errors. If empty, [Exit] is used.
+	Policy Policy
+	// Exit exits the process under the [Exit] policy. If nil, os.Exit is used.
+	Exit func(code int)
+}
+
+// FromEnv wraps provider with [Wrap], using the policy set in PULUMI_PROVIDER_ON_INTERNAL_ERROR
+// if any, and opts.Policy otherwise.
+//
+// An error is returned if PULUMI_PROVIDER_ON_INTERNAL_ERROR is not a known [Policy].
+func FromEnv(provider p.Provider, opts Options) (p.Provider, error) {
+	if v, ok := os.LookupEnv(EnvVar); ok && v != "" {
+		switch policy := Policy(v); policy {
+		case Exit, Recover:
+			opts.Policy = policy
+		default:
+			return p.Provider{}, fmt.Errorf("%s: unknown policy %q, expected %q or %q",
+				EnvVar, v, Exit, Recover)
+		}
+	}
+	return Wrap(provider, opts), nil
+}
+
+// Wrap provider so that panics in its calls are handled according to opts.
+//
+// Only panics on the goroutine serving a call are handled. A panic in a goroutine
+// started by the provider still crashes the process.
+func Wrap(provider p.Provider, opts Options) p.Provider {
+	w := &watchdog{opts: opts}
+	if w.opts.Policy == "" {
+		w.opts.Policy = Exit
+	}
+	if w.opts.Exit == nil {
+		w.opts.Exit = os.Exit
+	}
+
+	provider.GetSchema = wrapIO(w, "GetSchema", provider.GetSchema)
+	provider.Parameterize = wrapIO(w, "Parameterize", provider.Parameterize)
+	provider.CheckConfig = wrapIO(w, "CheckConfig", provider.CheckConfig)
+	provider.DiffConfig = wrapIO(w, "DiffConfig", provider.DiffConfig)
+	provider.Configure = wrapI(w, "Configure", provider.Configure)
+	provider.Invoke = wrapIO(w, "Invoke", provider.Invoke)
+	provider.Check = wrapIO(w, "Check", provider.Check)
+	provider.Diff = wrapIO(w, "Diff", provider.Diff)
+	provider.Create = wrapIO(w, "Create", provider.Create)
+	provider.Read = wrapIO(w, "Read", provider.Read)
+	provider.Update = wrapIO(w, "Update", provider.Update)
+	provider.Delete = wrapIO(w, "Delete", provider.Delete)
+	provider.Call = wrapIO(w, "Call", provider.Call)
+	provider.Construct = wrapIO(w, "Construct", provider.Construct)
+	if cancel := provider.Cancel; cancel != nil {
+		provider.Cancel = func(ctx context.Context) (err error) {
+			defer w.recover(ctx, "Cancel", &err)
+			return cancel(ctx)
+		}
+	}
+	return provider
+}
+
+type watchdog struct {
+	opts Options
+}
+
+func wrapIO[I, O any, F func(context.Context, I) (O, error)](w *watchdog, method string, f F) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (_ O, err error) {
+		defer w.recover(ctx, method, &err)
+		return f(ctx, req)
+	}
+}
+
+func wrapI[I any, F func(context.Context, I) error](w *watchdog, method string, f F) F {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, req I) (err error) {
+		defer w.recover(ctx, method, &err)
+		return f(ctx, req)
+	}
+}
+
+// recover handles a panic in method, if there was one. It must be deferred directly.
+//
+// Under the [Recover] policy, or if [Options.Exit] returns, *err is set to the internal
+// error.
+func (w *watchdog) recover(ctx context.Context, method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	if w.opts.Policy != Recover {
+		fmt.Fprintf(os.Stderr, "internal error in %s, exiting: %v\n%s", method, r, stack)
+		w.opts.Exit(2)
+	}
+	p.GetLogger(ctx).Debugf("internal error in %s: %v\n%s", method, r, stack)
+	*err = status.Errorf(codes.Internal, "internal error in %s: %v", method, r)
+}
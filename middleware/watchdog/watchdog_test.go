@@ -0,0 +1,95 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+var urn = resource.URN("urn:pulumi:stack::proj::test:index:A::a")
+
+func testProvider() p.Provider {
+	return p.Provider{
+		Create: func(context.Context, p.CreateRequest) (p.CreateResponse, error) {
+			contract.Assertf(false, "invariant violated")
+			return p.CreateResponse{}, nil
+		},
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			return p.DeleteResponse{}, nil
+		},
+	}
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+
+	exited := false
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), Wrap(testProvider(), Options{
+		Policy: Recover,
+		Exit:   func(int) { exited = true },
+	}))
+
+	_, err := server.Create(p.CreateRequest{Urn: urn})
+	require.Error(t, err)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Contains(t, err.Error(), "invariant violated")
+
+	// The provider keeps serving.
+	_, err = server.Delete(p.DeleteRequest{Urn: urn})
+	require.NoError(t, err)
+	assert.False(t, exited)
+}
+
+func TestExit(t *testing.T) {
+	t.Parallel()
+
+	var code *int
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), Wrap(testProvider(), Options{
+		Exit: func(c int) { code = &c },
+	}))
+
+	_, err := server.Create(p.CreateRequest{Urn: urn})
+	require.Error(t, err)
+	require.NotNil(t, code, "the provider should have exited")
+	assert.Equal(t, 2, *code)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "recover")
+	provider, err := FromEnv(testProvider(), Options{
+		Policy: Exit,
+		Exit:   func(int) { t.Error("the provider should not exit") },
+	})
+	require.NoError(t, err)
+	_, err = integration.NewServer("test", semver.MustParse("1.0.0"), provider).
+		Create(p.CreateRequest{Urn: urn})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	t.Setenv(EnvVar, "restart")
+	_, err = FromEnv(testProvider(), Options{})
+	assert.ErrorContains(t, err, `unknown policy "restart"`)
+}
@@ -0,0 +1,167 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package property provides helpers for inspecting the property.Value type of the Pulumi
+// SDK in hand-written request handlers, without long chains of Is* checks.
+//
+// Values from a resource.PropertyMap can be converted with
+// resource.FromResourcePropertyValue:
+//
+//	kind := property.KindOf(resource.FromResourcePropertyValue(req.News["length"]))
+package property
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+)
+
+// Kind is the kind of value held by a [property.Value].
+//
+// [property.Value]: https://pkg.go.dev/github.com/pulumi/pulumi/sdk/v3/go/property#Value
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Map
+	Asset
+	Archive
+	ResourceReference
+	// Computed is a value that is not yet known, such as an output during preview.
+	Computed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Array:
+		return "array"
+	case Map:
+		return "map"
+	case Asset:
+		return "asset"
+	case Archive:
+		return "archive"
+	case ResourceReference:
+		return "resource reference"
+	case Computed:
+		return "computed"
+	default:
+		return "unknown"
+	}
+}
+
+// KindOf returns the kind of v.
+//
+// Whether v is secret is independent of its kind, see [property.Value.Secret].
+//
+// [property.Value.Secret]: https://pkg.go.dev/github.com/pulumi/pulumi/sdk/v3/go/property#Value.Secret
+func KindOf(v property.Value) Kind {
+	switch {
+	case v.IsBool():
+		return Bool
+	case v.IsNumber():
+		return Number
+	case v.IsString():
+		return String
+	case v.IsArray():
+		return Array
+	case v.IsMap():
+		return Map
+	case v.IsAsset():
+		return Asset
+	case v.IsArchive():
+		return Archive
+	case v.IsResourceReference():
+		return ResourceReference
+	case v.IsComputed():
+		return Computed
+	default:
+		return Null
+	}
+}
+
+// Cases holds a function for each kind of value passed to [Match].
+//
+// Cases that are nil fall back to Default.
+type Cases[T any] struct {
+	Null              func() T
+	Bool              func(bool) T
+	Number            func(float64) T
+	String            func(string) T
+	Array             func(property.Array) T
+	Map               func(property.Map) T
+	Asset             func(property.Asset) T
+	Archive           func(property.Archive) T
+	ResourceReference func(property.ResourceReference) T
+	Computed          func() T
+
+	// Default is called with v when the case for the kind of v is nil. If Default is
+	// also nil, Match returns the zero value of T.
+	Default func(v property.Value) T
+}
+
+// Match calls the case of c that corresponds to the kind of v, passing it the value held
+// by v:
+//
+//	length := property.Match(v, property.Cases[int]{
+//		String:  func(s string) int { return len(s) },
+//		Array:   func(a property.Array) int { return len(a) },
+//		Default: func(property.Value) int { return 0 },
+//	})
+func Match[T any](v property.Value, c Cases[T]) T {
+	call := func(ok bool, f func() T) T {
+		if ok {
+			return f()
+		}
+		if c.Default != nil {
+			return c.Default(v)
+		}
+		var zero T
+		return zero
+	}
+
+	switch KindOf(v) {
+	case Bool:
+		return call(c.Bool != nil, func() T { return c.Bool(v.AsBool()) })
+	case Number:
+		return call(c.Number != nil, func() T { return c.Number(v.AsNumber()) })
+	case String:
+		return call(c.String != nil, func() T { return c.String(v.AsString()) })
+	case Array:
+		return call(c.Array != nil, func() T { return c.Array(v.AsArray()) })
+	case Map:
+		return call(c.Map != nil, func() T { return c.Map(v.AsMap()) })
+	case Asset:
+		return call(c.Asset != nil, func() T { return c.Asset(v.AsAsset()) })
+	case Archive:
+		return call(c.Archive != nil, func() T { return c.Archive(v.AsArchive()) })
+	case ResourceReference:
+		return call(c.ResourceReference != nil,
+			func() T { return c.ResourceReference(v.AsResourceReference()) })
+	case Computed:
+		return call(c.Computed != nil, c.Computed)
+	default:
+		return call(c.Null != nil, c.Null)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package property
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value property.Value
+		kind  Kind
+	}{
+		{property.Value{}, Null},
+		{property.New(true), Bool},
+		{property.New(1.0), Number},
+		{property.New("s"), String},
+		{property.New(property.Array{}), Array},
+		{property.New(property.Map{}), Map},
+		{property.New("s").WithSecret(true), String},
+		{resource.FromResourcePropertyValue(resource.MakeComputed(resource.NewStringProperty(""))), Computed},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.kind, KindOf(tt.value), "%v", tt.value)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	length := func(v property.Value) int {
+		return Match(v, Cases[int]{
+			String:  func(s string) int { return len(s) },
+			Array:   func(a property.Array) int { return len(a) },
+			Map:     func(m property.Map) int { return len(m) },
+			Default: func(property.Value) int { return -1 },
+		})
+	}
+
+	assert.Equal(t, 3, length(property.New("abc")))
+	assert.Equal(t, 2, length(property.New(property.Array{property.New(1.0), property.New(2.0)})))
+	assert.Equal(t, 1, length(property.New(property.Map{"k": property.New(true)})))
+	assert.Equal(t, -1, length(property.New(true)))
+	assert.Equal(t, -1, length(property.Value{}))
+
+	// Without a Default, unmatched kinds return the zero value.
+	assert.Equal(t, "", Match(property.New(1.0), Cases[string]{
+		Null: func() string { return "null" },
+	}))
+	assert.Equal(t, "null", Match(property.Value{}, Cases[string]{
+		Null: func() string { return "null" },
+	}))
+}
@@ -24,7 +24,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/go-multierror"
@@ -47,6 +52,7 @@ import (
 
 	"github.com/pulumi/pulumi-go-provider/internal"
 	"github.com/pulumi/pulumi-go-provider/internal/key"
+	"github.com/pulumi/pulumi-go-provider/internal/putil"
 	"github.com/pulumi/pulumi-go-provider/resourcex"
 )
 
@@ -63,6 +69,42 @@ type CheckRequest struct {
 	Olds       presource.PropertyMap
 	News       presource.PropertyMap
 	RandomSeed []byte
+	// Autonaming is the autonaming configuration of the stack, as set with
+	// `pulumi config set pulumi:autonaming`. It is nil when the stack does not configure
+	// autonaming.
+	Autonaming *AutonamingOptions
+}
+
+// AutonamingOptions describes how the engine expects a resource to be named.
+type AutonamingOptions struct {
+	// ProposedName is the name the engine proposes for the resource.
+	ProposedName string
+	// Mode describes how ProposedName should be used.
+	Mode AutonamingMode
+}
+
+// AutonamingMode describes how a provider should use [AutonamingOptions.ProposedName].
+type AutonamingMode int32
+
+const (
+	// AutonamingModePropose means that the provider may use the proposed name, or may
+	// derive its own name.
+	AutonamingModePropose AutonamingMode = iota
+	// AutonamingModeEnforce means that the provider must use the proposed name.
+	AutonamingModeEnforce
+	// AutonamingModeDisable means that the provider should not generate a name. The user
+	// must name the resource explicitly.
+	AutonamingModeDisable
+)
+
+func autonamingFromRPC(opts *rpc.CheckRequest_AutonamingOptions) *AutonamingOptions {
+	if opts == nil {
+		return nil
+	}
+	return &AutonamingOptions{
+		ProposedName: opts.GetProposedName(),
+		Mode:         AutonamingMode(opts.GetMode()),
+	}
 }
 
 type CheckFailure struct {
@@ -73,6 +115,53 @@ type CheckFailure struct {
 type CheckResponse struct {
 	Inputs   presource.PropertyMap
 	Failures []CheckFailure
+	// Diagnostics is non-fatal feedback on the inputs, such as suggestions or warnings
+	// about settings that are likely to be mistakes. Unlike Failures, diagnostics do not
+	// stop the resource from being created or updated.
+	Diagnostics []Diagnostic
+}
+
+// Diagnostic is a message about the inputs of a resource, shown to the user when the
+// resource is checked.
+//
+// Diagnostics are logged to the engine with the URN of the resource, so they are shown
+// next to it during `pulumi preview` and `pulumi up`.
+type Diagnostic struct {
+	// The severity of the diagnostic. If empty, [diag.Warning] is used.
+	//
+	// Use [CheckFailure] instead of [diag.Error] for inputs that are invalid, since only
+	// failures stop the operation.
+	Severity diag.Severity
+	// The input property the diagnostic is about, if any.
+	Property string
+	// A one line summary, such as "consider setting force=true".
+	Summary string
+	// Details shown after the summary, if any.
+	Detail string
+}
+
+// message renders d as a log message.
+func (d Diagnostic) message() string {
+	msg := d.Summary
+	if d.Property != "" {
+		msg = fmt.Sprintf("Property %q: %s", d.Property, msg)
+	}
+	if d.Detail != "" {
+		msg += "\n" + d.Detail
+	}
+	return msg
+}
+
+// logDiagnostics shows each of diagnostics to the user.
+func logDiagnostics(ctx context.Context, diagnostics []Diagnostic) {
+	logger := GetLogger(ctx)
+	for _, d := range diagnostics {
+		severity := d.Severity
+		if severity == "" {
+			severity = diag.Warning
+		}
+		logger.log(severity, d.message())
+	}
 }
 
 type DiffRequest struct {
@@ -152,7 +241,18 @@ type DiffResponse struct {
 	// - root["key with a ."]
 	// - ["root key with \"escaped\" quotes"].nested
 	// - ["root key with a ."][100]
+	//
+	// [NewDetailedDiff] builds keys that obey this grammar from the segments of a path.
 	DetailedDiff map[string]PropertyDiff
+
+	// ChangedOutputs lists the output properties whose values are expected to change when
+	// the resource is updated, even though they are not inputs. For example, an update that
+	// changes a password input might also change a saltedPassword output.
+	//
+	// ChangedOutputs are sent to the engine as updates to state, so `pulumi preview --diff`
+	// shows them as changing. They are ignored if HasChanges is false, and properties that
+	// are already in DetailedDiff are left as they are.
+	ChangedOutputs []presource.PropertyKey
 }
 
 type diffChanges bool
@@ -199,6 +299,17 @@ func (d DiffResponse) rpc() *rpc.DiffResponse {
 			r.Stables = append(r.Stables, k)
 		}
 	}
+	if d.HasChanges {
+		for _, k := range d.ChangedOutputs {
+			if _, ok := d.DetailedDiff[string(k)]; ok {
+				continue
+			}
+			r.Diffs = append(r.Diffs, string(k))
+			if hasDetailedDiff {
+				r.DetailedDiff[string(k)] = &rpc.PropertyDiff{Kind: rpc.PropertyDiff_UPDATE}
+			}
+		}
+	}
 	return &r
 }
 
@@ -222,6 +333,13 @@ type CreateRequest struct {
 	Properties presource.PropertyMap // the provider inputs to set during creation.
 	Timeout    float64               // the create request timeout represented in seconds.
 	Preview    bool                  // true if this is a preview and the provider should not actually create the resource.
+
+	// The URNs of the resources each input depends on, by the name of the input.
+	//
+	// Dependencies are only known when the engine sends inputs as output values, which
+	// it does for providers that advertise [Capabilities.AcceptOutputs]. Inputs without
+	// known dependencies are not in the map.
+	PropertyDependencies map[presource.PropertyKey][]presource.URN
 }
 
 type CreateResponse struct {
@@ -264,6 +382,10 @@ type UpdateRequest struct {
 	Timeout       float64                 // the update request timeout represented in seconds.
 	IgnoreChanges []presource.PropertyKey // a set of property paths that should be treated as unchanged.
 	Preview       bool                    // true if the provider should not actually create the resource.
+
+	// The URNs of the resources each new input depends on, by the name of the input. See
+	// [CreateRequest.PropertyDependencies].
+	PropertyDependencies map[presource.PropertyKey][]presource.URN
 }
 
 type UpdateResponse struct {
@@ -284,6 +406,18 @@ type DeleteRequest struct {
 	Timeout    float64               // the delete request timeout represented in seconds.
 }
 
+// DeleteResponse describes the outcome of a Delete.
+//
+// The engine removes the resource from state whenever Delete succeeds, so the response
+// only informs the user: its fields are logged against the resource.
+type DeleteResponse struct {
+	// Retained indicates that the provider chose not to delete the resource, such as when
+	// the API it manages doesn't support deletion. The resource is left in place.
+	Retained bool
+	// Diagnostics are warnings about the deletion to show the user.
+	Diagnostics []string
+}
+
 // InitializationFailed indicates that a resource exists but failed to initialize, and is
 // thus in a partial state.
 type InitializationFailed struct {
@@ -330,6 +464,14 @@ type Provider struct {
 	// https://pulumi-developer-docs.readthedocs.io/latest/docs/architecture/providers.html#parameterized-providers.
 	Parameterize func(context.Context, ParameterizeRequest) (ParameterizeResponse, error)
 
+	// GetMapping returns the mapping data this provider supplies to a converter, such as
+	// `pulumi convert --from terraform`, which uses it to translate another tool's
+	// resources into this provider's resources.
+	//
+	// [GetMappingRequest.Key] names the kind of mapping the converter asks for. A provider
+	// without a mapping for the request returns an empty [GetMappingResponse].
+	GetMapping func(context.Context, GetMappingRequest) (GetMappingResponse, error)
+
 	// Cancel signals the provider to gracefully shut down and abort any ongoing resource operations.
 	// Operations aborted in this way will return an error (e.g., `Update` and `Create` will either return a
 	// creation error or an initialization error). Since Cancel is advisory and non-blocking, it is up
@@ -340,9 +482,46 @@ type Provider struct {
 	// Provider Config
 	CheckConfig func(context.Context, CheckRequest) (CheckResponse, error)
 	DiffConfig  func(context.Context, DiffRequest) (DiffResponse, error)
-	// NOTE: We opt into all options.
+	// Configure configures the provider. The capabilities advertised in response are
+	// taken from [Provider.Capabilities].
 	Configure func(context.Context, ConfigureRequest) error
 
+	// Capabilities are the protocol features advertised to the engine in response to
+	// Configure. If nil, [DefaultCapabilities] are advertised.
+	//
+	// See [Provider.WithCapabilities].
+	Capabilities *Capabilities
+
+	// LogHandler, if set, receives every message logged with [GetLogger].
+	//
+	// See [Provider.WithLogHandler].
+	LogHandler slog.Handler
+
+	// FrameworkLog lists the decisions of the framework itself that are logged, in
+	// addition to those enabled by [FrameworkLogEnvVar].
+	//
+	// See [Provider.WithFrameworkLog].
+	FrameworkLog []FrameworkLogTopic
+
+	// ShutdownGracePeriod is how long in-flight operations are waited for when the
+	// provider shuts down. If zero, [DefaultShutdownGracePeriod] is used.
+	//
+	// See [Provider.WithShutdownGracePeriod].
+	ShutdownGracePeriod time.Duration
+
+	// MinimumPulumiVersion is the oldest version of the Pulumi CLI the provider supports,
+	// such as "3.120.0". If empty, any version is accepted.
+	//
+	// See [Provider.WithMinimumPulumiVersion].
+	MinimumPulumiVersion string
+
+	// OnShutdown, if set, is called once when a provider run with [RunProvider] shuts
+	// down, after the operations in flight have returned. Middleware uses it to flush
+	// buffered data and release resources.
+	//
+	// See [Provider.WithOnShutdown].
+	OnShutdown func(context.Context)
+
 	// Invokes
 	Invoke func(context.Context, InvokeRequest) (InvokeResponse, error)
 	// TODO Stream invoke (are those used anywhere)
@@ -359,7 +538,7 @@ type Provider struct {
 	Create func(context.Context, CreateRequest) (CreateResponse, error)
 	Read   func(context.Context, ReadRequest) (ReadResponse, error)
 	Update func(context.Context, UpdateRequest) (UpdateResponse, error)
-	Delete func(context.Context, DeleteRequest) error
+	Delete func(context.Context, DeleteRequest) (DeleteResponse, error)
 
 	// Call allows methods to be attached to resources.
 	//
@@ -448,8 +627,8 @@ func (d Provider) WithDefaults() Provider {
 		}
 	}
 	if d.Delete == nil {
-		d.Delete = func(context.Context, DeleteRequest) error {
-			return nyi("Delete")
+		d.Delete = func(context.Context, DeleteRequest) (DeleteResponse, error) {
+			return DeleteResponse{}, nyi("Delete")
 		}
 	}
 	if d.Call == nil {
@@ -462,12 +641,31 @@ func (d Provider) WithDefaults() Provider {
 			return ConstructResponse{}, nyi("Construct")
 		}
 	}
+	if d.GetMapping == nil {
+		// The engine treats an empty response as "no mapping", so providers that
+		// don't participate in conversion don't need to implement GetMapping.
+		d.GetMapping = func(context.Context, GetMappingRequest) (GetMappingResponse, error) {
+			return GetMappingResponse{}, nil
+		}
+	}
 	return d
 }
 
 // RunProvider runs a provider with the given name and version.
+//
+// On SIGTERM, the provider shuts down gracefully before exiting: see
+// [Provider.WithShutdownGracePeriod].
 func RunProvider(name, version string, provider Provider) error {
-	return pprovider.Main(name, newProvider(name, version, provider.WithDefaults()))
+	shutdown := newShutdown(provider.ShutdownGracePeriod)
+	shutdown.onExit = provider.OnShutdown
+	stop := shutdown.onSignal(terminationSignals...)
+	defer stop()
+	err := pprovider.Main(name, newProvider(name, version, provider.WithDefaults(), shutdown))
+	shutdown.exit()
+	// Messages logged by requests that are still running would be lost when the process
+	// exits.
+	shutdown.flush()
+	return err
 }
 
 // RawServer converts the Provider into a factory for gRPC servers.
@@ -477,7 +675,7 @@ func RawServer(
 	name, version string,
 	provider Provider,
 ) func(*pprovider.HostClient) (rpc.ResourceProviderServer, error) {
-	return newProvider(name, version, provider.WithDefaults())
+	return newProvider(name, version, provider.WithDefaults(), newShutdown(provider.ShutdownGracePeriod))
 }
 
 // A context which prints its diagnostics, collecting all errors.
@@ -525,10 +723,12 @@ func (e *errCollectingContext) RuntimeInformation() RunInfo {
 //
 //	pulumi package get-schema ./pulumi-resource-MYPROVIDER
 func GetSchema(ctx context.Context, name, version string, provider Provider) (schema.PackageSpec, error) {
-	collectingDiag := errCollectingContext{Context: ctx, stderr: os.Stderr, info: RunInfo{
+	info := RunInfo{
 		PackageName: name,
 		Version:     version,
-	}}
+	}
+	ctx = context.WithValue(ctx, key.RuntimeInfo, info)
+	collectingDiag := errCollectingContext{Context: ctx, stderr: os.Stderr, info: info}
 	s, err := provider.GetSchema(&collectingDiag, GetSchemaRequest{Version: 0})
 	var errs multierror.Error
 	if err != nil {
@@ -544,14 +744,28 @@ func GetSchema(ctx context.Context, name, version string, provider Provider) (sc
 	return spec, err
 }
 
-func newProvider(name, version string, p Provider) func(*pprovider.HostClient) (rpc.ResourceProviderServer, error) {
+func newProvider(
+	name, version string, p Provider, shutdown *shutdown,
+) func(*pprovider.HostClient) (rpc.ResourceProviderServer, error) {
 	return func(host *pprovider.HostClient) (rpc.ResourceProviderServer, error) {
-		return &provider{
-			name:    name,
-			version: version,
-			host:    host,
-			client:  p,
-		}, nil
+		var unknownTopics []FrameworkLogTopic
+		p.FrameworkLog, unknownTopics = frameworkLogFromEnv(p.FrameworkLog)
+		minimumVersion, err := parseMinimumPulumiVersion(p.MinimumPulumiVersion)
+		if err != nil {
+			return nil, err
+		}
+		server := &provider{
+			name:           name,
+			version:        version,
+			host:           host,
+			client:         p,
+			shutdown:       shutdown,
+			minimumVersion: minimumVersion,
+		}
+		if len(unknownTopics) > 0 {
+			warnUnknownFrameworkLog(server.ctx(context.Background(), ""), unknownTopics)
+		}
+		return server, nil
 	}
 }
 
@@ -562,11 +776,46 @@ type provider struct {
 	version string
 	host    *pprovider.HostClient
 	client  Provider
+
+	// stack holds the stack metadata most recently sent by the engine.
+	stack stackMetadata
+
+	// requests counts the requests served, to give each an ID for logging.
+	requests atomic.Uint64
+
+	// negotiated holds the capabilities negotiated with the engine in Configure, or nil
+	// before the provider is configured.
+	negotiated atomic.Pointer[negotiated]
+
+	// shutdown tracks the operations in flight once the provider starts to shut down.
+	shutdown *shutdown
+
+	// minimumVersion is the parsed [Provider.MinimumPulumiVersion], or nil.
+	minimumVersion *semver.Version
+
+	// warnSecrets ensures that an engine without secrets is only warned about once,
+	// however often it configures the provider.
+	warnSecrets sync.Once
 }
 
 type RunInfo struct {
 	PackageName string
 	Version     string
+
+	// Configured is true once the engine has configured the provider. Capabilities and
+	// MarshalOptions are only set once the provider is configured.
+	Configured bool
+	// Capabilities are the protocol features in effect between the engine and the
+	// provider: those the provider advertised, less those the engine doesn't support.
+	Capabilities Capabilities
+	// MarshalOptions describe how property values are exchanged with the engine.
+	MarshalOptions MarshalOptions
+}
+
+// negotiated is the outcome of Configure.
+type negotiated struct {
+	capabilities Capabilities
+	marshal      MarshalOptions
 }
 
 func GetRunInfo(ctx context.Context) RunInfo { return ctx.Value(key.RuntimeInfo).(RunInfo) }
@@ -574,14 +823,32 @@ func GetRunInfo(ctx context.Context) RunInfo { return ctx.Value(key.RuntimeInfo)
 func (p *provider) ctx(ctx context.Context, urn presource.URN) context.Context {
 	if p.host != nil {
 		ctx = context.WithValue(ctx, key.Logger, &hostSink{
-			host: p.host,
+			host:    p.host,
+			pending: &p.shutdown.logs,
 		})
 	}
+	if p.client.LogHandler != nil {
+		ctx = context.WithValue(ctx, key.LogHandler, p.client.LogHandler)
+	}
+	if len(p.client.FrameworkLog) > 0 {
+		ctx = context.WithValue(ctx, key.FrameworkLog, p.client.FrameworkLog)
+	}
 	ctx = context.WithValue(ctx, key.URN, urn)
-	return context.WithValue(ctx, key.RuntimeInfo, RunInfo{
+	if urn.IsValid() {
+		ctx = context.WithValue(ctx, key.Token, urn.Type())
+	}
+	ctx = context.WithValue(ctx, key.RequestID, strconv.FormatUint(p.requests.Add(1), 10))
+	ctx = context.WithValue(ctx, key.Stack, p.stack.get())
+	info := RunInfo{
 		PackageName: p.name,
 		Version:     p.version,
-	})
+	}
+	if n := p.negotiated.Load(); n != nil {
+		info.Configured = true
+		info.Capabilities = n.capabilities
+		info.MarshalOptions = n.marshal
+	}
+	return context.WithValue(ctx, key.RuntimeInfo, info)
 }
 
 func (p *provider) getMap(s *structpb.Struct) (presource.PropertyMap, error) {
@@ -593,11 +860,33 @@ func (p *provider) getMap(s *structpb.Struct) (presource.PropertyMap, error) {
 	})
 }
 
+// getDependencies returns the dependencies of the output values in s, which getMap
+// flattens.
+func (p *provider) getDependencies(s *structpb.Struct) (map[presource.PropertyKey][]presource.URN, error) {
+	m, err := plugin.UnmarshalProperties(s, plugin.MarshalOptions{
+		KeepUnknowns:     true,
+		SkipNulls:        true,
+		KeepResources:    true,
+		KeepSecrets:      true,
+		KeepOutputValues: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return putil.Dependencies(m), nil
+}
+
+// keepSecrets reports whether secret values are returned to the engine as secrets.
+func (p *provider) keepSecrets() bool {
+	n := p.negotiated.Load()
+	return n == nil || n.marshal.KeepSecrets
+}
+
 func (p *provider) asStruct(m presource.PropertyMap) (*structpb.Struct, error) {
 	return plugin.MarshalProperties(m, plugin.MarshalOptions{
 		KeepUnknowns: true,
 		SkipNulls:    true,
-		KeepSecrets:  true,
+		KeepSecrets:  p.keepSecrets(),
 	})
 }
 
@@ -609,6 +898,12 @@ func (p *provider) GetSchema(ctx context.Context, req *rpc.GetSchemaRequest) (*r
 	if err != nil {
 		return nil, err
 	}
+	if p.minimumVersion != nil {
+		r.Schema, err = withMinimumPulumiVersion(r.Schema, *p.minimumVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &rpc.GetSchemaResponse{
 		Schema: r.Schema,
 	}, nil
@@ -644,6 +939,7 @@ func (d detailedDiff) rpc() map[string]*rpc.PropertyDiff {
 }
 
 func (p *provider) CheckConfig(ctx context.Context, req *rpc.CheckRequest) (*rpc.CheckResponse, error) {
+	p.stack.observe(presource.URN(req.GetUrn()))
 	ctx = p.ctx(ctx, presource.URN(req.GetUrn()))
 	olds, err := p.getMap(req.Olds)
 	if err != nil {
@@ -665,6 +961,8 @@ func (p *provider) CheckConfig(ctx context.Context, req *rpc.CheckRequest) (*rpc
 		return nil, err
 	}
 
+	logDiagnostics(ctx, r.Diagnostics)
+
 	inputs, err := p.asStruct(r.Inputs)
 	if err != nil {
 		return nil, err
@@ -685,6 +983,7 @@ func getIgnoreChanges(l []string) []presource.PropertyKey {
 }
 
 func (p *provider) DiffConfig(ctx context.Context, req *rpc.DiffRequest) (*rpc.DiffResponse, error) {
+	p.stack.observe(presource.URN(req.GetUrn()))
 	ctx = p.ctx(ctx, presource.URN(req.GetUrn()))
 	olds, err := p.getMap(req.GetOlds())
 	if err != nil {
@@ -708,7 +1007,22 @@ func (p *provider) DiffConfig(ctx context.Context, req *rpc.DiffRequest) (*rpc.D
 }
 
 func (p *provider) Configure(ctx context.Context, req *rpc.ConfigureRequest) (*rpc.ConfigureResponse, error) {
+	capabilities := DefaultCapabilities()
+	if p.client.Capabilities != nil {
+		capabilities = *p.client.Capabilities
+	}
+	n := negotiated{}
+	n.capabilities, n.marshal = capabilities.negotiate(req.GetAcceptSecrets(), req.GetAcceptResources())
+	p.negotiated.Store(&n)
+
 	ctx = p.ctx(ctx, "")
+	if !req.GetAcceptSecrets() {
+		if p.requires(featureSecrets) {
+			return nil, p.unsupported(featureSecrets)
+		}
+		// Secrets are returned as plain values, so warn instead of failing.
+		p.warnSecrets.Do(func() { GetLogger(ctx).Warning(featureSecrets.message()) })
+	}
 	argMap, err := p.getMap(req.GetArgs())
 	if err != nil {
 		return nil, err
@@ -720,16 +1034,11 @@ func (p *provider) Configure(ctx context.Context, req *rpc.ConfigureRequest) (*r
 	if err != nil {
 		return nil, err
 	}
-	return &rpc.ConfigureResponse{
-		AcceptSecrets:   true,
-		SupportsPreview: true,
-		AcceptResources: true,
-		AcceptOutputs:   true,
-	}, nil
+	return capabilities.rpc(), nil
 }
 
 func (p *provider) Invoke(ctx context.Context, req *rpc.InvokeRequest) (*rpc.InvokeResponse, error) {
-	ctx = p.ctx(ctx, "")
+	ctx = context.WithValue(p.ctx(ctx, ""), key.Token, tokens.Type(req.GetTok()))
 	argMap, err := p.getMap(req.GetArgs())
 	if err != nil {
 		return nil, err
@@ -756,12 +1065,34 @@ func (p *provider) StreamInvoke(*rpc.InvokeRequest, rpc.ResourceProvider_StreamI
 }
 
 func (p *provider) Call(ctx context.Context, req *rpc.CallRequest) (*rpc.CallResponse, error) {
+	// An engine without output values is sent plain values instead, unless the provider
+	// requires a CLI that supports them.
+	if !req.GetAcceptsOutputValues() && p.requires(featureOutputValues) {
+		return nil, p.unsupported(featureOutputValues)
+	}
+	p.stack.set(stackInfo{
+		organization: req.GetOrganization(),
+		project:      req.GetProject(),
+		stack:        req.GetStack(),
+	})
+	args, err := p.getMap(req.GetArgs())
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert args into a property map: %w", err)
+	}
+	// Messages logged by a method are associated with the resource it is called on.
+	ctx = context.WithValue(p.ctx(ctx, selfURN(args)), key.Token, tokens.Type(req.GetTok()))
+
+	// The pulumi.Context is built before the request reaches the provider's middleware,
+	// so middleware that cancels requests needs a way to cancel the pulumi.Context too.
+	callCtx, cancelCall := context.WithCancel(ctx)
+	defer cancelCall()
+	callCtx = context.WithValue(callCtx, key.CancelCall, cancelCall)
 
 	configPropertyMap := make(presource.PropertyMap, len(req.GetConfig()))
 	for k, v := range req.GetConfig() {
 		configPropertyMap[presource.PropertyKey(k)] = presource.NewProperty(v)
 	}
-	pulumiContext, err := pulumi.NewContext(ctx, pulumi.RunInfo{
+	pulumiContext, err := pulumi.NewContext(callCtx, pulumi.RunInfo{
 		Project:           req.GetProject(),
 		Stack:             req.GetStack(),
 		Config:            req.GetConfig(),
@@ -776,12 +1107,7 @@ func (p *provider) Call(ctx context.Context, req *rpc.CallRequest) (*rpc.CallRes
 		return nil, fmt.Errorf("failed to build pulumi.Context: %w", err)
 	}
 
-	args, err := p.getMap(req.GetArgs())
-	if err != nil {
-		return nil, fmt.Errorf("unable to convert args into a property map: %w", err)
-	}
-
-	resp, err := p.client.Call(ctx, CallRequest{
+	resp, err := p.client.Call(callCtx, CallRequest{
 		Tok:     tokens.ModuleMember(req.GetTok()),
 		Args:    args,
 		Context: pulumiContext,
@@ -834,6 +1160,24 @@ func (p *provider) Call(ctx context.Context, req *rpc.CallRequest) (*rpc.CallRes
 	}, nil
 }
 
+// selfURN returns the URN of the resource a method is called on, held by the "__self__"
+// argument of the call, or "" if the call isn't a method.
+func selfURN(args presource.PropertyMap) presource.URN {
+	v := args["__self__"]
+	for {
+		switch {
+		case v.IsSecret():
+			v = v.SecretValue().Element
+		case v.IsOutput():
+			v = v.OutputValue().Element
+		case v.IsResourceReference():
+			return v.ResourceReferenceValue().URN
+		default:
+			return ""
+		}
+	}
+}
+
 // CallRequest represents a requested resource method invocation.
 //
 // It corresponds to [rpc.CallRequest] on the wire.
@@ -865,14 +1209,18 @@ func (p *provider) Check(ctx context.Context, req *rpc.CheckRequest) (*rpc.Check
 	}
 
 	r, err := p.client.Check(ctx, CheckRequest{
-		Urn:  presource.URN(req.GetUrn()),
-		Olds: olds,
-		News: news,
+		Urn:        presource.URN(req.GetUrn()),
+		Olds:       olds,
+		News:       news,
+		RandomSeed: req.GetRandomSeed(),
+		Autonaming: autonamingFromRPC(req.GetAutonaming()),
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	logDiagnostics(ctx, r.Diagnostics)
+
 	inputs, err := p.asStruct(r.Inputs)
 	if err != nil {
 		return nil, err
@@ -909,17 +1257,50 @@ func (p *provider) Diff(ctx context.Context, req *rpc.DiffRequest) (*rpc.DiffRes
 }
 
 func (p *provider) Create(ctx context.Context, req *rpc.CreateRequest) (*rpc.CreateResponse, error) {
-	ctx = p.ctx(ctx, presource.URN(req.GetUrn()))
+	ctx = internal.WithTimeout(p.ctx(ctx, presource.URN(req.GetUrn())), req.GetTimeout())
+	done, err := p.shutdown.enter("Create")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 	props, err := p.getMap(req.GetProperties())
 	if err != nil {
 		return nil, err
 	}
-	r, err := p.client.Create(ctx, CreateRequest{
-		Urn:        presource.URN(req.GetUrn()),
-		Properties: props,
-		Timeout:    req.GetTimeout(),
-		Preview:    req.GetPreview(),
+	deps, err := p.getDependencies(req.GetProperties())
+	if err != nil {
+		return nil, err
+	}
+	r, ok, err := untilShutdown(ctx, p.shutdown, func(ctx context.Context) (CreateResponse, error) {
+		return p.client.Create(ctx, CreateRequest{
+			Urn:                  presource.URN(req.GetUrn()),
+			Properties:           props,
+			Timeout:              req.GetTimeout(),
+			Preview:              req.GetPreview(),
+			PropertyDependencies: deps,
+		})
 	})
+	if !ok {
+		if r.ID == "" {
+			// The ID of the resource is not known, so there is no partial state to report.
+			return nil, status.Errorf(codes.Aborted,
+				"the provider shut down before creating %s completed; the resource may have been created",
+				req.GetUrn())
+		}
+		msg := fmt.Sprintf("the provider shut down before creating %s completed", req.GetUrn())
+		reasons := []string{msg}
+		if err != nil {
+			reasons = append(reasons, err.Error())
+		}
+		prop, propErr := p.asStruct(r.Properties)
+		return nil, errors.Join(rpcerror.WithDetails(
+			rpcerror.New(codes.Aborted, msg),
+			&rpc.ErrorResourceInitFailed{
+				Id:         r.ID,
+				Properties: prop,
+				Reasons:    reasons,
+			}), propErr)
+	}
 	if initFailed := r.PartialState; initFailed != nil {
 		prop, propErr := p.asStruct(r.Properties)
 		err = errors.Join(rpcerror.WithDetails(
@@ -992,7 +1373,12 @@ func (p *provider) Read(ctx context.Context, req *rpc.ReadRequest) (*rpc.ReadRes
 }
 
 func (p *provider) Update(ctx context.Context, req *rpc.UpdateRequest) (*rpc.UpdateResponse, error) {
-	ctx = p.ctx(ctx, presource.URN(req.GetUrn()))
+	ctx = internal.WithTimeout(p.ctx(ctx, presource.URN(req.GetUrn())), req.GetTimeout())
+	done, err := p.shutdown.enter("Update")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 	oldsMap, err := p.getMap(req.GetOlds())
 	if err != nil {
 		return nil, err
@@ -1001,15 +1387,34 @@ func (p *provider) Update(ctx context.Context, req *rpc.UpdateRequest) (*rpc.Upd
 	if err != nil {
 		return nil, err
 	}
-	r, err := p.client.Update(ctx, UpdateRequest{
-		ID:            req.GetId(),
-		Urn:           presource.URN(req.GetUrn()),
-		Olds:          oldsMap,
-		News:          newsMap,
-		Timeout:       req.GetTimeout(),
-		IgnoreChanges: getIgnoreChanges(req.GetIgnoreChanges()),
-		Preview:       req.GetPreview(),
+	deps, err := p.getDependencies(req.GetNews())
+	if err != nil {
+		return nil, err
+	}
+	r, ok, err := untilShutdown(ctx, p.shutdown, func(ctx context.Context) (UpdateResponse, error) {
+		return p.client.Update(ctx, UpdateRequest{
+			ID:                   req.GetId(),
+			Urn:                  presource.URN(req.GetUrn()),
+			Olds:                 oldsMap,
+			News:                 newsMap,
+			Timeout:              req.GetTimeout(),
+			IgnoreChanges:        getIgnoreChanges(req.GetIgnoreChanges()),
+			Preview:              req.GetPreview(),
+			PropertyDependencies: deps,
+		})
 	})
+	if !ok {
+		// The update may or may not have been applied, so the previous state is reported
+		// as the partial state of the resource.
+		msg := fmt.Sprintf("the provider shut down before updating %s completed", req.GetUrn())
+		return nil, rpcerror.WithDetails(
+			rpcerror.New(codes.Aborted, msg),
+			&rpc.ErrorResourceInitFailed{
+				Id:         req.GetId(),
+				Properties: req.GetOlds(),
+				Reasons:    []string{msg},
+			})
+	}
 	if initFailed := r.PartialState; initFailed != nil {
 		prop, propErr := p.asStruct(r.Properties)
 		err = errors.Join(rpcerror.WithDetails(
@@ -1034,28 +1439,65 @@ func (p *provider) Update(ctx context.Context, req *rpc.UpdateRequest) (*rpc.Upd
 }
 
 func (p *provider) Delete(ctx context.Context, req *rpc.DeleteRequest) (*emptypb.Empty, error) {
-	ctx = p.ctx(ctx, presource.URN(req.GetUrn()))
+	ctx = internal.WithTimeout(p.ctx(ctx, presource.URN(req.GetUrn())), req.GetTimeout())
+	done, err := p.shutdown.enter("Delete")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
 	props, err := p.getMap(req.GetProperties())
 	if err != nil {
 		return nil, err
 	}
-	err = p.client.Delete(ctx, DeleteRequest{
-		ID:         req.GetId(),
-		Urn:        presource.URN(req.GetUrn()),
-		Properties: props,
-		Timeout:    req.GetTimeout(),
+	r, ok, err := untilShutdown(ctx, p.shutdown, func(ctx context.Context) (DeleteResponse, error) {
+		return p.client.Delete(ctx, DeleteRequest{
+			ID:         req.GetId(),
+			Urn:        presource.URN(req.GetUrn()),
+			Properties: props,
+			Timeout:    req.GetTimeout(),
+		})
 	})
+	if !ok {
+		return nil, status.Errorf(codes.Aborted,
+			"the provider shut down before deleting %s completed; the resource may still exist",
+			req.GetUrn())
+	}
 	if err != nil {
 		return nil, err
 	}
+	// The protocol has no way to return the response, so it is reported to the user.
+	logger := GetLogger(ctx)
+	for _, msg := range r.Diagnostics {
+		logger.Warning(msg)
+	}
+	if r.Retained {
+		logger.Warningf("%s was retained by the provider instead of being deleted; "+
+			"it still exists, but is no longer managed by Pulumi", req.GetId())
+	}
 	return &emptypb.Empty{}, nil
 
 }
 
 type ConstructRequest struct {
-	URN       presource.URN
-	Preview   bool
-	Construct func(context.Context, ConstructFunc) (ConstructResponse, error)
+	URN     presource.URN
+	Preview bool
+	// CustomTimeouts are the custom timeouts set on the component, if any.
+	CustomTimeouts *pulumi.CustomTimeouts
+	// Providers are references to the providers set on the component, keyed by package.
+	//
+	// The same providers are part of the resource options passed to [ConstructFunc].
+	Providers map[string]string
+	// Protect, RetainOnDelete, IgnoreChanges, ReplaceOnChanges and
+	// AdditionalSecretOutputs are the resource options set on the component.
+	//
+	// The same options are part of the resource options passed to [ConstructFunc], where
+	// they apply to the component itself.
+	Protect                 bool
+	RetainOnDelete          bool
+	IgnoreChanges           []string
+	ReplaceOnChanges        []string
+	AdditionalSecretOutputs []string
+	Construct               func(context.Context, ConstructFunc) (ConstructResponse, error)
 }
 
 type ConstructFunc = func(
@@ -1065,12 +1507,18 @@ type ConstructFunc = func(
 type ConstructResponse struct{ inner *rpc.ConstructResponse }
 
 func (p *provider) Construct(ctx context.Context, req *rpc.ConstructRequest) (*rpc.ConstructResponse, error) {
-	// This returns the URN of the parent, we just need the type.
-	parent := tokens.Type(req.GetParent())
-	if parent != "" {
-		parent = presource.URN(parent).Type()
+	// An engine without output values is sent plain values instead, unless the provider
+	// requires a CLI that supports them.
+	if !req.GetAcceptsOutputValues() && p.requires(featureOutputValues) {
+		return nil, p.unsupported(featureOutputValues)
+	}
+	// The component's URN is generated as the engine generates it, so that messages
+	// logged while it is constructed are associated with it.
+	var parent tokens.Type
+	if parentURN := presource.URN(req.GetParent()); parentURN.IsValid() &&
+		parentURN.QualifiedType() != presource.RootStackType {
+		parent = parentURN.QualifiedType()
 	}
-
 	urn := presource.NewURN(
 		tokens.QName(req.GetStack()),
 		tokens.PackageName(req.GetProject()),
@@ -1078,6 +1526,11 @@ func (p *provider) Construct(ctx context.Context, req *rpc.ConstructRequest) (*r
 		tokens.Type(req.GetType()),
 		req.GetName(),
 	)
+	p.stack.set(stackInfo{
+		organization: req.GetOrganization(),
+		project:      req.GetProject(),
+		stack:        req.GetStack(),
+	})
 	ctx = p.ctx(ctx, urn)
 	f := func(ctx context.Context, construct ConstructFunc) (ConstructResponse, error) {
 		r, err := comProvider.Construct(ctx, req, p.host.EngineConn(),
@@ -1095,16 +1548,32 @@ func (p *provider) Construct(ctx context.Context, req *rpc.ConstructRequest) (*r
 		}
 		return ConstructResponse{r}, nil
 	}
+	var timeouts *pulumi.CustomTimeouts
+	if t := req.GetCustomTimeouts(); t != nil {
+		timeouts = &pulumi.CustomTimeouts{
+			Create: t.GetCreate(),
+			Update: t.GetUpdate(),
+			Delete: t.GetDelete(),
+		}
+	}
 	result, err := p.client.Construct(ctx, ConstructRequest{
-		URN:       urn,
-		Preview:   req.GetDryRun(),
-		Construct: f,
+		URN:                     urn,
+		Preview:                 req.GetDryRun(),
+		CustomTimeouts:          timeouts,
+		Protect:                 req.GetProtect(),
+		RetainOnDelete:          req.GetRetainOnDelete(),
+		IgnoreChanges:           req.GetIgnoreChanges(),
+		ReplaceOnChanges:        req.GetReplaceOnChanges(),
+		AdditionalSecretOutputs: req.GetAdditionalSecretOutputs(),
+		Providers:               req.GetProviders(),
+		Construct:               f,
 	})
 	return result.inner, err
 }
 
 func (p *provider) Cancel(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
 	ctx = p.ctx(ctx, "")
+	p.shutdown.begin()
 	err := p.client.Cancel(ctx)
 	if err != nil {
 		return nil, err
@@ -1173,6 +1642,9 @@ func (p *provider) Parameterize(ctx context.Context, req *rpc.ParameterizeReques
 			Version: version,
 			Value:   params.Value.Value,
 		}
+	default:
+		// Engines that predate parameterized providers send neither variant.
+		return nil, p.unsupported(featureParameterize)
 	}
 
 	resp, err := p.client.Parameterize(p.ctx(ctx, ""), parsedRequest)
@@ -1186,6 +1658,60 @@ func (p *provider) Parameterize(ctx context.Context, req *rpc.ParameterizeReques
 	}, nil
 }
 
+type (
+	// GetMappingRequest asks for the mapping data of a provider.
+	GetMappingRequest struct {
+		// Key is the kind of mapping requested, such as "terraform".
+		Key string
+		// Provider is the name of the provider the converter wants a mapping for. It is
+		// empty if the converter accepts the mapping of any provider.
+		Provider string
+	}
+
+	// GetMappingResponse holds the mapping data of a provider.
+	GetMappingResponse struct {
+		// Provider is the name of the provider the mapping is for. It may only be empty
+		// if Data is empty.
+		Provider string
+		// Data is the mapping itself, in a format agreed on with the converter. It is
+		// empty if the provider has no mapping for the request.
+		Data []byte
+	}
+)
+
+func (p *provider) GetMapping(ctx context.Context, req *rpc.GetMappingRequest) (*rpc.GetMappingResponse, error) {
+	resp, err := p.client.GetMapping(p.ctx(ctx, ""), GetMappingRequest{
+		Key:      req.GetKey(),
+		Provider: req.GetProvider(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) > 0 && resp.Provider == "" {
+		return nil, fmt.Errorf("the %q mapping must name the provider it is for", req.GetKey())
+	}
+	return &rpc.GetMappingResponse{
+		Provider: resp.Provider,
+		Data:     resp.Data,
+	}, nil
+}
+
+// GetMappings lists the providers that [Provider.GetMapping] has a mapping for.
+//
+// A provider serves the mapping of a single provider, so this is the provider of the
+// mapping returned for an unnamed provider, if any.
+func (p *provider) GetMappings(ctx context.Context, req *rpc.GetMappingsRequest) (*rpc.GetMappingsResponse, error) {
+	resp, err := p.client.GetMapping(p.ctx(ctx, ""), GetMappingRequest{Key: req.GetKey()})
+	if err != nil {
+		return nil, err
+	}
+	var providers []string
+	if len(resp.Data) > 0 && resp.Provider != "" {
+		providers = append(providers, resp.Provider)
+	}
+	return &rpc.GetMappingsResponse{Providers: providers}, nil
+}
+
 func (p *provider) GetPluginInfo(context.Context, *emptypb.Empty) (*rpc.PluginInfo, error) {
 	return &rpc.PluginInfo{
 		Version: p.version,
@@ -0,0 +1,245 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultShutdownGracePeriod is how long a provider waits for in-flight operations when it
+// shuts down, unless [Provider.ShutdownGracePeriod] is set.
+const DefaultShutdownGracePeriod = 30 * time.Second
+
+// abandonTimeout is how long an operation still running at the end of the shutdown grace
+// period has to return once its context is canceled.
+const abandonTimeout = time.Second
+
+// WithShutdownGracePeriod returns a provider that waits up to period for in-flight
+// Create, Update and Delete operations when it shuts down. It does not mutate its
+// receiver.
+//
+// A provider shuts down when the engine calls Cancel or, when run with [RunProvider], when
+// the process receives SIGTERM. From then on new Create, Update and Delete requests are
+// refused. Operations still running at the end of the grace period are canceled and fail:
+// Update reports the resource's previous state as its partial state, Create reports the
+// ID and properties it returned once canceled as its partial state, if any, and Delete
+// reports that the operation may not have completed.
+func (d Provider) WithShutdownGracePeriod(period time.Duration) Provider {
+	d.ShutdownGracePeriod = period
+	return d
+}
+
+// WithOnShutdown returns a provider that calls f when it shuts down, after any function
+// already registered with [Provider.OnShutdown]. It does not mutate its receiver.
+func (d Provider) WithOnShutdown(f func(context.Context)) Provider {
+	prev := d.OnShutdown
+	if prev == nil {
+		d.OnShutdown = f
+		return d
+	}
+	d.OnShutdown = func(ctx context.Context) {
+		prev(ctx)
+		f(ctx)
+	}
+	return d
+}
+
+// shutdown tracks the operations in flight while a provider shuts down.
+type shutdown struct {
+	grace time.Duration
+	// onExit is [Provider.OnShutdown], called once by exit.
+	onExit   func(context.Context)
+	exitOnce sync.Once
+
+	m        sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	// expired is closed at the end of the grace period.
+	expired chan struct{}
+	// abandoned is set when an operation did not complete before the grace period ended.
+	abandoned atomic.Bool
+	// logs are the messages still being sent to the engine.
+	logs pendingLogs
+}
+
+func newShutdown(grace time.Duration) *shutdown {
+	if grace <= 0 {
+		grace = DefaultShutdownGracePeriod
+	}
+	return &shutdown{grace: grace, expired: make(chan struct{})}
+}
+
+// begin starts the shutdown, if it hasn't started already. New operations are refused,
+// and operations still in flight at the end of the grace period are abandoned.
+func (s *shutdown) begin() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.draining {
+		return
+	}
+	s.draining = true
+	time.AfterFunc(s.grace, func() { close(s.expired) })
+}
+
+// enter records an operation as in flight. done must be called once the operation has
+// returned.
+//
+// An error is returned if the provider is shutting down.
+func (s *shutdown) enter(operation string) (done func(), err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.draining {
+		return nil, status.Errorf(codes.Unavailable,
+			"the provider is shutting down and cannot start a new %s", operation)
+	}
+	s.inFlight.Add(1)
+	return s.inFlight.Done, nil
+}
+
+// wait blocks until every operation in flight has returned.
+func (s *shutdown) wait() { s.inFlight.Wait() }
+
+// exit calls s.onExit, if it hasn't been called already.
+func (s *shutdown) exit() {
+	s.exitOnce.Do(func() {
+		if s.onExit != nil {
+			s.onExit(context.Background())
+		}
+	})
+}
+
+// flush blocks until the messages being sent to the engine have been sent, or for at
+// most hostLogTimeout.
+func (s *shutdown) flush() { s.logs.wait(hostLogTimeout) }
+
+// pendingLogs counts the messages being sent to the engine.
+type pendingLogs struct {
+	m sync.Mutex
+	n int
+	// idle is closed when n drops to 0. It is nil when no one is waiting.
+	idle chan struct{}
+}
+
+// add records a message as being sent. done must be called once it has been sent.
+func (l *pendingLogs) add() (done func()) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	l.n++
+	return func() {
+		l.m.Lock()
+		defer l.m.Unlock()
+		l.n--
+		if l.n == 0 && l.idle != nil {
+			close(l.idle)
+			l.idle = nil
+		}
+	}
+}
+
+// wait blocks until no message is being sent, or for at most timeout.
+func (l *pendingLogs) wait(timeout time.Duration) {
+	l.m.Lock()
+	if l.n == 0 {
+		l.m.Unlock()
+		return
+	}
+	if l.idle == nil {
+		l.idle = make(chan struct{})
+	}
+	idle := l.idle
+	l.m.Unlock()
+	select {
+	case <-idle:
+	case <-time.After(timeout):
+	}
+}
+
+// onSignal shuts down when the process receives sig, and exits once the operations in
+// flight have returned and their messages have reached the engine. The exit status is
+// nonzero if an operation was abandoned.
+func (s *shutdown) onSignal(sig ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig...)
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-c:
+			s.begin()
+			s.wait()
+			s.exit()
+			s.flush()
+			if s.abandoned.Load() {
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case <-stopped:
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(stopped)
+	}
+}
+
+// terminationSignals are the signals that shut down a provider run with [RunProvider].
+var terminationSignals = []os.Signal{syscall.SIGTERM}
+
+// untilShutdown calls op, unless the shutdown grace period ends first. If it does, op's
+// context is canceled and op is given abandonTimeout to return.
+//
+// ok is false if op was abandoned: it did not return successfully before then. resp and
+// err are what op returned, if it returned at all.
+func untilShutdown[T any](
+	ctx context.Context, s *shutdown, op func(context.Context) (T, error),
+) (resp T, ok bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		resp T
+		err  error
+	}
+	c := make(chan result, 1)
+	go func() {
+		resp, err := op(ctx)
+		c <- result{resp, err}
+	}()
+	select {
+	case r := <-c:
+		return r.resp, true, r.err
+	case <-s.expired:
+	}
+	cancel()
+	select {
+	case r := <-c:
+		if r.err == nil {
+			return r.resp, true, nil
+		}
+		s.abandoned.Store(true)
+		return r.resp, false, r.err
+	case <-time.After(abandonTimeout):
+		s.abandoned.Store(true)
+		var zero T
+		return zero, false, nil
+	}
+}
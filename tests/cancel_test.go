@@ -21,8 +21,12 @@ import (
 
 	"github.com/blang/semver"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	pprovider "github.com/pulumi/pulumi/sdk/v3/go/pulumi/provider"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/integration"
@@ -152,8 +156,8 @@ func TestCancelTimeout(t *testing.T) {
 			Update: func(ctx context.Context, _ p.UpdateRequest) (p.UpdateResponse, error) {
 				return p.UpdateResponse{}, checkDeadline(ctx)
 			},
-			Delete: func(ctx context.Context, _ p.DeleteRequest) error {
-				return checkDeadline(ctx)
+			Delete: func(ctx context.Context, _ p.DeleteRequest) (p.DeleteResponse, error) {
+				return p.DeleteResponse{}, checkDeadline(ctx)
 			},
 		}))
 
@@ -175,9 +179,73 @@ func TestCancelTimeout(t *testing.T) {
 
 	t.Run("delete", func(t *testing.T) {
 		t.Parallel()
-		err := s.Delete(p.DeleteRequest{
+		_, err := s.Delete(p.DeleteRequest{
 			Timeout: 0.1,
 		})
 		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
 }
+
+// TestCancelConstruct checks that Cancel reaches the *pulumi.Context of a component.
+func TestCancelConstruct(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	provider := integration.NewServer("cancel", semver.MustParse("1.2.3"), cancel.Wrap(p.Provider{
+		Construct: func(ctx context.Context, req p.ConstructRequest) (p.ConstructResponse, error) {
+			return req.Construct(ctx, func(
+				ctx *pulumi.Context, _ pprovider.ConstructInputs, _ pulumi.ResourceOption,
+			) (pulumi.ComponentResource, error) {
+				close(started)
+				<-ctx.Context().Done()
+				return nil, ctx.Context().Err()
+			})
+		},
+	}))
+
+	go func() {
+		<-started
+		assert.NoError(t, provider.Cancel())
+	}()
+
+	_, err := provider.Construct(p.ConstructRequest{
+		URN: resource.NewURN("stack", "proj", "", "cancel:index:Component", "c"),
+		Construct: func(ctx context.Context, construct p.ConstructFunc) (p.ConstructResponse, error) {
+			pctx, err := pulumi.NewContext(ctx, pulumi.RunInfo{Project: "proj", Stack: "stack"})
+			require.NoError(t, err)
+			_, err = construct(pctx, pprovider.ConstructInputs{}, nil)
+			return p.ConstructResponse{}, err
+		},
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestCancelCall checks that Cancel reaches the *pulumi.Context of a call, which is
+// created before the request reaches the middleware.
+func TestCancelCall(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	server, err := p.RawServer("cancel", "1.2.3", cancel.Wrap(p.Provider{
+		Call: func(_ context.Context, req p.CallRequest) (p.CallResponse, error) {
+			close(started)
+			<-req.Context.Context().Done()
+			return p.CallResponse{}, req.Context.Context().Err()
+		},
+	}))(nil)
+	require.NoError(t, err)
+
+	go func() {
+		<-started
+		_, err := server.Cancel(context.Background(), &emptypb.Empty{})
+		assert.NoError(t, err)
+	}()
+
+	_, err = server.Call(context.Background(), &pulumirpc.CallRequest{
+		Tok:                 "cancel:index:Component/method",
+		Stack:               "stack",
+		Project:             "proj",
+		AcceptsOutputValues: true,
+	})
+	assert.ErrorContains(t, err, context.Canceled.Error())
+}
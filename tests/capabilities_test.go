@@ -0,0 +1,108 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	pContext "github.com/pulumi/pulumi-go-provider/middleware/context"
+)
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	configure := func(t *testing.T, provider p.Provider) *pulumirpc.ConfigureResponse {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+		resp, err := s.Configure(context.Background(), &pulumirpc.ConfigureRequest{})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		resp := configure(t, p.Provider{})
+		assert.True(t, resp.AcceptSecrets)
+		assert.True(t, resp.AcceptResources)
+		assert.True(t, resp.AcceptOutputs)
+		assert.True(t, resp.SupportsPreview)
+		assert.False(t, resp.SupportsAutonamingConfiguration)
+	})
+
+	t.Run("opt-out", func(t *testing.T) {
+		t.Parallel()
+		capabilities := p.DefaultCapabilities()
+		capabilities.AcceptOutputs = false
+		capabilities.SupportsPreview = false
+
+		// Capabilities survive middleware that rebuilds the provider.
+		provider := pContext.Wrap(p.Provider{}.WithCapabilities(capabilities),
+			func(ctx context.Context) context.Context { return ctx })
+
+		resp := configure(t, provider)
+		assert.True(t, resp.AcceptSecrets)
+		assert.True(t, resp.AcceptResources)
+		assert.False(t, resp.AcceptOutputs)
+		assert.False(t, resp.SupportsPreview)
+		assert.False(t, resp.SupportsAutonamingConfiguration)
+	})
+}
+
+func TestNegotiatedRunInfo(t *testing.T) {
+	t.Parallel()
+
+	capabilities := p.DefaultCapabilities()
+	capabilities.AcceptOutputs = false
+	var info p.RunInfo
+	provider := p.Provider{
+		Invoke: func(ctx context.Context, _ p.InvokeRequest) (p.InvokeResponse, error) {
+			info = p.GetRunInfo(ctx)
+			return p.InvokeResponse{}, nil
+		},
+	}.WithCapabilities(capabilities)
+
+	s, err := p.RawServer("test", "1.0.0", provider)(nil)
+	require.NoError(t, err)
+	invoke := func(t *testing.T) {
+		_, err := s.Invoke(context.Background(), &pulumirpc.InvokeRequest{Tok: "test:index:fn"})
+		require.NoError(t, err)
+	}
+
+	invoke(t)
+	assert.False(t, info.Configured)
+
+	_, err = s.Configure(context.Background(), &pulumirpc.ConfigureRequest{
+		AcceptSecrets:   false,
+		AcceptResources: true,
+	})
+	require.NoError(t, err)
+	invoke(t)
+	assert.True(t, info.Configured)
+	assert.Equal(t, "test", info.PackageName)
+	assert.False(t, info.Capabilities.AcceptSecrets)
+	assert.True(t, info.Capabilities.AcceptResources)
+	assert.False(t, info.Capabilities.AcceptOutputs)
+	assert.True(t, info.Capabilities.SupportsPreview)
+	assert.Equal(t, p.MarshalOptions{
+		KeepSecrets:   false,
+		KeepResources: true,
+	}, info.MarshalOptions)
+}
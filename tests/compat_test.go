@@ -0,0 +1,179 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+// TestEngineCompatibility checks that requests from engines which predate a protocol
+// feature the framework relies on either fall back to what the engine supports, or fail
+// with the CLI version to upgrade to.
+func TestEngineCompatibility(t *testing.T) {
+	t.Parallel()
+
+	server := func(t *testing.T, provider p.Provider) pulumirpc.ResourceProviderServer {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+		return s
+	}
+
+	assertUnsupported := func(t *testing.T, err error, msg string) {
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, msg, status.Convert(err).Message())
+	}
+
+	t.Run("construct", func(t *testing.T) {
+		t.Parallel()
+		var constructed bool
+		provider := p.Provider{
+			Construct: func(context.Context, p.ConstructRequest) (p.ConstructResponse, error) {
+				constructed = true
+				return p.ConstructResponse{}, nil
+			},
+		}
+		_, err := server(t, provider).Construct(context.Background(), &pulumirpc.ConstructRequest{
+			Type: "test:index:Component",
+			Name: "c",
+		})
+		require.NoError(t, err, "an engine without output values is sent plain values")
+		assert.True(t, constructed)
+	})
+
+	t.Run("call", func(t *testing.T) {
+		t.Parallel()
+		provider := p.Provider{
+			Call: func(context.Context, p.CallRequest) (p.CallResponse, error) {
+				return p.CallResponse{Return: resource.PropertyMap{
+					"out": resource.NewOutputProperty(resource.Output{
+						Element: resource.NewStringProperty("v"),
+						Known:   true,
+					}),
+				}}, nil
+			},
+		}
+		resp, err := server(t, provider).Call(context.Background(), &pulumirpc.CallRequest{
+			Tok: "test:index:Component/method",
+		})
+		require.NoError(t, err, "an engine without output values is sent plain values")
+		assert.Equal(t, "v", resp.GetReturn().GetFields()["out"].GetStringValue())
+	})
+
+	t.Run("parameterize", func(t *testing.T) {
+		t.Parallel()
+		_, err := server(t, p.Provider{}).Parameterize(context.Background(), &pulumirpc.ParameterizeRequest{})
+		assertUnsupported(t, err,
+			"parameterized providers requires Pulumi CLI >= v3.121.0; please upgrade the Pulumi CLI")
+	})
+
+	t.Run("configure", func(t *testing.T) {
+		t.Parallel()
+		const msg = "secret values requires Pulumi CLI >= v2.0.0; please upgrade the Pulumi CLI"
+
+		handler := newRecordingHandler()
+		s := server(t, p.Provider{}.WithLogHandler(handler))
+
+		_, err := s.Configure(context.Background(), &pulumirpc.ConfigureRequest{AcceptSecrets: true})
+		require.NoError(t, err)
+		assert.NotContains(t, handler.records, msg)
+
+		_, err = s.Configure(context.Background(), &pulumirpc.ConfigureRequest{})
+		require.NoError(t, err, "an engine without secrets is warned, not rejected")
+		handler.m.Lock()
+		assert.Equal(t, "WARN", handler.records[msg]["level"])
+		delete(handler.records, msg)
+		handler.m.Unlock()
+
+		_, err = s.Configure(context.Background(), &pulumirpc.ConfigureRequest{})
+		require.NoError(t, err)
+		assert.NotContains(t, handler.records, msg, "the warning is only logged once")
+	})
+}
+
+// TestMinimumPulumiVersion checks that a provider's minimum CLI version is recorded in its
+// schema, and reported to engines known to be older.
+func TestMinimumPulumiVersion(t *testing.T) {
+	t.Parallel()
+
+	provider := p.Provider{
+		GetSchema: func(context.Context, p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+			return p.GetSchemaResponse{Schema: `{"name":"test","language":{"go":{"importBasePath":"x"}}}`}, nil
+		},
+	}.WithMinimumPulumiVersion("v3.120.0")
+
+	server := func(t *testing.T, provider p.Provider) pulumirpc.ResourceProviderServer {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("schema", func(t *testing.T) {
+		t.Parallel()
+		resp, err := server(t, provider).GetSchema(context.Background(), &pulumirpc.GetSchemaRequest{})
+		require.NoError(t, err)
+
+		var spec struct {
+			Name     string                     `json:"name"`
+			Language map[string]json.RawMessage `json:"language"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(resp.Schema), &spec))
+		assert.Equal(t, "test", spec.Name)
+		assert.JSONEq(t, `{"importBasePath":"x"}`, string(spec.Language["go"]))
+
+		var minimum p.MinimumPulumiVersionSpec
+		require.NoError(t, json.Unmarshal(spec.Language[p.MinimumPulumiVersionLanguage], &minimum))
+		assert.Equal(t, p.MinimumPulumiVersionSpec{MinimumVersion: "3.120.0"}, minimum)
+	})
+
+	t.Run("older engine", func(t *testing.T) {
+		t.Parallel()
+		const msg = "test requires Pulumi CLI >= v3.120.0; please upgrade the Pulumi CLI"
+		s := server(t, provider)
+
+		_, err := s.Configure(context.Background(), &pulumirpc.ConfigureRequest{})
+		require.Error(t, err)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.Equal(t, msg, status.Convert(err).Message())
+
+		_, err = s.Call(context.Background(), &pulumirpc.CallRequest{Tok: "test:index:Component/method"})
+		assert.Equal(t, msg, status.Convert(err).Message())
+	})
+
+	t.Run("feature newer than minimum", func(t *testing.T) {
+		t.Parallel()
+		_, err := server(t, provider).Parameterize(context.Background(), &pulumirpc.ParameterizeRequest{})
+		assert.Equal(t,
+			"parameterized providers requires Pulumi CLI >= v3.121.0; please upgrade the Pulumi CLI",
+			status.Convert(err).Message())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := p.RawServer("test", "1.0.0", p.Provider{}.WithMinimumPulumiVersion("latest"))(nil)
+		assert.ErrorContains(t, err, `invalid minimum Pulumi version "latest"`)
+	})
+}
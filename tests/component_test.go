@@ -16,6 +16,7 @@ package tests
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/blang/semver"
@@ -104,3 +105,62 @@ const componentSchema = `{
         }
     }
 }`
+
+type randomString struct {
+	pulumi.CustomResourceState
+	Result pulumi.StringOutput `pulumi:"result"`
+}
+
+type Pair struct {
+	pulumi.ResourceState
+	Joined pulumi.StringOutput `pulumi:"joined"`
+}
+
+func (*Pair) Construct(ctx *pulumi.Context, name string, typ string, inputs FooArgs, opts pulumi.ResourceOption) (*Pair, error) {
+	comp := &Pair{}
+	err := ctx.RegisterComponentResource(typ, name, comp, opts)
+	if err != nil {
+		return nil, err
+	}
+	var results [2]pulumi.StringOutput
+	for i := range results {
+		var r randomString
+		err := ctx.RegisterResource("random:index/randomString:RandomString",
+			fmt.Sprintf("%s-%d", name, i), pulumi.Map{"length": pulumi.Int(8)},
+			&r, pulumi.Parent(comp))
+		if err != nil {
+			return nil, err
+		}
+		results[i] = r.Result
+	}
+	comp.Joined = pulumi.Sprintf("%s-%s", results[0], results[1])
+	return comp, nil
+}
+
+func TestComponentWithMocks(t *testing.T) {
+	t.Parallel()
+
+	mocks := &integration.MockResourceMonitor{}
+	var joined string
+	err := integration.RunComponent(func(ctx *pulumi.Context) error {
+		pair, err := (&Pair{}).Construct(ctx, "pair", "foo:tests:Pair", FooArgs{}, nil)
+		if err != nil {
+			return err
+		}
+		pair.Joined.ApplyT(func(s string) string {
+			joined = s
+			return s
+		})
+		return nil
+	}, mocks)
+	require.NoError(t, err)
+
+	registered := mocks.Registered()
+	require.Len(t, registered, 3)
+	assert.Equal(t, "foo:tests:Pair", registered[0].TypeToken)
+	assert.Equal(t, "random:index/randomString:RandomString", registered[1].TypeToken)
+	assert.Equal(t, "pair-0", registered[1].Name)
+
+	require.Len(t, joined, 17)
+	assert.NotEqual(t, joined[:8], joined[9:], "each random string should be distinct")
+}
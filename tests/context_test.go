@@ -19,7 +19,9 @@ import (
 	"testing"
 
 	"github.com/blang/semver"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	p "github.com/pulumi/pulumi-go-provider"
 	"github.com/pulumi/pulumi-go-provider/integration"
@@ -59,3 +61,88 @@ func TestContextCancel(t *testing.T) {
 		assert.True(t, wasCalled)
 	})
 }
+
+func TestContextDecorator(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+	var seen []string
+	s := integration.NewServer("test", semver.Version{Major: 1},
+		p.Provider{
+			Configure: func(ctx context.Context, _ p.ConfigureRequest) error {
+				assert.Equal(t, "Configure", ctx.Value(key{}))
+				return nil
+			},
+			Create: func(ctx context.Context, _ p.CreateRequest) (p.CreateResponse, error) {
+				assert.Equal(t, "Create", ctx.Value(key{}))
+				return p.CreateResponse{ID: "id"}, nil
+			},
+		}.WithContextDecorator(func(ctx context.Context, rpcName string) context.Context {
+			seen = append(seen, rpcName)
+			return context.WithValue(ctx, key{}, rpcName)
+		}),
+	)
+
+	assert.NoError(t, s.Configure(p.ConfigureRequest{}))
+	_, err := s.Create(p.CreateRequest{})
+	assert.NoError(t, err)
+
+	// Methods that were not implemented are not decorated.
+	_, err = s.Read(p.ReadRequest{})
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"Configure", "Create"}, seen)
+}
+
+func TestStackMetadata(t *testing.T) {
+	t.Parallel()
+
+	s := integration.NewServer("test", semver.Version{Major: 1},
+		p.Provider{
+			Create: func(ctx context.Context, _ p.CreateRequest) (p.CreateResponse, error) {
+				assert.Equal(t, "dev", p.GetStack(ctx))
+				assert.Equal(t, "my-project", p.GetProject(ctx))
+				return p.CreateResponse{ID: "id"}, nil
+			},
+			Configure: func(ctx context.Context, _ p.ConfigureRequest) error {
+				assert.Empty(t, p.GetStack(ctx))
+				assert.Empty(t, p.GetProject(ctx))
+				assert.Empty(t, p.GetOrganization(ctx))
+				return nil
+			},
+		},
+	)
+
+	assert.NoError(t, s.Configure(p.ConfigureRequest{}))
+	_, err := s.Create(p.CreateRequest{
+		Urn: "urn:pulumi:dev::my-project::test:index:Resource::name",
+	})
+	assert.NoError(t, err)
+}
+
+func TestStackMetadataInvoke(t *testing.T) {
+	t.Parallel()
+
+	var stack, project string
+	s, err := p.RawServer("test", "1.0.0", p.Provider{
+		CheckConfig: func(_ context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			return p.CheckResponse{Inputs: req.News}, nil
+		},
+		Invoke: func(ctx context.Context, _ p.InvokeRequest) (p.InvokeResponse, error) {
+			stack, project = p.GetStack(ctx), p.GetProject(ctx)
+			return p.InvokeResponse{}, nil
+		},
+	}.WithDefaults())(nil)
+	require.NoError(t, err)
+
+	// Invoke requests carry no URN, so the stack is taken from the provider's own URN.
+	_, err = s.CheckConfig(context.Background(), &pulumirpc.CheckRequest{
+		Urn: "urn:pulumi:dev::my-project::pulumi:providers:test::default",
+	})
+	require.NoError(t, err)
+
+	_, err = s.Invoke(context.Background(), &pulumirpc.InvokeRequest{Tok: "test:index:fn"})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", stack)
+	assert.Equal(t, "my-project", project)
+}
@@ -0,0 +1,124 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+func TestDeadline(t *testing.T) {
+	t.Parallel()
+
+	var deadline time.Time
+	var hasDeadline bool
+	provider := p.Provider{
+		Create: func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			deadline, hasDeadline = p.Deadline(ctx)
+			return p.CreateResponse{ID: "id"}, nil
+		},
+	}
+	urn := resource.NewURN("stack", "proj", "", "test:index:Res", "name")
+
+	t.Run("grpc", func(t *testing.T) {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+
+		start := time.Now()
+		_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{Urn: string(urn), Timeout: 60})
+		require.NoError(t, err)
+		require.True(t, hasDeadline)
+		assert.WithinRange(t, deadline, start.Add(time.Minute), time.Now().Add(time.Minute))
+
+		_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{Urn: string(urn)})
+		require.NoError(t, err)
+		assert.False(t, hasDeadline, "a timeout of 0 means no deadline")
+	})
+
+	t.Run("context", func(t *testing.T) {
+		// The earlier of the context deadline and the request timeout wins.
+		ctxDeadline := time.Now().Add(time.Second)
+		ctx, cancel := context.WithDeadline(context.Background(), ctxDeadline)
+		defer cancel()
+		s := integration.NewServerWithContext(ctx, "test", semver.MustParse("1.0.0"), provider)
+		_, err := s.Create(p.CreateRequest{Urn: urn, Timeout: 60})
+		require.NoError(t, err)
+		require.True(t, hasDeadline)
+		assert.Equal(t, ctxDeadline, deadline)
+	})
+}
+
+func TestOnCancel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		called := make(chan struct{})
+		stop := p.OnCancel(ctx, func() { close(called) })
+
+		cancel()
+		select {
+		case <-called:
+		case <-time.After(10 * time.Second):
+			t.Fatal("fn was not called on cancellation")
+		}
+		assert.False(t, stop(), "fn has already been called")
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		stop := p.OnCancel(ctx, func() { t.Error("fn called after stop") })
+
+		assert.True(t, stop())
+		assert.False(t, stop(), "stop is idempotent")
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		called := make(chan struct{})
+		provider := p.Provider{
+			Delete: func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+				stop := p.OnCancel(ctx, func() { close(called) })
+				defer stop()
+				select {
+				case <-called:
+					return p.DeleteResponse{}, nil
+				case <-time.After(10 * time.Second):
+					return p.DeleteResponse{}, context.DeadlineExceeded
+				}
+			},
+		}
+		s := integration.NewServer("test", semver.MustParse("1.0.0"), provider)
+		_, err := s.Delete(p.DeleteRequest{
+			Urn:     resource.NewURN("stack", "proj", "", "test:index:Res", "name"),
+			Timeout: 0.01,
+		})
+		assert.NoError(t, err, "fn is called when the request times out")
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestDetailedDiff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("paths", func(t *testing.T) {
+		t.Parallel()
+		diff := p.NewDetailedDiff().
+			Update("spec", "replicas").
+			Add("spec", "containers", 0, "image").
+			Delete("metadata", "labels", "app.kubernetes.io/name").
+			Update("metadata", "annotations", `say "hi"`).
+			Update("root key").
+			Build()
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"spec.replicas":                             {Kind: p.Update},
+			"spec.containers[0].image":                  {Kind: p.Add},
+			`metadata.labels["app.kubernetes.io/name"]`: {Kind: p.Delete},
+			`metadata.annotations["say \"hi\""]`:        {Kind: p.Update},
+			`["root key"]`:                              {Kind: p.Update},
+		}, diff)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		diff := p.NewDetailedDiff().
+			Add("a").Replace("a").
+			Delete("b").Replace("b").
+			Replace("c").
+			Build()
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"a": {Kind: p.AddReplace},
+			"b": {Kind: p.DeleteReplace},
+			"c": {Kind: p.UpdateReplace},
+		}, diff)
+	})
+
+	t.Run("object diff", func(t *testing.T) {
+		t.Parallel()
+		olds := resource.NewPropertyMapFromMap(map[string]any{
+			"name": "a",
+			"tags": map[string]any{"env": "dev", "team.name": "x"},
+			"old":  true,
+		})
+		news := resource.NewPropertyMapFromMap(map[string]any{
+			"name":  "b",
+			"tags":  map[string]any{"env": "prod", "team.name": "x"},
+			"added": []any{1},
+		})
+		resp := p.NewDetailedDiff().FromObjectDiff(olds, news).Replace("name").Response()
+		assert.True(t, resp.HasChanges)
+		assert.Equal(t, map[string]p.PropertyDiff{
+			"name":     {Kind: p.UpdateReplace},
+			"tags.env": {Kind: p.Update},
+			"old":      {Kind: p.Delete},
+			"added":    {Kind: p.Add},
+		}, resp.DetailedDiff)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		m := resource.PropertyMap{"name": resource.NewStringProperty("a")}
+		resp := p.NewDetailedDiff().FromObjectDiff(m, m).Response()
+		assert.False(t, resp.HasChanges)
+		assert.Empty(t, resp.DetailedDiff)
+	})
+
+	t.Run("invalid segment", func(t *testing.T) {
+		t.Parallel()
+		assert.Panics(t, func() { p.NewDetailedDiff().Update("a", 1.5) })
+		assert.Panics(t, func() { p.NewDetailedDiff().Update() })
+	})
+}
+
+func TestChangedOutputs(t *testing.T) {
+	t.Parallel()
+
+	diff := func(t *testing.T, resp p.DiffResponse) *pulumirpc.DiffResponse {
+		s, err := p.RawServer("test", "1.0.0", p.Provider{
+			Diff: func(context.Context, p.DiffRequest) (p.DiffResponse, error) { return resp, nil },
+		})(nil)
+		require.NoError(t, err)
+		r, err := s.Diff(context.Background(), &pulumirpc.DiffRequest{Urn: "urn:pulumi:stack::project::test:index:Res::r"})
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		t.Parallel()
+		r := diff(t, p.DiffResponse{
+			HasChanges: true,
+			DetailedDiff: map[string]p.PropertyDiff{
+				"password": {Kind: p.Update, InputDiff: true},
+				"hash":     {Kind: p.UpdateReplace},
+			},
+			ChangedOutputs: []resource.PropertyKey{"saltedPassword", "hash"},
+		})
+		assert.ElementsMatch(t, []string{"password", "hash", "saltedPassword"}, r.Diffs)
+		assert.Equal(t, pulumirpc.PropertyDiff_UPDATE, r.DetailedDiff["saltedPassword"].Kind)
+		assert.False(t, r.DetailedDiff["saltedPassword"].InputDiff)
+		// Properties already in the detailed diff are left as they are.
+		assert.Equal(t, pulumirpc.PropertyDiff_UPDATE_REPLACE, r.DetailedDiff["hash"].Kind)
+		assert.Equal(t, []string{"hash"}, r.Replaces)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		t.Parallel()
+		r := diff(t, p.DiffResponse{
+			ChangedOutputs: []resource.PropertyKey{"saltedPassword"},
+		})
+		assert.Equal(t, pulumirpc.DiffResponse_DIFF_NONE, r.Changes)
+		assert.Empty(t, r.Diffs)
+		assert.Empty(t, r.DetailedDiff)
+	})
+}
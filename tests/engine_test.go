@@ -0,0 +1,146 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+// TestSimulatedEngine checks that the down-grades made for engines with fewer features
+// than the current Pulumi CLI can be exercised with integration.Server.
+func TestSimulatedEngine(t *testing.T) {
+	t.Parallel()
+
+	// echo returns its inputs along with a secret, and records the inputs it was sent.
+	echo := func(inputs *resource.PropertyMap) p.Provider {
+		return p.Provider{
+			Create: func(_ context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+				*inputs = req.Properties
+				props := req.Properties.Copy()
+				props["password"] = resource.MakeSecret(resource.NewStringProperty("hunter2"))
+				return p.CreateResponse{ID: "id", Properties: props}, nil
+			},
+		}
+	}
+
+	create := func(t *testing.T, server integration.Server, req p.CreateRequest) p.CreateResponse {
+		require.NoError(t, server.Configure(p.ConfigureRequest{}))
+		req.Urn = resource.NewURN("stack", "proj", "", "test:index:Echo", "echo")
+		resp, err := server.Create(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("secrets", func(t *testing.T) {
+		t.Parallel()
+		var inputs resource.PropertyMap
+		server := integration.NewServer("test", semver.MustParse("1.0.0"), echo(&inputs))
+		resp := create(t, server, p.CreateRequest{})
+		assert.Equal(t, resource.MakeSecret(resource.NewStringProperty("hunter2")), resp.Properties["password"])
+	})
+
+	t.Run("without-secrets", func(t *testing.T) {
+		t.Parallel()
+		var inputs resource.PropertyMap
+		server := integration.NewServer("test", semver.MustParse("1.0.0"), echo(&inputs),
+			integration.WithoutSecrets())
+		resp := create(t, server, p.CreateRequest{})
+		assert.Equal(t, resource.NewStringProperty("hunter2"), resp.Properties["password"])
+	})
+
+	t.Run("without-outputs", func(t *testing.T) {
+		t.Parallel()
+		capabilities := p.DefaultCapabilities()
+		capabilities.AcceptOutputs = false
+		var inputs resource.PropertyMap
+		server := integration.NewServer("test", semver.MustParse("1.0.0"), echo(&inputs),
+			integration.WithConfigureResponse(capabilities))
+		create(t, server, p.CreateRequest{Properties: resource.PropertyMap{
+			"name": resource.NewOutputProperty(resource.Output{
+				Element: resource.NewStringProperty("echo"),
+				Known:   true,
+			}),
+		}})
+		assert.Equal(t, resource.PropertyMap{"name": resource.NewStringProperty("echo")}, inputs)
+	})
+
+	t.Run("without-preview", func(t *testing.T) {
+		t.Parallel()
+		capabilities := p.DefaultCapabilities()
+		capabilities.SupportsPreview = false
+		var inputs resource.PropertyMap
+		server := integration.NewServer("test", semver.MustParse("1.0.0"), echo(&inputs),
+			integration.WithConfigureResponse(capabilities))
+		resp := create(t, server, p.CreateRequest{
+			Properties: resource.PropertyMap{"name": resource.NewStringProperty("echo")},
+			Preview:    true,
+		})
+		assert.Nil(t, inputs, "Create should not be called during preview")
+		assert.Equal(t, p.CreateResponse{}, resp)
+
+		resp = create(t, server, p.CreateRequest{
+			Properties: resource.PropertyMap{"name": resource.NewStringProperty("echo")},
+		})
+		assert.Equal(t, "id", resp.ID)
+	})
+}
+
+func TestServerRunInfo(t *testing.T) {
+	t.Parallel()
+
+	var info p.RunInfo
+	provider := p.Provider{
+		Create: func(ctx context.Context, _ p.CreateRequest) (p.CreateResponse, error) {
+			info = p.GetRunInfo(ctx)
+			return p.CreateResponse{ID: "id"}, nil
+		},
+	}
+	create := func(t *testing.T, server integration.Server) p.RunInfo {
+		require.NoError(t, server.Configure(p.ConfigureRequest{}))
+		_, err := server.Create(p.CreateRequest{
+			Urn: resource.NewURN("stack", "proj", "", "test:index:Echo", "echo"),
+		})
+		require.NoError(t, err)
+		return info
+	}
+
+	t.Run("default", func(t *testing.T) {
+		info := create(t, integration.NewServer("test", semver.MustParse("1.0.0"), provider))
+		assert.True(t, info.Configured)
+		assert.Equal(t, p.DefaultCapabilities(), info.Capabilities)
+		assert.Equal(t, p.MarshalOptions{
+			KeepSecrets:      true,
+			KeepResources:    true,
+			KeepOutputValues: true,
+		}, info.MarshalOptions)
+	})
+
+	t.Run("without-secrets", func(t *testing.T) {
+		info := create(t, integration.NewServer("test", semver.MustParse("1.0.0"), provider,
+			integration.WithoutSecrets()))
+		assert.True(t, info.Configured)
+		assert.False(t, info.Capabilities.AcceptSecrets)
+		assert.False(t, info.MarshalOptions.KeepSecrets)
+	})
+}
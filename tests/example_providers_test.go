@@ -0,0 +1,130 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/testing/integration"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// examplesDir holds an example provider in each subdirectory. Examples with a consumer
+// subdirectory have a Pulumi program that uses the provider.
+const examplesDir = "../examples"
+
+// requireExamplesEnv is set by `make test_examples` to fail TestExampleProviders instead
+// of skipping it when the pulumi CLI is not installed.
+const requireExamplesEnv = "PULUMI_GO_PROVIDER_REQUIRE_EXAMPLES"
+
+// TestExampleProviders builds each example provider, checks that it serves a valid
+// schema and runs its consumer program against a local backend.
+//
+// This keeps the examples from drifting out of date with the library. It requires the
+// pulumi CLI, and is skipped in short mode. It is also skipped when the pulumi CLI is not
+// installed, unless requireExamplesEnv is set.
+func TestExampleProviders(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping example providers in short mode")
+	}
+	pulumi, err := exec.LookPath("pulumi")
+	if err != nil {
+		if os.Getenv(requireExamplesEnv) != "" {
+			t.Fatalf("%s is set, but the pulumi CLI is not installed: %v", requireExamplesEnv, err)
+		}
+		t.Skip("skipping example providers: the pulumi CLI is not installed")
+	}
+
+	entries, err := os.ReadDir(examplesDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(examplesDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "consumer", "Pulumi.yaml")); err != nil {
+			continue
+		}
+		t.Run(entry.Name(), func(t *testing.T) {
+			t.Parallel()
+			testExampleProvider(t, pulumi, dir)
+		})
+	}
+}
+
+func testExampleProvider(t *testing.T, pulumi, dir string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	consumer := filepath.Join(dir, "consumer")
+	project, err := workspace.LoadProject(filepath.Join(consumer, "Pulumi.yaml"))
+	require.NoError(t, err)
+	var name string
+	if project.Plugins != nil {
+		for _, plugin := range project.Plugins.Providers {
+			if plugin.Path == ".." {
+				name = plugin.Name
+			}
+		}
+	}
+	require.NotEmpty(t, name, "the consumer of %s does not use the example provider", dir)
+
+	// The provider is built outside of the source tree, so the consumer is pointed at it
+	// once it has been copied for the test.
+	work := t.TempDir()
+	binary, err := filepath.Abs(filepath.Join(work, "pulumi-resource-"+name))
+	require.NoError(t, err)
+	build := exec.CommandContext(ctx, "go", "build", "-o", binary, ".")
+	build.Dir = dir
+	out, err := build.CombinedOutput()
+	require.NoError(t, err, "%s:\n%s", strings.Join(build.Args, " "), out)
+
+	t.Run("schema", func(t *testing.T) {
+		getSchema := exec.CommandContext(ctx, pulumi, "package", "get-schema", binary)
+		getSchema.Stderr = os.Stderr
+		out, err := getSchema.Output()
+		require.NoError(t, err, strings.Join(getSchema.Args, " "))
+
+		var spec pschema.PackageSpec
+		require.NoError(t, json.Unmarshal(out, &spec), "invalid schema")
+		assert.Equal(t, name, spec.Name)
+	})
+
+	t.Run("consumer", func(t *testing.T) {
+		integration.ProgramTest(t, &integration.ProgramTestOptions{
+			Dir:      consumer,
+			CloudURL: integration.MakeTempBackend(t),
+			PrePrepareProject: func(info *engine.Projinfo) error {
+				for i, plugin := range info.Proj.Plugins.Providers {
+					if plugin.Name == name {
+						info.Proj.Plugins.Providers[i].Path = work
+					}
+				}
+				return info.Proj.Save(filepath.Join(info.Root, "Pulumi.yaml"))
+			},
+		})
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+type ExampleShape string
+
+func (ExampleShape) Values() []infer.EnumValue[ExampleShape] {
+	return []infer.EnumValue[ExampleShape]{
+		{Name: "Round", Value: "round"},
+		{Name: "Square", Value: "square"},
+	}
+}
+
+type (
+	ExampleBox     struct{}
+	ExampleBoxArgs struct {
+		Label    string            `pulumi:"label"`
+		Count    int               `pulumi:"count"`
+		Shape    ExampleShape      `pulumi:"shape"`
+		Size     ExampleSize       `pulumi:"size"`
+		Tags     map[string]string `pulumi:"tags"`
+		Color    string            `pulumi:"color"`
+		Nickname *string           `pulumi:"nickname,optional"`
+	}
+	ExampleSize struct {
+		Width  float64 `pulumi:"width"`
+		Height *int    `pulumi:"height,optional"`
+	}
+)
+
+func (a *ExampleBoxArgs) Annotate(an infer.Annotator) {
+	an.SetDefault(&a.Color, "blue")
+}
+
+func (*ExampleBox) Create(
+	ctx context.Context, name string, inputs ExampleBoxArgs, preview bool,
+) (string, ExampleBoxArgs, error) {
+	return name, inputs, nil
+}
+
+func examplesProvider() integration.Server {
+	return integration.NewServer("test", semver.MustParse("1.0.0"), infer.Provider(infer.Options{
+		Resources: []infer.InferredResource{
+			infer.Resource[*ExampleBox, ExampleBoxArgs, ExampleBoxArgs](),
+		},
+	}))
+}
+
+func TestExamples(t *testing.T) {
+	t.Parallel()
+
+	examples, err := integration.Examples(examplesProvider())
+	require.NoError(t, err)
+	require.Len(t, examples, 1)
+
+	example := examples["test:tests:ExampleBox"]
+	assert.Equal(t, resource.PropertyMap{
+		"label": resource.NewStringProperty("example"),
+		"count": resource.NewNumberProperty(1),
+		"shape": resource.NewStringProperty("round"),
+		"size":  resource.NewObjectProperty(resource.PropertyMap{"width": resource.NewNumberProperty(1)}),
+		"tags": resource.NewObjectProperty(resource.PropertyMap{
+			"example": resource.NewStringProperty("example"),
+		}),
+		"color": resource.NewStringProperty("blue"),
+	}, example.Inputs)
+
+	program, err := example.YAML()
+	require.NoError(t, err)
+	assert.Equal(t, `name: example-examplebox
+runtime: yaml
+resources:
+    exampleBox:
+        type: test:tests:ExampleBox
+        properties:
+            color: blue
+            count: 1
+            label: example
+            shape: round
+            size:
+                width: 1
+            tags:
+                example: example
+`, program)
+}
+
+func TestRunExamples(t *testing.T) {
+	t.Parallel()
+
+	integration.RunExamples(t, examplesProvider())
+}
+
+func TestExamplesFromSchemaErrors(t *testing.T) {
+	t.Parallel()
+
+	examples, err := integration.ExamplesFromSchema(pschema.PackageSpec{
+		Resources: map[string]pschema.ResourceSpec{
+			"test:index:Ok": {
+				InputProperties: map[string]pschema.PropertySpec{
+					"name": {TypeSpec: pschema.TypeSpec{Type: "string"}},
+				},
+				RequiredInputs: []string{"name"},
+			},
+			"test:index:External": {
+				InputProperties: map[string]pschema.PropertySpec{
+					"password": {TypeSpec: pschema.TypeSpec{
+						Ref: "/random/v4.8.1/schema.json#/resources/random:index/randomPassword:RandomPassword",
+					}},
+				},
+				RequiredInputs: []string{"password"},
+			},
+			"test:index:Component": {IsComponent: true},
+		},
+	})
+	assert.ErrorContains(t, err, "test:index:External: password: cannot generate a value for")
+	assert.Equal(t, map[tokens.Type]integration.Example{
+		"test:index:Ok": {
+			Resource: "test:index:Ok",
+			Inputs:   resource.PropertyMap{"name": resource.NewStringProperty("example")},
+		},
+	}, examples)
+}
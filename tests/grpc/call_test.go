@@ -29,6 +29,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	p "github.com/pulumi/pulumi-go-provider"
+	pinteg "github.com/pulumi/pulumi-go-provider/integration"
 )
 
 func TestCallLifecycle(t *testing.T) {
@@ -44,7 +45,7 @@ func TestCallLifecycle(t *testing.T) {
 }
 
 func TestCall(t *testing.T) {
-	replay.Replay(t, callProvider(t), `{
+	replay.Replay(t, callProvider(t), pinteg.ExpandUnknowns(`{
     "method": "/pulumirpc.ResourceProvider/Call",
     "request": {
         "tok": "some-token",
@@ -56,7 +57,7 @@ func TestCall(t *testing.T) {
     },
     "response": {
 	"return": {
-	  "r1": "04da6b54-80e4-46f7-96ec-b56ff0331ba9"
+	  "r1": "<unknown>"
 	},
 	"returnDependencies": {
 	  "r1": {
@@ -69,7 +70,7 @@ func TestCall(t *testing.T) {
         "mode": "client",
         "name": "asset"
     }
-}`)
+}`))
 }
 
 func callProvider(t *testing.T) pulumirpc.ResourceProviderServer {
@@ -0,0 +1,349 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	pContext "github.com/pulumi/pulumi-go-provider/middleware/context"
+)
+
+// recordingHandler records the attributes of each message it handles, keyed by the
+// message.
+type recordingHandler struct {
+	m       *sync.Mutex
+	records map[string]map[string]string
+	attrs   []slog.Attr
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{m: new(sync.Mutex), records: map[string]map[string]string{}}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := map[string]string{"level": r.Level.String()}
+	for _, a := range h.attrs {
+		attrs[a.Key] = a.Value.String()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.records[r.Message] = attrs
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recordingHandler{m: h.m, records: h.records, attrs: append(slices.Clip(h.attrs), attrs...)}
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func TestLogHandler(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	provider := p.Provider{
+		Create: func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			p.GetLogger(ctx).Warningf("creating %s", req.Urn.Name())
+			p.GetLogger(ctx).Slog().With("attempt", 1).WithGroup("req").Debug("structured", "id", "abc")
+			return p.CreateResponse{ID: "id"}, nil
+		},
+		Invoke: func(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			p.GetLogger(ctx).Info("invoked")
+			return p.InvokeResponse{}, nil
+		},
+	}.WithLogHandler(handler)
+	// The handler survives middleware that rebuilds the provider.
+	provider = pContext.Wrap(provider, func(ctx context.Context) context.Context { return ctx })
+
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), provider)
+	urn := resource.NewURN("stack", "proj", "", "test:index:Res", "name")
+	_, err := server.Create(p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+	_, err = server.Invoke(p.InvokeRequest{Token: "test:index:getThing"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]map[string]string{
+		"creating name": {
+			"level": "WARN",
+			"urn":   string(urn),
+			"token": "test:index:Res",
+		},
+		"structured": {
+			"level":   "DEBUG",
+			"urn":     string(urn),
+			"token":   "test:index:Res",
+			"attempt": "1",
+			"req.id":  "abc",
+		},
+		"invoked": {
+			"level": "INFO",
+			"urn":   "",
+			"token": "test:index:getThing",
+		},
+	}, handler.records)
+}
+
+func TestLogHandlerRequestID(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	s, err := p.RawServer("test", "1.0.0", p.Provider{
+		Invoke: func(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			p.GetLogger(ctx).Info(string(req.Token))
+			return p.InvokeResponse{}, nil
+		},
+	}.WithLogHandler(handler))(nil)
+	require.NoError(t, err)
+
+	for _, tk := range []string{"test:index:first", "test:index:second"} {
+		_, err = s.Invoke(context.Background(), &pulumirpc.InvokeRequest{Tok: tk})
+		require.NoError(t, err)
+	}
+
+	first := handler.records["test:index:first"]["requestId"]
+	second := handler.records["test:index:second"]["requestId"]
+	assert.NotEmpty(t, first)
+	assert.NotEmpty(t, second)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, "test:index:second", handler.records["test:index:second"]["token"])
+}
+
+func TestCheckDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	provider := p.Provider{
+		Check: func(_ context.Context, req p.CheckRequest) (p.CheckResponse, error) {
+			return p.CheckResponse{
+				Inputs: req.News,
+				Diagnostics: []p.Diagnostic{
+					{Property: "force", Summary: "consider setting force=true"},
+					{
+						Severity: diag.Info,
+						Summary:  "the bucket is public",
+						Detail:   "Anyone can read its objects.",
+					},
+				},
+			}, nil
+		},
+	}.WithLogHandler(handler)
+
+	s, err := p.RawServer("test", "1.0.0", provider)(nil)
+	require.NoError(t, err)
+	urn := resource.NewURN("stack", "proj", "", "test:index:Bucket", "name")
+	resp, err := s.Check(context.Background(), &pulumirpc.CheckRequest{Urn: string(urn)})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetFailures())
+
+	assert.Equal(t, map[string]map[string]string{
+		`Property "force": consider setting force=true`: {
+			"level":     "WARN",
+			"urn":       string(urn),
+			"token":     "test:index:Bucket",
+			"requestId": "1",
+		},
+		"the bucket is public\nAnyone can read its objects.": {
+			"level":     "INFO",
+			"urn":       string(urn),
+			"token":     "test:index:Bucket",
+			"requestId": "1",
+		},
+	}, handler.records)
+}
+
+func TestDeleteResponseLogged(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	provider := p.Provider{
+		Delete: func(context.Context, p.DeleteRequest) (p.DeleteResponse, error) {
+			return p.DeleteResponse{
+				Retained:    true,
+				Diagnostics: []string{"the bucket was archived"},
+			}, nil
+		},
+	}.WithLogHandler(handler)
+
+	s, err := p.RawServer("test", "1.0.0", provider)(nil)
+	require.NoError(t, err)
+	urn := resource.NewURN("stack", "proj", "", "test:index:Bucket", "name")
+	_, err = s.Delete(context.Background(), &pulumirpc.DeleteRequest{Id: "b-1", Urn: string(urn)})
+	require.NoError(t, err)
+
+	attrs := map[string]string{
+		"level":     "WARN",
+		"urn":       string(urn),
+		"token":     "test:index:Bucket",
+		"requestId": "1",
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"the bucket was archived": attrs,
+		"b-1 was retained by the provider instead of being deleted; " +
+			"it still exists, but is no longer managed by Pulumi": attrs,
+	}, handler.records)
+}
+
+func TestLoggerScoped(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	provider := p.Provider{
+		Create: func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+			dns := p.GetLogger(ctx).Scoped("dns")
+			dns.Infof("%d of %d records ready", 2, 3)
+			dns.Scoped("wait").Slog().Warn("slow", "seconds", 30)
+			return p.CreateResponse{ID: "id"}, nil
+		},
+	}.WithLogHandler(handler)
+
+	server := integration.NewServer("test", semver.MustParse("1.0.0"), provider)
+	urn := resource.NewURN("stack", "proj", "", "test:index:Res", "name")
+	_, err := server.Create(p.CreateRequest{Urn: urn})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]map[string]string{
+		"dns: 2 of 3 records ready": {
+			"level": "INFO",
+			"urn":   string(urn),
+			"token": "test:index:Res",
+		},
+		"dns: wait: slow": {
+			"level":   "WARN",
+			"urn":     string(urn),
+			"token":   "test:index:Res",
+			"seconds": "30",
+		},
+	}, handler.records)
+}
+
+// TestLogURN checks that messages logged while constructing a component or calling a
+// method are associated with the resource, using the URN the engine gives it.
+func TestLogURN(t *testing.T) {
+	t.Parallel()
+
+	handler := newRecordingHandler()
+	s, err := p.RawServer("test", "1.0.0", p.Provider{
+		Construct: func(ctx context.Context, req p.ConstructRequest) (p.ConstructResponse, error) {
+			p.GetLogger(ctx).Info("constructing " + req.URN.Name())
+			return p.ConstructResponse{}, nil
+		},
+		Call: func(ctx context.Context, req p.CallRequest) (p.CallResponse, error) {
+			p.GetLogger(ctx).Info("calling")
+			return p.CallResponse{}, nil
+		},
+	}.WithLogHandler(handler))(nil)
+	require.NoError(t, err)
+
+	construct := func(name, parent string) {
+		_, err := s.Construct(context.Background(), &pulumirpc.ConstructRequest{
+			Stack:               "dev",
+			Project:             "proj",
+			Type:                "test:index:Component",
+			Name:                name,
+			Parent:              parent,
+			AcceptsOutputValues: true,
+		})
+		require.NoError(t, err)
+	}
+	construct("root", "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev")
+	construct("child", "urn:pulumi:dev::proj::my:index:Outer$my:index:Middle::middle")
+	assert.Equal(t, "urn:pulumi:dev::proj::test:index:Component::root",
+		handler.records["constructing root"]["urn"])
+	assert.Equal(t, "urn:pulumi:dev::proj::my:index:Outer$my:index:Middle$test:index:Component::child",
+		handler.records["constructing child"]["urn"])
+
+	self := resource.URN("urn:pulumi:dev::proj::test:index:Component::root")
+	args, err := plugin.MarshalProperties(resource.PropertyMap{
+		"__self__": resource.MakeComponentResourceReference(self, ""),
+	}, plugin.MarshalOptions{KeepResources: true})
+	require.NoError(t, err)
+	_, err = s.Call(context.Background(), &pulumirpc.CallRequest{
+		Tok:                 "test:index:Component/method",
+		Args:                args,
+		Stack:               "dev",
+		Project:             "proj",
+		AcceptsOutputValues: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"level":     "INFO",
+		"urn":       string(self),
+		"token":     "test:index:Component/method",
+		"requestId": "3",
+	}, handler.records["calling"])
+}
+
+// TestFrameworkLogEnv sets an environment variable, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestFrameworkLogEnv(t *testing.T) {
+	var enabled []bool
+	provider := p.Provider{
+		Invoke: func(ctx context.Context, req p.InvokeRequest) (p.InvokeResponse, error) {
+			enabled = []bool{
+				p.FrameworkLogEnabled(ctx, p.FrameworkLogEncoding),
+				p.FrameworkLogEnabled(ctx, p.FrameworkLogMigrations),
+				p.FrameworkLogEnabled(ctx, p.FrameworkLogDependencies),
+			}
+			return p.InvokeResponse{}, nil
+		},
+	}
+	invoke := func(t *testing.T, provider p.Provider) []bool {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+		_, err = s.Invoke(context.Background(), &pulumirpc.InvokeRequest{Tok: "test:index:fn"})
+		require.NoError(t, err)
+		return enabled
+	}
+
+	assert.Equal(t, []bool{false, false, false}, invoke(t, provider))
+	assert.Equal(t, []bool{false, true, false},
+		invoke(t, provider.WithFrameworkLog(p.FrameworkLogMigrations)))
+
+	t.Setenv(p.FrameworkLogEnvVar, "encoding, dependencies")
+	assert.Equal(t, []bool{true, false, true}, invoke(t, provider))
+	assert.Equal(t, []bool{true, true, true},
+		invoke(t, provider.WithFrameworkLog(p.FrameworkLogMigrations)))
+
+	t.Setenv(p.FrameworkLogEnvVar, "all")
+	assert.Equal(t, []bool{true, true, true}, invoke(t, provider))
+
+	// Unknown topics are ignored, with a warning.
+	t.Setenv(p.FrameworkLogEnvVar, "secrets,encoding")
+	handler := newRecordingHandler()
+	assert.Equal(t, []bool{true, false, false}, invoke(t, provider.WithLogHandler(handler)))
+	assert.Equal(t, "WARN", handler.records[`PULUMI_PROVIDER_FRAMEWORK_LOG: ignoring unknown topics ["secrets"], `+
+		`expected "all" or one of ["encoding" "migrations" "dependencies"]`]["level"])
+}
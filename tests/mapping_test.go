@@ -0,0 +1,100 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"testing"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+)
+
+func TestGetMapping(t *testing.T) {
+	t.Parallel()
+
+	server := func(t *testing.T, provider p.Provider) pulumirpc.ResourceProviderServer {
+		s, err := p.RawServer("test", "1.0.0", provider)(nil)
+		require.NoError(t, err)
+		return s
+	}
+	ctx := context.Background()
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		s := server(t, p.Provider{})
+
+		resp, err := s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "terraform"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Provider)
+		assert.Empty(t, resp.Data)
+
+		mappings, err := s.GetMappings(ctx, &pulumirpc.GetMappingsRequest{Key: "terraform"})
+		require.NoError(t, err)
+		assert.Empty(t, mappings.Providers)
+	})
+
+	t.Run("infer", func(t *testing.T) {
+		t.Parallel()
+		s := server(t, infer.Provider(infer.Options{
+			Mappings: map[string]infer.Mapping{
+				"terraform": {Provider: "random", Data: []byte(`{"name":"random"}`)},
+				"other":     {Data: []byte("other")},
+			},
+		}))
+
+		resp, err := s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "terraform"})
+		require.NoError(t, err)
+		assert.Equal(t, "random", resp.Provider)
+		assert.Equal(t, `{"name":"random"}`, string(resp.Data))
+
+		resp, err = s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "terraform", Provider: "random"})
+		require.NoError(t, err)
+		assert.Equal(t, "random", resp.Provider)
+
+		// Requests for another provider or kind of mapping are answered with no mapping.
+		resp, err = s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "terraform", Provider: "aws"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data)
+		resp, err = s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "crossplane"})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Data)
+
+		// Without a provider, the mapping is for the provider being served.
+		resp, err = s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "other"})
+		require.NoError(t, err)
+		assert.Equal(t, "test", resp.Provider)
+
+		mappings, err := s.GetMappings(ctx, &pulumirpc.GetMappingsRequest{Key: "terraform"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"random"}, mappings.Providers)
+	})
+
+	t.Run("missing provider", func(t *testing.T) {
+		t.Parallel()
+		s := server(t, p.Provider{
+			GetMapping: func(context.Context, p.GetMappingRequest) (p.GetMappingResponse, error) {
+				return p.GetMappingResponse{Data: []byte("data")}, nil
+			},
+		})
+
+		_, err := s.GetMapping(ctx, &pulumirpc.GetMappingRequest{Key: "terraform"})
+		assert.ErrorContains(t, err, `the "terraform" mapping must name the provider it is for`)
+	})
+}
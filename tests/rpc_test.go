@@ -581,7 +581,7 @@ func TestRPCDelete(t *testing.T) {
 		props, expectedProps := exampleOlds()
 		wasCalled := false
 
-		err := rpcServer(rpcTestServer{
+		_, err := rpcServer(rpcTestServer{
 			onDelete: func(_ context.Context, req *rpc.DeleteRequest) (*emptypb.Empty, error) {
 				assert.Equal(t, "my-id", req.GetId())
 				assert.Equal(t, "my-urn", req.GetUrn())
@@ -603,7 +603,7 @@ func TestRPCDelete(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		t.Parallel()
-		err := rpcServer(rpcTestServer{
+		_, err := rpcServer(rpcTestServer{
 			onDelete: func(_ context.Context, req *rpc.DeleteRequest) (*emptypb.Empty, error) {
 				return &emptypb.Empty{}, fmt.Errorf("my-error")
 			},
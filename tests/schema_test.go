@@ -16,7 +16,10 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/blang/semver"
@@ -90,3 +93,44 @@ func TestMergeSchema(t *testing.T) {
     }
 }`, string(bytes.Bytes()))
 }
+
+func TestSchemaSnapshot(t *testing.T) {
+	t.Parallel()
+
+	prov := schema.Wrap(p.Provider{}, schema.Options{
+		Metadata: schema.Metadata{
+			DisplayName: "Snapshot",
+			Keywords:    []string{"b", "a"},
+		},
+		Resources: []schema.Resource{
+			&givenResource{"pkg:index:zed", "the last resource"},
+			&givenResource{"pkg:index:alpha", "the first resource"},
+		},
+	})
+
+	integration.SchemaSnapshot(t,
+		integration.NewServer("snapshot", semver.Version{Major: 1}, prov),
+		"testdata/schema-snapshot.json")
+}
+
+// TestSchemaSnapshotAccept sets an environment variable, so it must not run in parallel.
+//
+//nolint:paralleltest
+func TestSchemaSnapshotAccept(t *testing.T) {
+	t.Setenv("PULUMI_ACCEPT", "true")
+	path := filepath.Join(t.TempDir(), "testdata", "schema.json")
+
+	integration.SchemaSnapshot(t,
+		integration.NewServer("snapshot", semver.Version{Major: 1}, p.Provider{
+			GetSchema: func(context.Context, p.GetSchemaRequest) (p.GetSchemaResponse, error) {
+				return p.GetSchemaResponse{Schema: `{"name":"snapshot","description":"Use <b> & <i>"}`}, nil
+			},
+		}),
+		path)
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	// HTML characters are written as is, rather than escaped.
+	assert.Equal(t, "{\n    \"description\": \"Use <b> & <i>\",\n    \"name\": \"snapshot\"\n}\n",
+		string(written))
+}
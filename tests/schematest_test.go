@@ -0,0 +1,159 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver"
+	pschema "github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/stretchr/testify/assert"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/integration"
+	"github.com/pulumi/pulumi-go-provider/integration/schematest"
+	"github.com/pulumi/pulumi-go-provider/middleware/schema"
+)
+
+type fileResource struct{}
+
+func (fileResource) GetToken() (tokens.Type, error) { return "file:index:File", nil }
+
+func (fileResource) GetSchema(schema.RegisterDerivativeType) (pschema.ResourceSpec, error) {
+	return pschema.ResourceSpec{
+		ObjectTypeSpec: pschema.ObjectTypeSpec{
+			Properties: map[string]pschema.PropertySpec{
+				"content": {TypeSpec: pschema.TypeSpec{Type: "string"}, Description: "The file's content."},
+				"size":    {TypeSpec: pschema.TypeSpec{Type: "integer"}},
+			},
+			Required: []string{"content"},
+		},
+		InputProperties: map[string]pschema.PropertySpec{
+			"content": {TypeSpec: pschema.TypeSpec{Type: "string"}},
+			"path": {
+				TypeSpec:         pschema.TypeSpec{Type: "string"},
+				ReplaceOnChanges: true,
+				Default:          "out.txt",
+			},
+		},
+		RequiredInputs: []string{"content"},
+	}, nil
+}
+
+type readFunction struct{}
+
+func (readFunction) GetToken() (tokens.Type, error) { return "file:index:read", nil }
+
+func (readFunction) GetSchema(schema.RegisterDerivativeType) (pschema.FunctionSpec, error) {
+	return pschema.FunctionSpec{
+		Inputs: &pschema.ObjectTypeSpec{
+			Properties: map[string]pschema.PropertySpec{"path": {TypeSpec: pschema.TypeSpec{Type: "string"}}},
+			Required:   []string{"path"},
+		},
+		Outputs: &pschema.ObjectTypeSpec{
+			Properties: map[string]pschema.PropertySpec{
+				"token": {TypeSpec: pschema.TypeSpec{Type: "string"}, Secret: true},
+			},
+		},
+	}, nil
+}
+
+// recorder is a [schematest.TestingT] that records failures instead of failing the test.
+type recorder struct{ errors []string }
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func fileSchema(t *testing.T) pschema.PackageSpec {
+	prov := schema.Wrap(p.Provider{}, schema.Options{
+		Resources: []schema.Resource{fileResource{}},
+		Invokes:   []schema.Function{readFunction{}},
+	})
+	return schematest.Spec(t, integration.NewServer("file", semver.Version{Major: 1}, prov))
+}
+
+func TestSchemaAssertions(t *testing.T) {
+	t.Parallel()
+
+	spec := fileSchema(t)
+	tests := []struct {
+		name       string
+		input      bool
+		token      string
+		property   string
+		assertions []schematest.Assertion
+	}{
+		{"output", false, "file:index:File", "content",
+			[]schematest.Assertion{schematest.Required, schematest.TypeString, schematest.HasDescription}},
+		{"optional output", false, "file:index:File", "size",
+			[]schematest.Assertion{schematest.Optional, schematest.TypeInteger}},
+		{"input", true, "file:index:File", "path", []schematest.Assertion{
+			schematest.Optional, schematest.ReplaceOnChanges, schematest.Default("out.txt"),
+		}},
+		{"function argument", true, "file:index:read", "path",
+			[]schematest.Assertion{schematest.Required, schematest.TypeString}},
+		{"function result", false, "file:index:read", "token",
+			[]schematest.Assertion{schematest.Secret, schematest.Optional}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if tt.input {
+				schematest.AssertInputProperty(t, spec, tt.token, tt.property, tt.assertions...)
+			} else {
+				schematest.AssertProperty(t, spec, tt.token, tt.property, tt.assertions...)
+			}
+		})
+	}
+}
+
+func TestSchemaAssertionFailures(t *testing.T) {
+	t.Parallel()
+
+	spec := fileSchema(t)
+	tests := []struct {
+		name       string
+		token      string
+		property   string
+		assertions []schematest.Assertion
+		expected   []string
+	}{
+		{"missing token", "file:index:Dir", "content", nil,
+			[]string{"file:index:Dir is not in the schema"}},
+		{"missing property", "file:index:File", "mode", nil,
+			[]string{`file:index:File has no property "mode"`}},
+		{"each failure is reported", "file:index:File", "size",
+			[]schematest.Assertion{schematest.Required, schematest.TypeString, schematest.HasDescription},
+			[]string{
+				"file:index:File.size: expected property to be required",
+				`file:index:File.size: expected type "string", got "integer"`,
+				"file:index:File.size: expected property to have a description",
+			}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var r recorder
+			ok := schematest.AssertProperty(&r, spec, tt.token, tt.property, tt.assertions...)
+			assert.False(t, ok)
+			assert.Equal(t, tt.expected, r.errors)
+		})
+	}
+}
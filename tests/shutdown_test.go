@@ -0,0 +1,194 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	pprovider "github.com/pulumi/pulumi/pkg/v3/resource/provider"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil/rpcerror"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	p "github.com/pulumi/pulumi-go-provider"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	t.Parallel()
+
+	const urn = "urn:pulumi:dev::dev::test:index:Res::r"
+
+	// server returns a provider whose Create and Update block until release is called,
+	// along with a channel that receives a value when each of them starts.
+	server := func(t *testing.T) (s pulumirpc.ResourceProviderServer, started chan struct{}, release func()) {
+		unblock := make(chan struct{})
+		release = sync.OnceFunc(func() { close(unblock) })
+		started = make(chan struct{}, 1)
+		block := func(ctx context.Context) error {
+			started <- struct{}{}
+			select {
+			case <-unblock:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		s, err := p.RawServer("test", "1.0.0", p.Provider{
+			Cancel: func(context.Context) error { return nil },
+			Create: func(ctx context.Context, req p.CreateRequest) (p.CreateResponse, error) {
+				return p.CreateResponse{ID: "id", Properties: req.Properties}, block(ctx)
+			},
+			Update: func(ctx context.Context, req p.UpdateRequest) (p.UpdateResponse, error) {
+				return p.UpdateResponse{Properties: req.News}, block(ctx)
+			},
+		}.WithShutdownGracePeriod(50*time.Millisecond))(nil)
+		require.NoError(t, err)
+		t.Cleanup(release)
+		return s, started, release
+	}
+
+	cancel := func(t *testing.T, s pulumirpc.ResourceProviderServer, started chan struct{}) {
+		<-started
+		_, err := s.Cancel(context.Background(), nil)
+		assert.NoError(t, err)
+	}
+
+	t.Run("refuses new operations", func(t *testing.T) {
+		t.Parallel()
+		s, _, _ := server(t)
+		_, err := s.Cancel(context.Background(), nil)
+		require.NoError(t, err)
+
+		_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{Urn: urn})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		_, err = s.Delete(context.Background(), &pulumirpc.DeleteRequest{Urn: urn, Id: "id"})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("waits for in-flight operations", func(t *testing.T) {
+		t.Parallel()
+		s, started, release := server(t)
+		go func() {
+			cancel(t, s, started)
+			release()
+		}()
+		resp, err := s.Create(context.Background(), &pulumirpc.CreateRequest{Urn: urn})
+		require.NoError(t, err)
+		assert.Equal(t, "id", resp.GetId())
+	})
+
+	t.Run("partial create", func(t *testing.T) {
+		t.Parallel()
+		s, started, _ := server(t)
+		go cancel(t, s, started)
+
+		props, err := structpb.NewStruct(map[string]any{"value": "new"})
+		require.NoError(t, err)
+		_, err = s.Create(context.Background(), &pulumirpc.CreateRequest{
+			Urn:        urn,
+			Properties: props,
+		})
+		rpcError, ok := rpcerror.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.Aborted, rpcError.Code())
+		require.Len(t, rpcError.Details(), 1)
+		initFailed, ok := rpcError.Details()[0].(*pulumirpc.ErrorResourceInitFailed)
+		require.True(t, ok)
+		assert.Equal(t, "id", initFailed.GetId())
+		assert.Equal(t, props.AsMap(), initFailed.GetProperties().AsMap())
+	})
+
+	t.Run("partial update", func(t *testing.T) {
+		t.Parallel()
+		s, started, _ := server(t)
+		go cancel(t, s, started)
+
+		olds, err := structpb.NewStruct(map[string]any{"value": "old"})
+		require.NoError(t, err)
+		_, err = s.Update(context.Background(), &pulumirpc.UpdateRequest{
+			Urn:  urn,
+			Id:   "id",
+			Olds: olds,
+		})
+		rpcError, ok := rpcerror.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.Aborted, rpcError.Code())
+		require.Len(t, rpcError.Details(), 1)
+		initFailed, ok := rpcError.Details()[0].(*pulumirpc.ErrorResourceInitFailed)
+		require.True(t, ok)
+		assert.Equal(t, "id", initFailed.GetId())
+		assert.Equal(t, olds.AsMap(), initFailed.GetProperties().AsMap())
+	})
+}
+
+// logEngine is an engine that records the messages logged to it.
+type logEngine struct {
+	pulumirpc.UnimplementedEngineServer
+	logs chan string
+}
+
+func (e *logEngine) Log(_ context.Context, req *pulumirpc.LogRequest) (*emptypb.Empty, error) {
+	e.logs <- req.GetMessage()
+	return &emptypb.Empty{}, nil
+}
+
+// TestLogAfterCancel checks that a message logged after its request is canceled still
+// reaches the engine.
+func TestLogAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	engine := &logEngine{logs: make(chan string, 1)}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	pulumirpc.RegisterEngineServer(srv, engine)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	host, err := pprovider.NewHostClient(lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = host.Close() })
+
+	s, err := p.RawServer("test", "1.0.0", p.Provider{
+		Delete: func(ctx context.Context, req p.DeleteRequest) (p.DeleteResponse, error) {
+			<-ctx.Done()
+			p.GetLogger(ctx).Error("the delete was interrupted")
+			return p.DeleteResponse{}, ctx.Err()
+		},
+	})(host)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = s.Delete(ctx, &pulumirpc.DeleteRequest{Urn: "urn:pulumi:dev::dev::test:index:Res::r", Id: "id"})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	select {
+	case msg := <-engine.logs:
+		assert.Equal(t, "the delete was interrupted", msg)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the message never reached the engine")
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	p "github.com/pulumi/pulumi-go-provider"
+	"github.com/pulumi/pulumi-go-provider/infer"
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+// Note records the calls made to it, so tests can see how a lifecycle was carried out.
+type (
+	Note struct {
+		calls *[]string
+	}
+	NoteArgs struct {
+		Folder string `pulumi:"folder" provider:"replaceOnChanges"`
+		Text   string `pulumi:"text"`
+	}
+	NoteState struct {
+		NoteArgs
+		Revision int `pulumi:"revision"`
+	}
+)
+
+func (n Note) Create(ctx context.Context, name string, inputs NoteArgs, preview bool) (string, NoteState, error) {
+	id := inputs.Folder + "/" + name
+	if !preview {
+		*n.calls = append(*n.calls, "create "+id)
+	}
+	return id, NoteState{inputs, 1}, nil
+}
+
+func (n Note) Update(
+	ctx context.Context, id string, olds NoteState, news NoteArgs, preview bool,
+) (NoteState, error) {
+	if !preview {
+		*n.calls = append(*n.calls, "update "+id)
+	}
+	return NoteState{news, olds.Revision + 1}, nil
+}
+
+func (n Note) Delete(ctx context.Context, id string, props NoteState) error {
+	*n.calls = append(*n.calls, fmt.Sprintf("delete %s@%d", id, props.Revision))
+	return nil
+}
+
+func TestStatefulServer(t *testing.T) {
+	t.Parallel()
+
+	var calls []string
+	server := integration.NewStatefulServer("test", semver.MustParse("1.0.0"),
+		infer.Provider(infer.Options{
+			Resources: []infer.InferredResource{
+				infer.ResourceInstance[Note, NoteArgs, NoteState](Note{calls: &calls}),
+			},
+		}))
+	urn := resource.NewURN("stack", "proj", "", "test:tests:Note", "todo")
+	note := func(folder, text string) resource.PropertyMap {
+		return resource.PropertyMap{
+			"folder": resource.NewStringProperty(folder),
+			"text":   resource.NewStringProperty(text),
+		}
+	}
+
+	r, err := server.Up(urn, note("home", "milk"))
+	require.NoError(t, err)
+	assert.Equal(t, "home/todo", r.ID)
+	assert.Equal(t, 1.0, r.Outputs["revision"].NumberValue())
+
+	r, err = server.Up(urn, note("home", "eggs"))
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, r.Outputs["revision"].NumberValue())
+
+	_, err = server.Up(urn, note("home", "eggs"))
+	require.NoError(t, err, "an unchanged resource is left alone")
+
+	r, err = server.Up(urn, note("work", "eggs"))
+	require.NoError(t, err)
+	assert.Equal(t, "work/todo", r.ID)
+
+	renamed := resource.NewURN("stack", "proj", "", "test:tests:Note", "chores")
+	require.NoError(t, server.Rename(urn, renamed))
+	_, ok := server.Get(urn)
+	assert.False(t, ok)
+
+	// Requests for a recorded resource are filled in from its state.
+	diff, err := server.Diff(p.DiffRequest{Urn: renamed, News: note("work", "eggs")})
+	require.NoError(t, err)
+	assert.False(t, diff.HasChanges)
+
+	require.NoError(t, server.Destroy(renamed))
+	assert.Empty(t, server.Resources())
+
+	assert.Equal(t, []string{
+		"create home/todo",
+		"update home/todo",
+		"create work/todo",
+		"delete home/todo@2",
+		"delete work/todo@1",
+	}, calls)
+}
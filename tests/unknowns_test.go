@@ -0,0 +1,66 @@
+// Copyright 2024, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi-go-provider/integration"
+)
+
+func TestRenderUnknowns(t *testing.T) {
+	t.Parallel()
+
+	unknown := resource.NewProperty(integration.UnknownPlaceholder)
+	actual := integration.RenderUnknowns(resource.PropertyMap{
+		"computed": resource.MakeComputed(resource.NewProperty("")),
+		"sentinel": resource.NewProperty(plugin.UnknownStringValue),
+		"output":   resource.NewProperty(resource.Output{Known: false}),
+		"secret":   resource.MakeSecret(resource.MakeComputed(resource.NewProperty(""))),
+		"nested": resource.NewProperty(resource.PropertyMap{
+			"arr": resource.NewProperty([]resource.PropertyValue{
+				resource.NewProperty("known"),
+				resource.MakeComputed(resource.NewProperty("")),
+			}),
+		}),
+		"known": resource.NewProperty(3.0),
+	})
+
+	assert.Equal(t, resource.PropertyMap{
+		"computed": unknown,
+		"sentinel": unknown,
+		"output":   unknown,
+		"secret":   resource.MakeSecret(unknown),
+		"nested": resource.NewProperty(resource.PropertyMap{
+			"arr": resource.NewProperty([]resource.PropertyValue{
+				resource.NewProperty("known"),
+				unknown,
+			}),
+		}),
+		"known": resource.NewProperty(3.0),
+	}, actual)
+}
+
+func TestExpandUnknowns(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t,
+		`{"a": "`+plugin.UnknownStringValue+`", "b": "<unknown>-suffix"}`,
+		integration.ExpandUnknowns(`{"a": "<unknown>", "b": "<unknown>-suffix"}`))
+}